@@ -0,0 +1,110 @@
+// Command stackprobe checks that RPC endpoints serve the chain you think they
+// do: chain and network ID, genesis hash, fork header fields, and agreement
+// between endpoints a few blocks below their heads. It exits non-zero on any issue, so
+// it can gate a deploy.
+//
+// Endpoints are URLs, optionally prefixed with the expected chain:
+//
+//	go run ./01-stack/cmd/stackprobe -expect mainnet https://rpc-a.example https://rpc-b.example
+//	go run ./01-stack/cmd/stackprobe sepolia=http://127.0.0.1:8545 mainnet=wss://rpc.example/ws
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	stack "geth-edu/01-stack/exercise"
+)
+
+func main() {
+	expect := flag.String("expect", "", "chain endpoints without a chain= prefix must serve (empty: identify by chain id)")
+	timeout := flag.Duration("timeout", 10*time.Second, "per-endpoint timeout")
+	confirmations := flag.Uint64("confirmations", 2, "compare endpoints this many blocks below the lowest head")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: stackprobe [flags] [chain=]url...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	var targets []stack.Target
+	for _, arg := range flag.Args() {
+		chain, url := *expect, arg
+		if name, rest, ok := strings.Cut(arg, "="); ok && !strings.Contains(name, "://") {
+			chain, url = name, rest
+		}
+		dialCtx, dialCancel := context.WithTimeout(ctx, *timeout)
+		client, err := ethclient.DialContext(dialCtx, url)
+		dialCancel()
+		if err != nil {
+			log.Fatalf("dial %s: %v", url, err)
+		}
+		defer client.Close()
+		targets = append(targets, stack.Target{Name: url, Client: client, Expect: chain})
+	}
+
+	report, err := stack.Probe(ctx, targets, stack.ProbeConfig{Timeout: *timeout, Confirmations: *confirmations})
+	if err != nil {
+		log.Fatal(err)
+	}
+	printReport(report)
+	if !report.OK() {
+		os.Exit(1)
+	}
+}
+
+func printReport(r *stack.ProbeReport) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ENDPOINT\tCHAIN\tCHAIN ID\tHEAD\tFORKS\tSTATUS")
+	for _, e := range r.Endpoints {
+		head, forks := "-", "-"
+		if e.Head != nil {
+			head = e.Head.Number.String()
+		}
+		if len(e.Forks) > 0 {
+			names := make([]string, len(e.Forks))
+			for i, f := range e.Forks {
+				names[i] = string(f)
+			}
+			forks = strings.Join(names, ",")
+		}
+		status := "ok"
+		if !e.OK() {
+			status = fmt.Sprintf("%d issue(s)", len(e.Issues))
+		}
+		chain := e.Chain
+		if chain == "" {
+			chain = "?"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\n", e.Name, chain, e.ChainID, head, forks, status)
+	}
+	tw.Flush()
+
+	for _, e := range r.Endpoints {
+		for _, i := range e.Issues {
+			fmt.Printf("%s: %s\n", e.Name, i)
+		}
+	}
+	for _, h := range r.Heights {
+		verdict := "agree"
+		if !h.Agree {
+			verdict = "DISAGREE"
+		}
+		fmt.Printf("%s block %d: %d endpoints %s\n", h.Chain, h.Number, len(h.Hashes), verdict)
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"errors"
 	"math/big"
+	"strings"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
 )
 
 type mockRPC struct {
@@ -101,3 +103,316 @@ func TestRunErrors(t *testing.T) {
 		t.Fatalf("expected header error")
 	}
 }
+
+// chainRPC serves a fake chain: a linked list of headers up to head.
+type chainRPC struct {
+	chainID   uint64
+	networkID uint64
+	headers   []*types.Header
+	err       error
+}
+
+func (c *chainRPC) ChainID(ctx context.Context) (*big.Int, error) {
+	return new(big.Int).SetUint64(c.chainID), c.err
+}
+
+func (c *chainRPC) NetworkID(ctx context.Context) (*big.Int, error) {
+	return new(big.Int).SetUint64(c.networkID), nil
+}
+
+func (c *chainRPC) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if number == nil {
+		return c.headers[len(c.headers)-1], nil
+	}
+	if n := number.Uint64(); n < uint64(len(c.headers)) {
+		return c.headers[n], nil
+	}
+	return nil, errors.New("header not found")
+}
+
+// buildChain returns headers 0..head carrying the fields of forks. Chains
+// built with the same seed share their blocks.
+func buildChain(seed byte, head int, forks ...Fork) []*types.Header {
+	headers := make([]*types.Header, head+1)
+	parent := common.Hash{}
+	for i := range headers {
+		h := &types.Header{Number: big.NewInt(int64(i)), ParentHash: parent, Extra: []byte{seed}, Difficulty: big.NewInt(0)}
+		for _, f := range forks {
+			switch f {
+			case ForkLondon:
+				h.BaseFee = big.NewInt(7)
+			case ForkShanghai:
+				h.WithdrawalsHash = &types.EmptyWithdrawalsHash
+			case ForkCancun:
+				zero := uint64(0)
+				h.BlobGasUsed, h.ExcessBlobGas = &zero, &zero
+			}
+		}
+		headers[i] = h
+		parent = h.Hash()
+	}
+	return headers
+}
+
+// forkAt returns a copy of headers that follows a different branch from
+// block n on.
+func forkAt(headers []*types.Header, n int) []*types.Header {
+	out := append([]*types.Header(nil), headers[:n]...)
+	parent := headers[n-1].Hash()
+	for _, h := range headers[n:] {
+		cp := types.CopyHeader(h)
+		cp.ParentHash, cp.Extra = parent, []byte("reorg")
+		out = append(out, cp)
+		parent = cp.Hash()
+	}
+	return out
+}
+
+var allForks = []Fork{ForkLondon, ForkShanghai, ForkCancun}
+
+func testRegistry(t *testing.T) (*Registry, []*types.Header) {
+	t.Helper()
+	headers := buildChain(1, 12, allForks...)
+	reg, err := NewRegistry(
+		Chain{Name: "devnet", ChainID: 1337, Genesis: headers[0].Hash(), Forks: allForks},
+		Chain{Name: "othernet", ChainID: 4242, NetworkID: 42},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reg, headers
+}
+
+func issueKinds(r EndpointReport) string {
+	var kinds []string
+	for _, i := range r.Issues {
+		kinds = append(kinds, i.Kind)
+	}
+	return strings.Join(kinds, ",")
+}
+
+func TestProbeHealthyEndpointsAgree(t *testing.T) {
+	reg, headers := testRegistry(t)
+	targets := []Target{
+		{Name: "a", Expect: "devnet", Client: &chainRPC{chainID: 1337, networkID: 1337, headers: headers}},
+		{Name: "b", Client: &chainRPC{chainID: 1337, networkID: 1337, headers: headers[:11]}},
+	}
+	report, err := Probe(context.Background(), targets, ProbeConfig{Registry: reg})
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+	if !report.OK() {
+		t.Fatalf("issues: %+v", report.Endpoints)
+	}
+	b := report.Endpoints[1]
+	if b.Chain != "devnet" || b.Head.Number.Uint64() != 10 || len(b.Forks) != 3 || b.Genesis != headers[0].Hash() {
+		t.Fatalf("endpoint b = %+v", b)
+	}
+	if len(report.Heights) != 1 {
+		t.Fatalf("heights = %+v", report.Heights)
+	}
+	// Two confirmations below b's head of 10.
+	if h := report.Heights[0]; h.Chain != "devnet" || h.Number != 8 || !h.Agree || h.Hashes["a"] != headers[8].Hash() || h.Hashes["b"] != headers[8].Hash() {
+		t.Fatalf("height check = %+v", h)
+	}
+
+	// The report holds copies, not the client's headers.
+	b.Head.Number.SetUint64(99)
+	if headers[10].Number.Uint64() != 10 {
+		t.Fatalf("head was not copied")
+	}
+}
+
+func TestProbeWrongChain(t *testing.T) {
+	report, err := Probe(context.Background(), []Target{
+		{Name: "staging", Expect: "mainnet", Client: &chainRPC{chainID: 11155111, networkID: 11155111, headers: buildChain(1, 3)}},
+	}, ProbeConfig{})
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+	r := report.Endpoints[0]
+	if issueKinds(r) != IssueWrongChain {
+		t.Fatalf("issues = %v, want only wrong-chain", r.Issues)
+	}
+	if want := "chain id 11155111 (sepolia), want 1 (mainnet)"; r.Issues[0].Detail != want {
+		t.Fatalf("detail = %q, want %q", r.Issues[0].Detail, want)
+	}
+	if r.Chain != "" || len(report.Heights) != 0 {
+		t.Fatalf("wrong-chain endpoint was compared: %+v", report)
+	}
+}
+
+func TestProbeUnknownChain(t *testing.T) {
+	reg, _ := testRegistry(t)
+	report, err := Probe(context.Background(), []Target{
+		{Name: "x", Client: &chainRPC{chainID: 31337, networkID: 31337, headers: buildChain(2, 3)}},
+	}, ProbeConfig{Registry: reg})
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+	if got := issueKinds(report.Endpoints[0]); got != IssueUnknownChain {
+		t.Fatalf("issues = %s", got)
+	}
+}
+
+func TestProbeGenesisNetworkIDAndForks(t *testing.T) {
+	reg, headers := testRegistry(t)
+	// Same chain ID, different genesis: a local devnet reusing the ID.
+	impostor := buildChain(9, 12, allForks...)
+	// Right chain, but net_version is off and the node predates Cancun.
+	stale := append([]*types.Header(nil), headers...)
+	oldHead := types.CopyHeader(headers[12])
+	oldHead.BlobGasUsed, oldHead.ExcessBlobGas = nil, nil
+	stale[12] = oldHead
+
+	report, err := Probe(context.Background(), []Target{
+		{Name: "impostor", Expect: "devnet", Client: &chainRPC{chainID: 1337, networkID: 1337, headers: impostor}},
+		{Name: "stale", Expect: "devnet", Client: &chainRPC{chainID: 1337, networkID: 1, headers: stale}},
+		{Name: "other", Expect: "othernet", Client: &chainRPC{chainID: 4242, networkID: 4242, headers: buildChain(3, 2)}},
+	}, ProbeConfig{Registry: reg})
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+	if got := issueKinds(report.Endpoints[0]); got != IssueGenesis {
+		t.Fatalf("impostor issues = %v", report.Endpoints[0].Issues)
+	}
+	stRep := report.Endpoints[1]
+	if got := issueKinds(stRep); got != IssueNetworkID+","+IssueMissingFork {
+		t.Fatalf("stale issues = %v", stRep.Issues)
+	}
+	if !strings.HasPrefix(stRep.Issues[1].Detail, "cancun expected") || len(stRep.Forks) != 2 {
+		t.Fatalf("stale = %+v", stRep)
+	}
+	// othernet expects network ID 42 and has no genesis or forks configured.
+	if got := issueKinds(report.Endpoints[2]); got != IssueNetworkID {
+		t.Fatalf("other issues = %v", report.Endpoints[2].Issues)
+	}
+	// The impostor is off the chain; the stale node alone leaves nothing to compare.
+	if len(report.Heights) != 0 {
+		t.Fatalf("heights = %+v", report.Heights)
+	}
+}
+
+func TestProbeDisagreementAtSameHeight(t *testing.T) {
+	reg, headers := testRegistry(t)
+	reorged := forkAt(headers, 8)
+	report, err := Probe(context.Background(), []Target{
+		{Name: "a", Client: &chainRPC{chainID: 1337, networkID: 1337, headers: headers}},
+		{Name: "b", Client: &chainRPC{chainID: 1337, networkID: 1337, headers: reorged[:11]}},
+		{Name: "c", Client: &chainRPC{chainID: 1337, networkID: 1337, headers: headers[:12]}},
+	}, ProbeConfig{Registry: reg})
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+	h := report.Heights[0]
+	if h.Number != 8 || h.Agree {
+		t.Fatalf("height check = %+v", h)
+	}
+	if h.Hashes["b"] != reorged[8].Hash() || h.Hashes["a"] != headers[8].Hash() {
+		t.Fatalf("hashes = %v", h.Hashes)
+	}
+	if issueKinds(report.Endpoints[0]) != "" || issueKinds(report.Endpoints[2]) != "" {
+		t.Fatalf("majority flagged: %+v", report.Endpoints)
+	}
+	b := report.Endpoints[1]
+	if issueKinds(b) != IssueDisagreement || !strings.Contains(b.Issues[0].Detail, "2 of 3 endpoints") {
+		t.Fatalf("b issues = %v", b.Issues)
+	}
+}
+
+func TestProbeTipReorgIsNotDisagreement(t *testing.T) {
+	reg, headers := testRegistry(t)
+	// b replaced block 12 and c blocks 11-12: a shallow reorg still
+	// settling at the tip, not a node on another chain.
+	report, err := Probe(context.Background(), []Target{
+		{Name: "a", Client: &chainRPC{chainID: 1337, networkID: 1337, headers: headers}},
+		{Name: "b", Client: &chainRPC{chainID: 1337, networkID: 1337, headers: forkAt(headers, 12)}},
+		{Name: "c", Client: &chainRPC{chainID: 1337, networkID: 1337, headers: forkAt(headers, 11)}},
+	}, ProbeConfig{Registry: reg})
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+	if !report.OK() {
+		t.Fatalf("issues: %+v", report.Endpoints)
+	}
+	if h := report.Heights[0]; h.Number != 10 || !h.Agree {
+		t.Fatalf("height check = %+v", h)
+	}
+
+	// One confirmation compares block 11, which c replaced.
+	report, err = Probe(context.Background(), []Target{
+		{Name: "a", Client: &chainRPC{chainID: 1337, networkID: 1337, headers: headers}},
+		{Name: "c", Client: &chainRPC{chainID: 1337, networkID: 1337, headers: forkAt(headers, 11)}},
+	}, ProbeConfig{Registry: reg, Confirmations: 1})
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+	if h := report.Heights[0]; h.Number != 11 || h.Agree {
+		t.Fatalf("one confirmation: height check = %+v", h)
+	}
+}
+
+func TestProbeUnreachable(t *testing.T) {
+	reg, headers := testRegistry(t)
+	report, err := Probe(context.Background(), []Target{
+		{Name: "down", Client: &chainRPC{err: errors.New("connection refused")}},
+		{Name: "up", Client: &chainRPC{chainID: 1337, networkID: 1337, headers: headers}},
+	}, ProbeConfig{Registry: reg})
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+	down := report.Endpoints[0]
+	if issueKinds(down) != IssueUnreachable || !strings.Contains(down.Issues[0].Detail, "connection refused") {
+		t.Fatalf("down issues = %v", down.Issues)
+	}
+	if report.OK() || !report.Endpoints[1].OK() {
+		t.Fatalf("report = %+v", report)
+	}
+}
+
+func TestProbeValidation(t *testing.T) {
+	client := &chainRPC{chainID: 1, networkID: 1, headers: buildChain(1, 1)}
+	cases := map[string][]Target{
+		"no targets":     nil,
+		"nil client":     {{Name: "a"}},
+		"duplicate name": {{Name: "a", Client: client}, {Name: "a", Client: client}},
+		"unknown chain":  {{Name: "a", Client: client, Expect: "ropsten"}},
+	}
+	for name, targets := range cases {
+		if _, err := Probe(context.Background(), targets, ProbeConfig{}); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	reg := DefaultRegistry()
+	mainnet, ok := reg.ByChainID(1)
+	if !ok || mainnet.Name != "mainnet" || mainnet.Genesis != params.MainnetGenesisHash {
+		t.Fatalf("mainnet = %+v", mainnet)
+	}
+	if c, ok := reg.ByName("holesky"); !ok || c.ChainID != 17000 {
+		t.Fatalf("holesky = %+v", c)
+	}
+	var names []string
+	for _, c := range reg.Chains() {
+		names = append(names, c.Name)
+	}
+	if strings.Join(names, ",") != "mainnet,holesky,sepolia" {
+		t.Fatalf("chains = %v", names)
+	}
+
+	bad := map[string][]Chain{
+		"empty":          nil,
+		"no name":        {{ChainID: 1}},
+		"no chain id":    {{Name: "a"}},
+		"duplicate name": {{Name: "a", ChainID: 1}, {Name: "a", ChainID: 2}},
+		"duplicate id":   {{Name: "a", ChainID: 1}, {Name: "b", ChainID: 1}},
+		"unknown fork":   {{Name: "a", ChainID: 1, Forks: []Fork{"prague"}}},
+	}
+	for name, chains := range bad {
+		if _, err := NewRegistry(chains...); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+package exercise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Issue kinds reported by Probe.
+const (
+	IssueUnreachable  = "unreachable"   // an RPC call failed or returned nothing
+	IssueUnknownChain = "unknown-chain" // chain ID not in the registry
+	IssueWrongChain   = "wrong-chain"   // chain ID differs from the expected chain
+	IssueNetworkID    = "network-id"    // net_version differs from the registry
+	IssueGenesis      = "genesis"       // block 0 hash differs from the registry
+	IssueMissingFork  = "missing-fork"  // head lacks a fork's header fields
+	IssueDisagreement = "disagreement"  // different block than the other endpoints at the same height
+)
+
+// Issue is one problem found with an endpoint.
+type Issue struct {
+	Kind   string
+	Detail string
+}
+
+func (i Issue) String() string { return i.Kind + ": " + i.Detail }
+
+// Target is an endpoint to probe.
+type Target struct {
+	Name   string // unique label, e.g. the URL
+	Client RPCClient
+	// Expect is the registry name of the chain the endpoint should serve.
+	// Empty identifies the chain by its chain ID instead.
+	Expect string
+}
+
+// EndpointReport is what one endpoint said about itself.
+type EndpointReport struct {
+	Name      string
+	Chain     string // registry chain it was checked against, empty if none
+	ChainID   uint64
+	NetworkID uint64
+	Genesis   common.Hash
+	Head      *types.Header
+	Forks     []Fork // forks detected at Head
+	Issues    []Issue
+}
+
+// OK reports whether no issues were found.
+func (r *EndpointReport) OK() bool { return len(r.Issues) == 0 }
+
+func (r *EndpointReport) add(kind, format string, args ...interface{}) {
+	r.Issues = append(r.Issues, Issue{Kind: kind, Detail: fmt.Sprintf(format, args...)})
+}
+
+// onChain reports whether the endpoint is known to serve r.Chain, which
+// makes its blocks comparable with other endpoints on the same chain.
+func (r *EndpointReport) onChain() bool {
+	if r.Chain == "" {
+		return false
+	}
+	for _, i := range r.Issues {
+		if i.Kind == IssueUnreachable || i.Kind == IssueGenesis {
+			return false
+		}
+	}
+	return true
+}
+
+// HeightCheck compares the block endpoints on one chain return for the
+// same height: Confirmations below the lowest head among them. All of them
+// should have it, and it is deep enough that a healthy node that just saw a
+// different tip than the others has settled on the same block.
+type HeightCheck struct {
+	Chain  string
+	Number uint64
+	Hashes map[string]common.Hash // by endpoint name
+	Agree  bool
+}
+
+// ProbeReport is the outcome of Probe.
+type ProbeReport struct {
+	Endpoints []EndpointReport // in target order
+	Heights   []HeightCheck    // one per chain served by two or more endpoints
+}
+
+// OK reports whether every endpoint passed.
+func (r *ProbeReport) OK() bool {
+	for i := range r.Endpoints {
+		if !r.Endpoints[i].OK() {
+			return false
+		}
+	}
+	return true
+}
+
+// ProbeConfig tunes Probe. Zero values use defaults.
+type ProbeConfig struct {
+	Registry *Registry     // default DefaultRegistry()
+	Timeout  time.Duration // per endpoint (default 10s)
+	// Confirmations is how far below the lowest head the endpoints are
+	// compared (default 2). Tips differ during ordinary 1-2 block reorgs.
+	Confirmations uint64
+}
+
+// Probe checks every target against the registry: chain ID, network ID,
+// genesis hash and the header fields of the forks the chain should have.
+// Endpoints on the same chain must then return the same block
+// cfg.Confirmations below the lowest head among them. Problems are reported
+// per endpoint; the error is only for invalid input.
+func Probe(ctx context.Context, targets []Target, cfg ProbeConfig) (*ProbeReport, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if cfg.Registry == nil {
+		cfg.Registry = DefaultRegistry()
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.Confirmations == 0 {
+		cfg.Confirmations = 2
+	}
+	if len(targets) == 0 {
+		return nil, errors.New("no targets")
+	}
+	seen := make(map[string]bool, len(targets))
+	for _, t := range targets {
+		if t.Client == nil {
+			return nil, fmt.Errorf("target %s: client is nil", t.Name)
+		}
+		if seen[t.Name] {
+			return nil, fmt.Errorf("duplicate target name %q", t.Name)
+		}
+		seen[t.Name] = true
+		if _, ok := cfg.Registry.ByName(t.Expect); t.Expect != "" && !ok {
+			return nil, fmt.Errorf("target %s: unknown chain %q", t.Name, t.Expect)
+		}
+	}
+
+	report := &ProbeReport{Endpoints: make([]EndpointReport, len(targets))}
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		go func(i int, t Target) {
+			defer wg.Done()
+			tctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+			defer cancel()
+			report.Endpoints[i] = checkEndpoint(tctx, t, cfg.Registry)
+		}(i, t)
+	}
+	wg.Wait()
+
+	var chains []string
+	groups := make(map[string][]int)
+	for i := range report.Endpoints {
+		if r := &report.Endpoints[i]; r.onChain() {
+			if groups[r.Chain] == nil {
+				chains = append(chains, r.Chain)
+			}
+			groups[r.Chain] = append(groups[r.Chain], i)
+		}
+	}
+	for _, chain := range chains {
+		if len(groups[chain]) < 2 {
+			continue
+		}
+		report.Heights = append(report.Heights, compareHeight(ctx, cfg, chain, targets, report.Endpoints, groups[chain]))
+	}
+	return report, nil
+}
+
+// checkEndpoint fetches what the endpoint claims and checks it against the
+// registry. Once the chain is known to be wrong, the finer checks are
+// skipped: they would all fail and bury the real problem.
+func checkEndpoint(ctx context.Context, t Target, reg *Registry) EndpointReport {
+	r := EndpointReport{Name: t.Name}
+	chainID, err := t.Client.ChainID(ctx)
+	if err != nil || chainID == nil {
+		r.add(IssueUnreachable, "chain id: %v", errOrNil(err))
+		return r
+	}
+	networkID, err := t.Client.NetworkID(ctx)
+	if err != nil || networkID == nil {
+		r.add(IssueUnreachable, "network id: %v", errOrNil(err))
+		return r
+	}
+	head, err := t.Client.HeaderByNumber(ctx, nil)
+	if err != nil || head == nil || head.Number == nil {
+		r.add(IssueUnreachable, "latest header: %v", errOrNil(err))
+		return r
+	}
+	genesis, err := t.Client.HeaderByNumber(ctx, big.NewInt(0))
+	if err != nil || genesis == nil {
+		r.add(IssueUnreachable, "genesis header: %v", errOrNil(err))
+		return r
+	}
+	r.ChainID, r.NetworkID = chainID.Uint64(), networkID.Uint64()
+	r.Head = types.CopyHeader(head)
+	r.Genesis = genesis.Hash()
+	for _, f := range knownForks {
+		if f.ActiveIn(head) {
+			r.Forks = append(r.Forks, f)
+		}
+	}
+
+	chain, ok := reg.ByChainID(r.ChainID)
+	if t.Expect != "" {
+		want, _ := reg.ByName(t.Expect)
+		if !ok || chain.Name != want.Name {
+			r.add(IssueWrongChain, "chain id %s, want %d (%s)", describeChain(reg, r.ChainID), want.ChainID, want.Name)
+			return r
+		}
+	} else if !ok {
+		r.add(IssueUnknownChain, "chain id %d is not in the registry", r.ChainID)
+		return r
+	}
+	r.Chain = chain.Name
+
+	if want := chain.networkID(); r.NetworkID != want {
+		r.add(IssueNetworkID, "network id %d, want %d", r.NetworkID, want)
+	}
+	if chain.Genesis != (common.Hash{}) && r.Genesis != chain.Genesis {
+		r.add(IssueGenesis, "genesis %s, want %s", r.Genesis.Hex(), chain.Genesis.Hex())
+	}
+	for _, f := range chain.Forks {
+		if !f.ActiveIn(head) {
+			r.add(IssueMissingFork, "%s expected but header %d lacks its fields", f, head.Number)
+		}
+	}
+	return r
+}
+
+// compareHeight fetches the block cfg.Confirmations below the lowest head
+// of the group from every endpoint in it and flags the ones that differ
+// from the majority. Ties go to the hash seen first in target order.
+func compareHeight(ctx context.Context, cfg ProbeConfig, chain string, targets []Target, reports []EndpointReport, group []int) HeightCheck {
+	number := reports[group[0]].Head.Number.Uint64()
+	for _, i := range group[1:] {
+		number = min(number, reports[i].Head.Number.Uint64())
+	}
+	if number > cfg.Confirmations {
+		number -= cfg.Confirmations
+	} else {
+		number = 0
+	}
+	check := HeightCheck{Chain: chain, Number: number, Hashes: make(map[string]common.Hash, len(group))}
+
+	hashes := make([]common.Hash, len(group))
+	errs := make([]error, len(group))
+	var wg sync.WaitGroup
+	for k, i := range group {
+		if head := reports[i].Head; head.Number.Uint64() == number {
+			hashes[k] = head.Hash()
+			continue
+		}
+		wg.Add(1)
+		go func(k, i int) {
+			defer wg.Done()
+			tctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+			defer cancel()
+			h, err := targets[i].Client.HeaderByNumber(tctx, new(big.Int).SetUint64(number))
+			if err != nil || h == nil {
+				errs[k] = errOrNil(err)
+				return
+			}
+			hashes[k] = h.Hash()
+		}(k, i)
+	}
+	wg.Wait()
+
+	var order []common.Hash
+	votes := make(map[common.Hash]int)
+	for k, i := range group {
+		if errs[k] != nil {
+			reports[i].add(IssueUnreachable, "header %d: %v", number, errs[k])
+			continue
+		}
+		check.Hashes[reports[i].Name] = hashes[k]
+		if votes[hashes[k]] == 0 {
+			order = append(order, hashes[k])
+		}
+		votes[hashes[k]]++
+	}
+	if len(order) == 0 {
+		return check
+	}
+	majority := order[0]
+	for _, h := range order[1:] {
+		if votes[h] > votes[majority] {
+			majority = h
+		}
+	}
+	check.Agree = len(order) == 1
+	for k, i := range group {
+		if errs[k] == nil && hashes[k] != majority {
+			reports[i].add(IssueDisagreement, "block %d is %s, %d of %d endpoints have %s",
+				number, hashes[k].Hex(), votes[majority], len(check.Hashes), majority.Hex())
+		}
+	}
+	return check
+}
+
+func describeChain(reg *Registry, id uint64) string {
+	if c, ok := reg.ByChainID(id); ok {
+		return fmt.Sprintf("%d (%s)", id, c.Name)
+	}
+	return fmt.Sprint(id)
+}
+
+// errOrNil names the nil-response case, where a client returned neither a
+// value nor an error.
+func errOrNil(err error) error {
+	if err == nil {
+		return errors.New("empty response")
+	}
+	return err
+}
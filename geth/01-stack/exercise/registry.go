@@ -0,0 +1,136 @@
+package exercise
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Fork is a hard fork that left a visible mark on the block header, so a
+// probe can tell from the latest header alone whether a node applies it.
+type Fork string
+
+const (
+	ForkLondon   Fork = "london"   // EIP-1559 added BaseFee
+	ForkShanghai Fork = "shanghai" // EIP-4895 added WithdrawalsHash
+	ForkCancun   Fork = "cancun"   // EIP-4844 added BlobGasUsed and ExcessBlobGas
+)
+
+// ActiveIn reports whether h carries the header fields f introduced.
+func (f Fork) ActiveIn(h *types.Header) bool {
+	switch f {
+	case ForkLondon:
+		return h.BaseFee != nil
+	case ForkShanghai:
+		return h.WithdrawalsHash != nil
+	case ForkCancun:
+		return h.BlobGasUsed != nil && h.ExcessBlobGas != nil
+	}
+	return false
+}
+
+// knownForks is every Fork in activation order.
+var knownForks = []Fork{ForkLondon, ForkShanghai, ForkCancun}
+
+// Chain is what we expect from an endpoint serving a given network.
+type Chain struct {
+	Name      string
+	ChainID   uint64
+	NetworkID uint64      // 0 means the same as ChainID
+	Genesis   common.Hash // hash of block 0; zero skips the check
+	Forks     []Fork      // forks active at the current head
+}
+
+// networkID returns the expected net_version.
+func (c Chain) networkID() uint64 {
+	if c.NetworkID == 0 {
+		return c.ChainID
+	}
+	return c.NetworkID
+}
+
+// Registry holds chains by name and chain ID.
+type Registry struct {
+	byName map[string]Chain
+	byID   map[uint64]Chain
+}
+
+// NewRegistry indexes chains. Names and chain IDs must be unique.
+func NewRegistry(chains ...Chain) (*Registry, error) {
+	r := &Registry{byName: make(map[string]Chain), byID: make(map[uint64]Chain)}
+	for _, c := range chains {
+		if c.Name == "" {
+			return nil, fmt.Errorf("chain %d has no name", c.ChainID)
+		}
+		if c.ChainID == 0 {
+			return nil, fmt.Errorf("chain %s has no chain id", c.Name)
+		}
+		if _, ok := r.byName[c.Name]; ok {
+			return nil, fmt.Errorf("duplicate chain name %q", c.Name)
+		}
+		if other, ok := r.byID[c.ChainID]; ok {
+			return nil, fmt.Errorf("chains %s and %s share chain id %d", other.Name, c.Name, c.ChainID)
+		}
+		for _, f := range c.Forks {
+			if !isKnownFork(f) {
+				return nil, fmt.Errorf("chain %s: unknown fork %q", c.Name, f)
+			}
+		}
+		c.Forks = append([]Fork(nil), c.Forks...)
+		r.byName[c.Name] = c
+		r.byID[c.ChainID] = c
+	}
+	if len(r.byName) == 0 {
+		return nil, errors.New("registry is empty")
+	}
+	return r, nil
+}
+
+func isKnownFork(f Fork) bool {
+	for _, k := range knownForks {
+		if k == f {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultRegistry returns mainnet and the public testnets, with every fork
+// the header can reveal already active.
+func DefaultRegistry() *Registry {
+	r, err := NewRegistry(
+		Chain{Name: "mainnet", ChainID: 1, Genesis: params.MainnetGenesisHash, Forks: knownForks},
+		Chain{Name: "sepolia", ChainID: 11155111, Genesis: params.SepoliaGenesisHash, Forks: knownForks},
+		Chain{Name: "holesky", ChainID: 17000, Genesis: params.HoleskyGenesisHash, Forks: knownForks},
+	)
+	if err != nil {
+		panic(err) // static data
+	}
+	return r
+}
+
+// ByName looks a chain up by name.
+func (r *Registry) ByName(name string) (Chain, bool) {
+	c, ok := r.byName[name]
+	return c, ok
+}
+
+// ByChainID looks a chain up by chain ID.
+func (r *Registry) ByChainID(id uint64) (Chain, bool) {
+	c, ok := r.byID[id]
+	return c, ok
+}
+
+// Chains returns every chain, ordered by chain ID.
+func (r *Registry) Chains() []Chain {
+	out := make([]Chain, 0, len(r.byID))
+	for _, c := range r.byID {
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ChainID < out[j].ChainID })
+	return out
+}
@@ -0,0 +1,165 @@
+package exercise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Error classes. Test for them with errors.Is; the concrete error is an
+// *RPCError carrying the code, message and revert data.
+var (
+	// ErrRateLimited means the provider refused the request (HTTP 429 or
+	// JSON-RPC -32005). The node never ran it, so retrying is always safe.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrExecutionReverted means eth_call or eth_estimateGas hit a revert.
+	// The answer is deterministic; retrying returns the same revert.
+	ErrExecutionReverted = errors.New("execution reverted")
+	// ErrHeaderNotFound means the node doesn't have the requested block,
+	// typically a load-balanced backend that is a block or two behind.
+	ErrHeaderNotFound = errors.New("header not found")
+	// ErrTimeout means a single attempt ran past its policy timeout.
+	ErrTimeout = errors.New("request timed out")
+)
+
+// JSON-RPC error codes used by Ethereum nodes and providers.
+const (
+	codeReverted      = 3      // geth: execution reverted, with revert data
+	codeLimitExceeded = -32005 // EIP-1474: request exceeds a defined limit
+)
+
+// RPCError is a classified failure of one JSON-RPC request.
+type RPCError struct {
+	Method     string
+	Code       int         // JSON-RPC error code, 0 for transport errors
+	Message    string      // message from the node or transport
+	Data       interface{} // the error's data field, e.g. revert data
+	HTTPStatus int         // non-zero when the HTTP request itself failed
+	Kind       error       // one of the Err* classes, nil if unclassified
+	Err        error       // the original error
+}
+
+func (e *RPCError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Method)
+	b.WriteString(": ")
+	b.WriteString(e.Message)
+	if e.Code != 0 {
+		fmt.Fprintf(&b, " (code %d)", e.Code)
+	}
+	if reason, ok := e.RevertReason(); ok {
+		fmt.Fprintf(&b, ": %s", reason)
+	}
+	return b.String()
+}
+
+// Unwrap exposes both the class and the original error to errors.Is/As.
+func (e *RPCError) Unwrap() []error {
+	var errs []error
+	if e.Kind != nil {
+		errs = append(errs, e.Kind)
+	}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
+
+// RevertData returns the raw revert payload of an execution revert.
+func (e *RPCError) RevertData() ([]byte, bool) {
+	s, ok := e.Data.(string)
+	if !ok || e.Kind != ErrExecutionReverted {
+		return nil, false
+	}
+	data, err := hexutil.Decode(s)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// RevertReason decodes an Error(string) revert, i.e. require(cond, "msg").
+// Custom errors and panics don't decode and report false.
+func (e *RPCError) RevertReason() (string, bool) {
+	data, ok := e.RevertData()
+	if !ok {
+		return "", false
+	}
+	reason, err := abi.UnpackRevert(data)
+	if err != nil {
+		return "", false
+	}
+	return reason, true
+}
+
+// Classify wraps err in an *RPCError and assigns its class. Errors that are
+// already classified and context errors are returned as is.
+func Classify(method string, err error) error {
+	var re *RPCError
+	if err == nil || errors.As(err, &re) ||
+		errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	re = &RPCError{Method: method, Message: err.Error(), Err: err}
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		re.HTTPStatus = httpErr.StatusCode
+	}
+	var codeErr rpc.Error
+	if errors.As(err, &codeErr) {
+		re.Code = codeErr.ErrorCode()
+	}
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		re.Data = dataErr.ErrorData()
+	}
+
+	msg := strings.ToLower(re.Message)
+	switch {
+	case re.HTTPStatus == 429, re.Code == codeLimitExceeded,
+		strings.Contains(msg, "rate limit"), strings.Contains(msg, "too many requests"):
+		re.Kind = ErrRateLimited
+	case re.Code == codeReverted, strings.Contains(msg, "execution reverted"):
+		re.Kind = ErrExecutionReverted
+	case strings.Contains(msg, "header not found"), strings.Contains(msg, "unknown block"):
+		re.Kind = ErrHeaderNotFound
+	}
+	return re
+}
+
+// retryable reports whether another attempt could succeed and is safe.
+// Rate-limited requests were rejected before running, so they are retried
+// for any method. Anything else is retried only for idempotent methods, and
+// never when the node gave a definite answer such as a revert.
+func retryable(err error, idempotent bool) bool {
+	if errors.Is(err, ErrRateLimited) {
+		return true
+	}
+	if !idempotent {
+		return false
+	}
+	switch {
+	case errors.Is(err, ErrTimeout), errors.Is(err, ErrHeaderNotFound):
+		return true
+	case errors.Is(err, ErrExecutionReverted):
+		return false
+	}
+	var re *RPCError
+	if !errors.As(err, &re) {
+		return false
+	}
+	switch {
+	case re.HTTPStatus >= 500:
+		return true // gateway or backend trouble
+	case re.HTTPStatus != 0:
+		return false // 4xx: the request itself is wrong
+	case re.Code != 0:
+		return false // the node answered (method not found, bad params, ...)
+	}
+	return true // transport failure: connection reset, EOF, ...
+}
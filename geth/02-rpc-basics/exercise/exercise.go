@@ -6,8 +6,12 @@ package exercise
 import (
 	"context"
 	"errors"
+	"time"
 )
 
+// retryDelay is the wait between BlockByNumber attempts, as in the solution.
+const retryDelay = 100 * time.Millisecond
+
 /*
 Problem: Build a resilient RPC client that can fetch full block data.
 
@@ -51,9 +55,10 @@ func Run(ctx context.Context, client RPCClient, cfg Config) (*Result, error) {
 	// - IMPORTANT: Use defensive copying for all fields:
 	//   - Use new(big.Int).Set(networkID) to copy NetworkID
 	//   - BlockNumber is a uint64, which is a value type, so no copy needed.
-	//   - Use types.CopyBlock(block) to copy the Block
-	// - Why defensive copying? `types.Block` contains pointers. If we don't
-	//   copy it, the caller could mutate the client's internal data.
+	//   - The Block can be returned as is: types.Block is immutable, and its
+	//     accessors (Header, Number, Transactions) return copies
+	// - Why defensive copying? A mutable pointer shared with the caller lets
+	//   them change the client's internal data.
 
 	return nil, errors.New("not implemented")
 }
\ No newline at end of file
@@ -2,13 +2,23 @@ package exercise
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 type mockClient struct {
@@ -107,3 +117,559 @@ func TestRunPropagatesErrors(t *testing.T) {
 		t.Fatalf("expected error from BlockNumber")
 	}
 }
+
+// stubReply scripts the stand-in server's answer to one request.
+type stubReply struct {
+	Result interface{}
+	Code   int // JSON-RPC error code; 0 with a Message means -32000
+	Msg    string
+	Data   interface{}
+	Drop   bool // leave the request out of the batch response
+	Stall  bool // hold the response until the client gives up
+}
+
+// stubServer is a JSON-RPC 2.0 stand-in: it speaks the wire format by hand
+// so tests can script rate limits, reverts, stalls and partial batches.
+type stubServer struct {
+	*httptest.Server
+	reply func(method string, n int) stubReply // n counts calls per method, from 1
+
+	mu        sync.Mutex
+	reject429 int // reject the next HTTP requests with 429
+	calls     map[string]int
+	batches   []int // size of each batch received
+}
+
+func newStubServer(t *testing.T, reply func(method string, n int) stubReply) *stubServer {
+	s := &stubServer{reply: reply, calls: make(map[string]int)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+type stubRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+}
+
+func (s *stubServer) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	if s.reject429 > 0 {
+		s.reject429--
+		s.mu.Unlock()
+		http.Error(w, "too many requests", http.StatusTooManyRequests)
+		return
+	}
+	s.mu.Unlock()
+
+	batch := len(body) > 0 && body[0] == '['
+	var reqs []stubRequest
+	if batch {
+		json.Unmarshal(body, &reqs)
+		s.mu.Lock()
+		s.batches = append(s.batches, len(reqs))
+		s.mu.Unlock()
+	} else {
+		var req stubRequest
+		json.Unmarshal(body, &req)
+		reqs = []stubRequest{req}
+	}
+
+	var resps []map[string]interface{}
+	for _, req := range reqs {
+		s.mu.Lock()
+		s.calls[req.Method]++
+		n := s.calls[req.Method]
+		s.mu.Unlock()
+		rep := s.reply(req.Method, n)
+		if rep.Stall {
+			<-r.Context().Done()
+			return
+		}
+		if rep.Drop {
+			continue
+		}
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		if rep.Msg != "" {
+			code := rep.Code
+			if code == 0 {
+				code = -32000
+			}
+			e := map[string]interface{}{"code": code, "message": rep.Msg}
+			if rep.Data != nil {
+				e["data"] = rep.Data
+			}
+			resp["error"] = e
+		} else {
+			resp["result"] = rep.Result
+		}
+		resps = append(resps, resp)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if batch {
+		json.NewEncoder(w).Encode(resps)
+	} else {
+		json.NewEncoder(w).Encode(resps[0])
+	}
+}
+
+func (s *stubServer) count(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[method]
+}
+
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waits   []time.Duration
+	onAfter func() // runs before the timer fires
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.waits = append(c.waits, d)
+	c.mu.Unlock()
+	if c.onAfter != nil {
+		c.onAfter()
+	}
+	ch := make(chan time.Time, 1)
+	ch <- c.Now()
+	return ch
+}
+
+func dialStub(t *testing.T, url string, cfg ClientConfig) *Client {
+	t.Helper()
+	if cfg.Clock == nil {
+		cfg.Clock = &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	}
+	c, err := Dial(context.Background(), url, cfg)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(c.Close)
+	return c
+}
+
+func revertData(t *testing.T, reason string) string {
+	t.Helper()
+	str, _ := abi.NewType("string", "", nil)
+	packed, err := abi.Arguments{{Type: str}}.Pack(reason)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hexutil.Encode(append(common.FromHex("0x08c379a0"), packed...))
+}
+
+func TestCallRetriesRateLimitWithBackoff(t *testing.T) {
+	srv := newStubServer(t, func(string, int) stubReply { return stubReply{Result: "0x2a"} })
+	srv.reject429 = 2
+	clock := &fakeClock{}
+	var events []string
+	c := dialStub(t, srv.URL, ClientConfig{
+		Clock: clock,
+		Hooks: Hooks{
+			OnRequest: func(r Request) { events = append(events, fmt.Sprintf("-> %d %s #%d", r.ID, r.Method, r.Attempt)) },
+			OnResponse: func(r Response) {
+				events = append(events, fmt.Sprintf("<- %d %s %s %v", r.ID, r.Method, r.Result, r.Err != nil))
+			},
+		},
+	})
+
+	var n hexutil.Uint64
+	if err := c.Call(context.Background(), &n, "eth_blockNumber"); err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+	if n != 42 {
+		t.Fatalf("result = %d, want 42", n)
+	}
+	want := []string{
+		"-> 1 eth_blockNumber #1", "<- 1 eth_blockNumber null true",
+		"-> 2 eth_blockNumber #2", "<- 2 eth_blockNumber null true",
+		"-> 3 eth_blockNumber #3", `<- 3 eth_blockNumber "0x2a" false`,
+	}
+	if strings.Join(events, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("hook events:\n%s\nwant:\n%s", strings.Join(events, "\n"), strings.Join(want, "\n"))
+	}
+	if len(clock.waits) != 2 || clock.waits[0] != 100*time.Millisecond || clock.waits[1] != 200*time.Millisecond {
+		t.Fatalf("backoff waits = %v, want [100ms 200ms]", clock.waits)
+	}
+}
+
+func TestCallGivesUpAfterMaxAttempts(t *testing.T) {
+	srv := newStubServer(t, func(string, int) stubReply { return stubReply{Result: "0x1"} })
+	srv.reject429 = 10
+	c := dialStub(t, srv.URL, ClientConfig{Methods: map[string]Policy{"eth_chainId": {MaxAttempts: 2}}})
+
+	err := c.Call(context.Background(), nil, "eth_chainId")
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("err = %v, want ErrRateLimited", err)
+	}
+	var re *RPCError
+	if !errors.As(err, &re) || re.HTTPStatus != http.StatusTooManyRequests || re.Method != "eth_chainId" {
+		t.Fatalf("err = %#v, want *RPCError with status 429", err)
+	}
+	if got := 10 - srv.reject429; got != 2 {
+		t.Fatalf("requests = %d, want 2", got)
+	}
+}
+
+func TestSendRawTransactionIsNotRetriedBlindly(t *testing.T) {
+	srv := newStubServer(t, func(method string, n int) stubReply {
+		return stubReply{Stall: true}
+	})
+	c := dialStub(t, srv.URL, ClientConfig{
+		Default: Policy{Timeout: 50 * time.Millisecond},
+		Clock:   realClock{},
+	})
+
+	// A timeout is ambiguous: the tx may have reached the node.
+	err := c.Call(context.Background(), nil, "eth_sendRawTransaction", "0x02f8")
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("err = %v, want ErrTimeout", err)
+	}
+	if got := srv.count("eth_sendRawTransaction"); got != 1 {
+		t.Fatalf("eth_sendRawTransaction sent %d times, want 1", got)
+	}
+
+	// The same failure on a read is retried up to MaxAttempts.
+	err = c.Call(context.Background(), nil, "eth_getBalance", common.Address{}, "latest")
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("err = %v, want ErrTimeout", err)
+	}
+	if got := srv.count("eth_getBalance"); got != 3 {
+		t.Fatalf("eth_getBalance sent %d times, want 3", got)
+	}
+}
+
+func TestSendRawTransactionRetriedWhenRateLimited(t *testing.T) {
+	hash := common.HexToHash("0xabc")
+	srv := newStubServer(t, func(string, int) stubReply { return stubReply{Result: hash} })
+	srv.reject429 = 1
+	c := dialStub(t, srv.URL, ClientConfig{})
+
+	var got common.Hash
+	if err := c.Call(context.Background(), &got, "eth_sendRawTransaction", "0x02f8"); err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+	if got != hash {
+		t.Fatalf("hash = %s, want %s", got, hash)
+	}
+	// The 429 was rejected before the node saw it, so one send reached it.
+	if n := srv.count("eth_sendRawTransaction"); n != 1 {
+		t.Fatalf("node saw %d sends, want 1", n)
+	}
+}
+
+func TestCallClassifiesRevert(t *testing.T) {
+	data := revertData(t, "not owner")
+	srv := newStubServer(t, func(string, int) stubReply {
+		return stubReply{Code: 3, Msg: "execution reverted: not owner", Data: data}
+	})
+	c := dialStub(t, srv.URL, ClientConfig{})
+
+	err := c.Call(context.Background(), nil, "eth_call", map[string]string{"to": "0x01"}, "latest")
+	if !errors.Is(err, ErrExecutionReverted) {
+		t.Fatalf("err = %v, want ErrExecutionReverted", err)
+	}
+	var re *RPCError
+	if !errors.As(err, &re) {
+		t.Fatalf("err = %T, want *RPCError", err)
+	}
+	if reason, ok := re.RevertReason(); !ok || reason != "not owner" {
+		t.Fatalf("revert reason = %q, %v", reason, ok)
+	}
+	if re.Code != 3 || re.Data != data {
+		t.Fatalf("code %d data %v", re.Code, re.Data)
+	}
+	if n := srv.count("eth_call"); n != 1 {
+		t.Fatalf("revert retried: %d calls", n)
+	}
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != 3 {
+		t.Fatalf("original rpc.Error not reachable through %v", err)
+	}
+}
+
+func TestCallRetriesHeaderNotFound(t *testing.T) {
+	srv := newStubServer(t, func(method string, n int) stubReply {
+		if n == 1 {
+			return stubReply{Msg: "header not found"}
+		}
+		return stubReply{Result: map[string]string{"number": "0x10"}}
+	})
+	c := dialStub(t, srv.URL, ClientConfig{})
+
+	var head struct {
+		Number hexutil.Uint64 `json:"number"`
+	}
+	if err := c.Call(context.Background(), &head, "eth_getBlockByNumber", "0x10", false); err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+	if head.Number != 16 || srv.count("eth_getBlockByNumber") != 2 {
+		t.Fatalf("number %d after %d calls", head.Number, srv.count("eth_getBlockByNumber"))
+	}
+
+	// Without retries the class is visible to the caller.
+	c = dialStub(t, srv.URL, ClientConfig{Default: Policy{MaxAttempts: 1}})
+	srv.mu.Lock()
+	srv.calls = map[string]int{}
+	srv.mu.Unlock()
+	err := c.Call(context.Background(), &head, "eth_getBlockByNumber", "0x11", false)
+	if !errors.Is(err, ErrHeaderNotFound) {
+		t.Fatalf("err = %v, want ErrHeaderNotFound", err)
+	}
+}
+
+func TestCallDoesNotRetryMethodNotFound(t *testing.T) {
+	srv := newStubServer(t, func(string, int) stubReply {
+		return stubReply{Code: -32601, Msg: "the method txpool_content does not exist/is not available"}
+	})
+	c := dialStub(t, srv.URL, ClientConfig{})
+	err := c.Call(context.Background(), nil, "txpool_content")
+	var re *RPCError
+	if !errors.As(err, &re) || re.Code != -32601 || re.Kind != nil {
+		t.Fatalf("err = %v, want unclassified -32601", err)
+	}
+	if n := srv.count("txpool_content"); n != 1 {
+		t.Fatalf("calls = %d, want 1", n)
+	}
+}
+
+func TestCallStopsOnContextCancel(t *testing.T) {
+	srv := newStubServer(t, func(string, int) stubReply { return stubReply{Stall: true} })
+	c := dialStub(t, srv.URL, ClientConfig{Clock: realClock{}})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := c.Call(ctx, nil, "eth_blockNumber"); !errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrTimeout) {
+		t.Fatalf("err = %v, want the caller's deadline", err)
+	}
+	if n := srv.count("eth_blockNumber"); n != 1 {
+		t.Fatalf("calls = %d, want 1", n)
+	}
+}
+
+func TestCallStopsWhenCancelledDuringBackoff(t *testing.T) {
+	srv := newStubServer(t, func(string, int) stubReply { return stubReply{Result: "0x1"} })
+	srv.reject429 = 1000
+	clock := &fakeClock{}
+	attempts := 0
+	c := dialStub(t, srv.URL, ClientConfig{Clock: clock, Hooks: Hooks{OnRequest: func(Request) { attempts++ }}})
+	// The backoff timer and the cancellation are ready together; select
+	// would pick either, so repeat to catch a second attempt.
+	for i := 0; i < 20; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		clock.onAfter = cancel
+		attempts = 0
+		err := c.Call(ctx, nil, "eth_blockNumber")
+		cancel()
+		if !errors.Is(err, context.Canceled) || attempts != 1 {
+			t.Fatalf("err = %v after %d attempts, want context.Canceled after 1", err, attempts)
+		}
+	}
+}
+
+func TestBatchRetriesOnlyFailedIdempotentRequests(t *testing.T) {
+	srv := newStubServer(t, func(method string, n int) stubReply {
+		switch method {
+		case "eth_chainId":
+			return stubReply{Result: "0x1"}
+		case "eth_getBlockByNumber":
+			if n == 1 {
+				return stubReply{Msg: "header not found"}
+			}
+			return stubReply{Result: map[string]string{"number": "0x10"}}
+		case "eth_call":
+			return stubReply{Code: 3, Msg: "execution reverted"}
+		case "eth_sendRawTransaction":
+			return stubReply{Drop: true} // the node may or may not have it
+		}
+		return stubReply{Msg: "unexpected"}
+	})
+	c := dialStub(t, srv.URL, ClientConfig{})
+
+	var chainID hexutil.Uint64
+	var head struct {
+		Number hexutil.Uint64 `json:"number"`
+	}
+	reqs := []BatchRequest{
+		{Method: "eth_chainId", Result: &chainID},
+		{Method: "eth_getBlockByNumber", Params: []interface{}{"0x10", false}, Result: &head},
+		{Method: "eth_call", Params: []interface{}{map[string]string{"to": "0x01"}, "latest"}},
+		{Method: "eth_sendRawTransaction", Params: []interface{}{"0x02f8"}},
+	}
+	if err := c.Batch(context.Background(), reqs); err != nil {
+		t.Fatalf("Batch: %v", err)
+	}
+	if reqs[0].Err != nil || chainID != 1 {
+		t.Fatalf("chainId: %v %d", reqs[0].Err, chainID)
+	}
+	if reqs[1].Err != nil || head.Number != 16 {
+		t.Fatalf("getBlockByNumber: %v %d", reqs[1].Err, head.Number)
+	}
+	if !errors.Is(reqs[2].Err, ErrExecutionReverted) {
+		t.Fatalf("eth_call err = %v", reqs[2].Err)
+	}
+	if !errors.Is(reqs[3].Err, rpc.ErrMissingBatchResponse) {
+		t.Fatalf("sendRawTransaction err = %v", reqs[3].Err)
+	}
+	if fmt.Sprint(srv.batches) != "[4 1]" {
+		t.Fatalf("batch sizes = %v, want [4 1]", srv.batches)
+	}
+	if srv.count("eth_sendRawTransaction") != 1 || srv.count("eth_call") != 1 {
+		t.Fatalf("non-retryable requests were re-sent")
+	}
+}
+
+func TestBatchRateLimitedRetriesEverything(t *testing.T) {
+	srv := newStubServer(t, func(method string, n int) stubReply { return stubReply{Result: "0x1"} })
+	srv.reject429 = 1
+	c := dialStub(t, srv.URL, ClientConfig{})
+	reqs := []BatchRequest{{Method: "eth_chainId"}, {Method: "eth_sendRawTransaction", Params: []interface{}{"0x02f8"}}}
+	if err := c.Batch(context.Background(), reqs); err != nil {
+		t.Fatalf("Batch: %v", err)
+	}
+	for _, r := range reqs {
+		if r.Err != nil {
+			t.Fatalf("%s: %v", r.Method, r.Err)
+		}
+	}
+	if fmt.Sprint(srv.batches) != "[2]" {
+		t.Fatalf("batch sizes reaching the node = %v, want [2]", srv.batches)
+	}
+}
+
+// revertError is how a geth-style service reports a revert: code 3 with the
+// revert data attached.
+type revertError struct{ data string }
+
+func (e *revertError) Error() string          { return "execution reverted" }
+func (e *revertError) ErrorCode() int         { return 3 }
+func (e *revertError) ErrorData() interface{} { return e.data }
+
+type wsEthService struct{ revert string }
+
+func (s *wsEthService) ChainId() hexutil.Uint64 { return 1337 }
+
+func (s *wsEthService) Call(args map[string]interface{}, block string) (hexutil.Bytes, error) {
+	return nil, &revertError{data: s.revert}
+}
+
+func TestClientOverWebSocket(t *testing.T) {
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", &wsEthService{revert: revertData(t, "paused")}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Stop)
+	srv := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	t.Cleanup(srv.Close)
+
+	var logs []string
+	c := dialStub(t, "ws"+strings.TrimPrefix(srv.URL, "http"), ClientConfig{
+		Hooks: LogHooks(func(format string, args ...interface{}) { logs = append(logs, fmt.Sprintf(format, args...)) }),
+	})
+
+	var id hexutil.Uint64
+	if err := c.Call(context.Background(), &id, "eth_chainId"); err != nil || id != 1337 {
+		t.Fatalf("eth_chainId = %d, %v", id, err)
+	}
+	err := c.Call(context.Background(), nil, "eth_call", map[string]string{"to": "0x01"}, "latest")
+	var re *RPCError
+	if !errors.As(err, &re) || !errors.Is(err, ErrExecutionReverted) {
+		t.Fatalf("err = %v, want revert", err)
+	}
+	if reason, _ := re.RevertReason(); reason != "paused" {
+		t.Fatalf("reason = %q", reason)
+	}
+
+	reqs := []BatchRequest{{Method: "eth_chainId", Result: &id}, {Method: "eth_call", Params: []interface{}{map[string]string{}, "latest"}}}
+	if err := c.Batch(context.Background(), reqs); err != nil {
+		t.Fatalf("Batch: %v", err)
+	}
+	if reqs[0].Err != nil || !errors.Is(reqs[1].Err, ErrExecutionReverted) {
+		t.Fatalf("batch errs: %v, %v", reqs[0].Err, reqs[1].Err)
+	}
+	if len(logs) != 8 || logs[0] != "rpc #1 -> eth_chainId [] (attempt 1)" || !strings.Contains(logs[3], "paused") {
+		t.Fatalf("logs:\n%s", strings.Join(logs, "\n"))
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		kind error
+	}{
+		{"http 429", rpc.HTTPError{StatusCode: 429, Status: "429 Too Many Requests"}, ErrRateLimited},
+		{"limit exceeded", &revertErrorCode{code: -32005, msg: "limit exceeded"}, ErrRateLimited},
+		{"provider message", errors.New("daily request count exceeded, request rate limited"), ErrRateLimited},
+		{"revert code", &revertErrorCode{code: 3, msg: "execution reverted"}, ErrExecutionReverted},
+		{"revert message", errors.New("execution reverted: Pausable: paused"), ErrExecutionReverted},
+		{"header", errors.New("header not found"), ErrHeaderNotFound},
+		{"unknown block", errors.New("unknown block"), ErrHeaderNotFound},
+		{"other", errors.New("connection reset by peer"), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var re *RPCError
+			if !errors.As(Classify("eth_x", tt.err), &re) {
+				t.Fatalf("not an *RPCError")
+			}
+			if re.Kind != tt.kind {
+				t.Fatalf("kind = %v, want %v", re.Kind, tt.kind)
+			}
+			if re.Message != tt.err.Error() || re.Err == nil {
+				t.Fatalf("original error lost")
+			}
+		})
+	}
+	if err := Classify("eth_x", context.Canceled); err != context.Canceled {
+		t.Fatalf("context error wrapped: %v", err)
+	}
+}
+
+type revertErrorCode struct {
+	code int
+	msg  string
+}
+
+func (e *revertErrorCode) Error() string  { return e.msg }
+func (e *revertErrorCode) ErrorCode() int { return e.code }
+
+func TestPolicyDefaultsAndBackoff(t *testing.T) {
+	if _, err := NewClient(nil, ClientConfig{}); err == nil {
+		t.Fatalf("expected error for nil caller")
+	}
+	c, err := NewClient(&rpc.Client{}, ClientConfig{
+		Default: Policy{Timeout: 5 * time.Second},
+		Methods: map[string]Policy{"debug_traceTransaction": {Timeout: time.Minute, MaxAttempts: 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Policy{Timeout: 5 * time.Second, MaxAttempts: 3, Backoff: 100 * time.Millisecond, MaxBackoff: 2 * time.Second}
+	if got := c.Policy("eth_call"); got != want {
+		t.Fatalf("default policy = %+v", got)
+	}
+	if got := c.Policy("debug_traceTransaction"); got.Timeout != time.Minute || got.MaxAttempts != 1 || got.Backoff != want.Backoff {
+		t.Fatalf("override = %+v", got)
+	}
+	if c.Idempotent("eth_sendRawTransaction") || !c.Idempotent("eth_call") {
+		t.Fatalf("idempotency defaults wrong")
+	}
+	var delays []time.Duration
+	for retry := 1; retry <= 6; retry++ {
+		delays = append(delays, want.delay(retry))
+	}
+	if fmt.Sprint(delays) != "[100ms 200ms 400ms 800ms 1.6s 2s]" {
+		t.Fatalf("delays = %v", delays)
+	}
+}
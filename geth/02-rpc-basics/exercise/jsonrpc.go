@@ -0,0 +1,408 @@
+package exercise
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Caller is the transport under Client. *rpc.Client satisfies it over HTTP,
+// WebSocket and IPC alike.
+type Caller interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+	BatchCallContext(ctx context.Context, b []rpc.BatchElem) error
+}
+
+// Clock abstracts time so tests can run retry backoffs instantly.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Policy controls how one method is called. Zero fields inherit from the
+// client's default policy, and from there fall back to built-in defaults.
+type Policy struct {
+	Timeout     time.Duration // per attempt (default 10s)
+	MaxAttempts int           // including the first; 1 disables retries (default 3)
+	Backoff     time.Duration // wait before the first retry, doubled after each (default 100ms)
+	MaxBackoff  time.Duration // cap on the wait (default 2s)
+}
+
+func (p Policy) orElse(d Policy) Policy {
+	if p.Timeout <= 0 {
+		p.Timeout = d.Timeout
+	}
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = d.MaxAttempts
+	}
+	if p.Backoff <= 0 {
+		p.Backoff = d.Backoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = d.MaxBackoff
+	}
+	return p
+}
+
+// delay is the wait before the given retry (1 for the first retry).
+func (p Policy) delay(retry int) time.Duration {
+	d := p.Backoff
+	for i := 1; i < retry && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, p.MaxBackoff)
+}
+
+var defaultPolicy = Policy{
+	Timeout:     10 * time.Second,
+	MaxAttempts: 3,
+	Backoff:     100 * time.Millisecond,
+	MaxBackoff:  2 * time.Second,
+}
+
+// DefaultNonIdempotent lists the methods whose side effects make a blind
+// retry unsafe: if the first attempt reached the node but the response was
+// lost, a second send could broadcast twice or trip "nonce too low".
+var DefaultNonIdempotent = []string{
+	"eth_sendRawTransaction",
+	"eth_sendTransaction",
+	"personal_sendTransaction",
+}
+
+// Request describes one attempt, passed to Hooks.OnRequest.
+type Request struct {
+	ID        uint64 // unique per attempt, matches the Response
+	Method    string
+	Params    []interface{}
+	Attempt   int // 1 for the first try
+	BatchSize int // size of the batch the request went out in, 0 for Call
+}
+
+// Response describes the outcome of one attempt, passed to Hooks.OnResponse.
+type Response struct {
+	Request
+	Result   json.RawMessage // nil on error
+	Err      error           // classified, see Classify
+	Duration time.Duration
+}
+
+// Hooks observe traffic, e.g. for logging or metrics. They run on the
+// calling goroutine and must not block.
+type Hooks struct {
+	OnRequest  func(Request)
+	OnResponse func(Response)
+}
+
+// LogHooks logs every request and response through logf, which can be
+// log.Printf or testing.T.Logf.
+func LogHooks(logf func(format string, args ...interface{})) Hooks {
+	return Hooks{
+		OnRequest: func(r Request) {
+			params := []byte("[]")
+			if len(r.Params) > 0 {
+				params, _ = json.Marshal(r.Params)
+			}
+			logf("rpc #%d -> %s %s (attempt %d)", r.ID, r.Method, params, r.Attempt)
+		},
+		OnResponse: func(r Response) {
+			if r.Err != nil {
+				logf("rpc #%d <- %s error after %s: %v", r.ID, r.Method, r.Duration, r.Err)
+				return
+			}
+			logf("rpc #%d <- %s %d bytes in %s", r.ID, r.Method, len(r.Result), r.Duration)
+		},
+	}
+}
+
+// ClientConfig configures a Client. Zero values use defaults.
+type ClientConfig struct {
+	// Default applies to methods without an entry in Methods.
+	Default Policy
+	// Methods overrides the policy per method, e.g. a longer timeout for
+	// debug_traceTransaction or a single attempt for eth_call.
+	Methods map[string]Policy
+	// NonIdempotent lists methods that are only retried when the node
+	// provably didn't run them (rate limits). Nil means DefaultNonIdempotent.
+	NonIdempotent []string
+	Hooks         Hooks
+	Clock         Clock
+}
+
+// Client is a thin JSON-RPC client with per-method timeouts and retries,
+// error classification and hooks. Unlike ethclient it speaks raw method
+// names, so it reaches namespaces ethclient has no wrappers for.
+type Client struct {
+	caller        Caller
+	close         func()
+	policies      map[string]Policy
+	nonIdempotent map[string]bool
+	hooks         Hooks
+	clock         Clock
+	def           Policy
+	nextID        atomic.Uint64
+}
+
+// Dial connects to an http(s):// or ws(s):// endpoint (or an IPC path).
+func Dial(ctx context.Context, url string, cfg ClientConfig, opts ...rpc.ClientOption) (*Client, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	c, err := rpc.DialOptions(ctx, url, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s: %w", url, err)
+	}
+	client, err := NewClient(c, cfg)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	client.close = c.Close
+	return client, nil
+}
+
+// NewClient wraps an existing transport. Close on the result does not close
+// it.
+func NewClient(caller Caller, cfg ClientConfig) (*Client, error) {
+	if caller == nil {
+		return nil, errors.New("caller is nil")
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = realClock{}
+	}
+	if cfg.NonIdempotent == nil {
+		cfg.NonIdempotent = DefaultNonIdempotent
+	}
+	c := &Client{
+		caller:        caller,
+		close:         func() {},
+		policies:      make(map[string]Policy, len(cfg.Methods)),
+		nonIdempotent: make(map[string]bool, len(cfg.NonIdempotent)),
+		hooks:         cfg.Hooks,
+		clock:         cfg.Clock,
+		def:           cfg.Default.orElse(defaultPolicy),
+	}
+	for method, p := range cfg.Methods {
+		c.policies[method] = p.orElse(c.def)
+	}
+	for _, method := range cfg.NonIdempotent {
+		c.nonIdempotent[method] = true
+	}
+	return c, nil
+}
+
+// Close closes the connection if the client dialed it.
+func (c *Client) Close() { c.close() }
+
+// Policy returns the effective policy for method.
+func (c *Client) Policy(method string) Policy {
+	if p, ok := c.policies[method]; ok {
+		return p
+	}
+	return c.def
+}
+
+// Idempotent reports whether method may be retried after an ambiguous
+// failure such as a timeout.
+func (c *Client) Idempotent(method string) bool { return !c.nonIdempotent[method] }
+
+// Call invokes method and decodes the result into result, which may be nil
+// to discard it. Errors are classified (see Classify); once ctx is done its
+// error is returned unwrapped.
+func (c *Client) Call(ctx context.Context, result interface{}, method string, params ...interface{}) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	policy := c.Policy(method)
+	idempotent := c.Idempotent(method)
+	for attempt := 1; ; attempt++ {
+		raw, err := c.attempt(ctx, policy, Request{Method: method, Params: params, Attempt: attempt})
+		if err == nil {
+			if result == nil {
+				return nil
+			}
+			if err := json.Unmarshal(raw, result); err != nil {
+				return fmt.Errorf("%s: decode result: %w", method, err)
+			}
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if attempt >= policy.MaxAttempts || !retryable(err, idempotent) {
+			return err
+		}
+		if err := c.sleep(ctx, policy.delay(attempt)); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Client) attempt(ctx context.Context, p Policy, req Request) (json.RawMessage, error) {
+	req.ID = c.nextID.Add(1)
+	if c.hooks.OnRequest != nil {
+		c.hooks.OnRequest(req)
+	}
+	start := c.clock.Now()
+	attemptCtx, cancel := context.WithTimeout(ctx, p.Timeout)
+	var raw json.RawMessage
+	err := c.caller.CallContext(attemptCtx, &raw, req.Method, req.Params...)
+	cancel()
+	err = c.classify(ctx, attemptCtx, req.Method, p, err)
+	if err != nil {
+		raw = nil
+	}
+	c.respond(Response{Request: req, Result: raw, Err: err, Duration: c.clock.Now().Sub(start)})
+	return raw, err
+}
+
+// classify turns a deadline hit by attemptCtx, but not by the caller's ctx,
+// into ErrTimeout, and classifies everything else.
+func (c *Client) classify(ctx, attemptCtx context.Context, method string, p Policy, err error) error {
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+		return &RPCError{
+			Method:  method,
+			Message: fmt.Sprintf("no response within %s", p.Timeout),
+			Kind:    ErrTimeout,
+			Err:     err,
+		}
+	}
+	return Classify(method, err)
+}
+
+func (c *Client) respond(r Response) {
+	if c.hooks.OnResponse != nil {
+		c.hooks.OnResponse(r)
+	}
+}
+
+func (c *Client) sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.clock.After(d):
+		// Both can be ready, and select picks at random: don't start
+		// another attempt after cancellation.
+		return ctx.Err()
+	}
+}
+
+// BatchRequest is one call in a batch. Result is decoded into like in Call;
+// Err is set per request after Batch returns.
+type BatchRequest struct {
+	Method string
+	Params []interface{}
+	Result interface{}
+	Err    error
+}
+
+// Batch sends reqs as one JSON-RPC batch. Requests that fail with a
+// retryable error are re-sent together in a smaller batch, each up to its
+// own method's MaxAttempts; the rest keep their result or error. A batch
+// attempt is bounded by the longest timeout among the pending methods and
+// backs off per the default policy.
+//
+// Like rpc.Client.BatchCallContext, Batch reports failures per request. It
+// returns an error only when ctx ends before every request is settled.
+func (c *Client) Batch(ctx context.Context, reqs []BatchRequest) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	pending := make([]int, len(reqs))
+	for i := range reqs {
+		pending[i] = i
+		reqs[i].Err = nil
+	}
+	for attempt := 1; len(pending) > 0; attempt++ {
+		raws := c.batchAttempt(ctx, reqs, pending, attempt)
+		if ctx.Err() != nil {
+			for _, i := range pending {
+				reqs[i].Err = ctx.Err()
+			}
+			return ctx.Err()
+		}
+		var retry []int
+		for k, i := range pending {
+			r := &reqs[i]
+			if r.Err == nil {
+				if r.Result != nil {
+					if err := json.Unmarshal(raws[k], r.Result); err != nil {
+						r.Err = fmt.Errorf("%s: decode result: %w", r.Method, err)
+					}
+				}
+				continue
+			}
+			if attempt < c.Policy(r.Method).MaxAttempts && retryable(r.Err, c.Idempotent(r.Method)) {
+				retry = append(retry, i)
+			}
+		}
+		pending = retry
+		if len(pending) > 0 {
+			if err := c.sleep(ctx, c.def.delay(attempt)); err != nil {
+				for _, i := range pending {
+					reqs[i].Err = err
+				}
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// batchAttempt sends reqs[pending] in one batch, sets their Err and returns
+// their raw results in pending order.
+func (c *Client) batchAttempt(ctx context.Context, reqs []BatchRequest, pending []int, attempt int) []json.RawMessage {
+	var timeout time.Duration
+	elems := make([]rpc.BatchElem, len(pending))
+	raws := make([]json.RawMessage, len(pending))
+	sent := make([]Request, len(pending))
+	for k, i := range pending {
+		r := reqs[i]
+		timeout = max(timeout, c.Policy(r.Method).Timeout)
+		elems[k] = rpc.BatchElem{Method: r.Method, Args: r.Params, Result: &raws[k]}
+		sent[k] = Request{
+			ID:        c.nextID.Add(1),
+			Method:    r.Method,
+			Params:    r.Params,
+			Attempt:   attempt,
+			BatchSize: len(pending),
+		}
+		if c.hooks.OnRequest != nil {
+			c.hooks.OnRequest(sent[k])
+		}
+	}
+
+	start := c.clock.Now()
+	attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+	err := c.caller.BatchCallContext(attemptCtx, elems)
+	cancel()
+	elapsed := c.clock.Now().Sub(start)
+
+	for k, i := range pending {
+		// A transport error fails the whole batch; otherwise each element
+		// carries its own error.
+		elemErr := err
+		if elemErr == nil {
+			elemErr = elems[k].Error
+		}
+		p := Policy{Timeout: timeout}
+		reqs[i].Err = c.classify(ctx, attemptCtx, reqs[i].Method, p, elemErr)
+		if reqs[i].Err != nil {
+			raws[k] = nil
+		}
+		c.respond(Response{Request: sent[k], Result: raws[k], Err: reqs[i].Err, Duration: elapsed})
+	}
+	return raws
+}
@@ -101,8 +101,10 @@ func Run(ctx context.Context, client RPCClient, cfg Config) (*Result, error) {
 	// We are again using defensive copying to ensure the caller cannot mutate
 	// the RPC client's internal data.
 	//
-	// `types.CopyBlock` is the equivalent of `types.CopyHeader` from module 01.
-	// It performs a deep copy of the block, including its header and transactions.
+	// Unlike a Header, a `types.Block` needs no copy: it is immutable by
+	// design. Its fields are unexported and its accessors copy on the way
+	// out (`block.Header()` returns a `types.CopyHeader`, `block.Number()`
+	// a fresh big.Int), so sharing the pointer is safe.
 	//
 	// Note that `blockNumber` is a `uint64`, which is a value type in Go.
 	// Value types are copied by default, so we don't need to do anything special.
@@ -111,6 +113,6 @@ func Run(ctx context.Context, client RPCClient, cfg Config) (*Result, error) {
 	return &Result{
 		NetworkID:   new(big.Int).Set(networkID),
 		BlockNumber: blockNumber,
-		Block:       types.CopyBlock(block),
+		Block:       block, // immutable: accessors return copies
 	}, nil
 }
@@ -30,6 +30,8 @@ func Run(cfg Config) (*Result, error) {
 	// TODO: Generate a new secp256k1 private key
 	// - Use `crypto.GenerateKey()`
 	// - Handle and wrap any errors
+	// - If cfg.Mnemonic is set, derive the key instead with
+	//   `DeriveKey(cfg.Mnemonic, "", AccountPath(cfg.AccountIndex))`
 
 	// TODO: Derive the Ethereum address from the private key's public key
 	// - The public key can be accessed via `privKey.PublicKey`
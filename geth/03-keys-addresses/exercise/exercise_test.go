@@ -1,13 +1,17 @@
 package exercise
 
 import (
+	"context"
 	"encoding/hex"
+	"errors"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -98,3 +102,222 @@ func TestRunUsesDefaults(t *testing.T) {
 		t.Fatalf("keystore file not found at %s: %v", res.KeystorePath, err)
 	}
 }
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func openTestKeystore(t *testing.T) *Keystore {
+	t.Helper()
+	ks, err := OpenKeystore(KeystoreConfig{Dir: t.TempDir(), ScryptN: keystore.LightScryptN, ScryptP: keystore.LightScryptP})
+	if err != nil {
+		t.Fatalf("OpenKeystore: %v", err)
+	}
+	return ks
+}
+
+func TestBIP32Vector1(t *testing.T) {
+	t.Parallel()
+
+	// BIP-32 test vector 1: seed 000102...0f.
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	key, chain, err := masterKey(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(key); got != "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35" {
+		t.Fatalf("master key = %s", got)
+	}
+	if got := hex.EncodeToString(chain); got != "873dff81c02f525623fd1fe5167eac3a55a049de3d314bb42ee227ffed37d508" {
+		t.Fatalf("master chain code = %s", got)
+	}
+	key, _, err = childKey(key, chain, hardenedOffset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(key); got != "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea" {
+		t.Fatalf("m/0' key = %s", got)
+	}
+}
+
+func TestDeriveKeyFromMnemonic(t *testing.T) {
+	t.Parallel()
+
+	for i, want := range []string{
+		"0x9858EfFD232B4033E47d90003D41EC34EcaEda94",
+		"0x6Fac4D18c912343BF86fa7049364Dd4E424Ab9C0",
+	} {
+		key, err := DeriveKey(testMnemonic, "", AccountPath(uint32(i)))
+		if err != nil {
+			t.Fatalf("DeriveKey(%d): %v", i, err)
+		}
+		if got := crypto.PubkeyToAddress(key.PublicKey); got != common.HexToAddress(want) {
+			t.Fatalf("account %d = %s, want %s", i, got.Hex(), want)
+		}
+	}
+	if got := AccountPath(3).String(); got != "m/44'/60'/0'/0/3" {
+		t.Fatalf("AccountPath(3) = %s", got)
+	}
+
+	// Last word changed: the checksum no longer matches.
+	bad := strings.Replace(testMnemonic, "about", "abandon", 1)
+	if _, err := DeriveKey(bad, "", AccountPath(0)); !errors.Is(err, ErrInvalidMnemonic) {
+		t.Fatalf("expected ErrInvalidMnemonic, got %v", err)
+	}
+
+	m, err := NewMnemonic(128)
+	if err != nil || len(strings.Fields(m)) != 12 {
+		t.Fatalf("NewMnemonic(128) = %q, %v", m, err)
+	}
+}
+
+func TestKeystoreImportExport(t *testing.T) {
+	t.Parallel()
+
+	ks := openTestKeystore(t)
+	const hexKey = "1ab42cc412b618bdea3a599e3c9bae199ebf030895b039e9db1e30dafb12b727"
+
+	fromHex, err := ks.ImportHex("0x"+hexKey, "one")
+	if err != nil {
+		t.Fatalf("ImportHex: %v", err)
+	}
+	if fromHex.Address != common.HexToAddress("0x9858EfFD232B4033E47d90003D41EC34EcaEda94") {
+		t.Fatalf("imported %s", fromHex.Address.Hex())
+	}
+	// The same key from its mnemonic is a duplicate.
+	if _, err := ks.ImportMnemonic(testMnemonic, "", 0, "two"); !errors.Is(err, keystore.ErrAccountAlreadyExists) {
+		t.Fatalf("expected duplicate import to fail, got %v", err)
+	}
+	second, err := ks.ImportMnemonic(testMnemonic, "", 1, "two")
+	if err != nil {
+		t.Fatalf("ImportMnemonic: %v", err)
+	}
+	if n := len(ks.Accounts()); n != 2 {
+		t.Fatalf("Accounts() has %d entries, want 2", n)
+	}
+
+	got, err := ks.ExportHex(fromHex.Address, "one")
+	if err != nil || got != hexKey {
+		t.Fatalf("ExportHex = %s, %v", got, err)
+	}
+	if _, err := ks.ExportHex(fromHex.Address, "wrong"); !errors.Is(err, keystore.ErrDecrypt) {
+		t.Fatalf("expected ErrDecrypt, got %v", err)
+	}
+
+	keyJSON, err := ks.Export(second.Address, "two", "moved")
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	other := openTestKeystore(t)
+	moved, err := other.ks.Import(keyJSON, "moved", "moved")
+	if err != nil || moved.Address != second.Address {
+		t.Fatalf("re-import exported key: %v, %v", moved.Address.Hex(), err)
+	}
+}
+
+func TestKeystoreChangePassphrase(t *testing.T) {
+	t.Parallel()
+
+	ks := openTestKeystore(t)
+	acct, err := ks.NewAccount("old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.ChangePassphrase(acct.Address, "nope", "new"); !errors.Is(err, keystore.ErrDecrypt) {
+		t.Fatalf("expected ErrDecrypt for wrong old passphrase, got %v", err)
+	}
+	if err := ks.ChangePassphrase(acct.Address, "old", "new"); err != nil {
+		t.Fatalf("ChangePassphrase: %v", err)
+	}
+	if _, err := ks.ExportHex(acct.Address, "old"); err == nil {
+		t.Fatalf("old passphrase still works")
+	}
+	if _, err := ks.ExportHex(acct.Address, "new"); err != nil {
+		t.Fatalf("new passphrase: %v", err)
+	}
+	if _, err := ks.Find(common.HexToAddress("0x01")); err == nil {
+		t.Fatalf("expected unknown address to fail")
+	}
+}
+
+func TestSignAndVerifyMessage(t *testing.T) {
+	t.Parallel()
+
+	ks := openTestKeystore(t)
+	acct, err := ks.ImportMnemonic(testMnemonic, "", 0, "pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("hello, ethereum")
+	sig, err := ks.SignMessage(acct.Address, "pw", msg)
+	if err != nil {
+		t.Fatalf("SignMessage: %v", err)
+	}
+	if v := sig[64]; v != 27 && v != 28 {
+		t.Fatalf("V = %d, want 27 or 28", v)
+	}
+
+	// Same signature as signing the EIP-191 hash directly.
+	key, _ := DeriveKey(testMnemonic, "", AccountPath(0))
+	want, _ := crypto.Sign(accounts.TextHash(msg), key)
+	want[64] += 27
+	if hex.EncodeToString(sig) != hex.EncodeToString(want) {
+		t.Fatalf("signature mismatch")
+	}
+
+	if err := VerifyMessage(acct.Address, msg, sig); err != nil {
+		t.Fatalf("VerifyMessage: %v", err)
+	}
+	raw := append([]byte(nil), sig...)
+	raw[64] -= 27
+	if err := VerifyMessage(acct.Address, msg, raw); err != nil {
+		t.Fatalf("VerifyMessage with V in {0,1}: %v", err)
+	}
+	if err := VerifyMessage(acct.Address, []byte("tampered"), sig); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature, got %v", err)
+	}
+	if err := VerifyMessage(acct.Address, msg, sig[:64]); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature for short sig, got %v", err)
+	}
+}
+
+func TestGenerateVanity(t *testing.T) {
+	t.Parallel()
+
+	res, err := GenerateVanity(context.Background(), VanityConfig{Prefix: "0xA", Suffix: "b", Workers: 4})
+	if err != nil {
+		t.Fatalf("GenerateVanity: %v", err)
+	}
+	h := strings.ToLower(res.Address.Hex())
+	if !strings.HasPrefix(h, "0xa") || !strings.HasSuffix(h, "b") {
+		t.Fatalf("address %s does not match", h)
+	}
+	if crypto.PubkeyToAddress(res.Key.PublicKey) != res.Address || res.Attempts == 0 {
+		t.Fatalf("bad result %+v", res)
+	}
+
+	if _, err := GenerateVanity(context.Background(), VanityConfig{Prefix: "xyz"}); err == nil {
+		t.Fatalf("expected non-hex prefix to fail")
+	}
+
+	// 40 characters will not be found; cancellation must stop every worker.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = GenerateVanity(ctx, VanityConfig{Prefix: strings.Repeat("0", 40), Workers: 2})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestRunFromMnemonic(t *testing.T) {
+	t.Parallel()
+
+	res, err := Run(Config{OutputDir: t.TempDir(), Passphrase: "pw", Mnemonic: testMnemonic, AccountIndex: 1})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if res.Address != common.HexToAddress("0x6Fac4D18c912343BF86fa7049364Dd4E424Ab9C0") {
+		t.Fatalf("address = %s", res.Address.Hex())
+	}
+	if _, err := Run(Config{OutputDir: t.TempDir(), Mnemonic: "not a mnemonic"}); !errors.Is(err, ErrInvalidMnemonic) {
+		t.Fatalf("expected ErrInvalidMnemonic, got %v", err)
+	}
+}
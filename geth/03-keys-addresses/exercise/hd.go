@@ -0,0 +1,116 @@
+package exercise
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// ErrInvalidMnemonic is returned when a mnemonic fails the BIP-39 wordlist or
+// checksum check.
+var ErrInvalidMnemonic = errors.New("invalid mnemonic")
+
+// hardenedOffset marks a hardened BIP-32 index (written i' in paths).
+const hardenedOffset = 0x80000000
+
+// AccountPath returns the standard Ethereum derivation path m/44'/60'/0'/0/i,
+// the one MetaMask, Ledger Live's legacy mode and geth all use.
+func AccountPath(i uint32) accounts.DerivationPath {
+	path := make(accounts.DerivationPath, 0, len(accounts.DefaultRootDerivationPath)+1)
+	path = append(path, accounts.DefaultRootDerivationPath...)
+	return append(path, i)
+}
+
+// NewMnemonic returns a fresh BIP-39 mnemonic with the given entropy
+// (128 bits => 12 words, 256 bits => 24 words).
+func NewMnemonic(bits int) (string, error) {
+	entropy, err := bip39.NewEntropy(bits)
+	if err != nil {
+		return "", fmt.Errorf("new entropy: %w", err)
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// DeriveKey turns a mnemonic into the private key at path.
+//
+//  1. BIP-39: seed = PBKDF2-HMAC-SHA512(mnemonic, "mnemonic"+password, 2048 rounds)
+//  2. BIP-32: master (key, chain code) = HMAC-SHA512("Bitcoin seed", seed)
+//  3. BIP-32: walk path one child at a time
+//
+// password is the optional BIP-39 "25th word", not a keystore passphrase.
+func DeriveKey(mnemonic, password string, path accounts.DerivationPath) (*ecdsa.PrivateKey, error) {
+	mnemonic = strings.Join(strings.Fields(mnemonic), " ")
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, password)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMnemonic, err)
+	}
+	key, chain, err := masterKey(seed)
+	if err != nil {
+		return nil, err
+	}
+	for depth, index := range path {
+		key, chain, err = childKey(key, chain, index)
+		if err != nil {
+			return nil, fmt.Errorf("derive %s at depth %d: %w", path, depth+1, err)
+		}
+	}
+	return crypto.ToECDSA(key)
+}
+
+// masterKey is BIP-32's master key generation.
+func masterKey(seed []byte) (key, chain []byte, err error) {
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	if !validScalar(sum[:32]) {
+		return nil, nil, errors.New("seed produced an invalid master key")
+	}
+	return sum[:32], sum[32:], nil
+}
+
+// childKey is BIP-32's CKDpriv. Hardened children mix in the parent private
+// key, normal children the compressed parent public key; either way the
+// child key is (IL + parent) mod n.
+func childKey(parent, chain []byte, index uint32) (key, childChain []byte, err error) {
+	var data []byte
+	if index >= hardenedOffset {
+		data = append([]byte{0}, parent...)
+	} else {
+		priv, err := crypto.ToECDSA(parent)
+		if err != nil {
+			return nil, nil, err
+		}
+		data = crypto.CompressPubkey(&priv.PublicKey)
+	}
+	data = binary.BigEndian.AppendUint32(data, index)
+
+	mac := hmac.New(sha512.New, chain)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	il := new(big.Int).SetBytes(sum[:32])
+	n := crypto.S256().Params().N
+	if il.Cmp(n) >= 0 {
+		return nil, nil, fmt.Errorf("index %d: IL out of range", index)
+	}
+	k := il.Add(il, new(big.Int).SetBytes(parent))
+	k.Mod(k, n)
+	if k.Sign() == 0 {
+		return nil, nil, fmt.Errorf("index %d: zero child key", index)
+	}
+	return k.FillBytes(make([]byte, 32)), sum[32:], nil
+}
+
+func validScalar(b []byte) bool {
+	k := new(big.Int).SetBytes(b)
+	return k.Sign() > 0 && k.Cmp(crypto.S256().Params().N) < 0
+}
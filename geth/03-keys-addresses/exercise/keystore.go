@@ -0,0 +1,209 @@
+package exercise
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ErrInvalidSignature is returned by VerifyMessage for signatures that are
+// malformed or were made by a different address.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// KeystoreConfig selects the directory and scrypt cost of a Keystore.
+type KeystoreConfig struct {
+	Dir string // created with 0700 when missing
+
+	// ScryptN/ScryptP default to keystore.StandardScryptN/P. Tests and demos
+	// can use keystore.LightScryptN/P, which decrypts in milliseconds.
+	ScryptN int
+	ScryptP int
+}
+
+// Keystore manages the encrypted key files in one directory. Every operation
+// that needs the private key takes the passphrase and decrypts on demand;
+// nothing stays unlocked.
+type Keystore struct {
+	ks  *keystore.KeyStore
+	dir string
+}
+
+// OpenKeystore opens (creating if needed) the keystore directory in cfg.
+func OpenKeystore(cfg KeystoreConfig) (*Keystore, error) {
+	if cfg.Dir == "" {
+		return nil, errors.New("keystore dir is empty")
+	}
+	if cfg.ScryptN == 0 {
+		cfg.ScryptN = keystore.StandardScryptN
+	}
+	if cfg.ScryptP == 0 {
+		cfg.ScryptP = keystore.StandardScryptP
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create keystore directory: %w", err)
+	}
+	return &Keystore{ks: keystore.NewKeyStore(cfg.Dir, cfg.ScryptN, cfg.ScryptP), dir: cfg.Dir}, nil
+}
+
+// Dir returns the keystore directory.
+func (k *Keystore) Dir() string { return k.dir }
+
+// Accounts lists the accounts in the directory, sorted by file URL.
+func (k *Keystore) Accounts() []accounts.Account {
+	return k.ks.Accounts()
+}
+
+// Find returns the account for addr.
+func (k *Keystore) Find(addr common.Address) (accounts.Account, error) {
+	acct, err := k.ks.Find(accounts.Account{Address: addr})
+	if err != nil {
+		return accounts.Account{}, fmt.Errorf("find %s: %w", addr.Hex(), err)
+	}
+	return acct, nil
+}
+
+// NewAccount generates a random key and stores it under passphrase.
+func (k *Keystore) NewAccount(passphrase string) (accounts.Account, error) {
+	acct, err := k.ks.NewAccount(passphrase)
+	if err != nil {
+		return accounts.Account{}, fmt.Errorf("new account: %w", err)
+	}
+	return acct, nil
+}
+
+// ImportKey stores priv under passphrase. Importing an address that is
+// already present fails with keystore.ErrAccountAlreadyExists.
+func (k *Keystore) ImportKey(priv *ecdsa.PrivateKey, passphrase string) (accounts.Account, error) {
+	acct, err := k.ks.ImportECDSA(priv, passphrase)
+	if err != nil {
+		return accounts.Account{}, fmt.Errorf("import key: %w", err)
+	}
+	return acct, nil
+}
+
+// ImportHex imports a raw hex private key, with or without 0x.
+func (k *Keystore) ImportHex(hexKey, passphrase string) (accounts.Account, error) {
+	priv, err := crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(hexKey), "0x"))
+	if err != nil {
+		return accounts.Account{}, fmt.Errorf("parse private key: %w", err)
+	}
+	return k.ImportKey(priv, passphrase)
+}
+
+// ImportMnemonic derives the key at m/44'/60'/0'/0/index and imports it.
+// password is the optional BIP-39 password; passphrase encrypts the key file.
+func (k *Keystore) ImportMnemonic(mnemonic, password string, index uint32, passphrase string) (accounts.Account, error) {
+	priv, err := DeriveKey(mnemonic, password, AccountPath(index))
+	if err != nil {
+		return accounts.Account{}, err
+	}
+	return k.ImportKey(priv, passphrase)
+}
+
+// Export returns the key file of addr re-encrypted under newPassphrase,
+// ready to be imported into another keystore.
+func (k *Keystore) Export(addr common.Address, passphrase, newPassphrase string) ([]byte, error) {
+	acct, err := k.Find(addr)
+	if err != nil {
+		return nil, err
+	}
+	keyJSON, err := k.ks.Export(acct, passphrase, newPassphrase)
+	if err != nil {
+		return nil, fmt.Errorf("export %s: %w", addr.Hex(), err)
+	}
+	return keyJSON, nil
+}
+
+// ExportHex decrypts addr's key and returns it as hex without 0x. Anyone
+// holding the result controls the account.
+func (k *Keystore) ExportHex(addr common.Address, passphrase string) (string, error) {
+	priv, err := k.decrypt(addr, passphrase)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(crypto.FromECDSA(priv)), nil
+}
+
+// ChangePassphrase re-encrypts addr's key file in place.
+func (k *Keystore) ChangePassphrase(addr common.Address, oldPassphrase, newPassphrase string) error {
+	acct, err := k.Find(addr)
+	if err != nil {
+		return err
+	}
+	if err := k.ks.Update(acct, oldPassphrase, newPassphrase); err != nil {
+		return fmt.Errorf("change passphrase of %s: %w", addr.Hex(), err)
+	}
+	return nil
+}
+
+// SignMessage signs msg the way personal_sign / eth_sign do (EIP-191
+// version 0x45): keccak256("\x19Ethereum Signed Message:\n" + len(msg) + msg).
+// The returned signature is [R || S || V] with V in {27, 28}, which is what
+// wallets and Solidity's ecrecover expect.
+func (k *Keystore) SignMessage(addr common.Address, passphrase string, msg []byte) ([]byte, error) {
+	acct, err := k.Find(addr)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := k.ks.SignHashWithPassphrase(acct, passphrase, accounts.TextHash(msg))
+	if err != nil {
+		return nil, fmt.Errorf("sign message: %w", err)
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	return sig, nil
+}
+
+// VerifyMessage checks that sig is addr's EIP-191 signature of msg. V may be
+// 0/1 or 27/28.
+func VerifyMessage(addr common.Address, msg, sig []byte) error {
+	signer, err := RecoverMessageSigner(msg, sig)
+	if err != nil {
+		return err
+	}
+	if signer != addr {
+		return fmt.Errorf("%w: signed by %s, not %s", ErrInvalidSignature, signer.Hex(), addr.Hex())
+	}
+	return nil
+}
+
+// RecoverMessageSigner returns the address that produced an EIP-191
+// signature of msg.
+func RecoverMessageSigner(msg, sig []byte) (common.Address, error) {
+	if len(sig) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("%w: %d bytes, want %d", ErrInvalidSignature, len(sig), crypto.SignatureLength)
+	}
+	rsv := common.CopyBytes(sig)
+	if rsv[crypto.RecoveryIDOffset] >= 27 {
+		rsv[crypto.RecoveryIDOffset] -= 27
+	}
+	pub, err := crypto.SigToPub(accounts.TextHash(msg), rsv)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// decrypt loads addr's key file and decrypts it.
+func (k *Keystore) decrypt(addr common.Address, passphrase string) (*ecdsa.PrivateKey, error) {
+	acct, err := k.Find(addr)
+	if err != nil {
+		return nil, err
+	}
+	keyJSON, err := os.ReadFile(acct.URL.Path)
+	if err != nil {
+		return nil, fmt.Errorf("read key file: %w", err)
+	}
+	key, err := keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt %s: %w", addr.Hex(), err)
+	}
+	return key.PrivateKey, nil
+}
@@ -4,6 +4,7 @@
 package exercise
 
 import (
+	"crypto/ecdsa"
 	"encoding/hex"
 	"fmt"
 	"os"
@@ -45,9 +46,21 @@ func Run(cfg Config) (*Result, error) {
 	//
 	// `crypto.GenerateKey` uses `crypto/rand` to ensure the key is generated
 	// from a cryptographically secure random number source.
-	privKey, err := crypto.GenerateKey()
-	if err != nil {
-		return nil, fmt.Errorf("generate secp256k1 key: %w", err)
+	//
+	// With a mnemonic we derive the key instead (BIP-39 seed, then BIP-32 down
+	// m/44'/60'/0'/0/i), so the same phrase always recovers the same account.
+	var privKey *ecdsa.PrivateKey
+	var err error
+	if cfg.Mnemonic != "" {
+		privKey, err = DeriveKey(cfg.Mnemonic, "", AccountPath(cfg.AccountIndex))
+		if err != nil {
+			return nil, fmt.Errorf("derive key from mnemonic: %w", err)
+		}
+	} else {
+		privKey, err = crypto.GenerateKey()
+		if err != nil {
+			return nil, fmt.Errorf("generate secp256k1 key: %w", err)
+		}
 	}
 
 	// ============================================================================
@@ -11,6 +11,11 @@ type Config struct {
 	// Passphrase encrypts/decrypts the keystore. The default "changeit"
 	// is intentionally weak so demos can be recovered quickly.
 	Passphrase string
+
+	// Mnemonic, when set, imports the key at m/44'/60'/0'/0/AccountIndex
+	// derived from this BIP-39 phrase instead of generating a random one.
+	Mnemonic     string
+	AccountIndex uint32
 }
 
 // Result summarizes the outputs of a Run invocation.
@@ -0,0 +1,106 @@
+package exercise
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// VanityConfig describes the address to search for.
+type VanityConfig struct {
+	Prefix  string // hex the address must start with, case-insensitive, 0x optional
+	Suffix  string // hex the address must end with, case-insensitive
+	Workers int    // goroutines to search with (default runtime.NumCPU())
+}
+
+// VanityResult is a key whose address matched.
+type VanityResult struct {
+	Key      *ecdsa.PrivateKey
+	Address  common.Address
+	Attempts uint64 // keys generated across all workers
+}
+
+// GenerateVanity generates random keys on Workers goroutines until one's
+// address matches, or ctx is done. Every extra hex character multiplies the
+// expected work by 16: a 4-character prefix takes ~65k attempts, an
+// 8-character one ~4 billion.
+func GenerateVanity(ctx context.Context, cfg VanityConfig) (*VanityResult, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	prefix := strings.ToLower(strings.TrimPrefix(cfg.Prefix, "0x"))
+	suffix := strings.ToLower(cfg.Suffix)
+	if len(prefix)+len(suffix) > common.AddressLength*2 {
+		return nil, errors.New("prefix and suffix are longer than an address")
+	}
+	for _, part := range []string{prefix, suffix} {
+		if strings.Trim(part, "0123456789abcdef") != "" {
+			return nil, fmt.Errorf("%q is not hex", part)
+		}
+	}
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		attempts atomic.Uint64
+		found    = make(chan *ecdsa.PrivateKey, 1)
+		errc     = make(chan error, 1)
+		wg       sync.WaitGroup
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := make([]byte, common.AddressLength*2)
+			for ctx.Err() == nil {
+				key, err := crypto.GenerateKey()
+				if err != nil {
+					select {
+					case errc <- err:
+					default:
+					}
+					cancel()
+					return
+				}
+				attempts.Add(1)
+				addr := crypto.PubkeyToAddress(key.PublicKey)
+				hex.Encode(buf, addr[:])
+				if strings.HasPrefix(string(buf), prefix) && strings.HasSuffix(string(buf), suffix) {
+					select {
+					case found <- key:
+						cancel()
+					default: // another worker won
+					}
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	select {
+	case key := <-found:
+		return &VanityResult{Key: key, Address: crypto.PubkeyToAddress(key.PublicKey), Attempts: attempts.Load()}, nil
+	default:
+	}
+	select {
+	case err := <-errc:
+		return nil, fmt.Errorf("generate key: %w", err)
+	default:
+	}
+	return nil, fmt.Errorf("vanity search stopped after %d attempts: %w", attempts.Load(), ctx.Err())
+}
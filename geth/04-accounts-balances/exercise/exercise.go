@@ -32,6 +32,12 @@ func Run(ctx context.Context, client AccountClient, cfg Config) (*Result, error)
 	// - Use `make([]AccountState, 0, len(cfg.Addresses))` to create a slice
 	//   with a pre-allocated capacity.
 
+	// TODO: If cfg.BatchSize > 0, fetch everything with JSON-RPC batches
+	// - Build one `rpc.BatchElem` per eth_getBalance and eth_getCode call
+	//   (results into `hexutil.Big` / `hexutil.Bytes`)
+	// - Send them with `batchCall(ctx, client, elems, cfg.BatchSize)` and
+	//   check each element's Error before building the account states
+
 	// TODO: Iterate over the addresses in `cfg.Addresses`
 	// - For each address, you will query its balance and code.
 
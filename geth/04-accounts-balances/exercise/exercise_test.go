@@ -2,11 +2,17 @@ package exercise
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"math/big"
+	"strings"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 type mockAccountClient struct {
@@ -15,6 +21,11 @@ type mockAccountClient struct {
 	errBal   error
 	errCode  error
 	lastReq  []requestRecord
+
+	tokens    map[common.Address]map[common.Address]*big.Int // token -> owner -> balance
+	snapshots map[string]*mockAccountClient                  // state by block arg, for batch calls
+	failAddr  common.Address                                 // batch reads for this address fail
+	batches   []int                                          // size of every batch received
 }
 
 type requestRecord struct {
@@ -38,6 +49,81 @@ func (m *mockAccountClient) CodeAt(ctx context.Context, account common.Address,
 	return m.codes[account], nil
 }
 
+// BatchCallContext answers like a node would: every element is served and
+// its result goes through JSON, as with a real rpc.Client.
+func (m *mockAccountClient) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	m.batches = append(m.batches, len(b))
+	for i := range b {
+		el := &b[i]
+		state := m
+		if snap := m.snapshots[el.Args[len(el.Args)-1].(string)]; snap != nil {
+			state = snap
+		}
+		v, err := state.serve(el.Method, el.Args)
+		if err != nil {
+			el.Error = err
+			continue
+		}
+		raw, _ := json.Marshal(v)
+		el.Error = json.Unmarshal(raw, el.Result)
+	}
+	return nil
+}
+
+func (m *mockAccountClient) serve(method string, args []interface{}) (interface{}, error) {
+	switch method {
+	case "eth_getBalance", "eth_getCode":
+		a := args[0].(common.Address)
+		if a == m.failAddr {
+			return nil, errors.New("header not found")
+		}
+		if method == "eth_getCode" {
+			return hexutil.Bytes(m.codes[a]), nil
+		}
+		return (*hexutil.Big)(orZero(m.balances[a])), nil
+	case "eth_call":
+		call := args[0].(map[string]interface{})
+		ret, err := m.call(call["to"].(common.Address), call["data"].(hexutil.Bytes))
+		return hexutil.Bytes(ret), err
+	}
+	return nil, fmt.Errorf("method %s not supported", method)
+}
+
+func (m *mockAccountClient) call(to common.Address, data []byte) ([]byte, error) {
+	if to == Multicall3Address {
+		method, err := multicallABI.MethodById(data[:4])
+		if err != nil {
+			return nil, err
+		}
+		args, err := method.Inputs.Unpack(data[4:])
+		if err != nil {
+			return nil, err
+		}
+		if method.Name == "getEthBalance" {
+			return method.Outputs.Pack(orZero(m.balances[args[0].(common.Address)]))
+		}
+		calls := *abi.ConvertType(args[0], new([]multicallCall)).(*[]multicallCall)
+		results := make([]multicallResult, len(calls))
+		for i, c := range calls {
+			ret, err := m.call(c.Target, c.CallData)
+			if err != nil && !c.AllowFailure {
+				return nil, err
+			}
+			results[i] = multicallResult{Success: err == nil, ReturnData: ret}
+		}
+		return method.Outputs.Pack(results)
+	}
+	holders, ok := m.tokens[to]
+	if !ok || len(data) != 36 {
+		return nil, errors.New("execution reverted")
+	}
+	owner := common.BytesToAddress(data[4:])
+	if owner == m.failAddr {
+		return nil, errors.New("execution reverted")
+	}
+	return common.LeftPadBytes(orZero(holders[owner]).Bytes(), 32), nil
+}
+
 func addr(hexStr string) common.Address {
 	return common.HexToAddress(hexStr)
 }
@@ -108,3 +194,163 @@ func TestRunErrors(t *testing.T) {
 		t.Fatalf("expected empty address error")
 	}
 }
+
+func numberedAddrs(n int) []common.Address {
+	out := make([]common.Address, n)
+	for i := range out {
+		out[i] = common.BigToAddress(big.NewInt(int64(0x1000 + i)))
+	}
+	return out
+}
+
+var (
+	tokenA = Token{Address: addr("0x00000000000000000000000000000000000000aa"), Symbol: "AAA", Decimals: 18}
+	tokenB = Token{Address: addr("0x00000000000000000000000000000000000000bb"), Symbol: "BBB", Decimals: 6}
+)
+
+// portfolioClient gives address i an ETH balance of i, i*10 AAA and i*100 BBB.
+func portfolioClient(addrs []common.Address) *mockAccountClient {
+	m := &mockAccountClient{
+		balances: make(map[common.Address]*big.Int),
+		codes:    make(map[common.Address][]byte),
+		tokens: map[common.Address]map[common.Address]*big.Int{
+			tokenA.Address: {},
+			tokenB.Address: {},
+		},
+	}
+	for i, a := range addrs {
+		m.balances[a] = big.NewInt(int64(i))
+		m.tokens[tokenA.Address][a] = big.NewInt(int64(i * 10))
+		m.tokens[tokenB.Address][a] = big.NewInt(int64(i * 100))
+		if i%2 == 1 {
+			m.codes[a] = []byte{0x60, 0x80}
+		}
+	}
+	return m
+}
+
+func checkPortfolios(t *testing.T, snap *Snapshot, addrs []common.Address) {
+	t.Helper()
+	if len(snap.Portfolios) != len(addrs) {
+		t.Fatalf("%d portfolios, want %d", len(snap.Portfolios), len(addrs))
+	}
+	for i, p := range snap.Portfolios {
+		if p.Address != addrs[i] || p.ETH.Int64() != int64(i) ||
+			p.Tokens[tokenA.Address].Int64() != int64(i*10) || p.Tokens[tokenB.Address].Int64() != int64(i*100) {
+			t.Fatalf("portfolio %d = %+v", i, p)
+		}
+	}
+}
+
+func TestRunBatched(t *testing.T) {
+	addrs := numberedAddrs(250)
+	client := portfolioClient(addrs)
+
+	res, err := Run(context.Background(), client, Config{Addresses: addrs, BatchSize: 100})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	// 250 addresses * 2 requests = 500 requests = 5 batches, no single calls.
+	if len(client.batches) != 5 || len(client.lastReq) != 0 {
+		t.Fatalf("batches %v, single calls %d", client.batches, len(client.lastReq))
+	}
+	for i, acct := range res.Accounts {
+		wantType := AccountTypeEOA
+		if i%2 == 1 {
+			wantType = AccountTypeContract
+		}
+		if acct.Address != addrs[i] || acct.Balance.Int64() != int64(i) || acct.Type != wantType {
+			t.Fatalf("account %d = %+v", i, acct)
+		}
+	}
+
+	client.failAddr = addrs[7]
+	if _, err := Run(context.Background(), client, Config{Addresses: addrs, BatchSize: 100}); err == nil || !strings.Contains(err.Error(), addrs[7].Hex()) {
+		t.Fatalf("expected error naming %s, got %v", addrs[7].Hex(), err)
+	}
+}
+
+func TestScanPortfoliosBatch(t *testing.T) {
+	addrs := numberedAddrs(1000)
+	client := portfolioClient(addrs)
+
+	snap, err := ScanPortfolios(context.Background(), client, addrs, ScanConfig{Tokens: []Token{tokenA, tokenB}})
+	if err != nil {
+		t.Fatalf("ScanPortfolios: %v", err)
+	}
+	checkPortfolios(t, snap, addrs)
+	// 1000 * (ETH + 2 tokens) = 3000 reads in batches of 100.
+	if len(client.batches) != 30 {
+		t.Fatalf("%d round-trips, want 30", len(client.batches))
+	}
+
+	client.failAddr = addrs[3]
+	_, err = ScanPortfolios(context.Background(), client, addrs, ScanConfig{Tokens: []Token{tokenA}})
+	if err == nil || !strings.Contains(err.Error(), addrs[3].Hex()) {
+		t.Fatalf("expected error naming %s, got %v", addrs[3].Hex(), err)
+	}
+}
+
+func TestScanPortfoliosMulticall(t *testing.T) {
+	addrs := numberedAddrs(1000)
+	client := portfolioClient(addrs)
+	mc := Multicall3Address
+
+	snap, err := ScanPortfolios(context.Background(), client, addrs, ScanConfig{Tokens: []Token{tokenA, tokenB}, Multicall: &mc})
+	if err != nil {
+		t.Fatalf("ScanPortfolios: %v", err)
+	}
+	checkPortfolios(t, snap, addrs)
+	// 3000 reads / 500 per aggregate3 = 6 eth_calls, all in one batch.
+	if len(client.batches) != 1 || client.batches[0] != 6 {
+		t.Fatalf("batches %v, want [6]", client.batches)
+	}
+
+	// A token that reverts is reported, not silently read as zero.
+	bogus := Token{Address: addr("0x00000000000000000000000000000000000000cc"), Symbol: "BOGUS"}
+	_, err = ScanPortfolios(context.Background(), client, addrs[:3], ScanConfig{Tokens: []Token{bogus}, Multicall: &mc})
+	if err == nil || !strings.Contains(err.Error(), "BOGUS") {
+		t.Fatalf("expected BOGUS revert, got %v", err)
+	}
+}
+
+func TestDiffBlocks(t *testing.T) {
+	alice, bob := addr("0x00000000000000000000000000000000000000a1"), addr("0x00000000000000000000000000000000000000b0")
+	at := func(ethA, ethB, tokA, tokB int64) *mockAccountClient {
+		return &mockAccountClient{
+			balances: map[common.Address]*big.Int{alice: big.NewInt(ethA), bob: big.NewInt(ethB)},
+			tokens: map[common.Address]map[common.Address]*big.Int{
+				tokenA.Address: {alice: big.NewInt(tokA), bob: big.NewInt(tokB)},
+			},
+		}
+	}
+	client := &mockAccountClient{snapshots: map[string]*mockAccountClient{
+		"0xa": at(100, 5, 7, 0),
+		"0xb": at(60, 5, 0, 7), // alice paid 40 wei and moved 7 AAA to bob
+	}}
+
+	for _, mc := range []*common.Address{nil, &Multicall3Address} {
+		diffs, err := DiffBlocks(context.Background(), client, []common.Address{bob, alice}, big.NewInt(10), big.NewInt(11), ScanConfig{Tokens: []Token{tokenA}, Multicall: mc})
+		if err != nil {
+			t.Fatalf("DiffBlocks: %v", err)
+		}
+		got := make([]string, len(diffs))
+		for i, d := range diffs {
+			got[i] = fmt.Sprintf("%s/%s %s->%s (%s)", strings.ToLower(d.Address.Hex()[40:]), strings.ToLower(d.Token.Hex()[40:]), d.Before, d.After, d.Delta)
+		}
+		want := "[a1/00 100->60 (-40) a1/aa 7->0 (-7) b0/aa 0->7 (7)]"
+		if fmt.Sprint(got) != want {
+			t.Fatalf("diffs = %v, want %s", got, want)
+		}
+	}
+}
+
+func TestDiffSnapshotsMissingBalances(t *testing.T) {
+	a := addr("0x01")
+	before := &Snapshot{Portfolios: []Portfolio{{Address: a, ETH: big.NewInt(1)}}}
+	after := &Snapshot{Portfolios: []Portfolio{{Address: a, ETH: big.NewInt(1), Tokens: map[common.Address]*big.Int{tokenA.Address: big.NewInt(3)}}}}
+	diffs := DiffSnapshots(before, after)
+	if len(diffs) != 1 || diffs[0].Token != tokenA.Address || diffs[0].Before.Sign() != 0 || diffs[0].Delta.Int64() != 3 {
+		t.Fatalf("diffs = %+v", diffs)
+	}
+}
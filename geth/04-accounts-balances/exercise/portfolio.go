@@ -0,0 +1,390 @@
+package exercise
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Multicall3Address is where Multicall3 is deployed on mainnet, most
+// testnets and most L2s (same address everywhere via a keyless deployment).
+var Multicall3Address = common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+
+// Multicall3ABI is the subset of Multicall3 the scanner uses.
+const Multicall3ABI = `[
+	{"type":"function","name":"aggregate3","stateMutability":"payable",
+	 "inputs":[{"name":"calls","type":"tuple[]","components":[
+		{"name":"target","type":"address"},{"name":"allowFailure","type":"bool"},{"name":"callData","type":"bytes"}]}],
+	 "outputs":[{"name":"returnData","type":"tuple[]","components":[
+		{"name":"success","type":"bool"},{"name":"returnData","type":"bytes"}]}]},
+	{"type":"function","name":"getEthBalance","stateMutability":"view",
+	 "inputs":[{"name":"addr","type":"address"}],"outputs":[{"name":"balance","type":"uint256"}]}
+]`
+
+const balanceOfABI = `[{"type":"function","name":"balanceOf","stateMutability":"view",
+	"inputs":[{"name":"account","type":"address"}],"outputs":[{"name":"","type":"uint256"}]}]`
+
+var (
+	multicallABI = mustABI(Multicall3ABI)
+	erc20ABI     = mustABI(balanceOfABI)
+)
+
+func mustABI(s string) abi.ABI {
+	a, err := abi.JSON(strings.NewReader(s))
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// multicallCall and multicallResult mirror Multicall3's Call3 and Result
+// structs; field names must match the ABI components for packing.
+type multicallCall struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+type multicallResult struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// RPCClient adapts a raw *rpc.Client to AccountClient, keeping access to
+// JSON-RPC batching that ethclient does not expose.
+type RPCClient struct {
+	*ethclient.Client
+	rpc *rpc.Client
+}
+
+// NewRPCClient wraps c.
+func NewRPCClient(c *rpc.Client) *RPCClient {
+	return &RPCClient{Client: ethclient.NewClient(c), rpc: c}
+}
+
+// BatchCallContext sends b as one JSON-RPC batch.
+func (c *RPCClient) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	return c.rpc.BatchCallContext(ctx, b)
+}
+
+// Token is an ERC-20 to include in a scan.
+type Token struct {
+	Address  common.Address
+	Symbol   string
+	Decimals uint8
+}
+
+// ScanConfig tunes ScanPortfolios. Zero values use defaults.
+type ScanConfig struct {
+	Tokens      []Token
+	BlockNumber *big.Int // snapshot block (nil => latest)
+
+	// BatchSize caps the requests in one JSON-RPC batch (default 100). Many
+	// providers reject batches above 100-1000 elements.
+	BatchSize int
+
+	// Multicall, when set, packs the balance reads into Multicall3
+	// aggregate3 eth_calls of up to CallsPerMulticall reads each (default
+	// 500), which are then batched as above.
+	Multicall         *common.Address
+	CallsPerMulticall int
+}
+
+// Portfolio is one address's balances at a block.
+type Portfolio struct {
+	Address common.Address
+	ETH     *big.Int
+	Tokens  map[common.Address]*big.Int // by token address
+}
+
+// Snapshot is a scan of many addresses at one block.
+type Snapshot struct {
+	BlockNumber *big.Int // nil for latest
+	Portfolios  []Portfolio
+}
+
+// Get returns the portfolio of addr.
+func (s *Snapshot) Get(addr common.Address) (Portfolio, bool) {
+	for _, p := range s.Portfolios {
+		if p.Address == addr {
+			return p, true
+		}
+	}
+	return Portfolio{}, false
+}
+
+// BalanceDiff is a balance that changed between two snapshots.
+type BalanceDiff struct {
+	Address common.Address
+	Token   common.Address // zero address for ETH
+	Before  *big.Int
+	After   *big.Int
+	Delta   *big.Int // After - Before
+}
+
+// ScanPortfolios reads the ETH balance and every configured token balance of
+// addrs. Instead of one round-trip per read, reads are grouped into JSON-RPC
+// batches (eth_getBalance + eth_call balanceOf) or, with Multicall set, into
+// aggregate3 calls that are themselves batched. Any failed read fails the
+// scan; partial portfolios would look like real zero balances.
+func ScanPortfolios(ctx context.Context, client AccountClient, addrs []common.Address, cfg ScanConfig) (*Snapshot, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if client == nil {
+		return nil, errors.New("client is nil")
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.CallsPerMulticall <= 0 {
+		cfg.CallsPerMulticall = 500
+	}
+
+	snap := &Snapshot{Portfolios: make([]Portfolio, len(addrs))}
+	if cfg.BlockNumber != nil {
+		snap.BlockNumber = new(big.Int).Set(cfg.BlockNumber)
+	}
+	for i, a := range addrs {
+		snap.Portfolios[i] = Portfolio{Address: a, Tokens: make(map[common.Address]*big.Int, len(cfg.Tokens))}
+	}
+
+	// One read per (address, asset); ETH is asset -1.
+	type read struct {
+		portfolio int
+		token     int
+	}
+	var reads []read
+	for i := range addrs {
+		for t := -1; t < len(cfg.Tokens); t++ {
+			reads = append(reads, read{portfolio: i, token: t})
+		}
+	}
+	store := func(r read, v *big.Int) {
+		p := &snap.Portfolios[r.portfolio]
+		if r.token < 0 {
+			p.ETH = v
+		} else {
+			p.Tokens[cfg.Tokens[r.token].Address] = v
+		}
+	}
+	describe := func(r read) string {
+		if r.token < 0 {
+			return fmt.Sprintf("ETH balance of %s", addrs[r.portfolio].Hex())
+		}
+		return fmt.Sprintf("%s balance of %s", tokenName(cfg.Tokens[r.token]), addrs[r.portfolio].Hex())
+	}
+	block := blockArg(cfg.BlockNumber)
+
+	if cfg.Multicall == nil {
+		elems := make([]rpc.BatchElem, len(reads))
+		for i, r := range reads {
+			owner := addrs[r.portfolio]
+			if r.token < 0 {
+				elems[i] = rpc.BatchElem{Method: "eth_getBalance", Args: []interface{}{owner, block}, Result: new(hexutil.Big)}
+				continue
+			}
+			data, err := erc20ABI.Pack("balanceOf", owner)
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = rpc.BatchElem{Method: "eth_call", Args: []interface{}{callArg(cfg.Tokens[r.token].Address, data), block}, Result: new(hexutil.Bytes)}
+		}
+		if err := batchCall(ctx, client, elems, cfg.BatchSize); err != nil {
+			return nil, err
+		}
+		for i, r := range reads {
+			if err := elems[i].Error; err != nil {
+				return nil, fmt.Errorf("%s: %w", describe(r), err)
+			}
+			var v *big.Int
+			if r.token < 0 {
+				v = (*big.Int)(elems[i].Result.(*hexutil.Big))
+			} else {
+				var err error
+				if v, err = unpackBalance(*elems[i].Result.(*hexutil.Bytes)); err != nil {
+					return nil, fmt.Errorf("%s: %w", describe(r), err)
+				}
+			}
+			store(r, v)
+		}
+		return snap, nil
+	}
+
+	// Multicall: chunk the reads into aggregate3 calls.
+	calls := make([]multicallCall, len(reads))
+	for i, r := range reads {
+		owner := addrs[r.portfolio]
+		var err error
+		if r.token < 0 {
+			calls[i].Target = *cfg.Multicall
+			calls[i].CallData, err = multicallABI.Pack("getEthBalance", owner)
+		} else {
+			calls[i].Target = cfg.Tokens[r.token].Address
+			calls[i].CallData, err = erc20ABI.Pack("balanceOf", owner)
+		}
+		if err != nil {
+			return nil, err
+		}
+		calls[i].AllowFailure = true // report which read failed instead of reverting the lot
+	}
+	var elems []rpc.BatchElem
+	for start := 0; start < len(calls); start += cfg.CallsPerMulticall {
+		end := min(start+cfg.CallsPerMulticall, len(calls))
+		data, err := multicallABI.Pack("aggregate3", calls[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("pack aggregate3: %w", err)
+		}
+		elems = append(elems, rpc.BatchElem{Method: "eth_call", Args: []interface{}{callArg(*cfg.Multicall, data), block}, Result: new(hexutil.Bytes)})
+	}
+	if err := batchCall(ctx, client, elems, cfg.BatchSize); err != nil {
+		return nil, err
+	}
+	for i, elem := range elems {
+		start := i * cfg.CallsPerMulticall
+		if elem.Error != nil {
+			return nil, fmt.Errorf("aggregate3 #%d: %w", i, elem.Error)
+		}
+		out, err := multicallABI.Unpack("aggregate3", *elem.Result.(*hexutil.Bytes))
+		if err != nil {
+			return nil, fmt.Errorf("aggregate3 #%d: unpack: %w", i, err)
+		}
+		results := *abi.ConvertType(out[0], new([]multicallResult)).(*[]multicallResult)
+		if want := min(cfg.CallsPerMulticall, len(calls)-start); len(results) != want {
+			return nil, fmt.Errorf("aggregate3 #%d: %d results for %d calls", i, len(results), want)
+		}
+		for j, res := range results {
+			r := reads[start+j]
+			if !res.Success {
+				return nil, fmt.Errorf("%s: call reverted", describe(r))
+			}
+			v, err := unpackBalance(res.ReturnData)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", describe(r), err)
+			}
+			store(r, v)
+		}
+	}
+	return snap, nil
+}
+
+// DiffBlocks scans addrs at two blocks and returns what changed.
+func DiffBlocks(ctx context.Context, client AccountClient, addrs []common.Address, from, to *big.Int, cfg ScanConfig) ([]BalanceDiff, error) {
+	cfg.BlockNumber = from
+	before, err := ScanPortfolios(ctx, client, addrs, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("scan block %v: %w", from, err)
+	}
+	cfg.BlockNumber = to
+	after, err := ScanPortfolios(ctx, client, addrs, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("scan block %v: %w", to, err)
+	}
+	return DiffSnapshots(before, after), nil
+}
+
+// DiffSnapshots compares two snapshots of the same addresses. Only non-zero
+// deltas are returned, ordered by address with ETH before tokens. A balance
+// missing on one side counts as zero.
+func DiffSnapshots(before, after *Snapshot) []BalanceDiff {
+	type key struct{ addr, token common.Address }
+	type pair struct{ before, after *big.Int }
+	balances := make(map[key]*pair)
+	collect := func(s *Snapshot, set func(*pair, *big.Int)) {
+		for _, p := range s.Portfolios {
+			get := func(token common.Address) *pair {
+				k := key{p.Address, token}
+				if balances[k] == nil {
+					balances[k] = &pair{}
+				}
+				return balances[k]
+			}
+			set(get(common.Address{}), p.ETH)
+			for token, v := range p.Tokens {
+				set(get(token), v)
+			}
+		}
+	}
+	collect(before, func(p *pair, v *big.Int) { p.before = v })
+	collect(after, func(p *pair, v *big.Int) { p.after = v })
+
+	var out []BalanceDiff
+	for k, p := range balances {
+		b, a := orZero(p.before), orZero(p.after)
+		if a.Cmp(b) == 0 {
+			continue
+		}
+		out = append(out, BalanceDiff{
+			Address: k.addr,
+			Token:   k.token,
+			Before:  new(big.Int).Set(b),
+			After:   new(big.Int).Set(a),
+			Delta:   new(big.Int).Sub(a, b),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if c := bytes.Compare(out[i].Address[:], out[j].Address[:]); c != 0 {
+			return c < 0
+		}
+		return bytes.Compare(out[i].Token[:], out[j].Token[:]) < 0
+	})
+	return out
+}
+
+// batchCall sends elems in batches of at most size. Per-element failures are
+// left in each BatchElem.Error; only transport errors are returned.
+func batchCall(ctx context.Context, client AccountClient, elems []rpc.BatchElem, size int) error {
+	for start := 0; start < len(elems); start += size {
+		end := min(start+size, len(elems))
+		if err := client.BatchCallContext(ctx, elems[start:end]); err != nil {
+			return fmt.Errorf("batch [%d, %d): %w", start, end, err)
+		}
+	}
+	return nil
+}
+
+// blockArg renders a block number the way ethclient does.
+func blockArg(n *big.Int) string {
+	if n == nil {
+		return "latest"
+	}
+	if n.Sign() >= 0 {
+		return hexutil.EncodeBig(n)
+	}
+	return rpc.BlockNumber(n.Int64()).String() // pending, finalized, ...
+}
+
+func callArg(to common.Address, data []byte) map[string]interface{} {
+	return map[string]interface{}{"to": to, "data": hexutil.Bytes(data)}
+}
+
+func unpackBalance(ret []byte) (*big.Int, error) {
+	if len(ret) != 32 {
+		return nil, fmt.Errorf("balanceOf returned %d bytes, want 32", len(ret))
+	}
+	return new(big.Int).SetBytes(ret), nil
+}
+
+func tokenName(t Token) string {
+	if t.Symbol != "" {
+		return t.Symbol
+	}
+	return t.Address.Hex()
+}
+
+func orZero(v *big.Int) *big.Int {
+	if v == nil {
+		return new(big.Int)
+	}
+	return v
+}
@@ -5,12 +5,17 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 // AccountClient captures the RPC methods required for this module.
 type AccountClient interface {
 	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
 	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+
+	// BatchCallContext sends several JSON-RPC requests in one round-trip
+	// (rpc.Client.BatchCallContext; see NewRPCClient for a real client).
+	BatchCallContext(ctx context.Context, b []rpc.BatchElem) error
 }
 
 // AccountType represents the coarse account classification.
@@ -25,6 +30,10 @@ const (
 type Config struct {
 	Addresses   []common.Address
 	BlockNumber *big.Int
+
+	// BatchSize > 0 fetches balances and code with JSON-RPC batches of up to
+	// BatchSize requests instead of two calls per address.
+	BatchSize int
 }
 
 // AccountState captures details for a single address.
@@ -30,6 +30,13 @@ func Run(ctx context.Context, client TXClient, cfg Config) (*Result, error) {
 	// TODO: Determine the sender's address from the private key.
 	// - Use `crypto.PubkeyToAddress(cfg.PrivateKey.PublicKey)`.
 
+	// TODO: Delegate to the nonce manager when one is configured.
+	// - If `cfg.Manager` is set (and `cfg.Nonce` is nil and `cfg.NoSend` is false),
+	//   check `cfg.Manager.From()` matches the sender, then call
+	//   `cfg.Manager.Send(ctx, TxRequest{...})` and return its tx in a Result.
+	// - The manager (nonce.go) hands out nonces locally, so concurrent senders
+	//   don't race on PendingNonceAt.
+
 	// TODO: Determine the nonce to use for the transaction.
 	// - If `cfg.Nonce` is provided, use it.
 	// - Otherwise, fetch the pending nonce for the sender's address using
@@ -4,7 +4,9 @@ import (
 	"context"
 	"crypto/ecdsa"
 	"errors"
+	"fmt"
 	"math/big"
+	"sync"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -33,6 +35,13 @@ func (m *mockTXClient) PendingNonceAt(ctx context.Context, account common.Addres
 	return m.nonce, nil
 }
 
+func (m *mockTXClient) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	if m.nonceErr != nil {
+		return 0, m.nonceErr
+	}
+	return m.nonce, nil
+}
+
 func (m *mockTXClient) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
 	m.suggestCalled = true
 	if m.gasErr != nil {
@@ -153,3 +162,355 @@ func TestRunErrors(t *testing.T) {
 		t.Fatalf("expected send error")
 	}
 }
+
+// poolClient is a stateful node: it enforces geth's txpool nonce and
+// replacement rules so the nonce manager can be tested end to end.
+type poolClient struct {
+	mu           sync.Mutex
+	chainID      *big.Int
+	gasPrice     *big.Int
+	mined        uint64                        // NonceAt(latest)
+	pool         map[uint64]*types.Transaction // unmined txs by nonce
+	pendingCalls int
+	hook         func(tx *types.Transaction) error // runs before the pool rules
+}
+
+func newPoolClient() *poolClient {
+	return &poolClient{chainID: big.NewInt(1), gasPrice: big.NewInt(100), pool: make(map[uint64]*types.Transaction)}
+}
+
+func (c *poolClient) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pendingCalls++
+	n := c.mined
+	for c.pool[n] != nil {
+		n++
+	}
+	return n, nil
+}
+
+func (c *poolClient) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.mined, nil
+}
+
+func (c *poolClient) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return new(big.Int).Set(c.gasPrice), nil
+}
+
+func (c *poolClient) ChainID(ctx context.Context) (*big.Int, error) { return c.chainID, nil }
+
+func (c *poolClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.hook != nil {
+		if err := c.hook(tx); err != nil {
+			return err
+		}
+	}
+	n := tx.Nonce()
+	if n < c.mined {
+		return fmt.Errorf("nonce too low: next nonce %d, tx nonce %d", c.mined, n)
+	}
+	if old := c.pool[n]; old != nil {
+		if old.Hash() == tx.Hash() {
+			return errors.New("already known")
+		}
+		// geth: new price must be >= old * (100 + 10) / 100.
+		threshold := new(big.Int).Mul(old.GasPrice(), big.NewInt(110))
+		threshold.Div(threshold, big.NewInt(100))
+		if tx.GasPrice().Cmp(threshold) < 0 {
+			return errors.New("replacement transaction underpriced")
+		}
+	}
+	c.pool[n] = tx
+	return nil
+}
+
+// mine includes up to k executable txs.
+func (c *poolClient) mine(k int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := 0; i < k && c.pool[c.mined] != nil; i++ {
+		delete(c.pool, c.mined)
+		c.mined++
+	}
+}
+
+func newManager(t *testing.T, client TXClient) *NonceManager {
+	t.Helper()
+	m, err := NewNonceManager(context.Background(), client, mustKey(t), NonceConfig{})
+	if err != nil {
+		t.Fatalf("NewNonceManager: %v", err)
+	}
+	return m
+}
+
+func TestNonceManagerConcurrentSends(t *testing.T) {
+	client := newPoolClient()
+	m := newManager(t, client)
+	to := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	const n = 50
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := m.Send(context.Background(), TxRequest{To: to, Value: big.NewInt(1)}); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("Send: %v", err)
+	}
+
+	if len(client.pool) != n {
+		t.Fatalf("pool has %d txs, want %d", len(client.pool), n)
+	}
+	for i := uint64(0); i < n; i++ {
+		if client.pool[i] == nil {
+			t.Fatalf("nonce %d missing", i)
+		}
+	}
+	if client.pendingCalls != 1 {
+		t.Fatalf("PendingNonceAt called %d times, want 1", client.pendingCalls)
+	}
+}
+
+func TestNonceManagerResyncsOnNonceTooLow(t *testing.T) {
+	client := newPoolClient()
+	m := newManager(t, client)
+	to := common.HexToAddress("0x01")
+	for i := 0; i < 2; i++ {
+		if _, err := m.Send(context.Background(), TxRequest{To: to}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Another wallet used the same key: nonces 0-4 are mined behind our back.
+	client.mu.Lock()
+	client.pool = make(map[uint64]*types.Transaction)
+	client.mined = 5
+	client.mu.Unlock()
+
+	tx, err := m.Send(context.Background(), TxRequest{To: to})
+	if err != nil {
+		t.Fatalf("Send after external use: %v", err)
+	}
+	if tx.Nonce() != 5 {
+		t.Fatalf("nonce = %d, want 5 after resync", tx.Nonce())
+	}
+}
+
+func TestNonceManagerReusesFailedNonce(t *testing.T) {
+	client := newPoolClient()
+	m := newManager(t, client)
+	to := common.HexToAddress("0x01")
+	client.hook = func(tx *types.Transaction) error {
+		if tx.Nonce() == 0 {
+			client.hook = nil
+			return errors.New("insufficient funds for gas * price + value")
+		}
+		return nil
+	}
+	if _, err := m.Send(context.Background(), TxRequest{To: to}); err == nil {
+		t.Fatalf("expected insufficient funds")
+	}
+	tx, err := m.Send(context.Background(), TxRequest{To: to})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Nonce() != 0 {
+		t.Fatalf("failed nonce was not reused: got %d", tx.Nonce())
+	}
+}
+
+func TestNonceManagerStatusAndFillGaps(t *testing.T) {
+	client := newPoolClient()
+	m := newManager(t, client)
+	ctx := context.Background()
+	to := common.HexToAddress("0x01")
+
+	// Two concurrent senders reserve 0 and 1; the first gives 0 back, so the
+	// next Send reuses it. Nonce 1 is given back after 2 is issued and never
+	// reused: a hole.
+	n0, _ := m.reserve(ctx)
+	n1, err := m.reserve(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.release(n0)
+	for _, want := range []uint64{0, 2} {
+		tx, err := m.Send(ctx, TxRequest{To: to})
+		if err != nil || tx.Nonce() != want {
+			t.Fatalf("Send = %v, %v; want nonce %d", tx, err, want)
+		}
+	}
+	m.release(n1)
+
+	st, err := m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Latest != 0 || st.Pending != 1 || st.Next != 3 {
+		t.Fatalf("status = %+v", st)
+	}
+	if fmt.Sprint(st.Unmined) != "[0]" || fmt.Sprint(st.Gaps) != "[1 2]" {
+		t.Fatalf("unmined %v gaps %v", st.Unmined, st.Gaps)
+	}
+
+	filled, err := m.FillGaps(ctx)
+	if err != nil {
+		t.Fatalf("FillGaps: %v", err)
+	}
+	if len(filled) != 2 || *filled[0].To() != m.From() || filled[0].Value().Sign() != 0 {
+		t.Fatalf("nonce 1 should be plugged with a self-transfer: %+v", filled)
+	}
+	client.mine(10)
+	if st, _ := m.Status(ctx); st.Latest != 3 || len(st.Gaps) != 0 || len(st.Unmined) != 0 {
+		t.Fatalf("after mining: %+v", st)
+	}
+}
+
+// gatedClient holds the first SendTransaction until released, so a test can
+// act while a Send is between reserving its nonce and tracking its tx.
+type gatedClient struct {
+	*poolClient
+	once    sync.Once
+	entered chan struct{}
+	proceed chan struct{}
+}
+
+func (c *gatedClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	c.once.Do(func() {
+		close(c.entered)
+		<-c.proceed
+	})
+	return c.poolClient.SendTransaction(ctx, tx)
+}
+
+func TestNonceManagerFillGapsSkipsInFlightSend(t *testing.T) {
+	client := &gatedClient{poolClient: newPoolClient(), entered: make(chan struct{}), proceed: make(chan struct{})}
+	m := newManager(t, client)
+	ctx := context.Background()
+	to := common.HexToAddress("0x01")
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := m.Send(ctx, TxRequest{To: to, Value: big.NewInt(1)})
+		done <- err
+	}()
+	<-client.entered
+
+	// Nonce 0 is reserved but not yet on the node: not a gap.
+	st, err := m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Next != 1 || len(st.Gaps) != 0 {
+		t.Fatalf("status during send = %+v", st)
+	}
+	filled, err := m.FillGaps(ctx)
+	if err != nil || len(filled) != 0 {
+		t.Fatalf("FillGaps = %v, %v; want nothing to fill", filled, err)
+	}
+
+	close(client.proceed)
+	if err := <-done; err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if tx := client.pool[0]; tx == nil || *tx.To() != to || tx.Value().Int64() != 1 {
+		t.Fatalf("nonce 0 should hold the original send, got %+v", tx)
+	}
+}
+
+func TestNonceManagerSpeedUpAndCancel(t *testing.T) {
+	client := newPoolClient()
+	m := newManager(t, client)
+	ctx := context.Background()
+	to := common.HexToAddress("0x01")
+
+	orig, err := m.Send(ctx, TxRequest{To: to, Value: big.NewInt(5), GasPrice: big.NewInt(100)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.gasPrice = big.NewInt(50) // market is lower than the bump
+
+	// The pool rejects a bump under 10%.
+	under, _ := m.sign(orig.Nonce(), to, big.NewInt(5), 0, big.NewInt(109), nil)
+	if err := client.SendTransaction(ctx, under); err == nil {
+		t.Fatalf("mock accepted a 9%% bump")
+	}
+
+	fast, err := m.SpeedUp(ctx, orig.Nonce())
+	if err != nil {
+		t.Fatalf("SpeedUp: %v", err)
+	}
+	if fast.GasPrice().Int64() != 110 || fast.Value().Int64() != 5 || *fast.To() != to {
+		t.Fatalf("speed-up = price %s value %s", fast.GasPrice(), fast.Value())
+	}
+
+	client.gasPrice = big.NewInt(500) // market moved above the bump
+	cancel, err := m.Cancel(ctx, orig.Nonce())
+	if err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	if cancel.GasPrice().Int64() != 500 || *cancel.To() != m.From() || cancel.Value().Sign() != 0 {
+		t.Fatalf("cancel = %+v", cancel)
+	}
+	if client.pool[0].Hash() != cancel.Hash() {
+		t.Fatalf("cancel did not replace the pooled tx")
+	}
+
+	if _, err := m.SpeedUp(ctx, 42); !errors.Is(err, ErrUnknownNonce) {
+		t.Fatalf("SpeedUp unknown nonce: %v", err)
+	}
+}
+
+func TestBumpGasPrice(t *testing.T) {
+	for _, tt := range []struct{ old, pct, want int64 }{
+		{100, 10, 110},
+		{101, 10, 112}, // 111.1 rounds up
+		{1, 10, 2},
+		{1_000_000_000, 12, 1_120_000_000},
+	} {
+		if got := BumpGasPrice(big.NewInt(tt.old), uint64(tt.pct)); got.Int64() != tt.want {
+			t.Fatalf("BumpGasPrice(%d, %d) = %s, want %d", tt.old, tt.pct, got, tt.want)
+		}
+	}
+}
+
+func TestRunWithManager(t *testing.T) {
+	client := newPoolClient()
+	m := newManager(t, client)
+	for want := uint64(0); want < 2; want++ {
+		res, err := Run(context.Background(), client, Config{
+			PrivateKey: mustKey(t),
+			To:         common.HexToAddress("0x01"),
+			Manager:    m,
+		})
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+		if res.Nonce != want {
+			t.Fatalf("nonce = %d, want %d", res.Nonce, want)
+		}
+	}
+	if client.pendingCalls != 1 {
+		t.Fatalf("PendingNonceAt called %d times", client.pendingCalls)
+	}
+
+	other, _ := crypto.GenerateKey()
+	if _, err := Run(context.Background(), client, Config{PrivateKey: other, Manager: m}); err == nil {
+		t.Fatalf("expected key/manager mismatch error")
+	}
+}
@@ -0,0 +1,411 @@
+package exercise
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// ErrUnknownNonce is returned by SpeedUp for a nonce this manager has no
+// transaction for.
+var ErrUnknownNonce = errors.New("no transaction tracked for nonce")
+
+// Error strings returned by geth's txpool. They cross the RPC boundary as
+// plain strings, so they can only be matched by text.
+const (
+	errNonceTooLow  = "nonce too low"
+	errAlreadyKnown = "already known"
+)
+
+func isNonceTooLow(err error) bool {
+	return err != nil && strings.Contains(err.Error(), errNonceTooLow)
+}
+func isAlreadyKnown(err error) bool {
+	return err != nil && strings.Contains(err.Error(), errAlreadyKnown)
+}
+
+// NonceConfig tunes the nonce manager. Zero values use defaults.
+type NonceConfig struct {
+	PriceBump  uint64 // percent a replacement must raise the gas price (default 10, geth's txpool minimum)
+	MaxRetries int    // resync-and-retry attempts after "nonce too low" (default 3)
+}
+
+// TxRequest is a legacy transaction without its nonce.
+type TxRequest struct {
+	To       common.Address
+	Value    *big.Int // nil => 0
+	GasLimit uint64   // 0 => 21000
+	GasPrice *big.Int // nil => SuggestGasPrice
+	Data     []byte
+}
+
+// NonceStatus compares the node's view of an account with the manager's.
+//
+//	Latest <= Pending <= Next
+//	[Latest, Pending): in the pool, executable, not mined yet (speed-up candidates)
+//	[Pending, Next):   issued here but not executable on the node (gaps)
+//
+// Nonces a Send has reserved but not yet broadcast are neither: they are
+// on their way, and plugging them would race the send.
+type NonceStatus struct {
+	Latest  uint64 // NonceAt(latest): next nonce to be mined
+	Pending uint64 // PendingNonceAt: next nonce after the pool's executable txs
+	Next    uint64 // next nonce the manager will hand out
+	Unmined []uint64
+	Gaps    []uint64
+}
+
+// NonceManager hands out nonces for one account to concurrent senders
+// without a round trip per transaction. The node is consulted on first use,
+// after "nonce too low" (someone else used the account, or the node
+// restarted), and by Status.
+//
+// A nonce whose send fails for another reason is returned to the pool and
+// handed out again, so a failed send does not leave a hole that blocks every
+// later transaction.
+type NonceManager struct {
+	client  TXClient
+	key     *ecdsa.PrivateKey
+	from    common.Address
+	chainID *big.Int
+	cfg     NonceConfig
+
+	mu       sync.Mutex
+	synced   bool
+	next     uint64
+	released []uint64                      // returned nonces below next, ascending
+	sent     map[uint64]*types.Transaction // latest tx sent per nonce, until mined
+	sending  map[uint64]bool               // reserved by Send, not yet tracked or released
+}
+
+// NewNonceManager returns a manager for the key's account. It fetches the
+// chain ID once; nonces are fetched lazily.
+func NewNonceManager(ctx context.Context, client TXClient, key *ecdsa.PrivateKey, cfg NonceConfig) (*NonceManager, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if client == nil {
+		return nil, errors.New("client is nil")
+	}
+	if key == nil {
+		return nil, errors.New("private key is required")
+	}
+	if cfg.PriceBump == 0 {
+		cfg.PriceBump = 10
+	}
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = 3
+	}
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("chain id: %w", err)
+	}
+	if chainID == nil {
+		return nil, errors.New("chain id was nil")
+	}
+	return &NonceManager{
+		client:  client,
+		key:     key,
+		from:    crypto.PubkeyToAddress(key.PublicKey),
+		chainID: chainID,
+		cfg:     cfg,
+		sent:    make(map[uint64]*types.Transaction),
+		sending: make(map[uint64]bool),
+	}, nil
+}
+
+// From returns the managed account.
+func (m *NonceManager) From() common.Address { return m.from }
+
+// Send assigns the next nonce to req, signs it and broadcasts it. On "nonce
+// too low" it resyncs with the node and retries with a fresh nonce.
+func (m *NonceManager) Send(ctx context.Context, req TxRequest) (*types.Transaction, error) {
+	gasPrice := req.GasPrice
+	if gasPrice == nil {
+		var err error
+		if gasPrice, err = m.client.SuggestGasPrice(ctx); err != nil {
+			return nil, fmt.Errorf("suggest gas price: %w", err)
+		}
+	}
+	for attempt := 0; ; attempt++ {
+		nonce, err := m.reserve(ctx)
+		if err != nil {
+			return nil, err
+		}
+		tx, err := m.sign(nonce, req.To, req.Value, req.GasLimit, gasPrice, req.Data)
+		if err != nil {
+			m.release(nonce)
+			return nil, err
+		}
+		err = m.client.SendTransaction(ctx, tx)
+		switch {
+		case err == nil || isAlreadyKnown(err):
+			m.track(tx)
+			return tx, nil
+		case isNonceTooLow(err) && attempt < m.cfg.MaxRetries:
+			// The nonce is used on chain; do not release it.
+			m.abandon(nonce)
+			m.invalidate()
+		default:
+			if isNonceTooLow(err) {
+				m.abandon(nonce)
+			} else {
+				m.release(nonce)
+			}
+			return nil, fmt.Errorf("send tx with nonce %d: %w", nonce, err)
+		}
+	}
+}
+
+// Resync forgets the local counter and re-reads PendingNonceAt on next use.
+func (m *NonceManager) Resync() { m.invalidate() }
+
+// Status reads the latest and pending nonces and reports which issued
+// nonces are unmined or missing on the node. Mined transactions stop being
+// tracked.
+func (m *NonceManager) Status(ctx context.Context) (*NonceStatus, error) {
+	latest, err := m.client.NonceAt(ctx, m.from, nil)
+	if err != nil {
+		return nil, fmt.Errorf("nonce at: %w", err)
+	}
+	pending, err := m.client.PendingNonceAt(ctx, m.from)
+	if err != nil {
+		return nil, fmt.Errorf("pending nonce: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for n := range m.sent {
+		if n < latest {
+			delete(m.sent, n)
+		}
+	}
+	m.dropReleasedBelow(pending)
+	if !m.synced || m.next < pending {
+		m.next, m.synced = pending, true
+	}
+
+	st := &NonceStatus{Latest: latest, Pending: pending, Next: m.next}
+	for n := latest; n < pending; n++ {
+		st.Unmined = append(st.Unmined, n)
+	}
+	for n := pending; n < m.next; n++ {
+		if !m.sending[n] {
+			st.Gaps = append(st.Gaps, n)
+		}
+	}
+	return st, nil
+}
+
+// SpeedUp re-sends the transaction tracked for nonce with its gas price
+// raised by PriceBump percent, or to the current suggestion if higher.
+func (m *NonceManager) SpeedUp(ctx context.Context, nonce uint64) (*types.Transaction, error) {
+	m.mu.Lock()
+	old, ok := m.sent[nonce]
+	m.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %d", ErrUnknownNonce, nonce)
+	}
+	price, err := m.replacementPrice(ctx, old)
+	if err != nil {
+		return nil, err
+	}
+	if old.To() == nil {
+		return nil, fmt.Errorf("nonce %d: cannot speed up a contract creation", nonce)
+	}
+	tx, err := m.sign(nonce, *old.To(), old.Value(), old.Gas(), price, old.Data())
+	if err != nil {
+		return nil, err
+	}
+	return tx, m.replace(ctx, tx)
+}
+
+// Cancel replaces whatever holds nonce with a 0-value transfer to self. It
+// also works for a nonce with no tracked transaction, which is how gaps are
+// plugged.
+func (m *NonceManager) Cancel(ctx context.Context, nonce uint64) (*types.Transaction, error) {
+	m.mu.Lock()
+	old := m.sent[nonce]
+	m.mu.Unlock()
+	price, err := m.replacementPrice(ctx, old)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := m.sign(nonce, m.from, nil, params.TxGas, price, nil)
+	if err != nil {
+		return nil, err
+	}
+	return tx, m.replace(ctx, tx)
+}
+
+// FillGaps makes every gap executable: a tracked transaction the node lost
+// is re-broadcast, and a nonce with nothing tracked is cancelled. Nonces a
+// concurrent Send is still broadcasting are left to it.
+func (m *NonceManager) FillGaps(ctx context.Context) ([]*types.Transaction, error) {
+	st, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out []*types.Transaction
+	for _, n := range st.Gaps {
+		m.mu.Lock()
+		if m.sending[n] {
+			// Reserved by a Send since Status ran.
+			m.mu.Unlock()
+			continue
+		}
+		tx, ok := m.sent[n]
+		if !ok {
+			m.takeReleased(n)
+		}
+		m.mu.Unlock()
+		if ok {
+			if err := m.client.SendTransaction(ctx, tx); err != nil && !isAlreadyKnown(err) {
+				return out, fmt.Errorf("rebroadcast nonce %d: %w", n, err)
+			}
+		} else if tx, err = m.Cancel(ctx, n); err != nil {
+			return out, err
+		}
+		out = append(out, tx)
+	}
+	return out, nil
+}
+
+// BumpGasPrice returns the lowest price geth's txpool accepts as a
+// replacement for old: old * (100 + percent) / 100, rounded up.
+func BumpGasPrice(old *big.Int, percent uint64) *big.Int {
+	bumped := new(big.Int).Mul(old, new(big.Int).SetUint64(100+percent))
+	bumped.Add(bumped, big.NewInt(99))
+	return bumped.Div(bumped, big.NewInt(100))
+}
+
+func (m *NonceManager) replacementPrice(ctx context.Context, old *types.Transaction) (*big.Int, error) {
+	suggested, err := m.client.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("suggest gas price: %w", err)
+	}
+	if old == nil {
+		return suggested, nil
+	}
+	bumped := BumpGasPrice(old.GasPrice(), m.cfg.PriceBump)
+	if suggested.Cmp(bumped) > 0 {
+		return suggested, nil
+	}
+	return bumped, nil
+}
+
+func (m *NonceManager) replace(ctx context.Context, tx *types.Transaction) error {
+	if err := m.client.SendTransaction(ctx, tx); err != nil && !isAlreadyKnown(err) {
+		return fmt.Errorf("replace nonce %d: %w", tx.Nonce(), err)
+	}
+	m.track(tx)
+	return nil
+}
+
+func (m *NonceManager) sign(nonce uint64, to common.Address, value *big.Int, gas uint64, gasPrice *big.Int, data []byte) (*types.Transaction, error) {
+	if value == nil {
+		value = new(big.Int)
+	}
+	if gas == 0 {
+		gas = params.TxGas
+	}
+	tx := types.NewTransaction(nonce, to, new(big.Int).Set(value), gas, new(big.Int).Set(gasPrice), append([]byte(nil), data...))
+	signed, err := types.SignTx(tx, types.LatestSignerForChainID(m.chainID), m.key)
+	if err != nil {
+		return nil, fmt.Errorf("sign tx: %w", err)
+	}
+	return signed, nil
+}
+
+// reserve hands out the lowest released nonce, or the next new one. The node
+// is only asked when the counter is not synced.
+func (m *NonceManager) reserve(ctx context.Context) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.synced {
+		pending, err := m.client.PendingNonceAt(ctx, m.from)
+		if err != nil {
+			return 0, fmt.Errorf("pending nonce: %w", err)
+		}
+		if pending > m.next {
+			m.next = pending
+		}
+		m.dropReleasedBelow(pending)
+		m.synced = true
+	}
+	n := m.next
+	if len(m.released) > 0 {
+		n, m.released = m.released[0], m.released[1:]
+	} else {
+		m.next++
+	}
+	m.sending[n] = true
+	return n, nil
+}
+
+// release returns an unused nonce. The newest nonce just rewinds the
+// counter; older ones are queued for reuse.
+func (m *NonceManager) release(nonce uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sending, nonce)
+	if nonce+1 == m.next {
+		m.next--
+		return
+	}
+	i := sort.Search(len(m.released), func(i int) bool { return m.released[i] >= nonce })
+	if i < len(m.released) && m.released[i] == nonce {
+		return
+	}
+	m.released = append(m.released, 0)
+	copy(m.released[i+1:], m.released[i:])
+	m.released[i] = nonce
+}
+
+// takeReleased removes nonce from the released list. Callers hold mu.
+func (m *NonceManager) takeReleased(nonce uint64) {
+	for i, n := range m.released {
+		if n == nonce {
+			m.released = append(m.released[:i], m.released[i+1:]...)
+			return
+		}
+	}
+}
+
+// dropReleasedBelow forgets released nonces the chain has moved past.
+// Callers hold mu.
+func (m *NonceManager) dropReleasedBelow(nonce uint64) {
+	i := sort.Search(len(m.released), func(i int) bool { return m.released[i] >= nonce })
+	m.released = m.released[i:]
+}
+
+func (m *NonceManager) track(tx *types.Transaction) {
+	m.mu.Lock()
+	m.sent[tx.Nonce()] = tx
+	delete(m.sending, tx.Nonce())
+	m.mu.Unlock()
+}
+
+// abandon drops a reserved nonce that is neither sent by us nor reusable:
+// the chain already has a transaction for it.
+func (m *NonceManager) abandon(nonce uint64) {
+	m.mu.Lock()
+	delete(m.sending, nonce)
+	m.mu.Unlock()
+}
+
+func (m *NonceManager) invalidate() {
+	m.mu.Lock()
+	m.synced = false
+	m.mu.Unlock()
+}
@@ -51,6 +51,28 @@ func Run(ctx context.Context, client TXClient, cfg Config) (*Result, error) {
 	// `PendingNonceAt` includes transactions that are in the mempool but not yet
 	// mined. Using this prevents "nonce too low" errors if we have other
 	// pending transactions.
+	//
+	// Bots that send many transactions at once cannot afford a PendingNonceAt
+	// round trip per transaction: two goroutines would read the same pending
+	// nonce and one send would fail. A NonceManager (nonce.go) hands out
+	// nonces locally under a lock and only asks the node when it has to.
+	if cfg.Manager != nil && cfg.Nonce == nil && !cfg.NoSend {
+		if cfg.Manager.From() != from {
+			return nil, fmt.Errorf("nonce manager is for %s, key is for %s", cfg.Manager.From(), from)
+		}
+		tx, err := cfg.Manager.Send(ctx, TxRequest{
+			To:       cfg.To,
+			Value:    cfg.AmountWei,
+			GasLimit: cfg.GasLimit,
+			GasPrice: cfg.GasPrice,
+			Data:     cfg.Data,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &Result{FromAddress: from, Nonce: tx.Nonce(), Tx: tx}, nil
+	}
+
 	var nonce uint64
 	var err error
 	if cfg.Nonce != nil {
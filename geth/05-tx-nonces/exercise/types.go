@@ -12,6 +12,7 @@ import (
 // TXClient captures just enough of ethclient.Client for this exercise.
 type TXClient interface {
 	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
 	SuggestGasPrice(ctx context.Context) (*big.Int, error)
 	ChainID(ctx context.Context) (*big.Int, error)
 	SendTransaction(ctx context.Context, tx *types.Transaction) error
@@ -29,6 +30,10 @@ type Config struct {
 	GasLimit uint64
 	Data     []byte
 	NoSend   bool // useful in tests/tutorials where you only want the signed tx
+
+	// Manager, when set, assigns the nonce and sends the tx. It must manage
+	// the account of PrivateKey. Ignored when Nonce is set or NoSend is true.
+	Manager *NonceManager
 }
 
 // Result surfaces the signed transaction plus metadata for display.
@@ -76,8 +76,14 @@ func Run(ctx context.Context, client FeeClient, cfg Config) (*Result, error) {
 	// - IMPORTANT: Make a defensive copy of baseFee (big.Int is mutable!)
 	// - Why copy? header.BaseFee points to client's internal data
 
+	// TODO: Run the fee oracle when cfg.Speed is set
+	// - Call estimateFees(ctx, client, header, OracleConfig{}) (see oracle.go)
+	// - Pick the preset with fees.Get(cfg.Speed); unknown speed is an error
+	// - The oracle samples eth_feeHistory percentiles and projects the next base fee
+
 	// TODO: Determine max priority fee (tip cap)
 	// - If cfg.MaxPriorityFee is provided, use it
+	// - Else if the oracle ran, use the preset's MaxPriorityFee
 	// - Otherwise, call client.SuggestGasTipCap(ctx) to get suggested tip
 	// - Handle errors from SuggestGasTipCap call
 	// - IMPORTANT: Make a defensive copy (big.Int is mutable!)
@@ -85,6 +91,7 @@ func Run(ctx context.Context, client FeeClient, cfg Config) (*Result, error) {
 
 	// TODO: Determine max fee cap
 	// - If cfg.MaxFee is provided, use it
+	// - Else if the oracle ran, use preset MaxFee - preset MaxPriorityFee + tipCap
 	// - Otherwise, calculate: 2 * baseFee + tipCap
 	// - The "2x" rule: Accounts for base fee volatility (can increase 12.5% per block)
 	// - IMPORTANT: Make defensive copies when using provided maxFee
@@ -130,6 +137,7 @@ func Run(ctx context.Context, client FeeClient, cfg Config) (*Result, error) {
 	//   - Nonce: transaction nonce (already determined)
 	//   - Tx: signed transaction (returned from SignTx)
 	//   - BaseFee: base fee from header (already copied)
+	//   - Fees: oracle estimates (nil unless cfg.Speed was set)
 	// - Return Result and nil error
 
 	return nil, errors.New("not implemented")
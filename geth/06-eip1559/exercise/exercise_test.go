@@ -5,8 +5,10 @@ import (
 	"crypto/ecdsa"
 	"errors"
 	"math/big"
+	"reflect"
 	"testing"
 
+	ethereum "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -25,6 +27,14 @@ type mockFeeClient struct {
 	sentTx      *types.Transaction
 	nonceCalled bool
 	tipCalled   bool
+
+	history     *ethereum.FeeHistory
+	historyErr  error
+	historyArgs struct {
+		blocks      uint64
+		last        *big.Int
+		percentiles []float64
+	}
 }
 
 func (m *mockFeeClient) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
@@ -65,6 +75,16 @@ func (m *mockFeeClient) SendTransaction(ctx context.Context, tx *types.Transacti
 	return nil
 }
 
+func (m *mockFeeClient) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	m.historyArgs.blocks = blockCount
+	m.historyArgs.last = lastBlock
+	m.historyArgs.percentiles = rewardPercentiles
+	if m.historyErr != nil {
+		return nil, m.historyErr
+	}
+	return m.history, nil
+}
+
 func mustKey(t *testing.T) *ecdsa.PrivateKey {
 	t.Helper()
 	key, err := crypto.GenerateKey()
@@ -171,3 +191,199 @@ func TestRunErrors(t *testing.T) {
 		t.Fatalf("expected send error")
 	}
 }
+
+func gwei(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1_000_000_000)) }
+
+func TestNextBaseFee(t *testing.T) {
+	const limit = 30_000_000 // target 15M
+	tests := []struct {
+		name    string
+		base    int64
+		gasUsed uint64
+		want    int64
+	}{
+		{"at target", 1_000_000_000, 15_000_000, 1_000_000_000},
+		{"full block +12.5%", 1_000_000_000, 30_000_000, 1_125_000_000},
+		{"empty block -12.5%", 1_000_000_000, 0, 875_000_000},
+		{"75% full +6.25%", 1_000_000_000, 22_500_000, 1_062_500_000},
+		{"25% full -6.25%", 1_000_000_000, 7_500_000, 937_500_000},
+		{"tiny base rises by at least 1 wei", 7, 30_000_000, 8},
+		{"tiny base may not fall", 7, 14_999_999, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NextBaseFee(big.NewInt(tt.base), tt.gasUsed, limit)
+			if got.Cmp(big.NewInt(tt.want)) != 0 {
+				t.Fatalf("got %s, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBaseFeeSequences(t *testing.T) {
+	// Three full blocks from 100 gwei: each step adds floor(base/8).
+	fee := gwei(100)
+	for i, want := range []int64{112_500_000_000, 126_562_500_000, 142_382_812_500} {
+		fee = NextBaseFee(fee, 30_000_000, 30_000_000)
+		if fee.Cmp(big.NewInt(want)) != 0 {
+			t.Fatalf("block %d: got %s, want %d", i+1, fee, want)
+		}
+	}
+	if got := MaxBaseFeeAfter(gwei(100), 3); got.Cmp(fee) != 0 {
+		t.Fatalf("MaxBaseFeeAfter = %s, want %s", got, fee)
+	}
+
+	// Alternating full and empty blocks do not cancel out: +12.5% then -12.5%
+	// leaves 98.4375% of the start.
+	fee = gwei(64)
+	for i, want := range []int64{72_000_000_000, 63_000_000_000, 70_875_000_000, 62_015_625_000} {
+		used := uint64(30_000_000)
+		if i%2 == 1 {
+			used = 0
+		}
+		fee = NextBaseFee(fee, used, 30_000_000)
+		if fee.Cmp(big.NewInt(want)) != 0 {
+			t.Fatalf("block %d: got %s, want %d", i+1, fee, want)
+		}
+	}
+}
+
+// feeHistoryFixture has rewards at the 10th/50th/90th percentiles for four
+// blocks. Block 101 is empty and must be ignored.
+func feeHistoryFixture() *ethereum.FeeHistory {
+	return &ethereum.FeeHistory{
+		OldestBlock:  big.NewInt(97),
+		GasUsedRatio: []float64{0.5, 0, 0.9, 0.3},
+		Reward: [][]*big.Int{
+			{gwei(1), gwei(2), gwei(3)},
+			{big.NewInt(0), big.NewInt(0), big.NewInt(0)},
+			{gwei(2), gwei(4), gwei(8)},
+			{gwei(3), gwei(5), gwei(9)},
+		},
+		BaseFee: []*big.Int{gwei(9), gwei(9), gwei(9), gwei(10), big.NewInt(11_250_000_000)},
+	}
+}
+
+func TestEstimateFees(t *testing.T) {
+	client := &mockFeeClient{
+		header: &types.Header{
+			Number:   big.NewInt(100),
+			BaseFee:  gwei(10),
+			GasUsed:  30_000_000,
+			GasLimit: 30_000_000,
+		},
+		history: feeHistoryFixture(),
+	}
+	fees, err := EstimateFees(context.Background(), client, OracleConfig{Blocks: 4})
+	if err != nil {
+		t.Fatalf("EstimateFees: %v", err)
+	}
+	if client.historyArgs.blocks != 4 || client.historyArgs.last.Int64() != 100 {
+		t.Fatalf("fee history called with %+v", client.historyArgs)
+	}
+	if !reflect.DeepEqual(client.historyArgs.percentiles, []float64{10, 50, 90}) {
+		t.Fatalf("percentiles = %v", client.historyArgs.percentiles)
+	}
+	if fees.NextBaseFee.Cmp(big.NewInt(11_250_000_000)) != 0 {
+		t.Fatalf("next base fee = %s", fees.NextBaseFee)
+	}
+
+	// Tips are medians of {1,2,3}, {2,4,5} and {3,8,9} gwei. Max fees are the
+	// next base fee after 1, 3 and 6 more full blocks, plus the tip:
+	// 11.25 -> 12.65625 -> 14.23828125 -> 16.018066406 -> 18.020324706
+	// -> 20.272865294 -> 22.806973455 gwei.
+	want := []FeeEstimate{
+		{Preset: "slow", MaxPriorityFee: gwei(2), MaxFee: big.NewInt(12_656_250_000 + 2_000_000_000)},
+		{Preset: "standard", MaxPriorityFee: gwei(4), MaxFee: big.NewInt(16_018_066_406 + 4_000_000_000)},
+		{Preset: "fast", MaxPriorityFee: gwei(8), MaxFee: big.NewInt(22_806_973_455 + 8_000_000_000)},
+	}
+	for _, w := range want {
+		got, ok := fees.Get(w.Preset)
+		if !ok {
+			t.Fatalf("missing preset %s", w.Preset)
+		}
+		if got.MaxPriorityFee.Cmp(w.MaxPriorityFee) != 0 || got.MaxFee.Cmp(w.MaxFee) != 0 {
+			t.Fatalf("%s: got tip %s max %s, want tip %s max %s",
+				w.Preset, got.MaxPriorityFee, got.MaxFee, w.MaxPriorityFee, w.MaxFee)
+		}
+	}
+}
+
+func TestEstimateFeesFallbackAndErrors(t *testing.T) {
+	header := &types.Header{Number: big.NewInt(5), BaseFee: big.NewInt(100), GasLimit: 30_000_000, GasUsed: 15_000_000}
+	empty := &ethereum.FeeHistory{
+		GasUsedRatio: []float64{0, 0},
+		Reward:       [][]*big.Int{{big.NewInt(0)}, {big.NewInt(0)}},
+	}
+	client := &mockFeeClient{header: header, history: empty}
+	fees, err := EstimateFees(context.Background(), client, OracleConfig{
+		Presets:     []Preset{{Name: "only", Percentile: 25}},
+		FallbackTip: big.NewInt(7),
+	})
+	if err != nil {
+		t.Fatalf("EstimateFees: %v", err)
+	}
+	if got, _ := fees.Get("only"); got.MaxPriorityFee.Int64() != 7 || got.MaxFee.Int64() != 107 {
+		t.Fatalf("fallback estimate = %+v", got)
+	}
+
+	if _, err := EstimateFees(context.Background(), &mockFeeClient{header: &types.Header{Number: big.NewInt(1)}}, OracleConfig{}); err == nil {
+		t.Fatalf("expected pre-London error")
+	}
+	client = &mockFeeClient{header: header, historyErr: errors.New("method not found")}
+	if _, err := EstimateFees(context.Background(), client, OracleConfig{}); err == nil {
+		t.Fatalf("expected fee history error")
+	}
+	client = &mockFeeClient{header: header, history: feeHistoryFixture()}
+	if _, err := EstimateFees(context.Background(), client, OracleConfig{Presets: []Preset{{Name: "bad", Percentile: 101}}}); err == nil {
+		t.Fatalf("expected percentile range error")
+	}
+}
+
+func TestRunWithSpeed(t *testing.T) {
+	client := &mockFeeClient{
+		nonce:   1,
+		chainID: big.NewInt(1),
+		header: &types.Header{
+			Number:   big.NewInt(100),
+			BaseFee:  gwei(10),
+			GasUsed:  30_000_000,
+			GasLimit: 30_000_000,
+		},
+		history: feeHistoryFixture(),
+	}
+	res, err := Run(context.Background(), client, Config{
+		PrivateKey: mustKey(t),
+		To:         common.HexToAddress("0x1"),
+		Speed:      "standard",
+		NoSend:     true,
+	})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if client.tipCalled {
+		t.Fatalf("oracle should replace SuggestGasTipCap")
+	}
+	if res.Fees == nil || res.Tx.GasTipCap().Cmp(gwei(4)) != 0 || res.Tx.GasFeeCap().Int64() != 20_018_066_406 {
+		t.Fatalf("unexpected fees: tip %s cap %s", res.Tx.GasTipCap(), res.Tx.GasFeeCap())
+	}
+
+	// A manual tip keeps the preset's base-fee headroom.
+	res, err = Run(context.Background(), client, Config{
+		PrivateKey:     mustKey(t),
+		To:             common.HexToAddress("0x1"),
+		Speed:          "standard",
+		MaxPriorityFee: gwei(1),
+		NoSend:         true,
+	})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if res.Tx.GasFeeCap().Int64() != 17_018_066_406 {
+		t.Fatalf("unexpected fee cap %s", res.Tx.GasFeeCap())
+	}
+
+	if _, err := Run(context.Background(), client, Config{PrivateKey: mustKey(t), Speed: "ludicrous", NoSend: true}); err == nil {
+		t.Fatalf("expected unknown speed error")
+	}
+}
@@ -0,0 +1,217 @@
+package exercise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Preset is one fee tier of the oracle.
+type Preset struct {
+	Name       string
+	Percentile float64 // reward percentile (0-100) the tip is taken from
+	Headroom   int     // consecutive full blocks maxFee must survive after the next one
+}
+
+// Built-in presets. Faster tiers bid a higher percentile of recent tips and
+// keep more headroom, so a base-fee spike while waiting does not price the
+// transaction out.
+var (
+	PresetSlow     = Preset{Name: "slow", Percentile: 10, Headroom: 1}
+	PresetStandard = Preset{Name: "standard", Percentile: 50, Headroom: 3}
+	PresetFast     = Preset{Name: "fast", Percentile: 90, Headroom: 6}
+)
+
+// OracleConfig tunes EstimateFees. Zero values use defaults.
+type OracleConfig struct {
+	Blocks      uint64   // blocks of eth_feeHistory to sample (default 20)
+	Presets     []Preset // tiers to price (default slow, standard, fast)
+	FallbackTip *big.Int // tip when the sampled blocks were all empty (default 1 gwei)
+	BlockNumber *big.Int // newest block to sample (nil => latest)
+}
+
+// FeeEstimate is the pair of caps for one preset.
+type FeeEstimate struct {
+	Preset         string
+	MaxPriorityFee *big.Int
+	MaxFee         *big.Int
+}
+
+// FeeEstimates is the oracle's answer for one head.
+type FeeEstimates struct {
+	Block       uint64   // newest sampled block
+	BaseFee     *big.Int // base fee of Block
+	NextBaseFee *big.Int // projected base fee of Block+1
+	Estimates   []FeeEstimate
+}
+
+// Get returns the estimate for the named preset.
+func (f *FeeEstimates) Get(name string) (FeeEstimate, bool) {
+	for _, e := range f.Estimates {
+		if e.Preset == name {
+			return e, true
+		}
+	}
+	return FeeEstimate{}, false
+}
+
+// NextBaseFee applies the EIP-1559 update rule to a parent block. The target
+// is half the gas limit; the base fee moves by at most 1/8 per block in
+// proportion to how far gas used was from the target, and rises by at least
+// 1 wei when above it.
+func NextBaseFee(parentBaseFee *big.Int, gasUsed, gasLimit uint64) *big.Int {
+	target := gasLimit / params.DefaultElasticityMultiplier
+	next := new(big.Int).Set(parentBaseFee)
+	if target == 0 || gasUsed == target {
+		return next
+	}
+	denom := new(big.Int).SetUint64(target * params.DefaultBaseFeeChangeDenominator)
+	if gasUsed > target {
+		delta := new(big.Int).Mul(parentBaseFee, new(big.Int).SetUint64(gasUsed-target))
+		delta.Div(delta, denom)
+		if delta.Sign() == 0 {
+			delta.SetUint64(1)
+		}
+		return next.Add(next, delta)
+	}
+	delta := new(big.Int).Mul(parentBaseFee, new(big.Int).SetUint64(target-gasUsed))
+	delta.Div(delta, denom)
+	if next.Sub(next, delta).Sign() < 0 {
+		next.SetUint64(0)
+	}
+	return next
+}
+
+// MaxBaseFeeAfter is the highest base fee reachable from baseFee after n
+// blocks, i.e. after n completely full blocks.
+func MaxBaseFeeAfter(baseFee *big.Int, n int) *big.Int {
+	fee := new(big.Int).Set(baseFee)
+	for i := 0; i < n; i++ {
+		fee = NextBaseFee(fee, 2, 2) // full: used == limit == 2 * target
+	}
+	return fee
+}
+
+// EstimateFees samples eth_feeHistory and prices each preset:
+//
+//	maxPriorityFee = median over non-empty blocks of the preset's reward percentile
+//	maxFee         = MaxBaseFeeAfter(NextBaseFee(head), Headroom) + maxPriorityFee
+func EstimateFees(ctx context.Context, client FeeClient, cfg OracleConfig) (*FeeEstimates, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if client == nil {
+		return nil, errors.New("client is nil")
+	}
+	header, err := client.HeaderByNumber(ctx, cfg.BlockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("header by number: %w", err)
+	}
+	return estimateFees(ctx, client, header, cfg)
+}
+
+func estimateFees(ctx context.Context, client FeeClient, header *types.Header, cfg OracleConfig) (*FeeEstimates, error) {
+	if header == nil || header.BaseFee == nil || header.Number == nil {
+		return nil, errors.New("base fee unavailable (upgrade to London block)")
+	}
+	if cfg.Blocks == 0 {
+		cfg.Blocks = 20
+	}
+	if len(cfg.Presets) == 0 {
+		cfg.Presets = []Preset{PresetSlow, PresetStandard, PresetFast}
+	}
+	if cfg.FallbackTip == nil {
+		cfg.FallbackTip = big.NewInt(params.GWei)
+	}
+
+	// eth_feeHistory wants strictly increasing percentiles.
+	var percentiles []float64
+	for _, p := range cfg.Presets {
+		if p.Percentile < 0 || p.Percentile > 100 {
+			return nil, fmt.Errorf("preset %s: percentile %v out of range", p.Name, p.Percentile)
+		}
+		if p.Headroom < 0 {
+			return nil, fmt.Errorf("preset %s: negative headroom", p.Name)
+		}
+		percentiles = append(percentiles, p.Percentile)
+	}
+	sort.Float64s(percentiles)
+	percentiles = dedupFloats(percentiles)
+
+	history, err := client.FeeHistory(ctx, cfg.Blocks, header.Number, percentiles)
+	if err != nil {
+		return nil, fmt.Errorf("fee history: %w", err)
+	}
+	if history == nil {
+		return nil, errors.New("fee history was nil")
+	}
+
+	next := NextBaseFee(header.BaseFee, header.GasUsed, header.GasLimit)
+	out := &FeeEstimates{
+		Block:       header.Number.Uint64(),
+		BaseFee:     new(big.Int).Set(header.BaseFee),
+		NextBaseFee: next,
+		Estimates:   make([]FeeEstimate, 0, len(cfg.Presets)),
+	}
+	for _, p := range cfg.Presets {
+		col := sort.SearchFloat64s(percentiles, p.Percentile)
+		tip, err := medianReward(history, col)
+		if err != nil {
+			return nil, err
+		}
+		if tip == nil {
+			tip = new(big.Int).Set(cfg.FallbackTip)
+		}
+		maxFee := MaxBaseFeeAfter(next, p.Headroom)
+		out.Estimates = append(out.Estimates, FeeEstimate{
+			Preset:         p.Name,
+			MaxPriorityFee: tip,
+			MaxFee:         maxFee.Add(maxFee, tip),
+		})
+	}
+	return out, nil
+}
+
+// medianReward is the median of column col across blocks that had gas used.
+// Empty blocks report a reward of zero, which would drag every tier down, so
+// they are skipped. It returns nil when no block qualifies.
+func medianReward(history *ethereum.FeeHistory, col int) (*big.Int, error) {
+	var tips []*big.Int
+	for i, rewards := range history.Reward {
+		if i < len(history.GasUsedRatio) && history.GasUsedRatio[i] == 0 {
+			continue
+		}
+		if col >= len(rewards) {
+			return nil, fmt.Errorf("fee history block %d: %d rewards, want column %d", i, len(rewards), col)
+		}
+		if rewards[col] != nil {
+			tips = append(tips, rewards[col])
+		}
+	}
+	if len(tips) == 0 {
+		return nil, nil
+	}
+	sort.Slice(tips, func(i, j int) bool { return tips[i].Cmp(tips[j]) < 0 })
+	mid := len(tips) / 2
+	if len(tips)%2 == 1 {
+		return new(big.Int).Set(tips[mid]), nil
+	}
+	sum := new(big.Int).Add(tips[mid-1], tips[mid])
+	return sum.Rsh(sum, 1), nil
+}
+
+func dedupFloats(s []float64) []float64 {
+	var out []float64
+	for i, v := range s {
+		if i == 0 || v != s[i-1] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
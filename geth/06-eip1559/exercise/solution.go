@@ -257,8 +257,28 @@ func Run(ctx context.Context, client FeeClient, cfg Config) (*Result, error) {
 	//
 	// The suggestion is just a hint - you're not required to use it. But it's
 	// usually reasonable for "normal" priority.
+	//
+	// Fee oracle (cfg.Speed): Instead of one node's opinion, sample the last
+	// blocks with eth_feeHistory and pick a reward percentile per preset
+	// (slow/standard/fast). See oracle.go. The oracle also projects next
+	// block's base fee with the EIP-1559 formula, which Step 7 builds on.
+	var fees *FeeEstimates
+	var estimate FeeEstimate
+	if cfg.Speed != "" {
+		fees, err = estimateFees(ctx, client, header, OracleConfig{})
+		if err != nil {
+			return nil, fmt.Errorf("estimate fees: %w", err)
+		}
+		var ok bool
+		if estimate, ok = fees.Get(cfg.Speed); !ok {
+			return nil, fmt.Errorf("unknown speed %q", cfg.Speed)
+		}
+	}
+
 	tipCap := cfg.MaxPriorityFee
-	if tipCap == nil {
+	if tipCap == nil && fees != nil {
+		tipCap = estimate.MaxPriorityFee
+	} else if tipCap == nil {
 		tipCap, err = client.SuggestGasTipCap(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("suggest gas tip cap: %w", err)
@@ -297,8 +317,15 @@ func Run(ctx context.Context, client FeeClient, cfg Config) (*Result, error) {
 	//
 	// Actual fee paid: min(maxFee, baseFee + tip). So even if you set a high
 	// maxFee, you only pay the actual baseFee + tip. The extra is refunded.
+	//
+	// With a fee oracle preset, the base-fee part is instead the projected next
+	// base fee grown by the preset's headroom of full blocks (MaxFee minus its
+	// tip), so a manual tip override still adds on top correctly.
 	maxFee := cfg.MaxFee
-	if maxFee == nil {
+	if maxFee == nil && fees != nil {
+		maxFee = new(big.Int).Sub(estimate.MaxFee, estimate.MaxPriorityFee)
+		maxFee.Add(maxFee, tipCap)
+	} else if maxFee == nil {
 		// Calculate: 2 * baseFee + tipCap
 		//
 		// Step 1: Multiply baseFee by 2
@@ -504,5 +531,6 @@ func Run(ctx context.Context, client FeeClient, cfg Config) (*Result, error) {
 		Nonce:       nonce,     // Sequence number
 		Tx:          signedTx,  // Signed transaction (ready for tracking)
 		BaseFee:     baseFee,   // Base fee context (already defensively copied)
+		Fees:        fees,      // Oracle estimates (nil unless cfg.Speed was set)
 	}, nil
 }
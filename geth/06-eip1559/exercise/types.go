@@ -5,6 +5,7 @@ import (
 	"crypto/ecdsa"
 	"math/big"
 
+	ethereum "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 )
@@ -16,6 +17,7 @@ type FeeClient interface {
 	ChainID(ctx context.Context) (*big.Int, error)
 	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
 	SendTransaction(ctx context.Context, tx *types.Transaction) error
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
 }
 
 // Config controls how Run constructs the transaction.
@@ -31,6 +33,7 @@ type Config struct {
 	Data           []byte
 	NoSend         bool
 	BlockNumber    *big.Int // optional header to sample fees from (nil => latest)
+	Speed          string   // optional oracle preset ("slow", "standard", "fast"); "" => SuggestGasTipCap and 2x base fee
 }
 
 // Result surfaces useful transaction metadata.
@@ -39,4 +42,5 @@ type Result struct {
 	Nonce       uint64
 	Tx          *types.Transaction
 	BaseFee     *big.Int
+	Fees        *FeeEstimates // set when Config.Speed was used
 }
@@ -0,0 +1,355 @@
+package exercise
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ErrUnknownMethod is returned when a method name is not in the ABI.
+var ErrUnknownMethod = errors.New("unknown method")
+
+// Kind classifies an ABI type.
+type Kind int
+
+const (
+	KindUint Kind = iota
+	KindInt
+	KindBool
+	KindAddress
+	KindFixedBytes // bytes1 ... bytes32
+	KindBytes
+	KindString
+	KindArray // T[k]
+	KindSlice // T[]
+	KindTuple
+)
+
+// Type is a parsed ABI type. Size is the bit width for int/uint, the byte
+// length for bytesN and the element count for fixed arrays.
+type Type struct {
+	Kind       Kind
+	Size       int
+	Elem       *Type      // arrays and slices
+	Components []Argument // tuples
+}
+
+// Argument is a named input, output or tuple component.
+type Argument struct {
+	Name string
+	Type Type
+}
+
+// Method is a contract function from a JSON ABI.
+type Method struct {
+	Name      string
+	Signature string // canonical form, e.g. "transfer(address,uint256)"
+	Selector  [4]byte
+	Inputs    []Argument
+	Outputs   []Argument
+}
+
+// ABI holds the functions of a contract keyed by name. Overloaded functions
+// get a numeric suffix in declaration order (foo, foo0, foo1, ...), the same
+// naming go-ethereum's abigen uses.
+type ABI struct {
+	Methods map[string]Method
+}
+
+// jsonArgument mirrors one entry of "inputs"/"outputs"/"components".
+type jsonArgument struct {
+	Name       string         `json:"name"`
+	Type       string         `json:"type"`
+	Components []jsonArgument `json:"components"`
+}
+
+type jsonEntry struct {
+	Type    string         `json:"type"`
+	Name    string         `json:"name"`
+	Inputs  []jsonArgument `json:"inputs"`
+	Outputs []jsonArgument `json:"outputs"`
+}
+
+// ParseABI parses a JSON ABI (the array solc and block explorers emit).
+// Entries other than functions are ignored.
+func ParseABI(data []byte) (*ABI, error) {
+	var entries []jsonEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("parse abi: %w", err)
+	}
+	out := &ABI{Methods: make(map[string]Method)}
+	for _, e := range entries {
+		if e.Type != "" && e.Type != "function" {
+			continue
+		}
+		inputs, err := parseArguments(e.Inputs)
+		if err != nil {
+			return nil, fmt.Errorf("parse abi: %s inputs: %w", e.Name, err)
+		}
+		outputs, err := parseArguments(e.Outputs)
+		if err != nil {
+			return nil, fmt.Errorf("parse abi: %s outputs: %w", e.Name, err)
+		}
+		m := NewMethod(e.Name, inputs, outputs)
+
+		name := e.Name
+		for i := 0; ; i++ {
+			if _, taken := out.Methods[name]; !taken {
+				break
+			}
+			name = fmt.Sprintf("%s%d", e.Name, i)
+		}
+		out.Methods[name] = m
+	}
+	return out, nil
+}
+
+// NewMethod builds a Method and derives its signature and selector.
+func NewMethod(name string, inputs, outputs []Argument) Method {
+	types := make([]string, len(inputs))
+	for i, in := range inputs {
+		types[i] = in.Type.String()
+	}
+	sig := name + "(" + strings.Join(types, ",") + ")"
+	m := Method{Name: name, Signature: sig, Inputs: inputs, Outputs: outputs}
+	copy(m.Selector[:], crypto.Keccak256([]byte(sig))[:4])
+	return m
+}
+
+// Method looks up a method by name.
+func (a *ABI) Method(name string) (Method, error) {
+	m, ok := a.Methods[name]
+	if !ok {
+		return Method{}, fmt.Errorf("%w: %q", ErrUnknownMethod, name)
+	}
+	return m, nil
+}
+
+// Pack encodes a call to the named method: selector followed by the
+// ABI-encoded arguments.
+func (a *ABI) Pack(name string, args ...interface{}) ([]byte, error) {
+	m, err := a.Method(name)
+	if err != nil {
+		return nil, err
+	}
+	return m.Pack(args...)
+}
+
+// Unpack decodes the named method's return data into typed Go values, one
+// per output.
+func (a *ABI) Unpack(name string, data []byte) ([]interface{}, error) {
+	m, err := a.Method(name)
+	if err != nil {
+		return nil, err
+	}
+	return m.Unpack(data)
+}
+
+// Pack encodes the selector and arguments.
+func (m Method) Pack(args ...interface{}) ([]byte, error) {
+	enc, err := Encode(m.Inputs, args...)
+	if err != nil {
+		return nil, fmt.Errorf("pack %s: %w", m.Signature, err)
+	}
+	return append(m.Selector[:], enc...), nil
+}
+
+// Unpack decodes return data.
+func (m Method) Unpack(data []byte) ([]interface{}, error) {
+	out, err := Decode(m.Outputs, data)
+	if err != nil {
+		return nil, fmt.Errorf("unpack %s: %w", m.Signature, err)
+	}
+	return out, nil
+}
+
+func parseArguments(in []jsonArgument) ([]Argument, error) {
+	out := make([]Argument, len(in))
+	for i, a := range in {
+		components, err := parseArguments(a.Components)
+		if err != nil {
+			return nil, err
+		}
+		t, err := ParseType(a.Type, components)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = Argument{Name: a.Name, Type: t}
+	}
+	return out, nil
+}
+
+// ParseType parses a type string such as "uint256", "bytes32[]" or
+// "tuple[2]". components are only used for tuple types.
+func ParseType(s string, components []Argument) (Type, error) {
+	// Array suffixes bind right to left: uint8[2][] is a slice of uint8[2].
+	if strings.HasSuffix(s, "]") {
+		open := strings.LastIndex(s, "[")
+		if open < 0 {
+			return Type{}, fmt.Errorf("bad type %q", s)
+		}
+		elem, err := ParseType(s[:open], components)
+		if err != nil {
+			return Type{}, err
+		}
+		size := s[open+1 : len(s)-1]
+		if size == "" {
+			return Type{Kind: KindSlice, Elem: &elem}, nil
+		}
+		n, err := strconv.Atoi(size)
+		if err != nil || n <= 0 {
+			return Type{}, fmt.Errorf("bad array length in %q", s)
+		}
+		return Type{Kind: KindArray, Size: n, Elem: &elem}, nil
+	}
+
+	switch s {
+	case "bool":
+		return Type{Kind: KindBool}, nil
+	case "address":
+		return Type{Kind: KindAddress}, nil
+	case "string":
+		return Type{Kind: KindString}, nil
+	case "bytes":
+		return Type{Kind: KindBytes}, nil
+	case "uint":
+		return Type{Kind: KindUint, Size: 256}, nil
+	case "int":
+		return Type{Kind: KindInt, Size: 256}, nil
+	case "tuple":
+		// Solidity has no empty structs, and an empty tuple takes no head
+		// space, which would leave a slice of them unbounded when decoding.
+		if len(components) == 0 {
+			return Type{}, fmt.Errorf("tuple %q has no components", s)
+		}
+		if err := checkComponentNames(components); err != nil {
+			return Type{}, err
+		}
+		return Type{Kind: KindTuple, Components: components}, nil
+	}
+
+	for _, p := range []struct {
+		prefix   string
+		kind     Kind
+		min, max int
+		step     int
+	}{
+		{"uint", KindUint, 8, 256, 8},
+		{"int", KindInt, 8, 256, 8},
+		{"bytes", KindFixedBytes, 1, 32, 1},
+	} {
+		if !strings.HasPrefix(s, p.prefix) {
+			continue
+		}
+		n, err := strconv.Atoi(s[len(p.prefix):])
+		if err != nil || n < p.min || n > p.max || n%p.step != 0 {
+			return Type{}, fmt.Errorf("bad type %q", s)
+		}
+		return Type{Kind: p.kind, Size: n}, nil
+	}
+	return Type{}, fmt.Errorf("unsupported type %q", s)
+}
+
+// checkComponentNames rejects tuples whose components would not map onto
+// distinct exported Go struct fields.
+func checkComponentNames(components []Argument) error {
+	seen := make(map[string]bool)
+	for i, c := range components {
+		name := fieldName(c.Name, i)
+		if !token.IsIdentifier(name) || !token.IsExported(name) {
+			return fmt.Errorf("tuple field %q is not a valid Go identifier", c.Name)
+		}
+		if seen[name] {
+			return fmt.Errorf("duplicate tuple field %q", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// String returns the canonical type name used in signatures.
+func (t Type) String() string {
+	switch t.Kind {
+	case KindUint:
+		return "uint" + strconv.Itoa(t.Size)
+	case KindInt:
+		return "int" + strconv.Itoa(t.Size)
+	case KindBool:
+		return "bool"
+	case KindAddress:
+		return "address"
+	case KindFixedBytes:
+		return "bytes" + strconv.Itoa(t.Size)
+	case KindBytes:
+		return "bytes"
+	case KindString:
+		return "string"
+	case KindArray:
+		return t.Elem.String() + "[" + strconv.Itoa(t.Size) + "]"
+	case KindSlice:
+		return t.Elem.String() + "[]"
+	case KindTuple:
+		parts := make([]string, len(t.Components))
+		for i, c := range t.Components {
+			parts[i] = c.Type.String()
+		}
+		return "(" + strings.Join(parts, ",") + ")"
+	}
+	return "?"
+}
+
+// Dynamic reports whether the type is encoded in the tail (behind an offset)
+// rather than in place.
+func (t Type) Dynamic() bool {
+	switch t.Kind {
+	case KindBytes, KindString, KindSlice:
+		return true
+	case KindArray:
+		return t.Elem.Dynamic()
+	case KindTuple:
+		for _, c := range t.Components {
+			if c.Type.Dynamic() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// headSize is the number of bytes the type occupies in its enclosing head.
+func (t Type) headSize() int {
+	if t.Dynamic() {
+		return 32
+	}
+	switch t.Kind {
+	case KindArray:
+		return t.Size * t.Elem.headSize()
+	case KindTuple:
+		n := 0
+		for _, c := range t.Components {
+			n += c.Type.headSize()
+		}
+		return n
+	}
+	return 32
+}
+
+// fieldName is the Go struct field a tuple component maps to: the name in
+// CamelCase (my_value -> MyValue), or FieldN when the component is unnamed.
+func fieldName(name string, i int) string {
+	parts := strings.Split(name, "_")
+	for j, p := range parts {
+		if p != "" {
+			parts[j] = strings.ToUpper(p[:1]) + p[1:]
+		}
+	}
+	if out := strings.Join(parts, ""); out != "" {
+		return out
+	}
+	return "Field" + strconv.Itoa(i)
+}
@@ -0,0 +1,475 @@
+package exercise
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Go representations produced by Decode and accepted by Encode:
+//
+//	uint8/16/32/64, int8/16/32/64  -> the matching Go integer
+//	other int/uint widths          -> *big.Int
+//	bool                           -> bool
+//	address                        -> common.Address
+//	bytesN                         -> [N]byte
+//	bytes                          -> []byte
+//	string                         -> string
+//	T[k]                           -> [k]T
+//	T[]                            -> []T
+//	tuple                          -> struct with one exported field per component
+//
+// Encode is more forgiving than Decode: any Go integer or *big.Int works for
+// an int/uint that fits, slices and arrays are interchangeable when lengths
+// match, and a tuple may also be given as []interface{}.
+
+var (
+	bigT     = reflect.TypeOf((*big.Int)(nil))
+	addressT = reflect.TypeOf(common.Address{})
+
+	tt256 = new(big.Int).Lsh(big.NewInt(1), 256)
+)
+
+// GoType returns the Go type Decode produces for t.
+func (t Type) GoType() reflect.Type {
+	switch t.Kind {
+	case KindUint:
+		switch t.Size {
+		case 8:
+			return reflect.TypeOf(uint8(0))
+		case 16:
+			return reflect.TypeOf(uint16(0))
+		case 32:
+			return reflect.TypeOf(uint32(0))
+		case 64:
+			return reflect.TypeOf(uint64(0))
+		}
+		return bigT
+	case KindInt:
+		switch t.Size {
+		case 8:
+			return reflect.TypeOf(int8(0))
+		case 16:
+			return reflect.TypeOf(int16(0))
+		case 32:
+			return reflect.TypeOf(int32(0))
+		case 64:
+			return reflect.TypeOf(int64(0))
+		}
+		return bigT
+	case KindBool:
+		return reflect.TypeOf(false)
+	case KindAddress:
+		return addressT
+	case KindFixedBytes:
+		return reflect.ArrayOf(t.Size, reflect.TypeOf(byte(0)))
+	case KindBytes:
+		return reflect.TypeOf([]byte(nil))
+	case KindString:
+		return reflect.TypeOf("")
+	case KindArray:
+		return reflect.ArrayOf(t.Size, t.Elem.GoType())
+	case KindSlice:
+		return reflect.SliceOf(t.Elem.GoType())
+	case KindTuple:
+		fields := make([]reflect.StructField, len(t.Components))
+		for i, c := range t.Components {
+			fields[i] = reflect.StructField{
+				Name: fieldName(c.Name, i),
+				Type: c.Type.GoType(),
+				Tag:  reflect.StructTag(fmt.Sprintf(`json:"%s"`, c.Name)),
+			}
+		}
+		return reflect.StructOf(fields)
+	}
+	panic(fmt.Sprintf("abi: unknown kind %d", t.Kind))
+}
+
+// Encode ABI-encodes values as the tuple described by args.
+func Encode(args []Argument, values ...interface{}) ([]byte, error) {
+	if len(values) != len(args) {
+		return nil, fmt.Errorf("got %d values for %d arguments", len(values), len(args))
+	}
+	types := make([]Type, len(args))
+	vals := make([]reflect.Value, len(args))
+	for i, a := range args {
+		types[i] = a.Type
+		vals[i] = reflect.ValueOf(values[i])
+	}
+	return encodeSequence(types, vals)
+}
+
+// encodeSequence lays out values as heads followed by tails; a dynamic value's
+// head is the offset of its tail from the start of the sequence.
+func encodeSequence(types []Type, vals []reflect.Value) ([]byte, error) {
+	headLen := 0
+	for _, t := range types {
+		headLen += t.headSize()
+	}
+	var head, tail []byte
+	for i, t := range types {
+		enc, err := encodeValue(t, vals[i])
+		if err != nil {
+			if len(types) > 1 {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			return nil, err
+		}
+		if t.Dynamic() {
+			head = append(head, word(big.NewInt(int64(headLen+len(tail))))...)
+			tail = append(tail, enc...)
+		} else {
+			head = append(head, enc...)
+		}
+	}
+	return append(head, tail...), nil
+}
+
+func encodeValue(t Type, v reflect.Value) ([]byte, error) {
+	v = indirect(v)
+	if !v.IsValid() {
+		return nil, fmt.Errorf("missing value for %s", t)
+	}
+	switch t.Kind {
+	case KindUint, KindInt:
+		n, err := toBig(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", t, err)
+		}
+		if !fits(t, n) {
+			return nil, fmt.Errorf("%s out of range for %s", n, t)
+		}
+		return word(n), nil
+
+	case KindBool:
+		if v.Kind() != reflect.Bool {
+			return nil, fmt.Errorf("cannot use %s as bool", v.Type())
+		}
+		if v.Bool() {
+			return word(big.NewInt(1)), nil
+		}
+		return make([]byte, 32), nil
+
+	case KindAddress:
+		b, ok := byteArray(v)
+		if !ok || len(b) != common.AddressLength {
+			return nil, fmt.Errorf("cannot use %s as address", v.Type())
+		}
+		return common.LeftPadBytes(b, 32), nil
+
+	case KindFixedBytes:
+		b, ok := byteArray(v)
+		if !ok || len(b) != t.Size {
+			return nil, fmt.Errorf("cannot use %s as %s", v.Type(), t)
+		}
+		return common.RightPadBytes(b, 32), nil
+
+	case KindBytes, KindString:
+		var b []byte
+		switch {
+		case t.Kind == KindString && v.Kind() == reflect.String:
+			b = []byte(v.String())
+		case t.Kind == KindBytes && v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+			b = v.Bytes()
+		default:
+			return nil, fmt.Errorf("cannot use %s as %s", v.Type(), t)
+		}
+		out := word(big.NewInt(int64(len(b))))
+		return append(out, common.RightPadBytes(b, (len(b)+31)/32*32)...), nil
+
+	case KindArray, KindSlice:
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return nil, fmt.Errorf("cannot use %s as %s", v.Type(), t)
+		}
+		if t.Kind == KindArray && v.Len() != t.Size {
+			return nil, fmt.Errorf("%s needs %d elements, got %d", t, t.Size, v.Len())
+		}
+		types := make([]Type, v.Len())
+		vals := make([]reflect.Value, v.Len())
+		for i := range vals {
+			types[i] = *t.Elem
+			vals[i] = v.Index(i)
+		}
+		enc, err := encodeSequence(types, vals)
+		if err != nil {
+			return nil, fmt.Errorf("%s%w", t, err)
+		}
+		if t.Kind == KindSlice {
+			enc = append(word(big.NewInt(int64(v.Len()))), enc...)
+		}
+		return enc, nil
+
+	case KindTuple:
+		vals, err := tupleFields(t, v)
+		if err != nil {
+			return nil, err
+		}
+		types := make([]Type, len(t.Components))
+		for i, c := range t.Components {
+			types[i] = c.Type
+		}
+		return encodeSequence(types, vals)
+	}
+	return nil, fmt.Errorf("unknown kind %d", t.Kind)
+}
+
+// tupleFields matches a struct's fields (by CamelCase component name) or a
+// []interface{} (by position) to the tuple's components.
+func tupleFields(t Type, v reflect.Value) ([]reflect.Value, error) {
+	out := make([]reflect.Value, len(t.Components))
+	switch v.Kind() {
+	case reflect.Struct:
+		for i, c := range t.Components {
+			f := v.FieldByName(fieldName(c.Name, i))
+			if !f.IsValid() {
+				return nil, fmt.Errorf("%s has no field %s for %s", v.Type(), fieldName(c.Name, i), t)
+			}
+			out[i] = f
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Len() != len(t.Components) {
+			return nil, fmt.Errorf("%s needs %d values, got %d", t, len(t.Components), v.Len())
+		}
+		for i := range out {
+			out[i] = v.Index(i)
+		}
+	default:
+		return nil, fmt.Errorf("cannot use %s as %s", v.Type(), t)
+	}
+	return out, nil
+}
+
+// indirect unwraps interfaces and pointers, except *big.Int.
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Interface || (v.Kind() == reflect.Ptr && v.Type() != bigT)) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	if v.IsValid() && v.Type() == bigT && v.IsNil() {
+		return reflect.Value{}
+	}
+	return v
+}
+
+func toBig(v reflect.Value) (*big.Int, error) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return new(big.Int).SetUint64(v.Uint()), nil
+	}
+	if v.Type() == bigT {
+		return v.Interface().(*big.Int), nil
+	}
+	if v.Type() == bigT.Elem() {
+		n := v.Interface().(big.Int)
+		return &n, nil
+	}
+	return nil, fmt.Errorf("cannot use %s as an integer", v.Type())
+}
+
+func byteArray(v reflect.Value) ([]byte, bool) {
+	if (v.Kind() != reflect.Array && v.Kind() != reflect.Slice) || v.Type().Elem().Kind() != reflect.Uint8 {
+		return nil, false
+	}
+	b := make([]byte, v.Len())
+	reflect.Copy(reflect.ValueOf(b), v)
+	return b, true
+}
+
+// fits reports whether n is representable by the int/uint type t.
+func fits(t Type, n *big.Int) bool {
+	if t.Kind == KindUint {
+		return n.Sign() >= 0 && n.BitLen() <= t.Size
+	}
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(t.Size-1))
+	return n.Cmp(limit) < 0 && n.Cmp(new(big.Int).Neg(limit)) >= 0
+}
+
+// word encodes n as a 32-byte two's-complement big-endian word.
+func word(n *big.Int) []byte {
+	if n.Sign() < 0 {
+		n = new(big.Int).Add(tt256, n)
+	}
+	return common.LeftPadBytes(n.Bytes(), 32)
+}
+
+// Decode decodes data laid out as the tuple described by args and returns
+// one typed Go value per argument.
+func Decode(args []Argument, data []byte) ([]interface{}, error) {
+	types := make([]Type, len(args))
+	for i, a := range args {
+		types[i] = a.Type
+	}
+	vals, err := decodeSequence(types, data)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]interface{}, len(vals))
+	for i, v := range vals {
+		out[i] = v.Interface()
+	}
+	return out, nil
+}
+
+var errShort = errors.New("data too short")
+
+func decodeSequence(types []Type, data []byte) ([]reflect.Value, error) {
+	out := make([]reflect.Value, len(types))
+	pos := 0
+	for i, t := range types {
+		if pos+32 > len(data) {
+			return nil, fmt.Errorf("%s at %d: %w", t, pos, errShort)
+		}
+		var err error
+		if t.Dynamic() {
+			offset, ok := readLength(data[pos:], len(data))
+			if !ok {
+				return nil, fmt.Errorf("%s at %d: bad offset", t, pos)
+			}
+			out[i], err = decodeValue(t, data[offset:])
+		} else {
+			out[i], err = decodeValue(t, data[pos:])
+		}
+		if err != nil {
+			return nil, err
+		}
+		pos += t.headSize()
+	}
+	return out, nil
+}
+
+func decodeValue(t Type, data []byte) (reflect.Value, error) {
+	if t.Kind != KindArray && t.Kind != KindTuple && len(data) < 32 {
+		return reflect.Value{}, fmt.Errorf("%s: %w", t, errShort)
+	}
+	switch t.Kind {
+	case KindUint, KindInt:
+		n := new(big.Int).SetBytes(data[:32])
+		if t.Kind == KindInt && n.Bit(255) == 1 {
+			n.Sub(n, tt256)
+		}
+		if !fits(t, n) {
+			return reflect.Value{}, fmt.Errorf("%s: value out of range", t)
+		}
+		gt := t.GoType()
+		if gt == bigT {
+			return reflect.ValueOf(n), nil
+		}
+		v := reflect.New(gt).Elem()
+		if t.Kind == KindUint {
+			v.SetUint(n.Uint64())
+		} else {
+			v.SetInt(n.Int64())
+		}
+		return v, nil
+
+	case KindBool:
+		n := new(big.Int).SetBytes(data[:32])
+		if n.BitLen() > 1 {
+			return reflect.Value{}, fmt.Errorf("bool: improperly encoded value %x", data[:32])
+		}
+		return reflect.ValueOf(n.Sign() == 1), nil
+
+	case KindAddress:
+		if !allZero(data[:12]) {
+			return reflect.Value{}, fmt.Errorf("address: dirty high bytes")
+		}
+		return reflect.ValueOf(common.BytesToAddress(data[12:32])), nil
+
+	case KindFixedBytes:
+		if !allZero(data[t.Size:32]) {
+			return reflect.Value{}, fmt.Errorf("%s: dirty padding", t)
+		}
+		v := reflect.New(t.GoType()).Elem()
+		reflect.Copy(v, reflect.ValueOf(data[:t.Size]))
+		return v, nil
+
+	case KindBytes, KindString:
+		n, ok := readLength(data, len(data)-32)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("%s: length exceeds data", t)
+		}
+		b := append([]byte(nil), data[32:32+n]...)
+		if t.Kind == KindString {
+			return reflect.ValueOf(string(b)), nil
+		}
+		return reflect.ValueOf(b), nil
+
+	case KindArray, KindSlice:
+		n, body := t.Size, data
+		if t.Kind == KindSlice {
+			// Every element needs at least one head word, which bounds n
+			// before we allocate anything. Only a hand-built empty tuple
+			// has none; ParseType rejects it.
+			size := t.Elem.headSize()
+			if size == 0 {
+				return reflect.Value{}, fmt.Errorf("%s: element type has no size", t)
+			}
+			var ok bool
+			n, ok = readLength(data, (len(data)-32)/size)
+			if !ok {
+				return reflect.Value{}, fmt.Errorf("%s: length exceeds data", t)
+			}
+			body = data[32:]
+		}
+		types := make([]Type, n)
+		for i := range types {
+			types[i] = *t.Elem
+		}
+		elems, err := decodeSequence(types, body)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		var v reflect.Value
+		if t.Kind == KindSlice {
+			v = reflect.MakeSlice(t.GoType(), n, n)
+		} else {
+			v = reflect.New(t.GoType()).Elem()
+		}
+		for i, e := range elems {
+			v.Index(i).Set(e)
+		}
+		return v, nil
+
+	case KindTuple:
+		types := make([]Type, len(t.Components))
+		for i, c := range t.Components {
+			types[i] = c.Type
+		}
+		fields, err := decodeSequence(types, data)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v := reflect.New(t.GoType()).Elem()
+		for i, f := range fields {
+			v.Field(i).Set(f)
+		}
+		return v, nil
+	}
+	return reflect.Value{}, fmt.Errorf("unknown kind %d", t.Kind)
+}
+
+// readLength reads a length or offset word and rejects it if it exceeds max,
+// so callers can slice and allocate with it safely.
+func readLength(data []byte, max int) (int, bool) {
+	n := new(big.Int).SetBytes(data[:32])
+	if !n.IsInt64() || n.Int64() > int64(max) {
+		return 0, false
+	}
+	return int(n.Int64()), true
+}
+
+func allZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
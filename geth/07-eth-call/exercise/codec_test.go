@@ -0,0 +1,434 @@
+package exercise
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const testABI = `[
+	{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"function","name":"mixed","inputs":[
+		{"name":"a","type":"int8"},
+		{"name":"b","type":"uint24"},
+		{"name":"c","type":"bytes3"},
+		{"name":"d","type":"bytes"},
+		{"name":"e","type":"string[]"},
+		{"name":"f","type":"uint16[2][]"},
+		{"name":"g","type":"int256"}
+	],"outputs":[]},
+	{"type":"function","name":"order","inputs":[{"name":"o","type":"tuple","components":[
+		{"name":"maker","type":"address"},
+		{"name":"amounts","type":"uint128[]"},
+		{"name":"fee_bps","type":"uint16"},
+		{"name":"legs","type":"tuple[2]","components":[
+			{"name":"token","type":"address"},
+			{"name":"memo","type":"string"}
+		]}
+	]}],"outputs":[{"name":"id","type":"bytes32"},{"name":"ok","type":"bool"}]},
+	{"type":"function","name":"f","inputs":[],"outputs":[]},
+	{"type":"function","name":"f","inputs":[{"name":"x","type":"uint256"}],"outputs":[]},
+	{"type":"event","name":"Transfer","inputs":[]}
+]`
+
+func parseTestABIs(t testing.TB) (*ABI, abi.ABI) {
+	t.Helper()
+	ours, err := ParseABI([]byte(testABI))
+	if err != nil {
+		t.Fatalf("ParseABI: %v", err)
+	}
+	theirs, err := abi.JSON(strings.NewReader(testABI))
+	if err != nil {
+		t.Fatalf("abi.JSON: %v", err)
+	}
+	return ours, theirs
+}
+
+func TestParseABI(t *testing.T) {
+	ours, theirs := parseTestABIs(t)
+	if len(ours.Methods) != len(theirs.Methods) {
+		t.Fatalf("methods = %d, want %d", len(ours.Methods), len(theirs.Methods))
+	}
+	for name, m := range theirs.Methods {
+		got, err := ours.Method(name)
+		if err != nil {
+			t.Fatalf("missing method %s", name)
+		}
+		if got.Signature != m.Sig || !bytes.Equal(got.Selector[:], m.ID) {
+			t.Fatalf("%s: got %s %x, want %s %x", name, got.Signature, got.Selector, m.Sig, m.ID)
+		}
+	}
+	if sel := ours.Methods["transfer"].Selector; hexutil.Encode(sel[:]) != "0xa9059cbb" {
+		t.Fatalf("transfer selector = %x", sel)
+	}
+	if _, err := ours.Pack("nope"); !errors.Is(err, ErrUnknownMethod) {
+		t.Fatalf("expected ErrUnknownMethod, got %v", err)
+	}
+}
+
+func TestParseTypeErrors(t *testing.T) {
+	for _, s := range []string{"uint7", "uint264", "int0", "bytes0", "bytes33", "uint256[0]", "uint256[x]", "fixed128x18", "uint256]"} {
+		if _, err := ParseType(s, nil); err == nil {
+			t.Errorf("ParseType(%q) succeeded", s)
+		}
+	}
+	dup := []Argument{{Name: "a_b", Type: Type{Kind: KindBool}}, {Name: "aB", Type: Type{Kind: KindBool}}}
+	if _, err := ParseType("tuple", dup); err == nil {
+		t.Errorf("expected duplicate field error")
+	}
+	if _, err := ParseType("tuple[]", nil); err == nil {
+		t.Errorf("expected empty tuple error")
+	}
+}
+
+func TestPackMatchesGeth(t *testing.T) {
+	ours, theirs := parseTestABIs(t)
+
+	type leg struct {
+		Token common.Address
+		Memo  string
+	}
+	type order struct {
+		Maker   common.Address
+		Amounts []*big.Int
+		FeeBps  uint16
+		Legs    [2]leg
+	}
+	huge, _ := new(big.Int).SetString("340282366920938463463374607431768211455", 10) // 2^128-1
+
+	cases := []struct {
+		method string
+		args   []interface{}
+	}{
+		{"transfer", []interface{}{common.HexToAddress("0xbeef"), big.NewInt(1e18)}},
+		{"mixed", []interface{}{
+			int8(-5), big.NewInt(0xabcdef), [3]byte{1, 2, 3}, []byte("hello, world"),
+			[]string{"a", "", strings.Repeat("x", 70)},
+			[][2]uint16{{1, 2}, {65535, 0}},
+			big.NewInt(-1),
+		}},
+		{"order", []interface{}{order{
+			Maker:   common.HexToAddress("0x1234"),
+			Amounts: []*big.Int{big.NewInt(1), huge},
+			FeeBps:  30,
+			Legs:    [2]leg{{common.HexToAddress("0x01"), "in"}, {common.HexToAddress("0x02"), "out"}},
+		}}},
+		{"f0", []interface{}{big.NewInt(7)}},
+	}
+	for _, tc := range cases {
+		want, err := theirs.Pack(tc.method, tc.args...)
+		if err != nil {
+			t.Fatalf("%s: geth pack: %v", tc.method, err)
+		}
+		got, err := ours.Pack(tc.method, tc.args...)
+		if err != nil {
+			t.Fatalf("%s: pack: %v", tc.method, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("%s: encoding mismatch\n got %x\nwant %x", tc.method, got, want)
+		}
+
+		// Inputs decode back to values that re-encode identically.
+		m := ours.Methods[tc.method]
+		vals, err := Decode(m.Inputs, got[4:])
+		if err != nil {
+			t.Fatalf("%s: decode: %v", tc.method, err)
+		}
+		again, err := m.Pack(vals...)
+		if err != nil || !bytes.Equal(again, got) {
+			t.Fatalf("%s: round trip mismatch (%v)", tc.method, err)
+		}
+	}
+}
+
+func TestDecodeTypedValues(t *testing.T) {
+	ours, theirs := parseTestABIs(t)
+	id := crypto.Keccak256Hash([]byte("order"))
+	data, err := theirs.Methods["order"].Outputs.Pack(id, true)
+	if err != nil {
+		t.Fatalf("geth pack: %v", err)
+	}
+	out, err := ours.Unpack("order", data)
+	if err != nil {
+		t.Fatalf("Unpack: %v", err)
+	}
+	if got, ok := out[0].([32]byte); !ok || got != id {
+		t.Fatalf("id = %#v", out[0])
+	}
+	if got, ok := out[1].(bool); !ok || !got {
+		t.Fatalf("ok = %#v", out[1])
+	}
+
+	// Tuples decode into structs with CamelCase fields.
+	args := ours.Methods["order"].Inputs
+	packed, _ := ours.Pack("order", []interface{}{
+		common.HexToAddress("0x1234"), []*big.Int{big.NewInt(9)}, 30,
+		[]interface{}{
+			[]interface{}{common.HexToAddress("0x01"), "in"},
+			[]interface{}{common.HexToAddress("0x02"), "out"},
+		},
+	})
+	vals, err := Decode(args, packed[4:])
+	if err != nil {
+		t.Fatalf("decode order: %v", err)
+	}
+	o := reflect.ValueOf(vals[0])
+	if fee := o.FieldByName("FeeBps").Interface().(uint16); fee != 30 {
+		t.Fatalf("FeeBps = %d", fee)
+	}
+	if memo := o.FieldByName("Legs").Index(1).FieldByName("Memo").String(); memo != "out" {
+		t.Fatalf("Legs[1].Memo = %q", memo)
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	ours, _ := parseTestABIs(t)
+	bad := [][]interface{}{
+		{common.HexToAddress("0x1")},                                       // missing argument
+		{"0x1", big.NewInt(1)},                                             // string for address
+		{common.HexToAddress("0x1"), big.NewInt(-1)},                       // negative uint
+		{common.HexToAddress("0x1"), new(big.Int).Lsh(big.NewInt(1), 256)}, // overflow
+		{common.HexToAddress("0x1"), (*big.Int)(nil)},                      // nil
+	}
+	for i, args := range bad {
+		if _, err := ours.Pack("transfer", args...); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+	if _, err := ours.Pack("mixed", int8(0), 1<<24, [3]byte{}, []byte{}, []string{}, [][2]uint16{}, 0); err == nil {
+		t.Errorf("expected uint24 overflow error")
+	}
+	if _, err := ours.Pack("mixed", 200, 1, [3]byte{}, []byte{}, []string{}, [][2]uint16{}, 0); err == nil {
+		t.Errorf("expected int8 overflow error")
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	ours, _ := parseTestABIs(t)
+	good, _ := ours.Pack("mixed", int8(1), 2, [3]byte{}, []byte("x"), []string{"y"}, [][2]uint16{{1, 2}}, 3)
+	args := ours.Methods["mixed"].Inputs
+	if _, err := Decode(args, good[4:]); err != nil {
+		t.Fatalf("decode good data: %v", err)
+	}
+	if _, err := Decode(args, good[4:100]); err == nil {
+		t.Fatalf("expected truncated data error")
+	}
+
+	dirty := append([]byte(nil), good[4:]...)
+	dirty[0] = 0x01 // int8 -1 must be sign-extended with 0xff
+	if _, err := Decode(args, dirty); err == nil {
+		t.Fatalf("expected int8 range error")
+	}
+
+	boolArgs := []Argument{{Type: Type{Kind: KindBool}}}
+	if _, err := Decode(boolArgs, common.LeftPadBytes([]byte{2}, 32)); err == nil {
+		t.Fatalf("expected bool error")
+	}
+
+	// A hand-built slice of empty tuples must not divide by its zero size.
+	emptySlice := []Argument{{Type: Type{Kind: KindSlice, Elem: &Type{Kind: KindTuple}}}}
+	if _, err := Decode(emptySlice, append(common.LeftPadBytes([]byte{0x20}, 32), common.LeftPadBytes([]byte{5}, 32)...)); err == nil {
+		t.Fatalf("expected empty tuple error")
+	}
+}
+
+func TestDecodeRevert(t *testing.T) {
+	errData := append(crypto.Keccak256([]byte("Error(string)"))[:4], mustEncode(t, []Argument{{Type: Type{Kind: KindString}}}, "Insufficient balance")...)
+	r, err := DecodeRevert(errData)
+	if err != nil || r.Reason != "Insufficient balance" || r.PanicCode != nil {
+		t.Fatalf("Error(string) = %+v, %v", r, err)
+	}
+	if want, _ := abi.UnpackRevert(errData); r.Reason != want {
+		t.Fatalf("reason %q, geth says %q", r.Reason, want)
+	}
+
+	panicData := append(crypto.Keccak256([]byte("Panic(uint256)"))[:4], common.LeftPadBytes([]byte{0x11}, 32)...)
+	r, err = DecodeRevert(panicData)
+	if err != nil || r.PanicCode == nil || r.PanicCode.Int64() != 0x11 {
+		t.Fatalf("Panic(uint256) = %+v, %v", r, err)
+	}
+	if !strings.Contains(r.Error(), "overflow") {
+		t.Fatalf("panic message = %q", r.Error())
+	}
+
+	custom := crypto.Keccak256([]byte("Unauthorized()"))[:4]
+	if r, err := DecodeRevert(custom); err != nil || r.Reason != "" || r.PanicCode != nil || !bytes.Equal(r.Data, custom) {
+		t.Fatalf("custom error = %+v, %v", r, err)
+	}
+	if _, err := DecodeRevert(errData[:40]); err == nil {
+		t.Fatalf("expected malformed Error(string) error")
+	}
+}
+
+func mustEncode(t testing.TB, args []Argument, values ...interface{}) []byte {
+	t.Helper()
+	out, err := Encode(args, values...)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	return out
+}
+
+// fuzzTypes cover every kind, nested dynamic arrays and a tuple with a
+// dynamic member.
+var fuzzTypes = []string{
+	"uint8", "uint64", "uint24", "uint256", "int8", "int40", "int256",
+	"bool", "address", "bytes1", "bytes20", "bytes32", "bytes", "string",
+	"uint32[3]", "int16[]", "bytes[]", "string[2]", "address[][]", "bool[2][]",
+	"tuple", "tuple[]",
+}
+
+var fuzzComponents = []Argument{
+	{Name: "id", Type: Type{Kind: KindUint, Size: 96}},
+	{Name: "tags", Type: Type{Kind: KindSlice, Elem: &Type{Kind: KindString}}},
+	{Name: "owner", Type: Type{Kind: KindAddress}},
+}
+
+// byteSource hands out fuzz input, returning zeros once it runs dry.
+type byteSource struct{ data []byte }
+
+func (s *byteSource) next(n int) []byte {
+	out := make([]byte, n)
+	m := copy(out, s.data)
+	s.data = s.data[m:]
+	return out
+}
+
+func (s *byteSource) small(max int) int { return int(s.next(1)[0]) % (max + 1) }
+
+// randomValue builds a value of t.GoType() from the byte source.
+func randomValue(t Type, src *byteSource) reflect.Value {
+	gt := t.GoType()
+	switch t.Kind {
+	case KindUint, KindInt:
+		n := new(big.Int).SetBytes(src.next(t.Size / 8))
+		if t.Kind == KindInt && n.Bit(t.Size-1) == 1 {
+			n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(t.Size)))
+		}
+		if gt == bigT {
+			return reflect.ValueOf(n)
+		}
+		v := reflect.New(gt).Elem()
+		if t.Kind == KindUint {
+			v.SetUint(n.Uint64())
+		} else {
+			v.SetInt(n.Int64())
+		}
+		return v
+	case KindBool:
+		return reflect.ValueOf(src.next(1)[0]&1 == 1)
+	case KindAddress:
+		return reflect.ValueOf(common.BytesToAddress(src.next(20)))
+	case KindFixedBytes:
+		v := reflect.New(gt).Elem()
+		reflect.Copy(v, reflect.ValueOf(src.next(t.Size)))
+		return v
+	case KindBytes:
+		return reflect.ValueOf(src.next(src.small(40)))
+	case KindString:
+		return reflect.ValueOf(string(src.next(src.small(40))))
+	case KindArray, KindSlice:
+		n := t.Size
+		var v reflect.Value
+		if t.Kind == KindSlice {
+			n = src.small(3)
+			v = reflect.MakeSlice(gt, n, n)
+		} else {
+			v = reflect.New(gt).Elem()
+		}
+		for i := 0; i < n; i++ {
+			v.Index(i).Set(randomValue(*t.Elem, src))
+		}
+		return v
+	case KindTuple:
+		v := reflect.New(gt).Elem()
+		for i, c := range t.Components {
+			v.Field(i).Set(randomValue(c.Type, src))
+		}
+		return v
+	}
+	panic("unreachable")
+}
+
+// FuzzEncodeMatchesGeth encodes random values of random types with both
+// codecs and requires identical bytes, then decodes ours and re-encodes.
+func FuzzEncodeMatchesGeth(f *testing.F) {
+	f.Add([]byte{0})
+	f.Add([]byte{20, 3, 'a', 'b', 'c', 2, 'x', 'y', 0xff})
+	f.Add(bytes.Repeat([]byte{0xff}, 64))
+	f.Add([]byte{21, 2, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 1, 1, 'z'})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		src := &byteSource{data: data}
+		name := fuzzTypes[src.small(len(fuzzTypes)-1)]
+
+		ourType, err := ParseType(name, fuzzComponents)
+		if err != nil {
+			t.Fatalf("ParseType(%s): %v", name, err)
+		}
+		var gethComponents []abi.ArgumentMarshaling
+		for _, c := range fuzzComponents {
+			gethComponents = append(gethComponents, abi.ArgumentMarshaling{Name: c.Name, Type: c.Type.String()})
+		}
+		gethType, err := abi.NewType(name, "", gethComponents)
+		if err != nil {
+			t.Fatalf("abi.NewType(%s): %v", name, err)
+		}
+
+		v := randomValue(ourType, src).Interface()
+		ours, err := Encode([]Argument{{Name: "v", Type: ourType}}, v)
+		if err != nil {
+			t.Fatalf("%s: encode %#v: %v", name, v, err)
+		}
+		theirs, err := abi.Arguments{{Name: "v", Type: gethType}}.Pack(v)
+		if err != nil {
+			t.Fatalf("%s: geth pack %#v: %v", name, v, err)
+		}
+		if !bytes.Equal(ours, theirs) {
+			t.Fatalf("%s: encoding mismatch for %#v\n got %x\nwant %x", name, v, ours, theirs)
+		}
+
+		decoded, err := Decode([]Argument{{Name: "v", Type: ourType}}, ours)
+		if err != nil {
+			t.Fatalf("%s: decode: %v", name, err)
+		}
+		again, err := Encode([]Argument{{Name: "v", Type: ourType}}, decoded...)
+		if err != nil || !bytes.Equal(again, ours) {
+			t.Fatalf("%s: round trip mismatch (%v)", name, err)
+		}
+	})
+}
+
+// FuzzDecode feeds arbitrary bytes to the decoder. It must never panic, and
+// whatever it accepts must re-encode to a payload that decodes the same way.
+func FuzzDecode(f *testing.F) {
+	ours, _ := parseTestABIs(f)
+	args := ours.Methods["order"].Inputs
+	seed, _ := ours.Pack("order", []interface{}{
+		common.HexToAddress("0x1234"), []*big.Int{big.NewInt(9)}, 30,
+		[]interface{}{[]interface{}{common.Address{}, "a"}, []interface{}{common.Address{}, "b"}},
+	})
+	f.Add(seed[4:])
+	f.Add([]byte{})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		vals, err := Decode(args, data)
+		if err != nil {
+			return
+		}
+		enc, err := Encode(args, vals...)
+		if err != nil {
+			t.Fatalf("re-encode accepted data: %v", err)
+		}
+		again, err := Decode(args, enc)
+		if err != nil || !reflect.DeepEqual(again, vals) {
+			t.Fatalf("decode(encode(decode(x))) differs (%v)", err)
+		}
+	})
+}
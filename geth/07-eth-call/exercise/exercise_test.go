@@ -8,6 +8,7 @@ import (
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
 type mockCallClient struct {
@@ -36,10 +37,10 @@ func TestRunSuccess(t *testing.T) {
 	contract := common.HexToAddress("0x1111111111111111111111111111111111111111")
 	mock := &mockCallClient{
 		responses: map[string][]byte{
-			common.Bytes2Hex(selectorName):        encodeString("Dai Stablecoin"),
-			common.Bytes2Hex(selectorSymbol):      encodeString("DAI"),
-			common.Bytes2Hex(selectorDecimals):    encodeUint256(big.NewInt(18)),
-			common.Bytes2Hex(selectorTotalSupply): encodeUint256(big.NewInt(1000)),
+			selectorHex("name"):        encodeOutputs(t, "name", "Dai Stablecoin"),
+			selectorHex("symbol"):      encodeOutputs(t, "symbol", "DAI"),
+			selectorHex("decimals"):    encodeOutputs(t, "decimals", uint8(18)),
+			selectorHex("totalSupply"): encodeOutputs(t, "totalSupply", big.NewInt(1000)),
 		},
 	}
 
@@ -80,27 +81,40 @@ func TestRunErrors(t *testing.T) {
 	}
 }
 
-func encodeString(s string) []byte {
-	strBytes := []byte(s)
-	length := len(strBytes)
-	paddedLen := ((length + 31) / 32) * 32
-	out := make([]byte, 64+paddedLen)
-	putUint(out[0:32], big.NewInt(32))             // offset
-	putUint(out[32:64], big.NewInt(int64(length))) // length
-	copy(out[64:64+length], strBytes)
-	return out
+// dataError mimics the rpc.DataError go-ethereum returns for reverted calls.
+type dataError struct{ data string }
+
+func (e dataError) Error() string          { return "execution reverted" }
+func (e dataError) ErrorData() interface{} { return e.data }
+
+func TestRunRevert(t *testing.T) {
+	reason, err := Encode([]Argument{{Type: Type{Kind: KindString}}}, "not a token")
+	if err != nil {
+		t.Fatalf("encode reason: %v", err)
+	}
+	sel := errorMethod.Selector
+	mock := &mockCallClient{err: dataError{data: hexutil.Encode(append(sel[:], reason...))}}
+
+	_, err = Run(context.Background(), mock, Config{Contract: common.HexToAddress("0x1")})
+	var revert *Revert
+	if !errors.As(err, &revert) {
+		t.Fatalf("expected *Revert, got %v", err)
+	}
+	if revert.Reason != "not a token" {
+		t.Fatalf("reason = %q", revert.Reason)
+	}
 }
 
-func encodeUint256(v *big.Int) []byte {
-	out := make([]byte, 32)
-	putUint(out, v)
-	return out
+func selectorHex(method string) string {
+	sel := erc20ABI.Methods[method].Selector
+	return common.Bytes2Hex(sel[:])
 }
 
-func putUint(dst []byte, v *big.Int) {
-	for i := range dst {
-		dst[i] = 0
+func encodeOutputs(t *testing.T, method string, values ...interface{}) []byte {
+	t.Helper()
+	out, err := Encode(erc20ABI.Methods[method].Outputs, values...)
+	if err != nil {
+		t.Fatalf("encode %s outputs: %v", method, err)
 	}
-	b := v.Bytes()
-	copy(dst[32-len(b):], b)
+	return out
 }
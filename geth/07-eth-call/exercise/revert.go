@@ -0,0 +1,105 @@
+package exercise
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var (
+	errorMethod = NewMethod("Error", []Argument{{Name: "message", Type: Type{Kind: KindString}}}, nil)
+	panicMethod = NewMethod("Panic", []Argument{{Name: "code", Type: Type{Kind: KindUint, Size: 256}}}, nil)
+)
+
+// panicReasons are the Panic(uint256) codes the Solidity compiler emits.
+var panicReasons = map[uint64]string{
+	0x00: "generic compiler panic",
+	0x01: "assert(false)",
+	0x11: "arithmetic overflow or underflow",
+	0x12: "division or modulo by zero",
+	0x21: "invalid enum value",
+	0x22: "invalid storage byte array encoding",
+	0x31: "pop() on empty array",
+	0x32: "array index out of bounds",
+	0x41: "out of memory",
+	0x51: "call to uninitialized function pointer",
+}
+
+// Revert is decoded revert data. Exactly one of Reason or PanicCode is set
+// for standard payloads; for anything else both are empty and Data holds the
+// raw bytes (a custom error or a bare revert()).
+type Revert struct {
+	Reason    string   // Error(string) message
+	PanicCode *big.Int // Panic(uint256) code
+	Data      []byte
+}
+
+// Error implements error so a Revert can be returned directly.
+func (r *Revert) Error() string {
+	switch {
+	case r.PanicCode != nil:
+		if r.PanicCode.IsUint64() {
+			if desc, ok := panicReasons[r.PanicCode.Uint64()]; ok {
+				return fmt.Sprintf("execution reverted: panic 0x%x (%s)", r.PanicCode, desc)
+			}
+		}
+		return fmt.Sprintf("execution reverted: panic 0x%x", r.PanicCode)
+	case r.Reason != "":
+		return "execution reverted: " + r.Reason
+	case len(r.Data) > 0:
+		return "execution reverted: " + hexutil.Encode(r.Data)
+	}
+	return "execution reverted"
+}
+
+// DecodeRevert decodes the return data of a reverted call. An error is
+// returned only when the data claims to be Error(string) or Panic(uint256)
+// but is malformed.
+func DecodeRevert(data []byte) (*Revert, error) {
+	r := &Revert{Data: common.CopyBytes(data)}
+	if len(data) < 4 {
+		return r, nil
+	}
+	switch {
+	case bytes.Equal(data[:4], errorMethod.Selector[:]):
+		vals, err := Decode(errorMethod.Inputs, data[4:])
+		if err != nil {
+			return nil, fmt.Errorf("decode Error(string): %w", err)
+		}
+		r.Reason = vals[0].(string)
+	case bytes.Equal(data[:4], panicMethod.Selector[:]):
+		vals, err := Decode(panicMethod.Inputs, data[4:])
+		if err != nil {
+			return nil, fmt.Errorf("decode Panic(uint256): %w", err)
+		}
+		r.PanicCode = vals[0].(*big.Int)
+	}
+	return r, nil
+}
+
+// callError turns a failed eth_call into a *Revert when the node attached
+// revert data to the error (go-ethereum's rpc.DataError), so callers can use
+// errors.As to get the decoded reason. Other errors are returned unchanged.
+func callError(err error) error {
+	var de interface{ ErrorData() interface{} }
+	if !errors.As(err, &de) {
+		return err
+	}
+	s, ok := de.ErrorData().(string)
+	if !ok {
+		return err
+	}
+	data, decErr := hexutil.Decode(s)
+	if decErr != nil {
+		return err
+	}
+	r, decErr := DecodeRevert(data)
+	if decErr != nil {
+		return err
+	}
+	return r
+}
@@ -11,28 +11,21 @@ import (
 
 	ethereum "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
-)
-
-var (
-	selectorName        = selector("name()")
-	selectorSymbol      = selector("symbol()")
-	selectorDecimals    = selector("decimals()")
-	selectorTotalSupply = selector("totalSupply()")
 )
 
 /*
-Problem: Query ERC20 token metadata using manual ABI encoding/decoding.
+Problem: Query ERC20 token metadata using an ABI-driven encoder/decoder.
 
 This module teaches you how to interact with contracts without using typed bindings.
-You'll manually encode function selectors and decode return values, giving you a deep
-understanding of how contract calls work at the ABI level.
+The codec in abi.go and codec.go turns a JSON ABI plus Go values into call data and
+decodes return data back into typed Go values, giving you a deep understanding of
+how contract calls work at the ABI level.
 
 Computer science principles highlighted:
   - ABI encoding/decoding: Understanding how function calls are encoded as bytes
   - Function selectors: First 4 bytes of keccak256(functionSignature)
   - eth_call: Simulating contract execution without sending transactions
-  - Manual memory management: Decoding dynamic types (strings) from raw bytes
+  - Head/tail layout: Decoding dynamic types (strings, arrays) from offsets
 */
 func Run(ctx context.Context, client CallClient, cfg Config) (*Result, error) {
 	// ============================================================================
@@ -77,174 +70,110 @@ func Run(ctx context.Context, client CallClient, cfg Config) (*Result, error) {
 	// ============================================================================
 	// Why a helper function? We'll make 4 contract calls (name, symbol, decimals,
 	// totalSupply), and they all follow the same pattern:
-	//   1. Build CallMsg with contract address and function selector
+	//   1. Pack the call: selector + ABI-encoded arguments (erc20ABI.Pack)
 	//   2. Execute eth_call via client.CallContract
-	//   3. Return raw bytes or error
+	//   3. Unpack the return data into typed Go values (erc20ABI.Unpack)
 	//
-	// This is the DRY (Don't Repeat Yourself) principle. By extracting common
-	// logic into a helper, we reduce code duplication and make the code more
-	// maintainable. If the call logic changes, we only update one place.
+	// The codec in abi.go/codec.go is driven by the JSON ABI (ERC20ABI in
+	// types.go), so nothing here knows a selector or a byte offset. Calling a
+	// different function is just a different method name and argument list.
 	//
 	// Closure pattern: This helper function is a closure—it captures ctx, client,
 	// and cfg from the outer scope. This is a common Go pattern for reducing
 	// boilerplate while keeping code clean.
-	//
-	// Computer science concept: This demonstrates abstraction—hiding complex
-	// details (building CallMsg, handling RPC) behind a simple interface.
-	call := func(selector []byte) ([]byte, error) {
+	call := func(method string, args ...interface{}) ([]interface{}, error) {
+		// Pack: The first 4 bytes are keccak256("name()")[:4], computed once
+		// from the method's canonical signature when the ABI was parsed.
+		data, err := erc20ABI.Pack(method, args...)
+		if err != nil {
+			return nil, err
+		}
+
 		// Build CallMsg: This struct describes the contract call we want to make.
 		// - To: pointer to contract address (required for calls)
-		// - Data: function selector + encoded arguments (just selector for view functions)
+		// - Data: function selector + encoded arguments
 		//
 		// Why pointer for To? The CallMsg struct uses *common.Address to allow
 		// nil (for contract creation txs). For calls, we always need an address.
 		msg := ethereum.CallMsg{
 			To:   &cfg.Contract,
-			Data: selector,
+			Data: data,
 		}
 
-		// Execute eth_call: This sends the call to the RPC node, which executes
-		// the function locally (without creating a transaction) and returns the
-		// result. No gas is consumed, no state is changed.
+		// Execute eth_call: The node executes the function locally (without
+		// creating a transaction). cfg.BlockNumber selects historical state;
+		// nil means "latest".
 		//
-		// cfg.BlockNumber: Allows querying historical state. nil means "latest".
-		// This demonstrates separation of concerns—the caller controls which
-		// block to query, we just execute the call.
-		return client.CallContract(ctx, msg, cfg.BlockNumber)
+		// If the contract reverts, geth attaches the revert data to the error.
+		// callError (revert.go) decodes it into a *Revert carrying the
+		// Error(string) message or Panic(uint256) code.
+		out, err := client.CallContract(ctx, msg, cfg.BlockNumber)
+		if err != nil {
+			return nil, callError(err)
+		}
+
+		// Unpack: Decode walks the outputs in the ABI. Static types (uint8,
+		// uint256) sit in place as 32-byte words; dynamic types (string) are
+		// an offset to a length-prefixed tail.
+		return erc20ABI.Unpack(method, out)
 	}
 
 	// ============================================================================
-	// STEP 3: Call and Decode name() - Understanding Dynamic Types
+	// STEP 3: Call name() and symbol() - Dynamic Types
 	// ============================================================================
-	// name() is an ERC20 view function that returns a string.
-	//
-	// Function selector: selectorName is computed from "name()" signature:
-	//   1. Hash the signature: keccak256("name()")
-	//   2. Take first 4 bytes: 0x06fdde03
-	//
-	// Why 4 bytes? This is the ABI standard. 4 bytes gives us 4 billion possible
-	// selectors, which is enough to avoid collisions in practice.
-	//
-	// Pattern: call → check error → decode → check error. This two-phase approach
-	// (network operation + data processing) is common in distributed systems.
-	// We separate concerns: networking vs parsing.
-	nameBytes, err := call(selectorName)
-	if err != nil {
-		// Error wrapping: We add context ("call name()") to the error while
-		// preserving the original error with %w. This creates a traceable error
-		// chain for debugging.
-		//
-		// Building on previous modules: This error wrapping pattern repeats from
-		// module 01-stack. By module 07, you should recognize this as standard
-		// practice for all error returns.
-		return nil, fmt.Errorf("call name(): %w", err)
-	}
-
-	// Decode string: Strings are dynamic types in ABI encoding. The encoding is:
+	// Both return string, a dynamic type. The encoding is:
 	//   - Bytes 0-31: Offset to string data (usually 0x20 = 32)
 	//   - Bytes 32-63: Length of string in bytes
 	//   - Bytes 64+: UTF-8 string data (padded to 32-byte boundary)
 	//
-	// Why so complex? Dynamic types need this structure because the EVM uses
-	// 32-byte words for everything. Static types (like uint256) fit in one word,
-	// but strings can be any length, so they need offset + length + data.
+	// The codec returns typed values, so a type assertion is all that's left.
+	// The ABI guarantees output 0 is a string; a mismatch here would be a bug
+	// in the ABI, not in the contract's response.
 	//
-	// The decodeString helper (implemented below) handles this complexity for us.
-	name, err := decodeString(nameBytes)
+	// Pattern: call → check error → assert. Error wrapping adds which call
+	// failed while keeping the original error (%w) for errors.As/Is.
+	out, err := call("name")
 	if err != nil {
-		return nil, fmt.Errorf("decode name(): %w", err)
+		return nil, fmt.Errorf("call name(): %w", err)
 	}
+	name := out[0].(string)
 
-	// ============================================================================
-	// STEP 4: Call and Decode symbol() - Pattern Repetition
-	// ============================================================================
-	// symbol() is another string-returning view function.
-	//
-	// Notice the pattern: This is EXACTLY the same as name()—call, check error,
-	// decode, check error. The only differences are:
-	//   1. Different selector (selectorSymbol vs selectorName)
-	//   2. Different error messages for debugging
-	//
-	// Why repeat the pattern? Because it demonstrates consistency. Once you
-	// understand one contract call, you understand them all. This predictability
-	// is a key principle of good API design.
-	//
-	// Building on previous concepts: You've seen this "call RPC → validate →
-	// process" pattern in every module so far (chain ID, network ID, headers,
-	// transactions). It's the fundamental pattern of blockchain development.
-	symbolBytes, err := call(selectorSymbol)
+	out, err = call("symbol")
 	if err != nil {
 		return nil, fmt.Errorf("call symbol(): %w", err)
 	}
-	symbol, err := decodeString(symbolBytes)
-	if err != nil {
-		return nil, fmt.Errorf("decode symbol(): %w", err)
-	}
+	symbol := out[0].(string)
 
 	// ============================================================================
-	// STEP 5: Call and Decode decimals() - Understanding Static Types
+	// STEP 4: Call decimals() - Static Types
 	// ============================================================================
-	// decimals() returns uint8, which is a static type in ABI encoding.
-	//
-	// Static vs dynamic types:
-	//   - Static: Fixed size (uint8, uint256, address, bool, bytes32)
-	//   - Dynamic: Variable size (string, bytes, arrays, structs)
-	//
-	// Static types are simpler to decode: just 32 bytes, value is right-aligned.
-	// For uint8, only the last byte contains the value, the rest are zero padding.
-	//
-	// Why uint8 for decimals? Token decimals are typically 6-18 (6 for USDC,
-	// 18 for most tokens). uint8 supports 0-255, which is more than enough.
-	// Using uint8 instead of uint256 saves gas when emitting events or storing.
-	decBytes, err := call(selectorDecimals)
+	// decimals() returns uint8, a static type: one 32-byte word, right-aligned.
+	// The codec maps uint8/16/32/64 to the matching Go integer and rejects a
+	// word whose value doesn't fit (e.g. 256 claimed as a uint8).
+	out, err = call("decimals")
 	if err != nil {
 		return nil, fmt.Errorf("call decimals(): %w", err)
 	}
-
-	// Decode uint8: The decodeUint8 helper extracts the last byte from the
-	// 32-byte response. This is simpler than string decoding—no offsets or
-	// length fields to parse.
-	decimals, err := decodeUint8(decBytes)
-	if err != nil {
-		return nil, fmt.Errorf("decode decimals(): %w", err)
-	}
+	decimals := out[0].(uint8)
 
 	// ============================================================================
-	// STEP 6: Call and Decode totalSupply() - Understanding uint256
+	// STEP 5: Call totalSupply() - Understanding uint256
 	// ============================================================================
-	// totalSupply() returns uint256, the most common Solidity type.
-	//
-	// uint256 in Solidity = *big.Int in Go. Why?
-	//   - Solidity uint256: 0 to 2^256-1 (huge range)
-	//   - Go uint64: 0 to 2^64-1 (much smaller)
-	//   - Go *big.Int: arbitrary precision (can handle uint256)
-	//
-	// Real-world example: Total supply of tokens can be trillions or more.
-	// For instance, Shiba Inu token has a supply of 1 quadrillion (10^15).
-	// This far exceeds uint64's max (~10^19), so we need big.Int.
-	//
-	// Pattern repetition: Again, call → check error → decode → check error.
-	// By now, this should feel automatic. Every contract interaction follows
-	// this pattern, regardless of the specific function being called.
-	supplyBytes, err := call(selectorTotalSupply)
+	// uint256 in Solidity = *big.Int in Go. Go's native integers stop at 64
+	// bits, so every width the standard library can't hold (uint24, uint128,
+	// uint256, ...) decodes to *big.Int.
+	out, err = call("totalSupply")
 	if err != nil {
 		return nil, fmt.Errorf("call totalSupply(): %w", err)
 	}
-
-	// Decode uint256: The decodeUint256 helper reads the last 32 bytes and
-	// converts them to *big.Int. Unlike uint8, we use all 32 bytes because
-	// uint256 can use the full range.
-	totalSupply, err := decodeUint256(supplyBytes)
-	if err != nil {
-		return nil, fmt.Errorf("decode totalSupply(): %w", err)
-	}
+	totalSupply := out[0].(*big.Int)
 
 	// ============================================================================
-	// STEP 7: Construct and Return Result - No Defensive Copying Needed
+	// STEP 6: Construct and Return Result - No Defensive Copying Needed
 	// ============================================================================
 	// Why no defensive copying? In modules 01 and 06, we used defensive copying
 	// for big.Int values returned from the RPC client. Here, we don't need to
-	// because these values are freshly created by our decoding functions.
+	// because these values are freshly created by the decoder.
 	//
 	// Ownership model:
 	//   - RPC client returns: May share internal data → need defensive copy
@@ -268,48 +197,3 @@ func Run(ctx context.Context, client CallClient, cfg Config) (*Result, error) {
 		TotalSupply: totalSupply,
 	}, nil
 }
-
-func selector(sig string) []byte {
-	hash := crypto.Keccak256([]byte(sig))
-	return hash[:4]
-}
-
-func decodeString(data []byte) (string, error) {
-	if len(data) < 64 {
-		return "", errors.New("data too short for string")
-	}
-	offset := new(big.Int).SetBytes(data[:32]).Int64()
-	if offset < 0 || offset+32 > int64(len(data)) {
-		return "", errors.New("invalid offset")
-	}
-	lengthStart := int(offset)
-	lengthEnd := lengthStart + 32
-	if lengthEnd > len(data) {
-		return "", errors.New("invalid length data")
-	}
-	length := new(big.Int).SetBytes(data[lengthStart:lengthEnd]).Int64()
-	if length < 0 {
-		return "", errors.New("negative length")
-	}
-	dataStart := lengthEnd
-	dataEnd := dataStart + int(length)
-	if dataEnd > len(data) {
-		return "", errors.New("string exceeds data bounds")
-	}
-	return string(data[dataStart:dataEnd]), nil
-}
-
-func decodeUint8(data []byte) (uint8, error) {
-	if len(data) < 32 {
-		return 0, errors.New("data too short for uint8")
-	}
-	return uint8(data[len(data)-1]), nil
-}
-
-func decodeUint256(data []byte) (*big.Int, error) {
-	if len(data) < 32 {
-		return nil, errors.New("data too short for uint256")
-	}
-	out := new(big.Int).SetBytes(data[len(data)-32:])
-	return out, nil
-}
@@ -8,6 +8,26 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 )
 
+// ERC20ABI is the read-only subset of the ERC-20 interface that Run calls.
+const ERC20ABI = `[
+	{"type":"function","name":"name","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"string"}]},
+	{"type":"function","name":"symbol","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"string"}]},
+	{"type":"function","name":"decimals","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint8"}]},
+	{"type":"function","name":"totalSupply","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"balanceOf","stateMutability":"view","inputs":[{"name":"account","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"allowance","stateMutability":"view","inputs":[{"name":"owner","type":"address"},{"name":"spender","type":"address"}],"outputs":[{"name":"","type":"uint256"}]}
+]`
+
+var erc20ABI = mustParseABI(ERC20ABI)
+
+func mustParseABI(s string) *ABI {
+	a, err := ParseABI([]byte(s))
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
 // CallClient exposes the single method we need from ethclient.
 type CallClient interface {
 	CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
@@ -0,0 +1,254 @@
+// Package bindgen generates small typed Go bindings from a contract ABI.
+//
+// It is a teaching-sized abigen: one type per contract with typed view
+// methods, transactors taking *bind.TransactOpts, typed event structs, and
+// Filter/Watch/Parse methods per event. Everything is built on
+// bind.BoundContract, so the generated code shows exactly which calls the
+// string-keyed API needs.
+package bindgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// Config describes one binding to generate.
+type Config struct {
+	Package string // Go package of the generated file
+	Type    string // Go type name, e.g. "ERC20"
+	ABI     []byte // contract ABI JSON
+	Source  string // where the ABI came from, recorded in the header (optional)
+}
+
+// Generate returns the gofmt'ed source of the binding.
+func Generate(cfg Config) ([]byte, error) {
+	if !token.IsIdentifier(cfg.Package) {
+		return nil, fmt.Errorf("invalid package name %q", cfg.Package)
+	}
+	if !token.IsIdentifier(cfg.Type) || !token.IsExported(cfg.Type) {
+		return nil, fmt.Errorf("type name %q must be an exported identifier", cfg.Type)
+	}
+	parsed, err := abi.JSON(bytes.NewReader(cfg.ABI))
+	if err != nil {
+		return nil, fmt.Errorf("parse ABI: %w", err)
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, cfg.ABI); err != nil {
+		return nil, fmt.Errorf("compact ABI: %w", err)
+	}
+
+	data := contractData{
+		Package: cfg.Package,
+		Type:    cfg.Type,
+		Source:  cfg.Source,
+		ABI:     strconv.Quote(compact.String()),
+	}
+	for _, name := range sortedKeys(parsed.Methods) {
+		m, err := newMethod(cfg.Type, parsed.Methods[name])
+		if err != nil {
+			return nil, err
+		}
+		if m.Constant {
+			data.Calls = append(data.Calls, m)
+		} else {
+			data.Transacts = append(data.Transacts, m)
+		}
+	}
+	for _, name := range sortedKeys(parsed.Events) {
+		ev := parsed.Events[name]
+		if ev.Anonymous {
+			continue // no topic0 to filter or check against
+		}
+		e, err := newEvent(ev)
+		if err != nil {
+			return nil, err
+		}
+		data.Events = append(data.Events, e)
+	}
+
+	var buf bytes.Buffer
+	if err := bindingTemplate.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("execute template: %w", err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w\n%s", err, buf.Bytes())
+	}
+	return src, nil
+}
+
+type contractData struct {
+	Package, Type, Source, ABI string
+	Calls, Transacts           []methodData
+	Events                     []eventData
+}
+
+type param struct {
+	Name string // Go identifier
+	Type string // Go type
+}
+
+type methodData struct {
+	GoName    string // exported Go method name
+	Name      string // key in abi.Methods (overloads are suffixed by the abi package)
+	Signature string // human-readable Solidity signature
+	Constant  bool
+	Inputs    []param
+	Outputs   []param
+	Output    string // struct type for multiple outputs
+}
+
+type eventData struct {
+	GoName    string
+	Name      string
+	Signature string
+	Fields    []param // every argument, as UnpackLog fills them
+	Indexed   []param // indexed arguments, in order, as filter rules
+}
+
+func newMethod(typ string, m abi.Method) (methodData, error) {
+	md := methodData{
+		GoName:    abi.ToCamelCase(m.Name),
+		Name:      m.Name,
+		Signature: m.String(),
+		Constant:  m.IsConstant(),
+	}
+	var err error
+	if md.Inputs, err = params(m.Inputs, "arg"); err != nil {
+		return md, fmt.Errorf("method %s: %w", m.Name, err)
+	}
+	outs, err := params(m.Outputs, "Arg")
+	if err != nil {
+		return md, fmt.Errorf("method %s: %w", m.Name, err)
+	}
+	for i := range outs {
+		outs[i].Name = abi.ToCamelCase(outs[i].Name)
+	}
+	md.Outputs = outs
+	if len(outs) > 1 {
+		md.Output = typ + md.GoName + "Output"
+	}
+	return md, nil
+}
+
+func newEvent(ev abi.Event) (eventData, error) {
+	ed := eventData{GoName: abi.ToCamelCase(ev.Name), Name: ev.Name, Signature: ev.String()}
+	for i, arg := range ev.Inputs {
+		// The abi package names unnamed event arguments argN, and UnpackLog
+		// matches struct fields by that name.
+		typ, err := goType(arg.Type)
+		if err != nil {
+			return ed, fmt.Errorf("event %s: %w", ev.Name, err)
+		}
+		field := typ
+		if arg.Indexed && isDynamic(arg.Type) {
+			// Only the keccak256 of an indexed dynamic value is in the topic.
+			field = "common.Hash"
+		}
+		ed.Fields = append(ed.Fields, param{Name: abi.ToCamelCase(arg.Name), Type: field})
+		if arg.Indexed {
+			ed.Indexed = append(ed.Indexed, param{Name: goIdent(arg.Name, i, "arg"), Type: typ})
+		}
+	}
+	return ed, nil
+}
+
+func params(args abi.Arguments, prefix string) ([]param, error) {
+	out := make([]param, len(args))
+	for i, arg := range args {
+		typ, err := goType(arg.Type)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = param{Name: goIdent(arg.Name, i, prefix), Type: typ}
+	}
+	return out, nil
+}
+
+// reserved are identifiers the templates declare inside generated methods.
+var reserved = map[string]bool{
+	"c": true, "opts": true, "sink": true, "out": true, "ret": true, "err": true,
+	"logs": true, "sub": true, "ev": true, "log": true, "quit": true, "v": true,
+}
+
+// goIdent turns an ABI argument name into a Go identifier, inventing one for
+// unnamed arguments and dodging keywords and template locals.
+func goIdent(name string, i int, prefix string) string {
+	if name == "" {
+		return prefix + strconv.Itoa(i)
+	}
+	name = strings.TrimLeft(name, "_")
+	if name == "" {
+		return prefix + strconv.Itoa(i)
+	}
+	if prefix == "arg" {
+		name = strings.ToLower(name[:1]) + name[1:]
+	}
+	if token.IsKeyword(name) || reserved[name] {
+		name += "_"
+	}
+	return name
+}
+
+// goType maps an ABI type to the Go type the abi package decodes it into.
+func goType(t abi.Type) (string, error) {
+	switch t.T {
+	case abi.IntTy, abi.UintTy:
+		prefix := "int"
+		if t.T == abi.UintTy {
+			prefix = "uint"
+		}
+		switch t.Size {
+		case 8, 16, 32, 64:
+			return prefix + strconv.Itoa(t.Size), nil
+		}
+		return "*big.Int", nil
+	case abi.BoolTy:
+		return "bool", nil
+	case abi.StringTy:
+		return "string", nil
+	case abi.AddressTy:
+		return "common.Address", nil
+	case abi.HashTy:
+		return "common.Hash", nil
+	case abi.BytesTy:
+		return "[]byte", nil
+	case abi.FixedBytesTy:
+		return fmt.Sprintf("[%d]byte", t.Size), nil
+	case abi.SliceTy:
+		elem, err := goType(*t.Elem)
+		return "[]" + elem, err
+	case abi.ArrayTy:
+		elem, err := goType(*t.Elem)
+		return fmt.Sprintf("[%d]%s", t.Size, elem), err
+	}
+	return "", fmt.Errorf("unsupported ABI type %s", t.String())
+}
+
+func isDynamic(t abi.Type) bool {
+	switch t.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		return true
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var bindingTemplate = template.Must(template.New("binding").Parse(bindingSource))
@@ -0,0 +1,76 @@
+package bindgen
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+// TestGolden pins the generator's output for an ABI that exercises multiple
+// return values, overloads, arrays, keywords, payable methods and indexed
+// dynamic event arguments. Run with -update after an intended change.
+func TestGolden(t *testing.T) {
+	abiJSON, err := os.ReadFile(filepath.Join("testdata", "vault.abi.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Generate(Config{Package: "vault", Type: "Vault", ABI: abiJSON, Source: "vault.abi.json"})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	golden := filepath.Join("testdata", "vault.go.golden")
+	if *update {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("generated code differs from %s; rerun with -update and review the diff\n%s", golden, got)
+	}
+}
+
+// TestExerciseBindingUpToDate fails when erc20_binding.go was not regenerated
+// after a change to erc20.abi.json or to the generator.
+func TestExerciseBindingUpToDate(t *testing.T) {
+	abiJSON, err := os.ReadFile(filepath.Join("..", "exercise", "erc20.abi.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Generate(Config{Package: "exercise", Type: "ERC20", ABI: abiJSON, Source: "erc20.abi.json"})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	want, err := os.ReadFile(filepath.Join("..", "exercise", "erc20_binding.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("exercise/erc20_binding.go is stale; run go generate ./08-abigen/exercise")
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		cfg  Config
+		want string
+	}{
+		"bad package": {Config{Package: "two words", Type: "T", ABI: []byte(`[]`)}, "package"},
+		"unexported":  {Config{Package: "p", Type: "token", ABI: []byte(`[]`)}, "exported"},
+		"bad json":    {Config{Package: "p", Type: "T", ABI: []byte(`{`)}, "parse ABI"},
+		"tuple": {Config{Package: "p", Type: "T", ABI: []byte(`[{"type":"function","name":"f","inputs":[],
+			"outputs":[{"name":"","type":"tuple","components":[{"name":"a","type":"uint256"}]}]}]`)}, "unsupported"},
+	} {
+		if _, err := Generate(tc.cfg); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: got %v, want error containing %q", name, err, tc.want)
+		}
+	}
+}
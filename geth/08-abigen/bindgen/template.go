@@ -0,0 +1,211 @@
+package bindgen
+
+// bindingSource is the text/template for one binding. The output is run
+// through gofmt, so the template only has to be syntactically right.
+const bindingSource = `// Code generated by bindgen{{if .Source}} from {{.Source}}{{end}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = context.Background
+	_ = errors.New
+	_ = big.NewInt
+	_ = ethereum.NotFound
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// {{.Type}}ABI is the ABI the {{.Type}} binding was generated from.
+const {{.Type}}ABI = {{.ABI}}
+
+// {{.Type}} is a typed binding to a deployed contract.
+type {{.Type}} struct {
+	address  common.Address
+	abi      abi.ABI
+	contract *bind.BoundContract
+	filterer bind.ContractFilterer
+}
+
+// New{{.Type}} binds the contract at address. transactor and filterer may be
+// nil when only view methods are used.
+func New{{.Type}}(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*{{.Type}}, error) {
+	parsed, err := abi.JSON(strings.NewReader({{.Type}}ABI))
+	if err != nil {
+		return nil, err
+	}
+	return &{{.Type}}{
+		address:  address,
+		abi:      parsed,
+		contract: bind.NewBoundContract(address, parsed, caller, transactor, filterer),
+		filterer: filterer,
+	}, nil
+}
+
+// Address returns the address of the bound contract.
+func (c *{{.Type}}) Address() common.Address {
+	return c.address
+}
+{{range .Calls}}{{if .Output}}
+// {{.Output}} holds the return values of {{.GoName}}.
+type {{.Output}} struct {
+{{range .Outputs}}	{{.Name}} {{.Type}}
+{{end}}}
+{{end}}
+// {{.GoName}} calls the view method {{.Name}}.
+//
+// Solidity: {{.Signature}}
+func (c *{{$.Type}}) {{.GoName}}(opts *bind.CallOpts{{range .Inputs}}, {{.Name}} {{.Type}}{{end}}) ({{if .Output}}{{.Output}}, {{else}}{{range .Outputs}}{{.Type}}, {{end}}{{end}}error) {
+	var out []interface{}
+	err := c.contract.Call(opts, &out, "{{.Name}}"{{range .Inputs}}, {{.Name}}{{end}})
+{{- if .Output}}
+	var ret {{.Output}}
+	if err != nil {
+		return ret, err
+	}
+{{- range $i, $o := .Outputs}}
+	ret.{{$o.Name}} = *abi.ConvertType(out[{{$i}}], new({{$o.Type}})).(*{{$o.Type}})
+{{- end}}
+	return ret, nil
+{{- else if .Outputs}}
+	if err != nil {
+		return *new({{(index .Outputs 0).Type}}), err
+	}
+	return *abi.ConvertType(out[0], new({{(index .Outputs 0).Type}})).(*{{(index .Outputs 0).Type}}), nil
+{{- else}}
+	return err
+{{- end}}
+}
+{{end}}{{range .Transacts}}
+// {{.GoName}} sends a transaction calling {{.Name}}.
+//
+// Solidity: {{.Signature}}
+func (c *{{$.Type}}) {{.GoName}}(opts *bind.TransactOpts{{range .Inputs}}, {{.Name}} {{.Type}}{{end}}) (*types.Transaction, error) {
+	return c.contract.Transact(opts, "{{.Name}}"{{range .Inputs}}, {{.Name}}{{end}})
+}
+{{end}}{{if .Events}}
+// filterLogs runs eth_getLogs for one event of this contract.
+func (c *{{.Type}}) filterLogs(opts *bind.FilterOpts, name string, query ...[]interface{}) ([]types.Log, error) {
+	if c.filterer == nil {
+		return nil, errors.New("{{.Type}}: no filterer configured")
+	}
+	if opts == nil {
+		opts = new(bind.FilterOpts)
+	}
+	topics, err := abi.MakeTopics(append([][]interface{}{{"{{"}}c.abi.Events[name].ID{{"}}"}}, query...)...)
+	if err != nil {
+		return nil, err
+	}
+	q := ethereum.FilterQuery{
+		Addresses: []common.Address{c.address},
+		Topics:    topics,
+		FromBlock: new(big.Int).SetUint64(opts.Start),
+	}
+	if opts.End != nil {
+		q.ToBlock = new(big.Int).SetUint64(*opts.End)
+	}
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return c.filterer.FilterLogs(ctx, q)
+}
+{{end}}{{range .Events}}
+// {{$.Type}}{{.GoName}} is the decoded {{.Name}} event.
+//
+// Solidity: {{.Signature}}
+type {{$.Type}}{{.GoName}} struct {
+{{range .Fields}}	{{.Name}} {{.Type}}
+{{end}}	Raw types.Log // the log the event was decoded from
+}
+
+// Filter{{.GoName}} returns the {{.Name}} events in the block range of opts.
+// Each rule narrows an indexed argument; nil matches anything.
+func (c *{{$.Type}}) Filter{{.GoName}}(opts *bind.FilterOpts{{range .Indexed}}, {{.Name}} []{{.Type}}{{end}}) ([]*{{$.Type}}{{.GoName}}, error) {
+{{- range .Indexed}}
+	var {{.Name}}Rule []interface{}
+	for _, v := range {{.Name}} {
+		{{.Name}}Rule = append({{.Name}}Rule, v)
+	}
+{{- end}}
+	logs, err := c.filterLogs(opts, "{{.Name}}"{{range .Indexed}}, {{.Name}}Rule{{end}})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*{{$.Type}}{{.GoName}}, 0, len(logs))
+	for _, log := range logs {
+		ev, err := c.Parse{{.GoName}}(log)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, ev)
+	}
+	return out, nil
+}
+
+// Watch{{.GoName}} delivers new {{.Name}} events to sink until the returned
+// subscription is cancelled or fails.
+func (c *{{$.Type}}) Watch{{.GoName}}(opts *bind.WatchOpts, sink chan<- *{{$.Type}}{{.GoName}}{{range .Indexed}}, {{.Name}} []{{.Type}}{{end}}) (event.Subscription, error) {
+	if c.filterer == nil {
+		return nil, errors.New("{{$.Type}}: no filterer configured")
+	}
+{{- range .Indexed}}
+	var {{.Name}}Rule []interface{}
+	for _, v := range {{.Name}} {
+		{{.Name}}Rule = append({{.Name}}Rule, v)
+	}
+{{- end}}
+	logs, sub, err := c.contract.WatchLogs(opts, "{{.Name}}"{{range .Indexed}}, {{.Name}}Rule{{end}})
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				ev, err := c.Parse{{.GoName}}(log)
+				if err != nil {
+					return err
+				}
+				select {
+				case sink <- ev:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// Parse{{.GoName}} decodes a log into {{$.Type}}{{.GoName}}.
+func (c *{{$.Type}}) Parse{{.GoName}}(log types.Log) (*{{$.Type}}{{.GoName}}, error) {
+	ev := new({{$.Type}}{{.GoName}})
+	if err := c.contract.UnpackLog(ev, "{{.Name}}", log); err != nil {
+		return nil, err
+	}
+	ev.Raw = log
+	return ev, nil
+}
+{{end}}`
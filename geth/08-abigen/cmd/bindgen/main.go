@@ -0,0 +1,55 @@
+// Command bindgen writes a typed Go binding for a contract ABI.
+//
+// Usage (typically from a go:generate directive):
+//
+//	go run ../cmd/bindgen -abi erc20.abi.json -pkg exercise -type ERC20 -out erc20_binding.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"geth-edu/08-abigen/bindgen"
+)
+
+func main() {
+	abiPath := flag.String("abi", "", "path to the contract ABI JSON (required)")
+	pkg := flag.String("pkg", "", "Go package of the generated file (default $GOPACKAGE)")
+	typ := flag.String("type", "", "Go type name of the binding (required)")
+	out := flag.String("out", "", "output file (default stdout)")
+	flag.Parse()
+
+	if err := run(*abiPath, *pkg, *typ, *out); err != nil {
+		fmt.Fprintln(os.Stderr, "bindgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(abiPath, pkg, typ, out string) error {
+	if abiPath == "" || typ == "" {
+		return fmt.Errorf("-abi and -type are required")
+	}
+	if pkg == "" {
+		pkg = os.Getenv("GOPACKAGE") // set by go generate
+	}
+	abiJSON, err := os.ReadFile(abiPath)
+	if err != nil {
+		return err
+	}
+	src, err := bindgen.Generate(bindgen.Config{
+		Package: pkg,
+		Type:    typ,
+		ABI:     abiJSON,
+		Source:  filepath.Base(abiPath),
+	})
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(out, src, 0o644)
+}
@@ -0,0 +1,357 @@
+// Code generated by bindgen from erc20.abi.json. DO NOT EDIT.
+
+package exercise
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = context.Background
+	_ = errors.New
+	_ = big.NewInt
+	_ = ethereum.NotFound
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// ERC20ABI is the ABI the ERC20 binding was generated from.
+const ERC20ABI = "[{\"type\":\"function\",\"name\":\"name\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"string\"}]},{\"type\":\"function\",\"name\":\"symbol\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"string\"}]},{\"type\":\"function\",\"name\":\"decimals\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint8\"}]},{\"type\":\"function\",\"name\":\"totalSupply\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"balanceOf\",\"stateMutability\":\"view\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"allowance\",\"stateMutability\":\"view\",\"inputs\":[{\"name\":\"owner\",\"type\":\"address\"},{\"name\":\"spender\",\"type\":\"address\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"transfer\",\"stateMutability\":\"nonpayable\",\"inputs\":[{\"name\":\"to\",\"type\":\"address\"},{\"name\":\"value\",\"type\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bool\"}]},{\"type\":\"function\",\"name\":\"approve\",\"stateMutability\":\"nonpayable\",\"inputs\":[{\"name\":\"spender\",\"type\":\"address\"},{\"name\":\"value\",\"type\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bool\"}]},{\"type\":\"function\",\"name\":\"transferFrom\",\"stateMutability\":\"nonpayable\",\"inputs\":[{\"name\":\"from\",\"type\":\"address\"},{\"name\":\"to\",\"type\":\"address\"},{\"name\":\"value\",\"type\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bool\"}]},{\"type\":\"event\",\"name\":\"Transfer\",\"anonymous\":false,\"inputs\":[{\"name\":\"from\",\"type\":\"address\",\"indexed\":true},{\"name\":\"to\",\"type\":\"address\",\"indexed\":true},{\"name\":\"value\",\"type\":\"uint256\",\"indexed\":false}]},{\"type\":\"event\",\"name\":\"Approval\",\"anonymous\":false,\"inputs\":[{\"name\":\"owner\",\"type\":\"address\",\"indexed\":true},{\"name\":\"spender\",\"type\":\"address\",\"indexed\":true},{\"name\":\"value\",\"type\":\"uint256\",\"indexed\":false}]}]"
+
+// ERC20 is a typed binding to a deployed contract.
+type ERC20 struct {
+	address  common.Address
+	abi      abi.ABI
+	contract *bind.BoundContract
+	filterer bind.ContractFilterer
+}
+
+// NewERC20 binds the contract at address. transactor and filterer may be
+// nil when only view methods are used.
+func NewERC20(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*ERC20, error) {
+	parsed, err := abi.JSON(strings.NewReader(ERC20ABI))
+	if err != nil {
+		return nil, err
+	}
+	return &ERC20{
+		address:  address,
+		abi:      parsed,
+		contract: bind.NewBoundContract(address, parsed, caller, transactor, filterer),
+		filterer: filterer,
+	}, nil
+}
+
+// Address returns the address of the bound contract.
+func (c *ERC20) Address() common.Address {
+	return c.address
+}
+
+// Allowance calls the view method allowance.
+//
+// Solidity: function allowance(address owner, address spender) view returns(uint256)
+func (c *ERC20) Allowance(opts *bind.CallOpts, owner common.Address, spender common.Address) (*big.Int, error) {
+	var out []interface{}
+	err := c.contract.Call(opts, &out, "allowance", owner, spender)
+	if err != nil {
+		return *new(*big.Int), err
+	}
+	return *abi.ConvertType(out[0], new(*big.Int)).(**big.Int), nil
+}
+
+// BalanceOf calls the view method balanceOf.
+//
+// Solidity: function balanceOf(address account) view returns(uint256)
+func (c *ERC20) BalanceOf(opts *bind.CallOpts, account common.Address) (*big.Int, error) {
+	var out []interface{}
+	err := c.contract.Call(opts, &out, "balanceOf", account)
+	if err != nil {
+		return *new(*big.Int), err
+	}
+	return *abi.ConvertType(out[0], new(*big.Int)).(**big.Int), nil
+}
+
+// Decimals calls the view method decimals.
+//
+// Solidity: function decimals() view returns(uint8)
+func (c *ERC20) Decimals(opts *bind.CallOpts) (uint8, error) {
+	var out []interface{}
+	err := c.contract.Call(opts, &out, "decimals")
+	if err != nil {
+		return *new(uint8), err
+	}
+	return *abi.ConvertType(out[0], new(uint8)).(*uint8), nil
+}
+
+// Name calls the view method name.
+//
+// Solidity: function name() view returns(string)
+func (c *ERC20) Name(opts *bind.CallOpts) (string, error) {
+	var out []interface{}
+	err := c.contract.Call(opts, &out, "name")
+	if err != nil {
+		return *new(string), err
+	}
+	return *abi.ConvertType(out[0], new(string)).(*string), nil
+}
+
+// Symbol calls the view method symbol.
+//
+// Solidity: function symbol() view returns(string)
+func (c *ERC20) Symbol(opts *bind.CallOpts) (string, error) {
+	var out []interface{}
+	err := c.contract.Call(opts, &out, "symbol")
+	if err != nil {
+		return *new(string), err
+	}
+	return *abi.ConvertType(out[0], new(string)).(*string), nil
+}
+
+// TotalSupply calls the view method totalSupply.
+//
+// Solidity: function totalSupply() view returns(uint256)
+func (c *ERC20) TotalSupply(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := c.contract.Call(opts, &out, "totalSupply")
+	if err != nil {
+		return *new(*big.Int), err
+	}
+	return *abi.ConvertType(out[0], new(*big.Int)).(**big.Int), nil
+}
+
+// Approve sends a transaction calling approve.
+//
+// Solidity: function approve(address spender, uint256 value) returns(bool)
+func (c *ERC20) Approve(opts *bind.TransactOpts, spender common.Address, value *big.Int) (*types.Transaction, error) {
+	return c.contract.Transact(opts, "approve", spender, value)
+}
+
+// Transfer sends a transaction calling transfer.
+//
+// Solidity: function transfer(address to, uint256 value) returns(bool)
+func (c *ERC20) Transfer(opts *bind.TransactOpts, to common.Address, value *big.Int) (*types.Transaction, error) {
+	return c.contract.Transact(opts, "transfer", to, value)
+}
+
+// TransferFrom sends a transaction calling transferFrom.
+//
+// Solidity: function transferFrom(address from, address to, uint256 value) returns(bool)
+func (c *ERC20) TransferFrom(opts *bind.TransactOpts, from common.Address, to common.Address, value *big.Int) (*types.Transaction, error) {
+	return c.contract.Transact(opts, "transferFrom", from, to, value)
+}
+
+// filterLogs runs eth_getLogs for one event of this contract.
+func (c *ERC20) filterLogs(opts *bind.FilterOpts, name string, query ...[]interface{}) ([]types.Log, error) {
+	if c.filterer == nil {
+		return nil, errors.New("ERC20: no filterer configured")
+	}
+	if opts == nil {
+		opts = new(bind.FilterOpts)
+	}
+	topics, err := abi.MakeTopics(append([][]interface{}{{c.abi.Events[name].ID}}, query...)...)
+	if err != nil {
+		return nil, err
+	}
+	q := ethereum.FilterQuery{
+		Addresses: []common.Address{c.address},
+		Topics:    topics,
+		FromBlock: new(big.Int).SetUint64(opts.Start),
+	}
+	if opts.End != nil {
+		q.ToBlock = new(big.Int).SetUint64(*opts.End)
+	}
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return c.filterer.FilterLogs(ctx, q)
+}
+
+// ERC20Approval is the decoded Approval event.
+//
+// Solidity: event Approval(address indexed owner, address indexed spender, uint256 value)
+type ERC20Approval struct {
+	Owner   common.Address
+	Spender common.Address
+	Value   *big.Int
+	Raw     types.Log // the log the event was decoded from
+}
+
+// FilterApproval returns the Approval events in the block range of opts.
+// Each rule narrows an indexed argument; nil matches anything.
+func (c *ERC20) FilterApproval(opts *bind.FilterOpts, owner []common.Address, spender []common.Address) ([]*ERC20Approval, error) {
+	var ownerRule []interface{}
+	for _, v := range owner {
+		ownerRule = append(ownerRule, v)
+	}
+	var spenderRule []interface{}
+	for _, v := range spender {
+		spenderRule = append(spenderRule, v)
+	}
+	logs, err := c.filterLogs(opts, "Approval", ownerRule, spenderRule)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*ERC20Approval, 0, len(logs))
+	for _, log := range logs {
+		ev, err := c.ParseApproval(log)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, ev)
+	}
+	return out, nil
+}
+
+// WatchApproval delivers new Approval events to sink until the returned
+// subscription is cancelled or fails.
+func (c *ERC20) WatchApproval(opts *bind.WatchOpts, sink chan<- *ERC20Approval, owner []common.Address, spender []common.Address) (event.Subscription, error) {
+	if c.filterer == nil {
+		return nil, errors.New("ERC20: no filterer configured")
+	}
+	var ownerRule []interface{}
+	for _, v := range owner {
+		ownerRule = append(ownerRule, v)
+	}
+	var spenderRule []interface{}
+	for _, v := range spender {
+		spenderRule = append(spenderRule, v)
+	}
+	logs, sub, err := c.contract.WatchLogs(opts, "Approval", ownerRule, spenderRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				ev, err := c.ParseApproval(log)
+				if err != nil {
+					return err
+				}
+				select {
+				case sink <- ev:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseApproval decodes a log into ERC20Approval.
+func (c *ERC20) ParseApproval(log types.Log) (*ERC20Approval, error) {
+	ev := new(ERC20Approval)
+	if err := c.contract.UnpackLog(ev, "Approval", log); err != nil {
+		return nil, err
+	}
+	ev.Raw = log
+	return ev, nil
+}
+
+// ERC20Transfer is the decoded Transfer event.
+//
+// Solidity: event Transfer(address indexed from, address indexed to, uint256 value)
+type ERC20Transfer struct {
+	From  common.Address
+	To    common.Address
+	Value *big.Int
+	Raw   types.Log // the log the event was decoded from
+}
+
+// FilterTransfer returns the Transfer events in the block range of opts.
+// Each rule narrows an indexed argument; nil matches anything.
+func (c *ERC20) FilterTransfer(opts *bind.FilterOpts, from []common.Address, to []common.Address) ([]*ERC20Transfer, error) {
+	var fromRule []interface{}
+	for _, v := range from {
+		fromRule = append(fromRule, v)
+	}
+	var toRule []interface{}
+	for _, v := range to {
+		toRule = append(toRule, v)
+	}
+	logs, err := c.filterLogs(opts, "Transfer", fromRule, toRule)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*ERC20Transfer, 0, len(logs))
+	for _, log := range logs {
+		ev, err := c.ParseTransfer(log)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, ev)
+	}
+	return out, nil
+}
+
+// WatchTransfer delivers new Transfer events to sink until the returned
+// subscription is cancelled or fails.
+func (c *ERC20) WatchTransfer(opts *bind.WatchOpts, sink chan<- *ERC20Transfer, from []common.Address, to []common.Address) (event.Subscription, error) {
+	if c.filterer == nil {
+		return nil, errors.New("ERC20: no filterer configured")
+	}
+	var fromRule []interface{}
+	for _, v := range from {
+		fromRule = append(fromRule, v)
+	}
+	var toRule []interface{}
+	for _, v := range to {
+		toRule = append(toRule, v)
+	}
+	logs, sub, err := c.contract.WatchLogs(opts, "Transfer", fromRule, toRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				ev, err := c.ParseTransfer(log)
+				if err != nil {
+					return err
+				}
+				select {
+				case sink <- ev:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseTransfer decodes a log into ERC20Transfer.
+func (c *ERC20) ParseTransfer(log types.Log) (*ERC20Transfer, error) {
+	ev := new(ERC20Transfer)
+	if err := c.contract.UnpackLog(ev, "Transfer", log); err != nil {
+		return nil, err
+	}
+	ev.Raw = log
+	return ev, nil
+}
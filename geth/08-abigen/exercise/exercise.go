@@ -6,28 +6,20 @@ package exercise
 import (
 	"context"
 	"errors"
-	"fmt"
-	"math/big"
-	"strings"
-
-	"github.com/ethereum/go-ethereum/accounts/abi"
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
-	"github.com/ethereum/go-ethereum/common"
 )
 
-const erc20ABI = `[{"constant":true,"inputs":[],"name":"name","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"},{"constant":true,"inputs":[],"name":"symbol","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"},{"constant":true,"inputs":[],"name":"decimals","outputs":[{"name":"","type":"uint8"}],"stateMutability":"view","type":"function"},{"constant":true,"inputs":[],"name":"totalSupply","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"constant":true,"inputs":[{"name":"account","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`
-
 /*
-Problem: Use BoundContract for type-safe contract calls with automatic ABI encoding/decoding.
+Problem: Use a generated typed binding for type-safe contract calls with automatic ABI encoding/decoding.
 
 This module teaches you how to use go-ethereum's BoundContract pattern for cleaner,
 safer contract interactions. Instead of manually encoding/decoding like module 07,
-you'll use the abi package to handle encoding/decoding automatically.
+you'll call the ERC20 binding that cmd/bindgen generates from erc20.abi.json; it
+wraps BoundContract and the abi package in typed Go methods.
 
 Computer science principles highlighted:
   - Adapter pattern: BoundContract wraps low-level RPC with high-level interface
   - Type safety: ABI definitions provide compile-time checks
-  - Code reuse: Helper functions eliminate boilerplate
+  - Code generation: the typed wrapper is written once from the ABI, not by hand
   - Separation of concerns: ABI encoding is separate from business logic
 */
 func Run(ctx context.Context, backend ContractCaller, cfg Config) (*Result, error) {
@@ -37,24 +29,22 @@ func Run(ctx context.Context, backend ContractCaller, cfg Config) (*Result, erro
 	// - Check if cfg.Contract is the zero address and return an error
 	// - Backend is the ContractCaller interface (RPC client)
 
-	// TODO: Parse ABI JSON string
-	// - Use cfg.ABI if provided, otherwise use erc20ABI constant (defined at top)
-	// - Trim whitespace from ABI string before checking if empty
-	// - Parse ABI using abi.JSON(strings.NewReader(abiJSON))
-	// - Handle parsing errors (invalid JSON, malformed ABI)
-	// - Why parse ABI? We need the function signatures and return types for encoding/decoding
-	// - ABI is like an interface definition: it tells us what functions exist and their types
+	// TODO: Check an optional ABI override
+	// - If strings.TrimSpace(cfg.ABI) is not empty, parse it with abi.JSON
+	// - The generated binding's ABI (ERC20ABI) is fixed, so the override can
+	//   only be checked: name, symbol, decimals, totalSupply and balanceOf must
+	//   exist with the same selectors (Method.ID) and output types
+	// - Return an error for malformed JSON or any mismatch
 
-	// TODO: Create BoundContract
-	// - Use bind.NewBoundContract(address, parsedABI, backend, nil, nil)
+	// TODO: Bind the contract with the generated binding
+	// - Use NewERC20(cfg.Contract, backend, nil, nil) from erc20_binding.go
 	// - Parameters explained:
 	//   * address: Contract address to call
-	//   * parsedABI: Parsed ABI object (from previous step)
 	//   * backend: RPC client for read operations (our ContractCaller)
 	//   * transactor: RPC client for write operations (nil - we're only reading)
 	//   * filterer: RPC client for event filtering (nil - not needed for this module)
-	// - BoundContract is the adapter pattern: it wraps low-level RPC with high-level interface
-	// - This is more convenient than manual ABI encoding (module 07)
+	// - The binding is generated by `go generate` (see types.go) and wraps
+	//   bind.BoundContract: every ABI method becomes a typed Go method
 
 	// TODO: Create CallOpts for contract calls
 	// - Build bind.CallOpts struct with:
@@ -65,43 +55,31 @@ func Run(ctx context.Context, backend ContractCaller, cfg Config) (*Result, erro
 	// - CallOpts is like a request context: it configures how the call should execute
 
 	// TODO: Call and decode name() function
-	// - Use callString helper (already implemented below) with:
-	//   * contract (BoundContract from above)
-	//   * callOpts (CallOpts from above)
-	//   * method name: "name"
+	// - Use token.Name(callOpts)
 	// - Handle errors (network failures, contract reverts, decoding errors)
-	// - callString helper wraps contract.Call and does type conversion
+	//   and wrap them with the method name
+	// - The generated method wraps contract.Call and does the type conversion
 	// - Compare to module 07: No manual selector computation or decoding!
 
 	// TODO: Call and decode symbol() function
 	// - Follow same pattern as name()
-	// - Use callString helper with method name "symbol"
+	// - Use token.Symbol(callOpts)
 	// - Handle errors
-	// - Notice the pattern: same helper, just different method name
-	// - This demonstrates code reuse: one helper for all string-returning functions
 
 	// TODO: Call and decode decimals() function
-	// - Follow same pattern but use callUint8 helper
-	// - Method name: "decimals"
+	// - Use token.Decimals(callOpts), which returns uint8
 	// - Handle errors
-	// - callUint8 is like callString but for uint8 return type
-	// - Pattern repeats: helper function → method name → error handling
 
 	// TODO: Call and decode totalSupply() function
-	// - Follow same pattern but use callUint256 helper
-	// - Method name: "totalSupply"
+	// - Use token.TotalSupply(callOpts), which returns *big.Int (for large numbers)
 	// - Handle errors
-	// - callUint256 returns *big.Int (for large numbers)
-	// - Again, same pattern with different helper and method name
 
 	// TODO: Optionally call balanceOf(address) if holder provided
 	// - Check if cfg.Holder is not nil
-	// - If provided, call callUint256 with:
-	//   * method name: "balanceOf"
-	//   * parameter: *cfg.Holder (dereference pointer)
+	// - If provided, call token.BalanceOf(callOpts, *cfg.Holder)
 	// - Handle errors
-	// - balanceOf takes a parameter (address), unlike the other functions
-	// - The helper supports variadic parameters for this: callUint256(contract, opts, "balanceOf", address)
+	// - balanceOf takes a parameter (address), unlike the other functions; in
+	//   the binding it is a typed common.Address argument
 
 	// TODO: Construct and return Result
 	// - Create Result struct with:
@@ -115,36 +93,3 @@ func Run(ctx context.Context, backend ContractCaller, cfg Config) (*Result, erro
 
 	return nil, errors.New("not implemented")
 }
-
-func callString(contract *bind.BoundContract, opts *bind.CallOpts, method string, params ...interface{}) (string, error) {
-	var out []interface{}
-	if err := contract.Call(opts, &out, method, params...); err != nil {
-		return "", fmt.Errorf("call %s: %w", method, err)
-	}
-	if len(out) == 0 {
-		return "", fmt.Errorf("call %s: empty result", method)
-	}
-	return *abi.ConvertType(out[0], new(string)).(*string), nil
-}
-
-func callUint8(contract *bind.BoundContract, opts *bind.CallOpts, method string, params ...interface{}) (uint8, error) {
-	var out []interface{}
-	if err := contract.Call(opts, &out, method, params...); err != nil {
-		return 0, fmt.Errorf("call %s: %w", method, err)
-	}
-	if len(out) == 0 {
-		return 0, fmt.Errorf("call %s: empty result", method)
-	}
-	return *abi.ConvertType(out[0], new(uint8)).(*uint8), nil
-}
-
-func callUint256(contract *bind.BoundContract, opts *bind.CallOpts, method string, params ...interface{}) (*big.Int, error) {
-	var out []interface{}
-	if err := contract.Call(opts, &out, method, params...); err != nil {
-		return nil, fmt.Errorf("call %s: %w", method, err)
-	}
-	if len(out) == 0 {
-		return nil, fmt.Errorf("call %s: empty result", method)
-	}
-	return *abi.ConvertType(out[0], new(*big.Int)).(**big.Int), nil
-}
@@ -9,7 +9,10 @@ import (
 
 	ethereum "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 type mockBackend struct {
@@ -21,7 +24,7 @@ type mockBackend struct {
 
 func newMockBackend(t *testing.T) *mockBackend {
 	t.Helper()
-	parsed, err := abi.JSON(strings.NewReader(erc20ABI))
+	parsed, err := abi.JSON(strings.NewReader(ERC20ABI))
 	if err != nil {
 		t.Fatalf("parse abi: %v", err)
 	}
@@ -108,3 +111,104 @@ func TestRunErrors(t *testing.T) {
 		t.Fatalf("expected ABI parse error")
 	}
 }
+
+func TestRunRejectsMismatchedABI(t *testing.T) {
+	mock := newMockBackend(t)
+	contract := common.HexToAddress("0x2000000000000000000000000000000000000002")
+
+	// Same methods in a different order and formatting: accepted.
+	if _, err := Run(context.Background(), mock, Config{Contract: contract, ABI: ERC20ABI}); err != nil {
+		t.Fatalf("Run with matching ABI: %v", err)
+	}
+	// decimals declared as uint256: the binding would decode it differently.
+	bad := strings.Replace(ERC20ABI, `"name":"decimals","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint8"}]`,
+		`"name":"decimals","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]`, 1)
+	if _, err := Run(context.Background(), mock, Config{Contract: contract, ABI: bad}); err == nil || !strings.Contains(err.Error(), "decimals") {
+		t.Fatalf("expected decimals mismatch, got %v", err)
+	}
+	// balanceOf missing.
+	noBalance := `[{"type":"function","name":"name","inputs":[],"outputs":[{"type":"string"}]}]`
+	if _, err := Run(context.Background(), mock, Config{Contract: contract, ABI: noBalance}); err == nil {
+		t.Fatalf("expected missing method error")
+	}
+}
+
+// mockFilterer answers eth_getLogs with a fixed set of logs and records the query.
+type mockFilterer struct {
+	logs  []types.Log
+	query ethereum.FilterQuery
+}
+
+func (m *mockFilterer) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	m.query = q
+	return m.logs, nil
+}
+
+func (m *mockFilterer) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	return nil, errors.New("not supported")
+}
+
+func TestBindingFilterAndParseTransfer(t *testing.T) {
+	token := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	from := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	to := common.HexToAddress("0x3000000000000000000000000000000000000003")
+	transferID := crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	log := types.Log{
+		Address: token,
+		Topics:  []common.Hash{transferID, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+		Data:    common.LeftPadBytes(big.NewInt(500).Bytes(), 32),
+	}
+	filterer := &mockFilterer{logs: []types.Log{log}}
+	erc20, err := NewERC20(token, newMockBackend(t), nil, filterer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	end := uint64(20)
+	events, err := erc20.FilterTransfer(&bind.FilterOpts{Start: 10, End: &end}, []common.Address{from}, nil)
+	if err != nil {
+		t.Fatalf("FilterTransfer: %v", err)
+	}
+	if len(events) != 1 || events[0].From != from || events[0].To != to || events[0].Value.Int64() != 500 {
+		t.Fatalf("events = %+v", events)
+	}
+	q := filterer.query
+	if q.FromBlock.Uint64() != 10 || q.ToBlock.Uint64() != 20 || q.Addresses[0] != token {
+		t.Fatalf("query range/address = %+v", q)
+	}
+	if len(q.Topics) < 2 || q.Topics[0][0] != transferID || q.Topics[1][0] != common.BytesToHash(from.Bytes()) {
+		t.Fatalf("query topics = %v", q.Topics)
+	}
+
+	// An Approval log is not a Transfer.
+	log.Topics[0] = crypto.Keccak256Hash([]byte("Approval(address,address,uint256)"))
+	if _, err := erc20.ParseTransfer(log); err == nil {
+		t.Fatalf("expected topic mismatch error")
+	}
+	readOnly, _ := NewERC20(token, newMockBackend(t), nil, nil)
+	if _, err := readOnly.FilterTransfer(nil, nil, nil); err == nil {
+		t.Fatalf("expected missing filterer error")
+	}
+}
+
+func TestBindingTransferEncodesCall(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	token := common.HexToAddress("0x2000000000000000000000000000000000000002")
+	to := common.HexToAddress("0x3000000000000000000000000000000000000003")
+	erc20, err := NewERC20(token, newMockBackend(t), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts, _ := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1))
+	opts.Nonce, opts.GasPrice, opts.GasLimit, opts.NoSend = big.NewInt(0), big.NewInt(1), 60_000, true
+
+	tx, err := erc20.Transfer(opts, to, big.NewInt(42))
+	if err != nil {
+		t.Fatalf("Transfer: %v", err)
+	}
+	parsed, _ := abi.JSON(strings.NewReader(ERC20ABI))
+	want, _ := parsed.Pack("transfer", to, big.NewInt(42))
+	if *tx.To() != token || string(tx.Data()) != string(want) {
+		t.Fatalf("tx to %s data %x, want %x", tx.To().Hex(), tx.Data(), want)
+	}
+}
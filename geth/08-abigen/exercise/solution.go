@@ -4,6 +4,7 @@
 package exercise
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -15,19 +16,18 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 )
 
-const erc20ABI = `[{"constant":true,"inputs":[],"name":"name","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"},{"constant":true,"inputs":[],"name":"symbol","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"},{"constant":true,"inputs":[],"name":"decimals","outputs":[{"name":"","type":"uint8"}],"stateMutability":"view","type":"function"},{"constant":true,"inputs":[],"name":"totalSupply","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"constant":true,"inputs":[{"name":"account","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`
-
 /*
-Problem: Use BoundContract for type-safe contract calls with automatic ABI encoding/decoding.
+Problem: Use a generated typed binding for type-safe contract calls with automatic ABI encoding/decoding.
 
 This module teaches you how to use go-ethereum's BoundContract pattern for cleaner,
 safer contract interactions. Instead of manually encoding/decoding like module 07,
-you'll use the abi package to handle encoding/decoding automatically.
+you'll call the ERC20 binding that cmd/bindgen generates from erc20.abi.json; it
+wraps BoundContract and the abi package in typed Go methods.
 
 Computer science principles highlighted:
   - Adapter pattern: BoundContract wraps low-level RPC with high-level interface
   - Type safety: ABI definitions provide compile-time checks
-  - Code reuse: Helper functions eliminate boilerplate
+  - Code generation: the typed wrapper is written once from the ABI, not by hand
   - Separation of concerns: ABI encoding is separate from business logic
 */
 func Run(ctx context.Context, backend ContractCaller, cfg Config) (*Result, error) {
@@ -61,67 +61,39 @@ func Run(ctx context.Context, backend ContractCaller, cfg Config) (*Result, erro
 	}
 
 	// ============================================================================
-	// STEP 2: Parse ABI JSON - Understanding Contract Interface
+	// STEP 2: Check an ABI Override - Generated Bindings Fix the Interface
 	// ============================================================================
 	// ABI (Application Binary Interface) is like an interface definition for contracts.
-	// It tells us:
-	//   - What functions exist
-	//   - What parameters they take
-	//   - What types they return
-	//   - Whether they're view/pure (read-only) or state-changing
-	//
-	// Why do we need ABI? Without it, we can't encode function calls or decode
-	// return values. The ABI is the "contract" between our Go code and the smart
-	// contract.
-	//
-	// Default ABI: We provide erc20ABI as a default so users don't have to specify
-	// it for standard ERC20 tokens. This is the "sensible defaults" pattern—make
-	// common cases easy, advanced cases possible.
-	abiJSON := cfg.ABI
-	if strings.TrimSpace(abiJSON) == "" {
-		abiJSON = erc20ABI
-	}
-
-	// Parse ABI: The abi.JSON function parses the JSON string into an abi.ABI object.
-	// This object contains method definitions, event definitions, and type information
-	// needed for encoding/decoding.
+	// It tells us what functions exist, what they take and what they return.
 	//
-	// Error handling: ABI parsing can fail if:
-	//   - JSON is malformed
-	//   - Function signatures are invalid
-	//   - Type definitions are incorrect
-	// We wrap the error with context for debugging.
-	parsedABI, err := abi.JSON(strings.NewReader(abiJSON))
-	if err != nil {
-		return nil, fmt.Errorf("parse ABI: %w", err)
+	// With a generated binding, the ABI is baked in at generation time
+	// (erc20.abi.json -> erc20_binding.go via go:generate). A caller-supplied
+	// ABI can no longer change how we call the contract; we parse it only to
+	// confirm that it describes the same methods, so a mismatch fails loudly
+	// instead of decoding garbage.
+	if strings.TrimSpace(cfg.ABI) != "" {
+		if err := checkABI(cfg.ABI); err != nil {
+			return nil, err
+		}
 	}
 
 	// ============================================================================
-	// STEP 3: Create BoundContract - The Adapter Pattern
+	// STEP 3: Bind the Contract - Generated Adapter
 	// ============================================================================
-	// BoundContract is the adapter pattern in action. It adapts the low-level
-	// RPC interface (CallContract) to a high-level interface that understands
-	// ABI encoding/decoding.
-	//
-	// What BoundContract provides:
-	//   - Call(): For view/pure functions (read operations)
-	//   - Transact(): For state-changing functions (write operations)
-	//   - FilterLogs(): For querying events
+	// NewERC20 is generated code. Under the hood it still builds a
+	// bind.BoundContract (the adapter from the low-level RPC interface to
+	// ABI-aware calls), but every method is now a typed Go method:
 	//
-	// Parameters explained:
-	//   - cfg.Contract: The contract address we want to interact with
-	//   - parsedABI: The ABI definition (tells us how to encode/decode)
-	//   - backend: The RPC client for read operations (ContractCaller)
-	//   - nil: Transactor for write operations (not needed here—we're only reading)
-	//   - nil: Filterer for event queries (not needed in this module)
+	//	Before: callUint256(contract, opts, "balanceOf", holder) // typo => runtime error
+	//	After:  token.BalanceOf(opts, holder)                    // typo => compile error
 	//
-	// Why three separate interfaces? Separation of concerns. A read-only client
-	// doesn't need transaction signing capabilities. This follows the interface
-	// segregation principle.
-	//
-	// Compare to module 07: In module 07, we manually built CallMsg and decoded
-	// responses. BoundContract does all of this for us automatically based on the ABI.
-	contract := bind.NewBoundContract(cfg.Contract, parsedABI, backend, nil, nil)
+	// Parameters: caller for reads, then transactor and filterer, which are
+	// nil because Run only reads. This is interface segregation again: a
+	// read-only client doesn't need transaction signing capabilities.
+	token, err := NewERC20(cfg.Contract, backend, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("bind ERC20: %w", err)
+	}
 
 	// ============================================================================
 	// STEP 4: Create CallOpts - Configuring Contract Calls
@@ -153,66 +125,47 @@ func Run(ctx context.Context, backend ContractCaller, cfg Config) (*Result, erro
 	}
 
 	// ============================================================================
-	// STEP 5: Call name() - First BoundContract Usage
+	// STEP 5: Call name() - First Typed Binding Usage
 	// ============================================================================
 	// name() is a view function that returns a string.
 	//
 	// Compare to module 07:
 	//   Module 07: Manually compute selector, build CallMsg, decode string
-	//   Module 08: Just call callString(contract, opts, "name")
-	//
-	// This is the power of abstraction. BoundContract + helpers hide all the
-	// encoding/decoding complexity. The code is simpler, safer, and easier to maintain.
+	//   Module 08: Just call token.Name(opts)
 	//
-	// The callString helper (implemented below) does:
+	// The generated Name method does what the old callString helper did:
 	//   1. Calls contract.Call() with method name "name"
 	//   2. Gets back []interface{} of return values
-	//   3. Converts first return value to string type
-	//   4. Returns the string or error
+	//   3. Converts the first return value with abi.ConvertType
+	// The difference is that the method name and return type are fixed by the
+	// generator, so the compiler checks them instead of a runtime assertion.
 	//
-	// Type safety: The helper does runtime type conversion. In a production system,
-	// you'd use abigen to generate typed bindings with compile-time type safety.
-	// This module shows you how it works under the hood.
-	name, err := callString(contract, callOpts, "name")
+	// Error handling: the call can fail on network errors, reverts, or a
+	// return value that doesn't match the ABI. We add the method name.
+	name, err := token.Name(callOpts)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("call name: %w", err)
 	}
 
 	// ============================================================================
 	// STEP 6: Call symbol() - Pattern Repetition
 	// ============================================================================
-	// symbol() follows the exact same pattern as name(). This demonstrates how
-	// consistent patterns make code predictable and easy to understand.
-	//
-	// Notice: Same helper (callString), different method name. The helper is
-	// reusable because all string-returning functions have the same structure.
-	// This is the benefit of abstraction and code reuse.
-	//
-	// Building on previous modules: You've seen this "call → check error" pattern
-	// in every module (01-stack, 06-eip1559, 07-eth-call). By module 08, it should
-	// feel automatic.
-	symbol, err := callString(contract, callOpts, "symbol")
+	// symbol() follows the exact same pattern as name(). Consistent patterns
+	// make code predictable and easy to understand.
+	symbol, err := token.Symbol(callOpts)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("call symbol: %w", err)
 	}
 
 	// ============================================================================
 	// STEP 7: Call decimals() - Different Return Type, Same Pattern
 	// ============================================================================
-	// decimals() returns uint8 instead of string.
-	//
-	// Compare to name/symbol: Different return type, so we use callUint8 instead
-	// of callString. But the pattern is identical:
-	//   1. Call helper with (contract, opts, method name)
-	//   2. Check error
-	//   3. Use the value
-	//
-	// This demonstrates polymorphism through function naming. We can't use Go
-	// generics here (pre-Go 1.18 code), so we use separate functions for each
-	// return type. The pattern is consistent even though the types differ.
-	decimals, err := callUint8(contract, callOpts, "decimals")
+	// decimals() returns uint8 instead of string. With string-keyed helpers
+	// we needed one helper per return type (callString, callUint8, ...); the
+	// generator emits the right return type for every method instead.
+	decimals, err := token.Decimals(callOpts)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("call decimals: %w", err)
 	}
 
 	// ============================================================================
@@ -221,48 +174,27 @@ func Run(ctx context.Context, backend ContractCaller, cfg Config) (*Result, erro
 	// totalSupply() returns uint256, which maps to *big.Int in Go.
 	//
 	// Why *big.Int? Solidity uint256 can hold values up to 2^256-1, which is far
-	// larger than Go's uint64 (max ~2^64). Go's math/big package provides arbitrary
-	// precision integers that can handle Solidity's uint256 range.
-	//
-	// Real-world importance: Token supplies can be enormous. For example:
-	//   - Shiba Inu: 1 quadrillion tokens (10^15)
-	//   - Many tokens: Supply in the trillions
-	// These values exceed uint64's max, so we must use *big.Int.
-	//
-	// Pattern repetition: Again, same pattern—call helper, check error, use value.
-	// The only difference is the return type (callUint256 returns *big.Int).
-	totalSupply, err := callUint256(contract, callOpts, "totalSupply")
+	// larger than Go's uint64 (max ~2^64). The generator maps uint8..uint64 to
+	// native Go integers and every wider integer to *big.Int.
+	totalSupply, err := token.TotalSupply(callOpts)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("call totalSupply: %w", err)
 	}
 
 	// ============================================================================
 	// STEP 9: Optionally Call balanceOf(address) - Functions with Parameters
 	// ============================================================================
-	// balanceOf(address) is different from the previous functions because it takes
-	// a parameter. This demonstrates how BoundContract handles function parameters.
+	// balanceOf(address) takes a parameter. In the binding it is a typed Go
+	// argument: passing anything but a common.Address is a compile error.
 	//
 	// Conditional logic: We only call balanceOf if cfg.Holder is provided. This is
 	// the "optional feature" pattern—some fields in Result are optional (nil if
 	// not requested).
-	//
-	// Why optional? Not all callers need balance information. By making it optional,
-	// we avoid unnecessary RPC calls and make the function more flexible.
 	var balance *big.Int
 	if cfg.Holder != nil {
-		// Variadic parameters: The callUint256 helper uses variadic params
-		// (params ...interface{}) to accept any number of function arguments.
-		// Here we pass *cfg.Holder as the address parameter for balanceOf.
-		//
-		// Type handling: *cfg.Holder is common.Address, which BoundContract
-		// automatically encodes according to the ABI (addresses are 20 bytes,
-		// padded to 32 bytes for ABI encoding).
-		//
-		// Compare to module 07: In module 07, you'd manually encode the address
-		// parameter. Here, BoundContract does it automatically based on the ABI.
-		balance, err = callUint256(contract, callOpts, "balanceOf", *cfg.Holder)
+		balance, err = token.BalanceOf(callOpts, *cfg.Holder)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("call balanceOf: %w", err)
 		}
 	}
 
@@ -270,7 +202,7 @@ func Run(ctx context.Context, backend ContractCaller, cfg Config) (*Result, erro
 	// STEP 10: Construct and Return Result - No Defensive Copying Needed
 	// ============================================================================
 	// Why no defensive copying? The values we're returning are already independent
-	// copies created by our helper functions' type conversions.
+	// copies created by the binding's type conversions.
 	//
 	// Ownership analysis:
 	//   - name, symbol: Strings are immutable in Go (safe to share)
@@ -278,7 +210,7 @@ func Run(ctx context.Context, backend ContractCaller, cfg Config) (*Result, erro
 	//   - totalSupply, balance: *big.Int pointers from type conversion (fresh allocations)
 	//
 	// Compare to module 01: In module 01, we needed defensive copying because
-	// RPC client might return shared pointers. Here, our helpers create new values,
+	// RPC client might return shared pointers. Here, the decoder creates new values,
 	// so they're already independent.
 	//
 	// This demonstrates understanding of ownership:
@@ -298,67 +230,40 @@ func Run(ctx context.Context, backend ContractCaller, cfg Config) (*Result, erro
 	}, nil
 }
 
-// callString is a helper that calls a contract method returning string.
-//
-// How it works:
-//   1. Calls contract.Call() with method name and parameters
-//   2. contract.Call encodes parameters using ABI
-//   3. Executes eth_call via backend
-//   4. Decodes return value using ABI
-//   5. Returns []interface{} of return values
-//   6. We convert first value to string type
+// checkABI parses an ABI override and checks that every method Run calls has
+// the same selector as in the generated binding.
 //
-// Why []interface{}? Solidity functions can return multiple values. The ABI
-// decoder returns a slice of interface{} values, one per return value. We
-// extract the first one and convert to string.
-//
-// Error handling: contract.Call can fail if:
-//   - Network error (RPC call failed)
-//   - Contract reverted (require/revert statement)
-//   - Decoding failed (return value doesn't match ABI)
-func callString(contract *bind.BoundContract, opts *bind.CallOpts, method string, params ...interface{}) (string, error) {
-	var out []interface{}
-	if err := contract.Call(opts, &out, method, params...); err != nil {
-		return "", fmt.Errorf("call %s: %w", method, err)
-	}
-	if len(out) == 0 {
-		return "", fmt.Errorf("call %s: empty result", method)
+// Why selectors? A selector is keccak256 of the canonical signature, so two
+// ABIs agree on a method exactly when its name and parameter types agree.
+func checkABI(abiJSON string) error {
+	override, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return fmt.Errorf("parse ABI: %w", err)
 	}
-	// abi.ConvertType does type conversion from interface{} to concrete type.
-	// It handles the low-level type assertion and conversion, returning a pointer
-	// to the requested type. We dereference to get the actual string value.
-	return *abi.ConvertType(out[0], new(string)).(*string), nil
-}
-
-// callUint8 is a helper that calls a contract method returning uint8.
-//
-// Same pattern as callString, but converts to uint8 instead of string.
-// See callString comments for detailed explanation of how this works.
-func callUint8(contract *bind.BoundContract, opts *bind.CallOpts, method string, params ...interface{}) (uint8, error) {
-	var out []interface{}
-	if err := contract.Call(opts, &out, method, params...); err != nil {
-		return 0, fmt.Errorf("call %s: %w", method, err)
+	generated, err := abi.JSON(strings.NewReader(ERC20ABI))
+	if err != nil {
+		return fmt.Errorf("parse generated ABI: %w", err)
 	}
-	if len(out) == 0 {
-		return 0, fmt.Errorf("call %s: empty result", method)
+	for _, name := range []string{"name", "symbol", "decimals", "totalSupply", "balanceOf"} {
+		m, ok := override.Methods[name]
+		if !ok {
+			return fmt.Errorf("ABI has no %s method", name)
+		}
+		if want := generated.Methods[name]; !bytes.Equal(m.ID, want.ID) || !outputsMatch(m, want) {
+			return fmt.Errorf("ABI method %s does not match the ERC20 binding (%s)", m.Sig, want.String())
+		}
 	}
-	return *abi.ConvertType(out[0], new(uint8)).(*uint8), nil
+	return nil
 }
 
-// callUint256 is a helper that calls a contract method returning uint256.
-//
-// Same pattern as callString/callUint8, but converts to *big.Int.
-// uint256 in Solidity maps to *big.Int in Go because uint256 can represent
-// values larger than Go's native integer types (up to 2^256-1).
-//
-// See callString comments for detailed explanation of how this works.
-func callUint256(contract *bind.BoundContract, opts *bind.CallOpts, method string, params ...interface{}) (*big.Int, error) {
-	var out []interface{}
-	if err := contract.Call(opts, &out, method, params...); err != nil {
-		return nil, fmt.Errorf("call %s: %w", method, err)
+func outputsMatch(a, b abi.Method) bool {
+	if len(a.Outputs) != len(b.Outputs) {
+		return false
 	}
-	if len(out) == 0 {
-		return nil, fmt.Errorf("call %s: empty result", method)
+	for i := range a.Outputs {
+		if a.Outputs[i].Type.String() != b.Outputs[i].Type.String() {
+			return false
+		}
 	}
-	return *abi.ConvertType(out[0], new(*big.Int)).(**big.Int), nil
+	return true
 }
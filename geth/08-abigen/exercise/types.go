@@ -1,5 +1,7 @@
 package exercise
 
+//go:generate go run ../cmd/bindgen -abi erc20.abi.json -type ERC20 -out erc20_binding.go
+
 import (
 	"context"
 	"math/big"
@@ -16,6 +18,9 @@ type ContractCaller interface {
 
 // Config drives the typed binding demo.
 type Config struct {
+	// ABI optionally overrides the token ABI. Calls always go through the
+	// generated ERC20 binding, so an override must agree with it on the
+	// methods Run calls.
 	ABI         string
 	Contract    common.Address
 	Holder      *common.Address
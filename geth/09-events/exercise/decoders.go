@@ -0,0 +1,247 @@
+package exercise
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ErrUnknownEvent is returned by Registry.Decode when no decoder matches the
+// log's topic0 and topic count.
+var ErrUnknownEvent = errors.New("unknown event")
+
+// Event signatures of the built-in decoders. ERC-20 and ERC-721 share the
+// Transfer and Approval signatures; they differ only in whether the last
+// argument is indexed, so the topic count tells them apart.
+var (
+	approvalSigHash       = crypto.Keccak256Hash([]byte("Approval(address,address,uint256)"))
+	transferSingleSigHash = crypto.Keccak256Hash([]byte("TransferSingle(address,address,address,uint256,uint256)"))
+	transferBatchSigHash  = crypto.Keccak256Hash([]byte("TransferBatch(address,address,address,uint256[],uint256[])"))
+)
+
+// ERC20Transfer is Transfer(address indexed, address indexed, uint256).
+type ERC20Transfer struct {
+	From  common.Address
+	To    common.Address
+	Value *big.Int
+}
+
+// ERC20Approval is Approval(address indexed, address indexed, uint256).
+type ERC20Approval struct {
+	Owner   common.Address
+	Spender common.Address
+	Value   *big.Int
+}
+
+// ERC721Transfer is Transfer(address indexed, address indexed, uint256 indexed).
+type ERC721Transfer struct {
+	From    common.Address
+	To      common.Address
+	TokenID *big.Int
+}
+
+// ERC721Approval is Approval(address indexed, address indexed, uint256 indexed).
+type ERC721Approval struct {
+	Owner    common.Address
+	Approved common.Address
+	TokenID  *big.Int
+}
+
+// ERC1155TransferSingle is TransferSingle(address indexed operator,
+// address indexed from, address indexed to, uint256 id, uint256 value).
+type ERC1155TransferSingle struct {
+	Operator common.Address
+	From     common.Address
+	To       common.Address
+	ID       *big.Int
+	Value    *big.Int
+}
+
+// ERC1155TransferBatch is TransferBatch(address indexed operator,
+// address indexed from, address indexed to, uint256[] ids, uint256[] values).
+type ERC1155TransferBatch struct {
+	Operator common.Address
+	From     common.Address
+	To       common.Address
+	IDs      []*big.Int
+	Values   []*big.Int
+}
+
+// DecodedLog pairs a log with its decoded arguments. Args holds one of the
+// ERC* structs above, or whatever a custom decoder returns.
+type DecodedLog struct {
+	Name string // e.g. "ERC20.Transfer"
+	Log  types.Log
+	Args interface{}
+}
+
+// EventDecoder decodes one event shape.
+type EventDecoder struct {
+	Name   string
+	Topic0 common.Hash
+	Topics int // exact number of topics, including topic0
+	Decode func(lg types.Log) (interface{}, error)
+}
+
+// Registry maps topic0 to the decoders registered for it.
+type Registry struct {
+	decoders map[common.Hash][]EventDecoder
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{decoders: make(map[common.Hash][]EventDecoder)}
+}
+
+// DefaultRegistry returns a registry with the ERC-20, ERC-721 and ERC-1155
+// token events.
+func DefaultRegistry() *Registry {
+	r := NewRegistry()
+	for _, d := range []EventDecoder{
+		{Name: "ERC20.Transfer", Topic0: transferSigHash, Topics: 3, Decode: decodeERC20Transfer},
+		{Name: "ERC20.Approval", Topic0: approvalSigHash, Topics: 3, Decode: decodeERC20Approval},
+		{Name: "ERC721.Transfer", Topic0: transferSigHash, Topics: 4, Decode: decodeERC721Transfer},
+		{Name: "ERC721.Approval", Topic0: approvalSigHash, Topics: 4, Decode: decodeERC721Approval},
+		{Name: "ERC1155.TransferSingle", Topic0: transferSingleSigHash, Topics: 4, Decode: decodeTransferSingle},
+		{Name: "ERC1155.TransferBatch", Topic0: transferBatchSigHash, Topics: 4, Decode: decodeTransferBatch},
+	} {
+		r.Register(d)
+	}
+	return r
+}
+
+// Register adds a decoder. A later decoder with the same topic0 and topic
+// count replaces the earlier one.
+func (r *Registry) Register(d EventDecoder) {
+	list := r.decoders[d.Topic0]
+	for i, existing := range list {
+		if existing.Topics == d.Topics {
+			list[i] = d
+			return
+		}
+	}
+	r.decoders[d.Topic0] = append(list, d)
+}
+
+// Topics returns every registered topic0, for use in a FilterQuery.
+func (r *Registry) Topics() []common.Hash {
+	out := make([]common.Hash, 0, len(r.decoders))
+	for t := range r.decoders {
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool { return bytes.Compare(out[i][:], out[j][:]) < 0 })
+	return out
+}
+
+// Decode finds the decoder for lg and runs it.
+func (r *Registry) Decode(lg types.Log) (*DecodedLog, error) {
+	if len(lg.Topics) == 0 {
+		return nil, fmt.Errorf("%w: log %s#%d has no topics", ErrUnknownEvent, lg.TxHash.Hex(), lg.Index)
+	}
+	for _, d := range r.decoders[lg.Topics[0]] {
+		if d.Topics != len(lg.Topics) {
+			continue
+		}
+		args, err := d.Decode(lg)
+		if err != nil {
+			return nil, fmt.Errorf("decode %s in %s#%d: %w", d.Name, lg.TxHash.Hex(), lg.Index, err)
+		}
+		return &DecodedLog{Name: d.Name, Log: lg, Args: args}, nil
+	}
+	return nil, fmt.Errorf("%w: topic %s with %d topics", ErrUnknownEvent, lg.Topics[0].Hex(), len(lg.Topics))
+}
+
+func topicAddress(h common.Hash) common.Address {
+	return common.BytesToAddress(h.Bytes()[12:])
+}
+
+func dataWord(lg types.Log, i int) (*big.Int, error) {
+	if len(lg.Data) < 32*(i+1) {
+		return nil, fmt.Errorf("data too short: %d bytes", len(lg.Data))
+	}
+	return new(big.Int).SetBytes(lg.Data[32*i : 32*(i+1)]), nil
+}
+
+func decodeERC20Transfer(lg types.Log) (interface{}, error) {
+	v, err := dataWord(lg, 0)
+	if err != nil {
+		return nil, err
+	}
+	return ERC20Transfer{From: topicAddress(lg.Topics[1]), To: topicAddress(lg.Topics[2]), Value: v}, nil
+}
+
+func decodeERC20Approval(lg types.Log) (interface{}, error) {
+	v, err := dataWord(lg, 0)
+	if err != nil {
+		return nil, err
+	}
+	return ERC20Approval{Owner: topicAddress(lg.Topics[1]), Spender: topicAddress(lg.Topics[2]), Value: v}, nil
+}
+
+func decodeERC721Transfer(lg types.Log) (interface{}, error) {
+	return ERC721Transfer{
+		From:    topicAddress(lg.Topics[1]),
+		To:      topicAddress(lg.Topics[2]),
+		TokenID: lg.Topics[3].Big(),
+	}, nil
+}
+
+func decodeERC721Approval(lg types.Log) (interface{}, error) {
+	return ERC721Approval{
+		Owner:    topicAddress(lg.Topics[1]),
+		Approved: topicAddress(lg.Topics[2]),
+		TokenID:  lg.Topics[3].Big(),
+	}, nil
+}
+
+func decodeTransferSingle(lg types.Log) (interface{}, error) {
+	id, err := dataWord(lg, 0)
+	if err != nil {
+		return nil, err
+	}
+	value, err := dataWord(lg, 1)
+	if err != nil {
+		return nil, err
+	}
+	return ERC1155TransferSingle{
+		Operator: topicAddress(lg.Topics[1]),
+		From:     topicAddress(lg.Topics[2]),
+		To:       topicAddress(lg.Topics[3]),
+		ID:       id,
+		Value:    value,
+	}, nil
+}
+
+// batchArgs is the non-indexed (uint256[], uint256[]) tail of TransferBatch.
+var batchArgs = func() abi.Arguments {
+	t, err := abi.NewType("uint256[]", "", nil)
+	if err != nil {
+		panic(err)
+	}
+	return abi.Arguments{{Name: "ids", Type: t}, {Name: "values", Type: t}}
+}()
+
+func decodeTransferBatch(lg types.Log) (interface{}, error) {
+	vals, err := batchArgs.Unpack(lg.Data)
+	if err != nil {
+		return nil, err
+	}
+	ids, values := vals[0].([]*big.Int), vals[1].([]*big.Int)
+	if len(ids) != len(values) {
+		return nil, fmt.Errorf("%d ids but %d values", len(ids), len(values))
+	}
+	return ERC1155TransferBatch{
+		Operator: topicAddress(lg.Topics[1]),
+		From:     topicAddress(lg.Topics[2]),
+		To:       topicAddress(lg.Topics[3]),
+		IDs:      ids,
+		Values:   values,
+	}, nil
+}
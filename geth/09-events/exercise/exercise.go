@@ -6,12 +6,8 @@ package exercise
 import (
 	"context"
 	"errors"
-	"fmt"
-	"math/big"
 
-	ethereum "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
@@ -46,6 +42,9 @@ func Run(ctx context.Context, client LogClient, cfg Config) (*Result, error) {
 	// - Why Topics? Topics are indexed log parameters. Topic[0] is always the event signature hash.
 	// - transferSigHash is already defined: keccak256("Transfer(address,address,uint256)")
 	// - This filters for Transfer events from the specified token contract
+	// - If cfg.Registry is set, use [][]common.Hash{cfg.Registry.Topics()} instead
+	//   so topic0 matches every event the registry can decode
+	// - If cfg.Registry is nil, decode with DefaultRegistry()
 
 	// TODO: Optionally filter by sender address (FromHolder)
 	// - If cfg.FromHolder is provided (not nil):
@@ -68,18 +67,22 @@ func Run(ctx context.Context, client LogClient, cfg Config) (*Result, error) {
 	// - FilterLogs uses eth_getLogs RPC method
 	// - Returns []types.Log (raw log entries with topics and data)
 
-	// TODO: Initialize result with preallocated slice
-	// - Create Result struct with Events slice
+	// TODO: Initialize result with preallocated slices
+	// - Create Result struct with Events and Decoded slices
 	// - Preallocate capacity: make([]TransferEvent, 0, len(logs))
 	// - Why preallocate? Avoids slice reallocations during append loop
 	// - This is a performance optimization for large result sets
 
 	// TODO: Decode each log entry
 	// - Loop through logs returned from FilterLogs
-	// - For each log, call decodeTransferLog helper (already implemented below)
-	// - decodeTransferLog extracts from/to/value from topics and data
-	// - Handle decoding errors (malformed logs, unexpected structure)
-	// - Append decoded TransferEvent to result.Events
+	// - For each log, call registry.Decode(lg) (see decoders.go)
+	// - The registry picks a decoder by topic0 and topic count: ERC-20 and
+	//   ERC-721 share the Transfer signature but ERC-721 has 4 topics
+	// - Handle decoding errors (unknown events, malformed data)
+	// - Append every decoded log to result.Decoded
+	// - If decoded.Args is an ERC20Transfer, also append a TransferEvent
+	//   (BlockNumber, TxHash, LogIndex from the log;
+	//   From, To, Value from the decoded args) to result.Events
 
 	// TODO: Return result
 	// - Return Result struct with all decoded events
@@ -92,28 +95,3 @@ func Run(ctx context.Context, client LogClient, cfg Config) (*Result, error) {
 func addressTopic(addr common.Address) common.Hash {
 	return common.BytesToHash(common.LeftPadBytes(addr.Bytes(), 32))
 }
-
-func decodeTransferLog(lg types.Log) (TransferEvent, error) {
-	if len(lg.Topics) < 3 {
-		return TransferEvent{}, fmt.Errorf("log %s missing topics", lg.TxHash.Hex())
-	}
-	if lg.Topics[0] != transferSigHash {
-		return TransferEvent{}, fmt.Errorf("unexpected topic %s", lg.Topics[0].Hex())
-	}
-
-	from := common.BytesToAddress(lg.Topics[1].Bytes()[12:])
-	to := common.BytesToAddress(lg.Topics[2].Bytes()[12:])
-	if len(lg.Data) < 32 {
-		return TransferEvent{}, fmt.Errorf("log %s data too short", lg.TxHash.Hex())
-	}
-	value := new(big.Int).SetBytes(lg.Data[len(lg.Data)-32:])
-
-	return TransferEvent{
-		BlockNumber: lg.BlockNumber,
-		TxHash:      lg.TxHash,
-		LogIndex:    lg.Index,
-		From:        from,
-		To:          to,
-		Value:       value,
-	}, nil
-}
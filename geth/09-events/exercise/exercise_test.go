@@ -4,7 +4,11 @@ import (
 	"context"
 	"errors"
 	"math/big"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	ethereum "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
@@ -81,3 +85,336 @@ func TestRunErrors(t *testing.T) {
 		t.Fatalf("expected missing token error")
 	}
 }
+
+func TestRegistryDecodesTokenEvents(t *testing.T) {
+	a := common.HexToAddress("0x00000000000000000000000000000000000000a1")
+	b := common.HexToAddress("0x00000000000000000000000000000000000000b2")
+	op := common.HexToAddress("0x00000000000000000000000000000000000000c3")
+	word := func(v int64) []byte { return common.LeftPadBytes(big.NewInt(v).Bytes(), 32) }
+	concat := func(words ...[]byte) []byte {
+		var out []byte
+		for _, w := range words {
+			out = append(out, w...)
+		}
+		return out
+	}
+	idTopic := common.BigToHash(big.NewInt(42))
+
+	tests := []struct {
+		name string
+		log  types.Log
+		want interface{}
+	}{
+		{
+			name: "ERC20.Transfer",
+			log:  types.Log{Topics: []common.Hash{transferSigHash, addressTopic(a), addressTopic(b)}, Data: word(7)},
+			want: ERC20Transfer{From: a, To: b, Value: big.NewInt(7)},
+		},
+		{
+			name: "ERC20.Approval",
+			log:  types.Log{Topics: []common.Hash{approvalSigHash, addressTopic(a), addressTopic(b)}, Data: word(8)},
+			want: ERC20Approval{Owner: a, Spender: b, Value: big.NewInt(8)},
+		},
+		{
+			name: "ERC721.Transfer",
+			log:  types.Log{Topics: []common.Hash{transferSigHash, addressTopic(a), addressTopic(b), idTopic}},
+			want: ERC721Transfer{From: a, To: b, TokenID: big.NewInt(42)},
+		},
+		{
+			name: "ERC721.Approval",
+			log:  types.Log{Topics: []common.Hash{approvalSigHash, addressTopic(a), addressTopic(b), idTopic}},
+			want: ERC721Approval{Owner: a, Approved: b, TokenID: big.NewInt(42)},
+		},
+		{
+			name: "ERC1155.TransferSingle",
+			log:  types.Log{Topics: []common.Hash{transferSingleSigHash, addressTopic(op), addressTopic(a), addressTopic(b)}, Data: concat(word(5), word(100))},
+			want: ERC1155TransferSingle{Operator: op, From: a, To: b, ID: big.NewInt(5), Value: big.NewInt(100)},
+		},
+		{
+			name: "ERC1155.TransferBatch",
+			// (uint256[] ids, uint256[] values): two offsets, then each array
+			// as length followed by elements.
+			log: types.Log{Topics: []common.Hash{transferBatchSigHash, addressTopic(op), addressTopic(a), addressTopic(b)},
+				Data: concat(word(0x40), word(0xa0), word(2), word(1), word(2), word(2), word(10), word(20))},
+			want: ERC1155TransferBatch{Operator: op, From: a, To: b,
+				IDs: []*big.Int{big.NewInt(1), big.NewInt(2)}, Values: []*big.Int{big.NewInt(10), big.NewInt(20)}},
+		},
+	}
+
+	reg := DefaultRegistry()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := reg.Decode(tt.log)
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if got.Name != tt.name {
+				t.Fatalf("decoded as %s", got.Name)
+			}
+			if !reflect.DeepEqual(got.Args, tt.want) {
+				t.Fatalf("args = %+v, want %+v", got.Args, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistryErrors(t *testing.T) {
+	reg := DefaultRegistry()
+	unknown := types.Log{Topics: []common.Hash{common.HexToHash("0x01")}}
+	if _, err := reg.Decode(unknown); !errors.Is(err, ErrUnknownEvent) {
+		t.Fatalf("unknown topic: got %v", err)
+	}
+	// Transfer with 2 topics matches neither the ERC-20 nor ERC-721 shape.
+	short := types.Log{Topics: []common.Hash{transferSigHash, {}}}
+	if _, err := reg.Decode(short); !errors.Is(err, ErrUnknownEvent) {
+		t.Fatalf("wrong topic count: got %v", err)
+	}
+	noData := types.Log{Topics: []common.Hash{transferSigHash, {}, {}}}
+	if _, err := reg.Decode(noData); err == nil || errors.Is(err, ErrUnknownEvent) {
+		t.Fatalf("short data: got %v", err)
+	}
+
+	// Register replaces a decoder with the same shape.
+	reg.Register(EventDecoder{Name: "Custom", Topic0: transferSigHash, Topics: 3,
+		Decode: func(types.Log) (interface{}, error) { return "custom", nil }})
+	got, err := reg.Decode(noData)
+	if err != nil || got.Name != "Custom" {
+		t.Fatalf("custom decoder not used: %v %v", got, err)
+	}
+}
+
+func TestRunWithRegistry(t *testing.T) {
+	token := common.HexToAddress("0xdead")
+	a := common.HexToAddress("0x01")
+	b := common.HexToAddress("0x02")
+	mock := &mockLogClient{logs: []types.Log{
+		{Topics: []common.Hash{approvalSigHash, addressTopic(a), addressTopic(b)}, Data: common.LeftPadBytes([]byte{9}, 32)},
+		{Topics: []common.Hash{transferSigHash, addressTopic(a), addressTopic(b)}, Data: common.LeftPadBytes([]byte{3}, 32)},
+	}}
+	res, err := Run(context.Background(), mock, Config{Token: token, Registry: DefaultRegistry()})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(mock.last.Topics) != 1 || len(mock.last.Topics[0]) != len(DefaultRegistry().Topics()) {
+		t.Fatalf("topic0 filter = %v, want every registered event", mock.last.Topics)
+	}
+	if len(res.Decoded) != 2 || res.Decoded[0].Name != "ERC20.Approval" {
+		t.Fatalf("decoded = %+v", res.Decoded)
+	}
+	if len(res.Events) != 1 || res.Events[0].Value.Int64() != 3 {
+		t.Fatalf("events = %+v", res.Events)
+	}
+}
+
+// fakeChain serves eth_getLogs and eth_blockNumber from an in-memory chain
+// and runs one script per subscription to push live logs.
+type fakeChain struct {
+	mu      sync.Mutex
+	logs    []types.Log
+	head    uint64
+	ranges  [][2]uint64
+	subErr  error
+	scripts []func(ch chan<- types.Log, sub *fakeSub)
+	subs    int
+}
+
+func (c *fakeChain) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	from, to := q.FromBlock.Uint64(), q.ToBlock.Uint64()
+	c.ranges = append(c.ranges, [2]uint64{from, to})
+	var out []types.Log
+	for _, lg := range c.logs {
+		if lg.BlockNumber >= from && lg.BlockNumber <= to {
+			out = append(out, lg)
+		}
+	}
+	return out, nil
+}
+
+func (c *fakeChain) BlockNumber(ctx context.Context) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.head, nil
+}
+
+func (c *fakeChain) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.subErr != nil {
+		return nil, c.subErr
+	}
+	sub := &fakeSub{err: make(chan error, 1)}
+	if c.subs < len(c.scripts) {
+		go c.scripts[c.subs](ch, sub)
+	}
+	c.subs++
+	return sub, nil
+}
+
+func (c *fakeChain) add(lg types.Log) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.logs = append(c.logs, lg)
+	if lg.BlockNumber > c.head {
+		c.head = lg.BlockNumber
+	}
+}
+
+type fakeSub struct {
+	err  chan error
+	once sync.Once
+}
+
+func (s *fakeSub) Err() <-chan error { return s.err }
+func (s *fakeSub) Unsubscribe()      { s.once.Do(func() { close(s.err) }) }
+func (s *fakeSub) drop()             { s.once.Do(func() { s.err <- errors.New("connection reset"); close(s.err) }) }
+
+func streamLog(block uint64, index uint) types.Log {
+	a := common.HexToAddress("0x01")
+	return types.Log{
+		Topics:      []common.Hash{transferSigHash, addressTopic(a), addressTopic(a)},
+		Data:        common.LeftPadBytes(big.NewInt(int64(block)).Bytes(), 32),
+		BlockNumber: block,
+		BlockHash:   common.BigToHash(big.NewInt(int64(block))),
+		Index:       index,
+	}
+}
+
+func TestStreamerBackfillLiveAndRemoved(t *testing.T) {
+	chain := &fakeChain{}
+	for b := uint64(0); b < 10; b++ {
+		chain.add(streamLog(b, uint(b)))
+	}
+	live := streamLog(10, 10)
+	removed := live
+	removed.Removed = true
+	neverSeen := streamLog(11, 11)
+	neverSeen.Removed = true
+	chain.scripts = []func(chan<- types.Log, *fakeSub){func(ch chan<- types.Log, _ *fakeSub) {
+		ch <- streamLog(9, 9) // already backfilled
+		ch <- live
+		ch <- neverSeen // retracts something never delivered: ignored
+		ch <- removed
+	}}
+
+	s, err := NewStreamer(chain, StreamConfig{ChunkSize: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var got []StreamEvent
+	err = s.Run(ctx, func(ev StreamEvent) error {
+		got = append(got, ev)
+		if ev.Removed {
+			cancel()
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run returned %v", err)
+	}
+
+	wantRanges := [][2]uint64{{0, 3}, {4, 7}, {8, 9}}
+	if !reflect.DeepEqual(chain.ranges, wantRanges) {
+		t.Fatalf("ranges = %v, want %v", chain.ranges, wantRanges)
+	}
+	if len(got) != 12 {
+		t.Fatalf("got %d events, want 10 backfilled + 1 live + 1 removed", len(got))
+	}
+	for i, ev := range got[:10] {
+		if ev.Source != SourceBackfill || ev.Log.BlockNumber != uint64(i) || ev.Decoded == nil {
+			t.Fatalf("event %d = %+v", i, ev)
+		}
+	}
+	if got[10].Source != SourceLive || got[10].Removed || got[10].Log.BlockNumber != 10 {
+		t.Fatalf("live event = %+v", got[10])
+	}
+	if !got[11].Removed || got[11].Log.BlockNumber != 10 {
+		t.Fatalf("removal = %+v", got[11])
+	}
+}
+
+func TestStreamerGapFillAfterDrop(t *testing.T) {
+	chain := &fakeChain{}
+	chain.add(streamLog(1, 0))
+	consumed := make(chan struct{})
+	chain.scripts = []func(chan<- types.Log, *fakeSub){
+		func(ch chan<- types.Log, sub *fakeSub) {
+			ch <- streamLog(2, 0)
+			<-consumed
+			// Blocks 2-4 land while the connection is down. Block 2's log was
+			// already delivered live and must not be repeated.
+			chain.add(streamLog(2, 0))
+			chain.add(streamLog(3, 0))
+			chain.add(streamLog(4, 0))
+			sub.drop()
+		},
+	}
+
+	s, err := NewStreamer(chain, StreamConfig{ChunkSize: 2, ResubscribeDelay: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var got []StreamEvent
+	err = s.Run(ctx, func(ev StreamEvent) error {
+		got = append(got, ev)
+		switch ev.Log.BlockNumber {
+		case 2:
+			close(consumed)
+		case 4:
+			cancel()
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run returned %v", err)
+	}
+
+	var blocks, sources []string
+	for _, ev := range got {
+		blocks = append(blocks, ev.Log.BlockHash.Big().String())
+		sources = append(sources, ev.Source)
+	}
+	if want := []string{"1", "2", "3", "4"}; !reflect.DeepEqual(blocks, want) {
+		t.Fatalf("blocks = %v, want %v", blocks, want)
+	}
+	if want := []string{SourceBackfill, SourceLive, SourceGapfill, SourceGapfill}; !reflect.DeepEqual(sources, want) {
+		t.Fatalf("sources = %v, want %v", sources, want)
+	}
+	// The gap-fill starts at the block of the last live log, not after it:
+	// that block may have had more logs than the subscription delivered.
+	if last := chain.ranges[len(chain.ranges)-2:]; !reflect.DeepEqual(last, [][2]uint64{{2, 3}, {4, 4}}) {
+		t.Fatalf("gap-fill ranges = %v", last)
+	}
+	if cp := s.Checkpoint(); cp != 5 {
+		t.Fatalf("checkpoint = %d, want 5", cp)
+	}
+}
+
+func TestStreamerErrors(t *testing.T) {
+	if _, err := NewStreamer(nil, StreamConfig{}); err == nil {
+		t.Fatalf("expected nil client error")
+	}
+	if _, err := NewStreamer(&fakeChain{}, StreamConfig{Registry: NewRegistry()}); err == nil {
+		t.Fatalf("expected error for empty registry")
+	}
+
+	chain := &fakeChain{subErr: errors.New("websocket required")}
+	s, _ := NewStreamer(chain, StreamConfig{ResubscribeDelay: time.Millisecond, MaxRetries: 3})
+	err := s.Run(context.Background(), func(StreamEvent) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "3 consecutive failures") {
+		t.Fatalf("Run returned %v", err)
+	}
+
+	chain = &fakeChain{}
+	chain.add(streamLog(0, 0))
+	boom := errors.New("handler failed")
+	s, _ = NewStreamer(chain, StreamConfig{})
+	if err := s.Run(context.Background(), func(StreamEvent) error { return boom }); !errors.Is(err, boom) {
+		t.Fatalf("Run returned %v, want handler error", err)
+	}
+}
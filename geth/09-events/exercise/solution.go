@@ -7,11 +7,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"math/big"
 
 	ethereum "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
@@ -104,6 +102,17 @@ func Run(ctx context.Context, client LogClient, cfg Config) (*Result, error) {
 		Topics: [][]common.Hash{{transferSigHash}},
 	}
 
+	// With a Registry the query matches every event it can decode: topic0
+	// becomes an OR list (Transfer, Approval, TransferSingle, ...). The
+	// holder filters below still apply by position, so they only make sense
+	// for events whose topic1/topic2 are from/to.
+	registry := cfg.Registry
+	if registry != nil {
+		query.Topics = [][]common.Hash{registry.Topics()}
+	} else {
+		registry = DefaultRegistry()
+	}
+
 	// ============================================================================
 	// STEP 3: Optionally Filter by Sender Address - Understanding Indexed Parameters
 	// ============================================================================
@@ -206,11 +215,12 @@ func Run(ctx context.Context, client LogClient, cfg Config) (*Result, error) {
 	// Computer science concept: This is the difference between amortized O(1)
 	// append (with preallocation) vs amortized O(log n) append (without).
 	result := &Result{
-		Events: make([]TransferEvent, 0, len(logs)),
+		Events:  make([]TransferEvent, 0, len(logs)),
+		Decoded: make([]DecodedLog, 0, len(logs)),
 	}
 
 	// ============================================================================
-	// STEP 7: Decode Each Log - Understanding Log Structure
+	// STEP 7: Decode Each Log - Dispatching on Topic[0]
 	// ============================================================================
 	// types.Log contains:
 	//   - Address: Contract that emitted the log
@@ -220,31 +230,35 @@ func Run(ctx context.Context, client LogClient, cfg Config) (*Result, error) {
 	//   - TxHash: Which transaction emitted this log
 	//   - Index: Position within the block's logs
 	//
-	// For Transfer events:
-	//   - Topics[0]: Event signature hash
-	//   - Topics[1]: from address (indexed)
-	//   - Topics[2]: to address (indexed)
-	//   - Data: ABI-encoded value (uint256, non-indexed)
-	//
-	// decodeTransferLog helper: Extracts from/to/value from the raw log structure.
-	// It handles:
-	//   - Topic validation (correct event signature, enough topics)
-	//   - Address extraction from topics (converting 32-byte hash to 20-byte address)
-	//   - Value decoding from data (parsing uint256 from bytes)
+	// Topic[0] says which event this is, so decoding is a lookup: the registry
+	// maps topic0 to the decoders registered for it. Topic0 alone is not
+	// always enough. ERC-20 and ERC-721 both emit
+	// Transfer(address,address,uint256), but ERC-721 indexes the token ID,
+	// so its logs carry 4 topics instead of 3. The registry picks the decoder
+	// whose topic count matches.
 	//
 	// Error handling: Decoding can fail if:
-	//   - Log has wrong number of topics (malformed event)
-	//   - Topic[0] doesn't match Transfer signature (wrong event type)
+	//   - No decoder matches (unknown event or unexpected topic count)
 	//   - Data is too short (incomplete value encoding)
 	//
-	// Building on previous modules: This is the same "process each item" loop
-	// pattern from previous modules. Process, check error, accumulate results.
+	// ERC-20 transfers are also flattened into TransferEvent, the shape the
+	// earlier steps of this course used.
 	for _, lg := range logs {
-		event, err := decodeTransferLog(lg)
+		decoded, err := registry.Decode(lg)
 		if err != nil {
 			return nil, err
 		}
-		result.Events = append(result.Events, event)
+		result.Decoded = append(result.Decoded, *decoded)
+		if t, ok := decoded.Args.(ERC20Transfer); ok {
+			result.Events = append(result.Events, TransferEvent{
+				BlockNumber: lg.BlockNumber,
+				TxHash:      lg.TxHash,
+				LogIndex:    lg.Index,
+				From:        t.From,
+				To:          t.To,
+				Value:       t.Value,
+			})
+		}
 	}
 
 	// ============================================================================
@@ -287,64 +301,3 @@ func Run(ctx context.Context, client LogClient, cfg Config) (*Result, error) {
 func addressTopic(addr common.Address) common.Hash {
 	return common.BytesToHash(common.LeftPadBytes(addr.Bytes(), 32))
 }
-
-// decodeTransferLog extracts Transfer event data from a raw log entry.
-//
-// Transfer event structure:
-//   - Topics[0]: keccak256("Transfer(address,address,uint256)")
-//   - Topics[1]: from address (32 bytes, last 20 are address)
-//   - Topics[2]: to address (32 bytes, last 20 are address)
-//   - Data: value (32 bytes, uint256 in big-endian)
-//
-// Why Topics[1:] instead of Topics[0:]? Topic[0] is the event signature, which
-// we already validated in the filter. Topics[1] and Topics[2] are the indexed
-// parameters we want to extract.
-//
-// Error handling: This function validates:
-//   1. Enough topics (need at least 3: signature + from + to)
-//   2. Correct event signature (prevent decoding wrong event type)
-//   3. Enough data (need at least 32 bytes for value)
-//
-// These checks prevent panics and catch malformed logs early.
-func decodeTransferLog(lg types.Log) (TransferEvent, error) {
-	// Validate topic count: Transfer has 3 topics (signature + from + to)
-	if len(lg.Topics) < 3 {
-		return TransferEvent{}, fmt.Errorf("log %s missing topics", lg.TxHash.Hex())
-	}
-
-	// Validate event signature: Ensure this is actually a Transfer event
-	if lg.Topics[0] != transferSigHash {
-		return TransferEvent{}, fmt.Errorf("unexpected topic %s", lg.Topics[0].Hex())
-	}
-
-	// Extract from address: Topics[1] is 32 bytes, last 20 bytes are the address.
-	// We use [12:] to skip the 12 zero-padding bytes at the start.
-	from := common.BytesToAddress(lg.Topics[1].Bytes()[12:])
-
-	// Extract to address: Same process for Topics[2]
-	to := common.BytesToAddress(lg.Topics[2].Bytes()[12:])
-
-	// Validate data length: value is uint256 (32 bytes)
-	if len(lg.Data) < 32 {
-		return TransferEvent{}, fmt.Errorf("log %s data too short", lg.TxHash.Hex())
-	}
-
-	// Extract value: Last 32 bytes of Data are the uint256 value in big-endian.
-	// We use [len(lg.Data)-32:] to get exactly the last 32 bytes, which handles
-	// cases where Data might have extra padding (though it shouldn't for Transfer).
-	value := new(big.Int).SetBytes(lg.Data[len(lg.Data)-32:])
-
-	// Return decoded event with metadata:
-	//   - BlockNumber: Which block contains this event
-	//   - TxHash: Which transaction emitted this event
-	//   - LogIndex: Position within block's logs (for ordering)
-	//   - From/To/Value: The actual Transfer data
-	return TransferEvent{
-		BlockNumber: lg.BlockNumber,
-		TxHash:      lg.TxHash,
-		LogIndex:    lg.Index,
-		From:        from,
-		To:          to,
-		Value:       value,
-	}, nil
-}
@@ -0,0 +1,277 @@
+package exercise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Sources of a StreamEvent.
+const (
+	SourceBackfill = "backfill" // historical range read before going live
+	SourceLive     = "live"     // pushed over eth_subscribe("logs")
+	SourceGapfill  = "gapfill"  // re-queried after a subscription drop
+)
+
+// StreamClient is what the streamer needs: eth_getLogs for history,
+// eth_blockNumber to know where history ends, and a websocket/IPC log
+// subscription for the head.
+type StreamClient interface {
+	LogClient
+	BlockNumber(ctx context.Context) (uint64, error)
+	SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error)
+}
+
+// StreamConfig tunes the streamer. Zero values use defaults.
+type StreamConfig struct {
+	Addresses        []common.Address // contracts to follow (empty means all)
+	Topics           [][]common.Hash  // topic filter (default: every topic0 in Registry)
+	FromBlock        uint64           // first block to backfill
+	ChunkSize        uint64           // blocks per eth_getLogs call (default 2000)
+	Registry         *Registry        // decoders (default DefaultRegistry())
+	ResubscribeDelay time.Duration    // wait between reconnect attempts (default 2s)
+	MaxRetries       int              // consecutive failed reconnects before giving up (default 5)
+	DedupWindow      uint64           // blocks of (blockHash, logIndex) keys kept (default 128)
+}
+
+// StreamEvent is one log delivered to the handler. Removed events retract a
+// log delivered earlier whose block was reorged out; the replacement logs
+// (if any) arrive as ordinary events from the new canonical block.
+type StreamEvent struct {
+	Log       types.Log
+	Decoded   *DecodedLog // nil when no decoder matched or decoding failed
+	DecodeErr error       // set when a decoder matched but the log was malformed
+	Removed   bool
+	Source    string
+}
+
+// logKey identifies a log across backfill, live and gap-fill deliveries.
+type logKey struct {
+	block common.Hash
+	index uint
+}
+
+// Streamer backfills a log range in chunks and then follows the head over a
+// subscription. If the subscription drops, it reconnects and re-queries the
+// blocks it may have missed. Every log is delivered at most once per
+// (blockHash, logIndex); overlapping ranges are expected and filtered out.
+type Streamer struct {
+	client StreamClient
+	cfg    StreamConfig
+
+	next atomic.Uint64     // first block not yet fully delivered
+	seen map[logKey]uint64 // delivered logs -> block number
+}
+
+// NewStreamer validates cfg and returns a streamer positioned at cfg.FromBlock.
+func NewStreamer(client StreamClient, cfg StreamConfig) (*Streamer, error) {
+	if client == nil {
+		return nil, errors.New("client is nil")
+	}
+	if cfg.ChunkSize == 0 {
+		cfg.ChunkSize = 2000
+	}
+	if cfg.Registry == nil {
+		cfg.Registry = DefaultRegistry()
+	}
+	if len(cfg.Topics) == 0 {
+		topics := cfg.Registry.Topics()
+		if len(topics) == 0 {
+			return nil, errors.New("no topics to follow: registry is empty")
+		}
+		cfg.Topics = [][]common.Hash{topics}
+	}
+	if cfg.ResubscribeDelay <= 0 {
+		cfg.ResubscribeDelay = 2 * time.Second
+	}
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = 5
+	}
+	if cfg.DedupWindow == 0 {
+		cfg.DedupWindow = 128
+	}
+	s := &Streamer{client: client, cfg: cfg, seen: make(map[logKey]uint64)}
+	s.next.Store(cfg.FromBlock)
+	return s, nil
+}
+
+// Checkpoint returns the first block that has not been fully delivered.
+// Persisting it and passing it back as FromBlock resumes without gaps; the
+// first block may be partially re-delivered.
+func (s *Streamer) Checkpoint() uint64 {
+	return s.next.Load()
+}
+
+// handlerError marks errors returned by the caller's handler so Run stops
+// instead of treating them as a connection problem.
+type handlerError struct{ err error }
+
+func (e handlerError) Error() string { return e.err.Error() }
+func (e handlerError) Unwrap() error { return e.err }
+
+// Run streams logs to handle until ctx is cancelled, the handler returns an
+// error, or MaxRetries reconnects in a row fail.
+//
+// Each connection subscribes first and only then reads the head, so no block
+// falls between the end of the backfill and the start of the subscription.
+// Logs that arrive on both paths are dropped by the dedup set.
+func (s *Streamer) Run(ctx context.Context, handle func(StreamEvent) error) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if handle == nil {
+		return errors.New("handler is nil")
+	}
+	source := SourceBackfill
+	failures := 0
+	for {
+		err := s.connect(ctx, source, handle)
+		var herr handlerError
+		switch {
+		case errors.As(err, &herr):
+			return herr.err
+		case ctx.Err() != nil:
+			return ctx.Err()
+		}
+		if err == nil {
+			// The connection went live and then dropped; whatever is
+			// re-queried from now on is filling a gap.
+			failures = 0
+			source = SourceGapfill
+		} else if failures++; failures >= s.cfg.MaxRetries {
+			return fmt.Errorf("log stream: %d consecutive failures: %w", failures, err)
+		}
+
+		t := time.NewTimer(s.cfg.ResubscribeDelay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// connect runs one subscription. It returns nil when an established
+// subscription drops, and an error when setting it up fails.
+func (s *Streamer) connect(ctx context.Context, source string, handle func(StreamEvent) error) error {
+	logs := make(chan types.Log, 256)
+	sub, err := s.client.SubscribeFilterLogs(ctx, s.query(nil, nil), logs)
+	if err != nil {
+		return fmt.Errorf("subscribe: %w", err)
+	}
+	defer sub.Unsubscribe()
+
+	head, err := s.client.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("block number: %w", err)
+	}
+	if err := s.fill(ctx, head, source, handle); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-sub.Err():
+			// Err is closed on Unsubscribe and receives the error on a drop;
+			// either way the blocks from Checkpoint on need re-querying.
+			return nil
+		case lg := <-logs:
+			if err := s.deliver(lg, SourceLive, handle); err != nil {
+				return err
+			}
+			// Logs arrive in block order, so everything below this block
+			// has been seen. The block itself may still have more logs.
+			if !lg.Removed && lg.BlockNumber > s.next.Load() {
+				s.next.Store(lg.BlockNumber)
+				s.prune()
+			}
+		}
+	}
+}
+
+// fill delivers [Checkpoint, head] in ChunkSize pieces, advancing the
+// checkpoint after each chunk so a failure resumes where it stopped.
+func (s *Streamer) fill(ctx context.Context, head uint64, source string, handle func(StreamEvent) error) error {
+	for from := s.next.Load(); from <= head; from = s.next.Load() {
+		to := from + s.cfg.ChunkSize - 1
+		if to > head || to < from { // clamp, and guard against overflow
+			to = head
+		}
+		logs, err := s.client.FilterLogs(ctx, s.query(new(big.Int).SetUint64(from), new(big.Int).SetUint64(to)))
+		if err != nil {
+			return fmt.Errorf("filter logs [%d, %d]: %w", from, to, err)
+		}
+		for _, lg := range logs {
+			if err := s.deliver(lg, source, handle); err != nil {
+				return err
+			}
+		}
+		s.next.Store(to + 1)
+		s.prune()
+	}
+	return nil
+}
+
+// deliver dedups lg, decodes it and passes it to handle. Removals are only
+// forwarded for logs the handler actually received.
+func (s *Streamer) deliver(lg types.Log, source string, handle func(StreamEvent) error) error {
+	key := logKey{block: lg.BlockHash, index: lg.Index}
+	if lg.Removed {
+		if _, ok := s.seen[key]; !ok {
+			return nil
+		}
+		delete(s.seen, key)
+	} else {
+		if _, ok := s.seen[key]; ok {
+			return nil
+		}
+		s.seen[key] = lg.BlockNumber
+	}
+
+	ev := StreamEvent{Log: lg, Removed: lg.Removed, Source: source}
+	decoded, err := s.cfg.Registry.Decode(lg)
+	switch {
+	case err == nil:
+		ev.Decoded = decoded
+	case !errors.Is(err, ErrUnknownEvent):
+		ev.DecodeErr = err
+	}
+	if err := handle(ev); err != nil {
+		return handlerError{err}
+	}
+	return nil
+}
+
+// prune forgets logs more than DedupWindow blocks behind the checkpoint.
+// Anything older is assumed final and will not be re-delivered or removed.
+func (s *Streamer) prune() {
+	next := s.next.Load()
+	if next <= s.cfg.DedupWindow {
+		return
+	}
+	cutoff := next - s.cfg.DedupWindow
+	for k, block := range s.seen {
+		if block < cutoff {
+			delete(s.seen, k)
+		}
+	}
+}
+
+func (s *Streamer) query(from, to *big.Int) ethereum.FilterQuery {
+	return ethereum.FilterQuery{
+		Addresses: s.cfg.Addresses,
+		Topics:    s.cfg.Topics,
+		FromBlock: from,
+		ToBlock:   to,
+	}
+}
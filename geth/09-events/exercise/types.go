@@ -21,6 +21,10 @@ type Config struct {
 	ToBlock    *big.Int
 	FromHolder *common.Address
 	ToHolder   *common.Address
+
+	// Registry, when set, widens the query to every topic0 it knows and
+	// decodes each log with it. Nil keeps the ERC-20 Transfer-only query.
+	Registry *Registry
 }
 
 // TransferEvent represents a decoded Transfer log.
@@ -35,5 +39,6 @@ type TransferEvent struct {
 
 // Result aggregates decoded events.
 type Result struct {
-	Events []TransferEvent
+	Events  []TransferEvent // ERC-20 transfers only
+	Decoded []DecodedLog    // every log, in chain order
 }
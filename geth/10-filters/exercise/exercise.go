@@ -6,11 +6,7 @@ package exercise
 import (
 	"context"
 	"errors"
-	"fmt"
 	"time"
-
-	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/types"
 )
 
 const defaultMaxHeads = 5
@@ -42,6 +38,7 @@ func Run(ctx context.Context, client HeadClient, cfg Config) (*Result, error) {
 	// - This is the "sensible defaults" pattern
 
 	// TODO: Route to appropriate monitoring strategy
+	// - If cfg.Failover is true, call followHeads (subscription with polling fallback)
 	// - If cfg.PollMode is true, call pollHeads (HTTP polling strategy)
 	// - Otherwise, call subscribeHeads (WebSocket subscription strategy)
 	// - Why two strategies? WebSocket is better but not always available
@@ -50,6 +47,20 @@ func Run(ctx context.Context, client HeadClient, cfg Config) (*Result, error) {
 	return nil, errors.New("not implemented")
 }
 
+func followHeads(ctx context.Context, client HeadClient, cfg Config) (*Result, error) {
+	// TODO: Create a HeadFollower
+	// - NewHeadFollower(client, FollowerConfig{PollInterval: cfg.PollInterval})
+	// - The follower (follower.go) owns failover, backoff, gap-filling and reorgs
+
+	// TODO: Collect MaxHeads from follower.Follow(ctx)
+	// - Derive a cancelable context and defer cancel() so the follower stops
+	//   once we have enough heads
+	// - A closed channel means ctx was cancelled: return an error
+	// - Set result.Mode from follower.Stats().Mode
+
+	return nil, errors.New("not implemented")
+}
+
 func subscribeHeads(ctx context.Context, client HeadClient, cfg Config) (*Result, error) {
 	// TODO: Create channel for receiving headers
 	// - make(chan *types.Header) creates an unbuffered channel
@@ -2,7 +2,10 @@ package exercise
 
 import (
 	"context"
+	"errors"
 	"math/big"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -130,3 +133,268 @@ func makeHeader(number uint64, parent common.Hash) *types.Header {
 		Time:       number,
 	}
 }
+
+// fakeHeadChain serves a mutable canonical chain. Each SubscribeNewHead call
+// runs the next script; once scripts run out, subscribing fails.
+type fakeHeadChain struct {
+	mu        sync.Mutex
+	canonical map[uint64]*types.Header
+	tip       uint64
+	scripts   []func(ch chan<- *types.Header, sub *fakeHeadSub)
+	subs      int
+}
+
+func newFakeHeadChain(headers ...*types.Header) *fakeHeadChain {
+	c := &fakeHeadChain{canonical: make(map[uint64]*types.Header)}
+	c.set(headers...)
+	return c
+}
+
+// set makes headers canonical and moves the tip to the last one.
+func (c *fakeHeadChain) set(headers ...*types.Header) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, h := range headers {
+		c.canonical[h.Number.Uint64()] = h
+		c.tip = h.Number.Uint64()
+	}
+}
+
+func (c *fakeHeadChain) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.subs >= len(c.scripts) {
+		c.subs++
+		return nil, errors.New("notifications not supported")
+	}
+	sub := &fakeHeadSub{err: make(chan error, 1)}
+	go c.scripts[c.subs](ch, sub)
+	c.subs++
+	return sub, nil
+}
+
+func (c *fakeHeadChain) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := c.tip
+	if number != nil {
+		n = number.Uint64()
+	}
+	h, ok := c.canonical[n]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return h, nil
+}
+
+type fakeHeadSub struct {
+	err  chan error
+	once sync.Once
+}
+
+func (s *fakeHeadSub) Err() <-chan error { return s.err }
+func (s *fakeHeadSub) Unsubscribe()      { s.once.Do(func() { close(s.err) }) }
+func (s *fakeHeadSub) drop() {
+	s.once.Do(func() { s.err <- errors.New("connection reset"); close(s.err) })
+}
+
+// makeBranch returns n linked headers starting at number from. salt keeps
+// competing branches at the same height distinct.
+func makeBranch(from uint64, n int, parent common.Hash, salt byte) []*types.Header {
+	out := make([]*types.Header, 0, n)
+	for i := 0; i < n; i++ {
+		h := makeHeader(from+uint64(i), parent)
+		h.Extra = []byte{salt}
+		out = append(out, h)
+		parent = h.Hash()
+	}
+	return out
+}
+
+func fastFollower(t *testing.T, client HeadClient) *HeadFollower {
+	t.Helper()
+	f, err := NewHeadFollower(client, FollowerConfig{
+		HeadTimeout:  time.Second,
+		PollInterval: time.Millisecond,
+		MinBackoff:   5 * time.Millisecond,
+		MaxBackoff:   20 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func collectHeads(t *testing.T, heads <-chan HeadInfo, n int) []HeadInfo {
+	t.Helper()
+	var out []HeadInfo
+	timeout := time.After(5 * time.Second)
+	for len(out) < n {
+		select {
+		case h, ok := <-heads:
+			if !ok {
+				t.Fatalf("stream closed after %d heads", len(out))
+			}
+			out = append(out, h)
+		case <-timeout:
+			t.Fatalf("timed out after %d heads: %+v", len(out), out)
+		}
+	}
+	return out
+}
+
+// assertLinked checks numbers are consecutive and every head's parent is the
+// previous head.
+func assertLinked(t *testing.T, heads []HeadInfo, first uint64) {
+	t.Helper()
+	for i, h := range heads {
+		if h.Number != first+uint64(i) {
+			t.Fatalf("head %d has number %d, want %d", i, h.Number, first+uint64(i))
+		}
+		if i > 0 && h.ParentHash != heads[i-1].Hash {
+			t.Fatalf("head %d does not link to head %d", h.Number, heads[i-1].Number)
+		}
+	}
+}
+
+func TestHeadFollowerFillsSkippedBlocks(t *testing.T) {
+	chain := makeBranch(1, 5, common.HexToHash("0x01"), 0)
+	client := newFakeHeadChain(chain...)
+	client.scripts = append(client.scripts, func(ch chan<- *types.Header, _ *fakeHeadSub) {
+		ch <- chain[0]
+		ch <- chain[3] // 2 and 3 never pushed
+		ch <- chain[3] // duplicate
+		ch <- chain[4]
+	})
+
+	f := fastFollower(t, client)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	heads := collectHeads(t, f.Follow(ctx), 5)
+	assertLinked(t, heads, 1)
+
+	stats := f.Stats()
+	if stats.Filled != 2 || stats.Mode != ModeSubscription || stats.Reorgs != 0 {
+		t.Fatalf("stats = %+v", stats)
+	}
+}
+
+func TestHeadFollowerFailsOverToPolling(t *testing.T) {
+	chain := makeBranch(1, 6, common.HexToHash("0x01"), 0)
+	client := newFakeHeadChain(chain[:2]...)
+	client.scripts = append(client.scripts,
+		func(ch chan<- *types.Header, sub *fakeHeadSub) {
+			ch <- chain[0]
+			ch <- chain[1]
+			// Blocks 3-5 are mined while the socket is down.
+			client.set(chain[2:5]...)
+			sub.drop()
+		},
+		func(ch chan<- *types.Header, _ *fakeHeadSub) {
+			client.set(chain[5])
+			ch <- chain[5]
+		},
+	)
+
+	f := fastFollower(t, client)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	heads := collectHeads(t, f.Follow(ctx), 6)
+	assertLinked(t, heads, 1)
+
+	stats := f.Stats()
+	if stats.Fallbacks == 0 || stats.Resubscribe == 0 {
+		t.Fatalf("expected a fallback and a resubscribe: %+v", stats)
+	}
+	if stats.LastErr == nil || !strings.Contains(stats.LastErr.Error(), "connection reset") {
+		t.Fatalf("LastErr = %v", stats.LastErr)
+	}
+}
+
+func TestHeadFollowerPollsWhenSubscriptionSilentOrUnsupported(t *testing.T) {
+	chain := makeBranch(7, 3, common.HexToHash("0x01"), 0)
+	client := newFakeHeadChain(chain[0])
+	client.scripts = append(client.scripts, func(ch chan<- *types.Header, sub *fakeHeadSub) {
+		// Connected, but never delivers a head.
+	})
+
+	f, err := NewHeadFollower(client, FollowerConfig{
+		HeadTimeout:  10 * time.Millisecond,
+		PollInterval: time.Millisecond,
+		MinBackoff:   time.Millisecond,
+		MaxBackoff:   4 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := f.Follow(ctx)
+	first := collectHeads(t, stream, 1)
+	client.set(chain[1:]...)
+	heads := append(first, collectHeads(t, stream, 2)...)
+	assertLinked(t, heads, 7)
+
+	// The silent subscription timed out; every later attempt is refused and
+	// the follower keeps polling with capped backoff between attempts.
+	stats := f.Stats()
+	if stats.Mode != ModePolling || stats.Fallbacks < 2 {
+		t.Fatalf("stats = %+v", stats)
+	}
+}
+
+func TestHeadFollowerReorg(t *testing.T) {
+	genesis := common.HexToHash("0x01")
+	a := makeBranch(1, 3, genesis, 'a')
+	b := makeBranch(2, 3, a[0].Hash(), 'b') // forks after block 1, one longer
+	client := newFakeHeadChain(a...)
+	client.scripts = append(client.scripts, func(ch chan<- *types.Header, _ *fakeHeadSub) {
+		for _, h := range a {
+			ch <- h
+		}
+		client.set(b...)
+		ch <- b[2]
+	})
+
+	f := fastFollower(t, client)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	heads := collectHeads(t, f.Follow(ctx), 6)
+
+	assertLinked(t, heads[:3], 1)
+	assertLinked(t, heads[3:], 2)
+	if !heads[3].Reorg || heads[3].ParentHash != a[0].Hash() {
+		t.Fatalf("fork head = %+v, want Reorg linked to block 1", heads[3])
+	}
+	for i, h := range heads {
+		if h.Reorg != (i == 3) {
+			t.Fatalf("head %d Reorg = %v", i, h.Reorg)
+		}
+	}
+	if heads[5].Hash != b[2].Hash() {
+		t.Fatalf("tip is not the new branch")
+	}
+	if stats := f.Stats(); stats.Reorgs != 1 || stats.Filled != 2 {
+		t.Fatalf("stats = %+v", stats)
+	}
+}
+
+func TestRunFailover(t *testing.T) {
+	chain := makeBranch(1, 3, common.HexToHash("0x01"), 0)
+	client := newFakeHeadChain(chain...)
+
+	res, err := Run(context.Background(), client, Config{MaxHeads: 1, Failover: true, PollInterval: time.Millisecond})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if res.Mode != ModePolling || len(res.Heads) != 1 || res.Heads[0].Number != 3 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+
+	if _, err := NewHeadFollower(nil, FollowerConfig{}); err == nil {
+		t.Fatalf("expected nil client error")
+	}
+	if _, err := NewHeadFollower(client, FollowerConfig{MinBackoff: time.Second, MaxBackoff: time.Millisecond}); err == nil {
+		t.Fatalf("expected backoff validation error")
+	}
+}
@@ -0,0 +1,299 @@
+package exercise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Follower modes, matching Result.Mode.
+const (
+	ModeSubscription = "subscription"
+	ModePolling      = "polling"
+)
+
+// errBrokenChain means the node answered with a header whose hash does not
+// match the child's ParentHash: the chain moved while we were filling.
+var errBrokenChain = errors.New("parent hash mismatch while filling")
+
+// FollowerConfig tunes the head follower. Zero values use defaults.
+type FollowerConfig struct {
+	HeadTimeout  time.Duration // no head over the subscription for this long means fall back (default 30s)
+	PollInterval time.Duration // polling period while the subscription is down (default 1s)
+	MinBackoff   time.Duration // first wait before retrying the subscription (default 1s)
+	MaxBackoff   time.Duration // backoff cap (default 1m)
+	MaxDepth     int           // emitted heads remembered for reorg detection (default 64)
+	Buffer       int           // capacity of the heads channel (default 16)
+}
+
+// FollowerStats counts what the follower did to keep the stream gap-free.
+type FollowerStats struct {
+	Mode        string
+	Emitted     uint64
+	Filled      uint64 // heads fetched by number to close a gap
+	Reorgs      uint64
+	Fallbacks   uint64 // subscription lost or silent, switched to polling
+	Resubscribe uint64 // subscription attempts after the first
+	LastErr     error
+}
+
+// HeadFollower turns an unreliable head source into an ordered, gap-free
+// stream. It prefers SubscribeNewHead and falls back to polling
+// HeaderByNumber(nil) when the subscription errors or goes quiet, retrying
+// the subscription with exponential backoff.
+//
+// Every head is linked to the previous one by ParentHash. Skipped numbers are
+// fetched and emitted in order. When a head does not connect, the follower
+// walks back to the fork point and re-emits the new branch, marking its first
+// head with Reorg. Numbers therefore increase by exactly one, except at a
+// Reorg head, which restarts at the fork point plus one.
+type HeadFollower struct {
+	client HeadClient
+	cfg    FollowerConfig
+
+	mu    sync.Mutex
+	stats FollowerStats
+
+	// Only touched by the follow goroutine.
+	last   *types.Header
+	hashes map[uint64]common.Hash // emitted canonical hashes, last MaxDepth numbers
+}
+
+// NewHeadFollower validates cfg and returns a follower that has not started.
+func NewHeadFollower(client HeadClient, cfg FollowerConfig) (*HeadFollower, error) {
+	if client == nil {
+		return nil, errors.New("client is nil")
+	}
+	if cfg.HeadTimeout <= 0 {
+		cfg.HeadTimeout = 30 * time.Second
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.MinBackoff <= 0 {
+		cfg.MinBackoff = time.Second
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = time.Minute
+	}
+	if cfg.MaxBackoff < cfg.MinBackoff {
+		return nil, fmt.Errorf("max backoff %s below min backoff %s", cfg.MaxBackoff, cfg.MinBackoff)
+	}
+	if cfg.MaxDepth <= 0 {
+		cfg.MaxDepth = 64
+	}
+	if cfg.Buffer < 0 {
+		return nil, fmt.Errorf("negative buffer %d", cfg.Buffer)
+	}
+	if cfg.Buffer == 0 {
+		cfg.Buffer = 16
+	}
+	return &HeadFollower{client: client, cfg: cfg, hashes: make(map[uint64]common.Hash)}, nil
+}
+
+// Follow starts following the head and returns the stream. The channel is
+// closed when ctx is cancelled. Follow must be called at most once.
+func (f *HeadFollower) Follow(ctx context.Context) <-chan HeadInfo {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	out := make(chan HeadInfo, f.cfg.Buffer)
+	go func() {
+		defer close(out)
+		f.run(ctx, out)
+	}()
+	return out
+}
+
+// Stats returns a snapshot of the follower's counters.
+func (f *HeadFollower) Stats() FollowerStats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.stats
+}
+
+func (f *HeadFollower) update(fn func(*FollowerStats)) {
+	f.mu.Lock()
+	fn(&f.stats)
+	f.mu.Unlock()
+}
+
+func (f *HeadFollower) run(ctx context.Context, out chan<- HeadInfo) {
+	backoff := f.cfg.MinBackoff
+	for attempt := 0; ctx.Err() == nil; attempt++ {
+		if attempt > 0 {
+			f.update(func(s *FollowerStats) { s.Resubscribe++ })
+		}
+		healthy, err := f.subscribe(ctx, out)
+		if ctx.Err() != nil {
+			return
+		}
+		if healthy {
+			backoff = f.cfg.MinBackoff
+		}
+		f.update(func(s *FollowerStats) {
+			s.Mode = ModePolling
+			s.Fallbacks++
+			s.LastErr = err
+		})
+		f.poll(ctx, out, backoff)
+		if backoff *= 2; backoff > f.cfg.MaxBackoff {
+			backoff = f.cfg.MaxBackoff
+		}
+	}
+}
+
+// subscribe follows the subscription until it errors or stays silent for
+// HeadTimeout. healthy reports whether it delivered at least one head.
+func (f *HeadFollower) subscribe(ctx context.Context, out chan<- HeadInfo) (healthy bool, err error) {
+	heads := make(chan *types.Header, f.cfg.Buffer)
+	sub, err := f.client.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		return false, fmt.Errorf("subscribe new head: %w", err)
+	}
+	defer sub.Unsubscribe()
+	f.update(func(s *FollowerStats) { s.Mode = ModeSubscription })
+
+	timer := time.NewTimer(f.cfg.HeadTimeout)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return healthy, ctx.Err()
+		case err := <-sub.Err():
+			if err == nil {
+				err = errors.New("subscription closed")
+			}
+			return healthy, fmt.Errorf("subscription: %w", err)
+		case <-timer.C:
+			return healthy, fmt.Errorf("no head for %s", f.cfg.HeadTimeout)
+		case h := <-heads:
+			if h == nil {
+				continue
+			}
+			healthy = true
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(f.cfg.HeadTimeout)
+			f.handle(ctx, out, h)
+		}
+	}
+}
+
+// poll fetches the latest header every PollInterval for the duration d.
+func (f *HeadFollower) poll(ctx context.Context, out chan<- HeadInfo, d time.Duration) {
+	deadline := time.NewTimer(d)
+	defer deadline.Stop()
+	for {
+		h, err := f.client.HeaderByNumber(ctx, nil)
+		switch {
+		case err != nil:
+			f.update(func(s *FollowerStats) { s.LastErr = fmt.Errorf("header by number: %w", err) })
+		case h != nil:
+			f.handle(ctx, out, h)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-deadline.C:
+			return
+		case <-time.After(f.cfg.PollInterval):
+		}
+	}
+}
+
+// handle links h to the emitted chain and emits whatever is new. On failure
+// nothing is emitted; the next head retries from the same point.
+func (f *HeadFollower) handle(ctx context.Context, out chan<- HeadInfo, h *types.Header) {
+	branch, reorg, err := f.connect(ctx, h)
+	if err != nil {
+		f.update(func(s *FollowerStats) { s.LastErr = err })
+		return
+	}
+	for i, head := range branch {
+		info := HeadInfo{
+			Number:     head.Number.Uint64(),
+			Hash:       head.Hash(),
+			ParentHash: head.ParentHash,
+			Reorg:      reorg && i == 0,
+		}
+		select {
+		case out <- info:
+		case <-ctx.Done():
+			return
+		}
+		f.record(head)
+		f.update(func(s *FollowerStats) {
+			s.Emitted++
+			if info.Reorg {
+				s.Reorgs++
+			}
+		})
+	}
+}
+
+// connect returns the headers to emit so that the stream reaches h: nothing
+// for a head already emitted, the missing numbers for a skip, or the new
+// branch from the fork point for a reorg.
+func (f *HeadFollower) connect(ctx context.Context, h *types.Header) ([]*types.Header, bool, error) {
+	if f.last == nil {
+		return []*types.Header{h}, false, nil
+	}
+	number := h.Number.Uint64()
+	if hash, ok := f.hashes[number]; ok && hash == h.Hash() {
+		return nil, false, nil // duplicate, e.g. polling the same head twice
+	}
+
+	branch := []*types.Header{h}
+	for {
+		first := branch[0]
+		n := first.Number.Uint64()
+		if n == 0 {
+			break
+		}
+		if hash, ok := f.hashes[n-1]; ok {
+			if hash == first.ParentHash {
+				break
+			}
+		} else if n-1 < f.last.Number.Uint64() {
+			// Below the remembered window: deeper than MaxDepth. Emit what
+			// we have as a reorg rather than walking back forever.
+			break
+		}
+		parent, err := f.client.HeaderByNumber(ctx, new(big.Int).SetUint64(n-1))
+		if err != nil {
+			return nil, false, fmt.Errorf("header %d: %w", n-1, err)
+		}
+		if parent == nil || parent.Hash() != first.ParentHash {
+			return nil, false, fmt.Errorf("%w: block %d", errBrokenChain, n-1)
+		}
+		branch = append([]*types.Header{parent}, branch...)
+		f.update(func(s *FollowerStats) { s.Filled++ })
+	}
+	// A branch that starts at or below the tip replaces emitted heads.
+	reorg := branch[0].Number.Uint64() <= f.last.Number.Uint64()
+	return branch, reorg, nil
+}
+
+// record makes head the tip of the emitted chain, forgetting any numbers
+// above it (a reorg replaced them) and anything older than MaxDepth.
+func (f *HeadFollower) record(head *types.Header) {
+	number := head.Number.Uint64()
+	if f.last != nil {
+		for n := number + 1; n <= f.last.Number.Uint64(); n++ {
+			delete(f.hashes, n)
+		}
+	}
+	f.hashes[number] = head.Hash()
+	if number >= uint64(f.cfg.MaxDepth) {
+		delete(f.hashes, number-uint64(f.cfg.MaxDepth))
+	}
+	f.last = head
+}
@@ -31,12 +31,39 @@ func Run(ctx context.Context, client HeadClient, cfg Config) (*Result, error) {
 		cfg.PollInterval = defaultPollInterval
 	}
 
+	if cfg.Failover {
+		return followHeads(ctx, client, cfg)
+	}
 	if cfg.PollMode {
 		return pollHeads(ctx, client, cfg)
 	}
 	return subscribeHeads(ctx, client, cfg)
 }
 
+// followHeads collects MaxHeads from a HeadFollower. Unlike the two modes
+// below, a dropped subscription is not an error: the follower polls until it
+// can resubscribe, and fills any numbers it missed in between.
+func followHeads(ctx context.Context, client HeadClient, cfg Config) (*Result, error) {
+	follower, err := NewHeadFollower(client, FollowerConfig{PollInterval: cfg.PollInterval})
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	result := &Result{Heads: make([]HeadInfo, 0, cfg.MaxHeads)}
+	heads := follower.Follow(ctx)
+	for len(result.Heads) < cfg.MaxHeads {
+		head, ok := <-heads
+		if !ok {
+			return nil, fmt.Errorf("context canceled: %w", ctx.Err())
+		}
+		result.Heads = append(result.Heads, head)
+	}
+	result.Mode = follower.Stats().Mode
+	return result, nil
+}
+
 func subscribeHeads(ctx context.Context, client HeadClient, cfg Config) (*Result, error) {
 	headCh := make(chan *types.Header)
 	sub, err := client.SubscribeNewHead(ctx, headCh)
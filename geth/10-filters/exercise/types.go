@@ -21,6 +21,7 @@ type Config struct {
 	MaxHeads     int
 	PollInterval time.Duration
 	PollMode     bool // if true, use HTTP polling; otherwise prefer ws subscription
+	Failover     bool // if true, use a HeadFollower that switches between both
 }
 
 // HeadInfo contains the metadata surfaced to the CLI/tests.
@@ -34,5 +35,5 @@ type HeadInfo struct {
 // Result aggregates collected head info.
 type Result struct {
 	Heads []HeadInfo
-	Mode  string // "subscription" or "polling"
+	Mode  string // "subscription" or "polling" (for Failover, the mode at the end)
 }
@@ -35,9 +35,16 @@ func Run(ctx context.Context, client StorageClient, cfg Config) (*Result, error)
 	// - Check if ctx is nil and provide a default context if needed
 	// - Check if client is nil and return an appropriate error
 	// - Validate that cfg.Contract is not the zero address
-	// - Validate that cfg.Slot is not nil (slot number is required)
+	// - Validate that cfg.Slot is not nil (slot number is required) unless cfg.Path is set
 	// Why validate? Storage reads are expensive RPC calls; fail fast on bad inputs
 
+	// TODO: Resolve named variables when cfg.Path is set
+	// - Build an Explorer with client, cfg.Contract, cfg.Layout and cfg.BlockNumber
+	// - Call explorer.Read(ctx, cfg.Path) and return any error
+	// - Return a Result with ResolvedSlot = v.Location.Slot, Value = v.Raw, Variable = v
+	// - The Slot check above only applies when no Path is given
+	// Why? Paths like "balances[0xabc]" are how auditors think; the layout maps them to slots
+
 	// TODO: Convert slot number to storage hash
 	// - Call slotToHash(cfg.Slot) to convert big.Int slot to common.Hash
 	// - Storage slots are identified by 32-byte hashes, not integers directly
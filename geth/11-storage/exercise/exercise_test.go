@@ -4,9 +4,14 @@ import (
 	"context"
 	"errors"
 	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 type mockStorageClient struct {
@@ -97,3 +102,218 @@ func TestRunErrors(t *testing.T) {
 		t.Fatalf("expected slot validation error")
 	}
 }
+
+// mapStorage serves slots from a map; unset slots read as zero.
+type mapStorage struct {
+	slots map[common.Hash][]byte
+	reads int
+}
+
+func (m *mapStorage) StorageAt(ctx context.Context, contract common.Address, slot common.Hash, blockNumber *big.Int) ([]byte, error) {
+	m.reads++
+	if v, ok := m.slots[slot]; ok {
+		return v, nil
+	}
+	return make([]byte, 32), nil
+}
+
+func (m *mapStorage) set(slot common.Hash, word []byte) {
+	m.slots[slot] = common.LeftPadBytes(word, 32)
+}
+
+func loadLayout(t *testing.T) *StorageLayout {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join("testdata", "vault_layout.json"))
+	if err != nil {
+		t.Fatalf("read layout: %v", err)
+	}
+	layout, err := ParseStorageLayout(raw)
+	if err != nil {
+		t.Fatalf("parse layout: %v", err)
+	}
+	return layout
+}
+
+func slotN(n int64) common.Hash { return common.BigToHash(big.NewInt(n)) }
+
+func addSlot(h common.Hash, n int64) common.Hash {
+	return common.BigToHash(new(big.Int).Add(h.Big(), big.NewInt(n)))
+}
+
+var (
+	vaultOwner = common.HexToAddress("0x00000000000000000000000000000000000a11ce")
+	vaultBob   = common.HexToAddress("0x0000000000000000000000000000000000000b0b")
+	longName   = strings.Repeat("Vault Shares ", 5) // 65 bytes: long form
+)
+
+// vaultStorage lays out a Vault the way solc would, computing every slot by
+// hand so the Explorer is checked against an independent derivation.
+func vaultStorage() *mapStorage {
+	m := &mapStorage{slots: make(map[common.Hash][]byte)}
+
+	// slot 0: delta(int16=-2) | paused(true) | decimals(18) | owner
+	word0 := make([]byte, 32)
+	copy(word0[12:], vaultOwner.Bytes())
+	word0[11] = 18
+	word0[10] = 1
+	word0[8], word0[9] = 0xff, 0xfe
+	m.set(slotN(0), word0)
+
+	// slot 1: long string, length*2+1 in the slot, data at keccak(1)
+	m.set(slotN(1), big.NewInt(int64(len(longName)*2+1)).Bytes())
+	data := crypto.Keccak256Hash(slotN(1).Bytes())
+	for i := 0; i*32 < len(longName); i++ {
+		chunk := []byte(longName[i*32:])
+		if len(chunk) > 32 {
+			chunk = chunk[:32]
+		}
+		m.slots[addSlot(data, int64(i))] = common.RightPadBytes(chunk, 32)
+	}
+
+	// slot 2: short bytes 0xc0ffee, data left-aligned, length*2 in the last byte
+	short := make([]byte, 32)
+	copy(short, []byte{0xc0, 0xff, 0xee})
+	short[31] = 6
+	m.slots[slotN(2)] = short
+
+	// balances[owner] = 1000
+	m.set(crypto.Keccak256Hash(common.LeftPadBytes(vaultOwner.Bytes(), 32), slotN(3).Bytes()), big.NewInt(1000).Bytes())
+
+	// allowances[owner][bob] = 7
+	inner := crypto.Keccak256Hash(common.LeftPadBytes(vaultOwner.Bytes(), 32), slotN(4).Bytes())
+	m.set(crypto.Keccak256Hash(common.LeftPadBytes(vaultBob.Bytes(), 32), inner.Bytes()), big.NewInt(7).Bytes())
+
+	// items: length 2, each Item takes two slots from keccak(5)
+	m.set(slotN(5), []byte{2})
+	items := crypto.Keccak256Hash(slotN(5).Bytes())
+	item1 := make([]byte, 32)
+	copy(item1[12:], vaultBob.Bytes())
+	copy(item1[:12], common.LeftPadBytes(big.NewInt(500).Bytes(), 12))
+	m.slots[addSlot(items, 2)] = item1
+	m.slots[addSlot(items, 3)] = common.RightPadBytes([]byte("gold"), 32)
+
+	// weights = [1, 2, 3] packed into slot 6
+	m.set(slotN(6), []byte{0, 3, 0, 2, 0, 1})
+
+	// byName["alice"].owner = owner
+	alice := crypto.Keccak256Hash([]byte("alice"), slotN(7).Bytes())
+	m.set(alice, vaultOwner.Bytes())
+	return m
+}
+
+func TestExplorerRead(t *testing.T) {
+	storage := vaultStorage()
+	explorer := &Explorer{Client: storage, Contract: common.HexToAddress("0x1"), Layout: loadLayout(t)}
+	items := crypto.Keccak256Hash(slotN(5).Bytes())
+
+	cases := []struct {
+		path string
+		want interface{}
+		slot common.Hash
+	}{
+		{"owner", vaultOwner, slotN(0)},
+		{"decimals", big.NewInt(18), slotN(0)},
+		{"paused", true, slotN(0)},
+		{"delta", big.NewInt(-2), slotN(0)},
+		{"name", longName, slotN(1)},
+		{"blob", []byte{0xc0, 0xff, 0xee}, slotN(2)},
+		{"balances[" + vaultOwner.Hex() + "]", big.NewInt(1000), crypto.Keccak256Hash(common.LeftPadBytes(vaultOwner.Bytes(), 32), slotN(3).Bytes())},
+		{"balances[" + vaultBob.Hex() + "]", big.NewInt(0), crypto.Keccak256Hash(common.LeftPadBytes(vaultBob.Bytes(), 32), slotN(3).Bytes())},
+		{"allowances[" + vaultOwner.Hex() + "][" + vaultBob.Hex() + "]", big.NewInt(7), common.Hash{}},
+		{"items.length", big.NewInt(2), slotN(5)},
+		{"items[1].owner", vaultBob, addSlot(items, 2)},
+		{"items[1].amount", big.NewInt(500), addSlot(items, 2)},
+		{"items[1].tag", common.RightPadBytes([]byte("gold"), 32), addSlot(items, 3)},
+		{"weights[0]", big.NewInt(1), slotN(6)},
+		{"weights[2]", big.NewInt(3), slotN(6)},
+		{`byName["alice"].owner`, vaultOwner, crypto.Keccak256Hash([]byte("alice"), slotN(7).Bytes())},
+	}
+	for _, tc := range cases {
+		v, err := explorer.Read(context.Background(), tc.path)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.path, err)
+		}
+		if !sameValue(v.Value, tc.want) {
+			t.Fatalf("%s = %#v, want %#v", tc.path, v.Value, tc.want)
+		}
+		if tc.slot != (common.Hash{}) && v.Location.Slot != tc.slot {
+			t.Fatalf("%s slot = %s, want %s", tc.path, v.Location.Slot.Hex(), tc.slot.Hex())
+		}
+	}
+}
+
+func sameValue(got, want interface{}) bool {
+	if g, ok := got.(*big.Int); ok {
+		w, ok := want.(*big.Int)
+		return ok && g.Cmp(w) == 0
+	}
+	return reflect.DeepEqual(got, want)
+}
+
+func TestExplorerLocations(t *testing.T) {
+	explorer := &Explorer{Client: vaultStorage(), Contract: common.HexToAddress("0x1"), Layout: loadLayout(t)}
+
+	loc, _, err := explorer.Locate(context.Background(), "items[0].amount")
+	if err != nil {
+		t.Fatalf("locate: %v", err)
+	}
+	if loc.Offset != 20 || loc.Size != 12 {
+		t.Fatalf("items[0].amount at offset %d size %d", loc.Offset, loc.Size)
+	}
+
+	v, err := explorer.Read(context.Background(), "name")
+	if err != nil {
+		t.Fatalf("read name: %v", err)
+	}
+	if len(v.Slots) != 4 { // length slot + 3 data slots for 65 bytes
+		t.Fatalf("name read %d slots, want 4", len(v.Slots))
+	}
+}
+
+func TestExplorerErrors(t *testing.T) {
+	explorer := &Explorer{Client: vaultStorage(), Contract: common.HexToAddress("0x1"), Layout: loadLayout(t)}
+	ctx := context.Background()
+
+	if _, err := explorer.Read(ctx, "missing"); !errors.Is(err, ErrUnknownVariable) {
+		t.Fatalf("expected ErrUnknownVariable, got %v", err)
+	}
+	if _, err := explorer.Read(ctx, "items[0].nope"); !errors.Is(err, ErrUnknownVariable) {
+		t.Fatalf("expected unknown member error, got %v", err)
+	}
+	if _, err := explorer.Read(ctx, "items[2].owner"); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Fatalf("expected ErrIndexOutOfRange, got %v", err)
+	}
+	if _, err := explorer.Read(ctx, "weights[3]"); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Fatalf("expected static ErrIndexOutOfRange, got %v", err)
+	}
+	for _, path := range []string{
+		"balances",                     // mapping has no single value
+		"items[0]",                     // struct has no single value
+		"balances[0x1234]",             // bad address key
+		"byName[alice]",                // unquoted string key
+		"owner[1]",                     // not indexable
+		"balances[" + vaultOwner.Hex(), // unclosed
+	} {
+		if _, err := explorer.Read(ctx, path); err == nil {
+			t.Fatalf("%s: expected error", path)
+		}
+	}
+}
+
+func TestRunPath(t *testing.T) {
+	storage := vaultStorage()
+	res, err := Run(context.Background(), storage, Config{
+		Contract: common.HexToAddress("0x1"),
+		Path:     "balances[" + vaultOwner.Hex() + "]",
+		Layout:   loadLayout(t),
+	})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if res.Variable == nil || res.Variable.Value.(*big.Int).Int64() != 1000 {
+		t.Fatalf("unexpected variable %+v", res.Variable)
+	}
+	if res.ResolvedSlot != crypto.Keccak256Hash(common.LeftPadBytes(vaultOwner.Bytes(), 32), slotN(3).Bytes()) {
+		t.Fatalf("unexpected slot %s", res.ResolvedSlot.Hex())
+	}
+}
@@ -0,0 +1,490 @@
+package exercise
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	// ErrUnknownVariable is returned when a path names a variable or struct
+	// member that is not in the layout.
+	ErrUnknownVariable = errors.New("unknown variable")
+	// ErrIndexOutOfRange is returned when a dynamic array index is not below
+	// the array's current length, or a static array index is past its end.
+	ErrIndexOutOfRange = errors.New("index out of range")
+)
+
+// StorageLayout is the "storageLayout" output of solc
+// (--storage-layout, or outputSelection "storageLayout").
+type StorageLayout struct {
+	Storage []StorageVar           `json:"storage"`
+	Types   map[string]StorageType `json:"types"`
+}
+
+// StorageVar is one state variable (or struct member).
+type StorageVar struct {
+	Label  string `json:"label"`
+	Offset int    `json:"offset"` // byte offset from the right of the slot
+	Slot   string `json:"slot"`   // decimal, relative to the enclosing struct
+	Type   string `json:"type"`   // key into StorageLayout.Types
+}
+
+// StorageType describes a type id such as "t_mapping(t_address,t_uint256)".
+type StorageType struct {
+	Encoding      string       `json:"encoding"` // inplace, mapping, dynamic_array, bytes
+	Label         string       `json:"label"`
+	NumberOfBytes string       `json:"numberOfBytes"`
+	Key           string       `json:"key"`     // mappings
+	Value         string       `json:"value"`   // mappings
+	Base          string       `json:"base"`    // arrays
+	Members       []StorageVar `json:"members"` // structs
+}
+
+// ParseStorageLayout decodes solc's storageLayout JSON.
+func ParseStorageLayout(data []byte) (*StorageLayout, error) {
+	var l StorageLayout
+	if err := json.Unmarshal(data, &l); err != nil {
+		return nil, fmt.Errorf("parse storage layout: %w", err)
+	}
+	if l.Types == nil {
+		return nil, errors.New("parse storage layout: no types")
+	}
+	return &l, nil
+}
+
+// Location is where a resolved path lives: Size bytes at Offset (counted
+// from the right) within Slot.
+type Location struct {
+	Slot   common.Hash
+	Offset int
+	Size   int
+	TypeID string
+	Type   StorageType
+}
+
+// Variable is a decoded storage value.
+//
+// Value holds:
+//
+//	uintN, intN, enum  -> *big.Int
+//	bool               -> bool
+//	address, contract  -> common.Address
+//	bytesN             -> []byte (N bytes)
+//	bytes              -> []byte
+//	string             -> string
+//	T[].length         -> *big.Int
+type Variable struct {
+	Path     string
+	Location Location
+	Slots    []common.Hash // every slot read, in order
+	Raw      []byte        // the 32-byte word at Location.Slot
+	Value    interface{}
+}
+
+// Explorer resolves variable paths against a contract's storage layout and
+// reads them through a StorageClient. Supported paths:
+//
+//	owner                    plain variable (packed or not)
+//	balances[0xabc...]       mapping
+//	allowances[0xa..][0xb..] nested mapping
+//	items[3].owner           dynamic or static array element, struct member
+//	items.length             dynamic array length
+//	names["alice"]           string-keyed mapping
+type Explorer struct {
+	Client      StorageClient
+	Contract    common.Address
+	Layout      *StorageLayout
+	BlockNumber *big.Int
+}
+
+// Read resolves path and decodes its value. Paths must end at a value type,
+// bytes, string or ".length"; mappings, structs and arrays have no single
+// value to decode.
+func (e *Explorer) Read(ctx context.Context, path string) (*Variable, error) {
+	loc, length, err := e.Locate(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := e.word(ctx, loc.Slot)
+	if err != nil {
+		return nil, err
+	}
+	v := &Variable{Path: path, Location: *loc, Slots: []common.Hash{loc.Slot}, Raw: raw}
+
+	if length {
+		v.Value = new(big.Int).SetBytes(raw)
+		return v, nil
+	}
+	switch loc.Type.Encoding {
+	case "bytes":
+		err = e.readBytes(ctx, v)
+	case "inplace":
+		if loc.Type.Members != nil || strings.HasPrefix(loc.TypeID, "t_array(") {
+			return nil, fmt.Errorf("%s is a %s; select a member or element", path, loc.Type.Label)
+		}
+		v.Value, err = decodeValue(loc.TypeID, raw[32-loc.Offset-loc.Size:32-loc.Offset])
+	default:
+		return nil, fmt.Errorf("%s is a %s; select a key or element", path, loc.Type.Label)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return v, nil
+}
+
+// Locate resolves path to a storage location without decoding it. The
+// boolean is true when the path ends in ".length". Dynamic array indexes
+// are checked against the on-chain length, which costs one read each.
+func (e *Explorer) Locate(ctx context.Context, path string) (*Location, bool, error) {
+	if e.Layout == nil {
+		return nil, false, errors.New("storage layout required")
+	}
+	name, steps, err := parsePath(path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var top *StorageVar
+	for i := range e.Layout.Storage {
+		if e.Layout.Storage[i].Label == name {
+			top = &e.Layout.Storage[i]
+			break
+		}
+	}
+	if top == nil {
+		return nil, false, fmt.Errorf("%w: %s", ErrUnknownVariable, name)
+	}
+	loc, err := e.locationOf(*top, new(big.Int))
+	if err != nil {
+		return nil, false, err
+	}
+
+	for i, st := range steps {
+		if st.member == "length" && loc.Type.Encoding == "dynamic_array" {
+			if i != len(steps)-1 {
+				return nil, false, fmt.Errorf("%s: .length must come last", path)
+			}
+			loc.Offset, loc.Size = 0, 32
+			return loc, true, nil
+		}
+		if loc, err = e.step(ctx, loc, st); err != nil {
+			return nil, false, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return loc, false, nil
+}
+
+// pathStep is one "[key]" or ".member" after the variable name.
+type pathStep struct {
+	key    string // raw text inside brackets
+	member string
+}
+
+func parsePath(path string) (string, []pathStep, error) {
+	end := strings.IndexAny(path, "[.")
+	if end < 0 {
+		end = len(path)
+	}
+	name := strings.TrimSpace(path[:end])
+	if name == "" {
+		return "", nil, fmt.Errorf("bad path %q", path)
+	}
+	var steps []pathStep
+	rest := path[end:]
+	for rest != "" {
+		switch rest[0] {
+		case '[':
+			close := closingBracket(rest)
+			if close < 0 {
+				return "", nil, fmt.Errorf("bad path %q: unclosed [", path)
+			}
+			steps = append(steps, pathStep{key: strings.TrimSpace(rest[1:close])})
+			rest = rest[close+1:]
+		case '.':
+			next := strings.IndexAny(rest[1:], "[.")
+			if next < 0 {
+				next = len(rest) - 1
+			}
+			member := rest[1 : next+1]
+			if member == "" {
+				return "", nil, fmt.Errorf("bad path %q: empty member", path)
+			}
+			steps = append(steps, pathStep{member: member})
+			rest = rest[next+1:]
+		default:
+			return "", nil, fmt.Errorf("bad path %q", path)
+		}
+	}
+	return name, steps, nil
+}
+
+// closingBracket finds the ] matching the [ at s[0], skipping quoted keys.
+func closingBracket(s string) int {
+	quoted := false
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			quoted = !quoted
+		case ']':
+			if !quoted {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// locationOf places a variable or member whose slot is relative to base.
+func (e *Explorer) locationOf(v StorageVar, base *big.Int) (*Location, error) {
+	rel, ok := new(big.Int).SetString(v.Slot, 10)
+	if !ok {
+		return nil, fmt.Errorf("%s: bad slot %q", v.Label, v.Slot)
+	}
+	return e.at(v.Type, new(big.Int).Add(base, rel), v.Offset)
+}
+
+func (e *Explorer) at(typeID string, slot *big.Int, offset int) (*Location, error) {
+	t, ok := e.Layout.Types[typeID]
+	if !ok {
+		return nil, fmt.Errorf("type %s missing from layout", typeID)
+	}
+	size, err := strconv.Atoi(t.NumberOfBytes)
+	if err != nil {
+		return nil, fmt.Errorf("type %s: bad numberOfBytes %q", typeID, t.NumberOfBytes)
+	}
+	return &Location{Slot: wordHash(slot), Offset: offset, Size: size, TypeID: typeID, Type: t}, nil
+}
+
+func (e *Explorer) step(ctx context.Context, loc *Location, st pathStep) (*Location, error) {
+	t := loc.Type
+	base := loc.Slot.Big()
+	switch {
+	case st.member != "":
+		if t.Members == nil {
+			return nil, fmt.Errorf("%s has no member %q", t.Label, st.member)
+		}
+		for _, m := range t.Members {
+			if m.Label == st.member {
+				return e.locationOf(m, base)
+			}
+		}
+		return nil, fmt.Errorf("%w: %s.%s", ErrUnknownVariable, t.Label, st.member)
+
+	case t.Encoding == "mapping":
+		key, err := e.mappingKey(t.Key, st.key)
+		if err != nil {
+			return nil, err
+		}
+		slot := crypto.Keccak256Hash(key, loc.Slot.Bytes())
+		return e.at(t.Value, slot.Big(), 0)
+
+	case t.Encoding == "dynamic_array":
+		index, err := parseIndex(st.key)
+		if err != nil {
+			return nil, err
+		}
+		length, err := e.word(ctx, loc.Slot)
+		if err != nil {
+			return nil, err
+		}
+		if index.Cmp(new(big.Int).SetBytes(length)) >= 0 {
+			return nil, fmt.Errorf("%w: %s[%s] (length %s)", ErrIndexOutOfRange, t.Label, index, new(big.Int).SetBytes(length))
+		}
+		data := crypto.Keccak256Hash(loc.Slot.Bytes()).Big()
+		return e.element(t.Base, data, index)
+
+	case strings.HasPrefix(loc.TypeID, "t_array("):
+		index, err := parseIndex(st.key)
+		if err != nil {
+			return nil, err
+		}
+		n, err := staticLength(loc.TypeID)
+		if err != nil {
+			return nil, err
+		}
+		if index.Cmp(big.NewInt(n)) >= 0 {
+			return nil, fmt.Errorf("%w: %s[%s]", ErrIndexOutOfRange, t.Label, index)
+		}
+		return e.element(t.Base, base, index)
+	}
+	return nil, fmt.Errorf("cannot index %s", t.Label)
+}
+
+// element places item index of an array whose data starts at slot start.
+// Items smaller than 17 bytes share slots; larger ones take whole slots.
+func (e *Explorer) element(baseType string, start, index *big.Int) (*Location, error) {
+	elem, err := e.at(baseType, start, 0)
+	if err != nil {
+		return nil, err
+	}
+	if elem.Size <= 16 {
+		perSlot := big.NewInt(int64(32 / elem.Size))
+		slot, pos := new(big.Int).DivMod(index, perSlot, new(big.Int))
+		return e.at(baseType, slot.Add(slot, start), int(pos.Int64())*elem.Size)
+	}
+	slotsPer := big.NewInt(int64((elem.Size + 31) / 32))
+	slot := new(big.Int).Mul(index, slotsPer)
+	return e.at(baseType, slot.Add(slot, start), 0)
+}
+
+// staticLength reads N from a static array id like t_array(t_uint8)3_storage.
+func staticLength(typeID string) (int64, error) {
+	rest := typeID[strings.LastIndex(typeID, ")")+1:]
+	n, err := strconv.ParseInt(strings.TrimSuffix(rest, "_storage"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("cannot read length of %s", typeID)
+	}
+	return n, nil
+}
+
+func parseIndex(s string) (*big.Int, error) {
+	n, ok := new(big.Int).SetString(s, 0)
+	if !ok || n.Sign() < 0 {
+		return nil, fmt.Errorf("bad array index %q", s)
+	}
+	return n, nil
+}
+
+// mappingKey encodes a key the way Solidity hashes it: value types are
+// padded to 32 bytes, string and bytes keys are hashed unpadded.
+func (e *Explorer) mappingKey(typeID, key string) ([]byte, error) {
+	switch {
+	case typeID == "t_string_memory_ptr" || typeID == "t_string_storage" || typeID == "t_string_calldata_ptr":
+		s, err := strconv.Unquote(key)
+		if err != nil {
+			return nil, fmt.Errorf("string key must be quoted: %s", key)
+		}
+		return []byte(s), nil
+	case strings.HasPrefix(typeID, "t_bytes_"):
+		return decodeHexKey(key)
+	case typeID == "t_bool":
+		switch key {
+		case "true":
+			return common.LeftPadBytes([]byte{1}, 32), nil
+		case "false":
+			return make([]byte, 32), nil
+		}
+		return nil, fmt.Errorf("bad bool key %q", key)
+	case strings.HasPrefix(typeID, "t_address") || strings.HasPrefix(typeID, "t_contract("):
+		if !common.IsHexAddress(key) {
+			return nil, fmt.Errorf("bad address key %q", key)
+		}
+		return common.LeftPadBytes(common.HexToAddress(key).Bytes(), 32), nil
+	case strings.HasPrefix(typeID, "t_bytes"):
+		b, err := decodeHexKey(key)
+		if err != nil {
+			return nil, err
+		}
+		n, _ := strconv.Atoi(strings.TrimPrefix(typeID, "t_bytes"))
+		if len(b) > n {
+			return nil, fmt.Errorf("key %s longer than bytes%d", key, n)
+		}
+		return common.RightPadBytes(b, 32), nil
+	case strings.HasPrefix(typeID, "t_uint") || strings.HasPrefix(typeID, "t_int") || strings.HasPrefix(typeID, "t_enum("):
+		n, ok := new(big.Int).SetString(key, 0)
+		if !ok {
+			return nil, fmt.Errorf("bad integer key %q", key)
+		}
+		if n.Sign() < 0 {
+			if !strings.HasPrefix(typeID, "t_int") {
+				return nil, fmt.Errorf("negative key %s for %s", key, typeID)
+			}
+			n.Add(n, new(big.Int).Lsh(big.NewInt(1), 256))
+		}
+		return wordHash(n).Bytes(), nil
+	}
+	return nil, fmt.Errorf("unsupported mapping key type %s", typeID)
+}
+
+func decodeHexKey(key string) ([]byte, error) {
+	if !strings.HasPrefix(key, "0x") {
+		return nil, fmt.Errorf("bytes key must be 0x-prefixed hex: %s", key)
+	}
+	return common.FromHex(key), nil
+}
+
+// readBytes decodes a bytes or string variable. Short values (< 32 bytes)
+// live in the slot itself with length*2 in the lowest byte; long values store
+// length*2+1 in the slot and the data from keccak256(slot) onwards.
+func (e *Explorer) readBytes(ctx context.Context, v *Variable) error {
+	raw := v.Raw
+	var data []byte
+	if raw[31]&1 == 0 {
+		n := int(raw[31]) / 2
+		if n > 31 {
+			return fmt.Errorf("corrupt short %s length %d", v.Location.Type.Label, n)
+		}
+		data = append([]byte(nil), raw[:n]...)
+	} else {
+		n := new(big.Int).Rsh(new(big.Int).SetBytes(raw), 1)
+		// Refuse absurd lengths before issuing one read per 32 bytes.
+		if !n.IsInt64() || n.Int64() > 1<<20 {
+			return fmt.Errorf("%s length %s too large", v.Location.Type.Label, n)
+		}
+		start := crypto.Keccak256Hash(v.Location.Slot.Bytes()).Big()
+		for i := int64(0); i*32 < n.Int64(); i++ {
+			slot := wordHash(new(big.Int).Add(start, big.NewInt(i)))
+			w, err := e.word(ctx, slot)
+			if err != nil {
+				return err
+			}
+			v.Slots = append(v.Slots, slot)
+			data = append(data, w...)
+		}
+		data = data[:n.Int64()]
+	}
+	if v.Location.TypeID == "t_string_storage" {
+		v.Value = string(data)
+	} else {
+		v.Value = data
+	}
+	return nil
+}
+
+// decodeValue interprets the bytes of an inplace value type.
+func decodeValue(typeID string, b []byte) (interface{}, error) {
+	switch {
+	case typeID == "t_bool":
+		return b[len(b)-1] != 0, nil
+	case strings.HasPrefix(typeID, "t_address") || strings.HasPrefix(typeID, "t_contract("):
+		return common.BytesToAddress(b), nil
+	case strings.HasPrefix(typeID, "t_bytes"):
+		return append([]byte(nil), b...), nil
+	case strings.HasPrefix(typeID, "t_uint") || strings.HasPrefix(typeID, "t_enum("):
+		return new(big.Int).SetBytes(b), nil
+	case strings.HasPrefix(typeID, "t_int"):
+		n := new(big.Int).SetBytes(b)
+		if len(b) > 0 && b[0]&0x80 != 0 {
+			n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(8*len(b))))
+		}
+		return n, nil
+	}
+	return nil, fmt.Errorf("cannot decode %s", typeID)
+}
+
+// word reads one slot, left-padded to 32 bytes.
+func (e *Explorer) word(ctx context.Context, slot common.Hash) ([]byte, error) {
+	b, err := e.Client.StorageAt(ctx, e.Contract, slot, e.BlockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("storage at slot %s: %w", slot.Hex(), err)
+	}
+	if len(b) > 32 {
+		return nil, fmt.Errorf("storage at slot %s: %d-byte value", slot.Hex(), len(b))
+	}
+	return common.LeftPadBytes(b, 32), nil
+}
+
+// wordHash reduces n modulo 2^256; slot arithmetic wraps like the EVM's.
+func wordHash(n *big.Int) common.Hash {
+	return common.BigToHash(new(big.Int).And(n, maxSlot))
+}
+
+var maxSlot = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
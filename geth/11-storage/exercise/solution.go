@@ -67,6 +67,32 @@ func Run(ctx context.Context, client StorageClient, cfg Config) (*Result, error)
 		return nil, errors.New("contract address required")
 	}
 
+	// ============================================================================
+	// Named Variables - Resolving Paths Through the Storage Layout
+	// ============================================================================
+	// Raw slot numbers are what the EVM sees; auditors think in variable names.
+	// With solc's storageLayout, the Explorer (layout.go) turns a path like
+	// "allowances[0xa..][0xb..]" or "items[3].owner" into a slot by applying
+	// the same rules we do by hand below, one step at a time:
+	//   - mapping:        keccak256(pad(key) . slot)
+	//   - dynamic array:  keccak256(slot) + index * slotsPerItem
+	//   - struct member:  slot + member.slot
+	//   - packed fields:  a byte offset inside the slot
+	// and then decodes the bytes into a typed value (see Variable).
+	if cfg.Path != "" {
+		explorer := &Explorer{
+			Client:      client,
+			Contract:    cfg.Contract,
+			Layout:      cfg.Layout,
+			BlockNumber: cfg.BlockNumber,
+		}
+		v, err := explorer.Read(ctx, cfg.Path)
+		if err != nil {
+			return nil, err
+		}
+		return &Result{ResolvedSlot: v.Location.Slot, Value: v.Raw, Variable: v}, nil
+	}
+
 	// Slot validation: Storage slots are identified by numbers (0, 1, 2, ...).
 	// A nil slot means the caller didn't specify which slot to read. This is
 	// an error because we can't guess what they want to read.
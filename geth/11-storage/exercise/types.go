@@ -12,16 +12,22 @@ type StorageClient interface {
 	StorageAt(ctx context.Context, contract common.Address, slot common.Hash, blockNumber *big.Int) ([]byte, error)
 }
 
-// Config controls which contract slot to read.
+// Config controls which contract slot to read. Set either Slot (with an
+// optional MappingKey) or Path together with Layout.
 type Config struct {
 	Contract    common.Address
 	Slot        *big.Int
 	MappingKey  []byte
 	BlockNumber *big.Int
+	// Path names a variable, e.g. "balances[0xabc...]" or "items[3].owner",
+	// resolved through Layout (solc's storageLayout output).
+	Path   string
+	Layout *StorageLayout
 }
 
 // Result surfaces the resolved slot hash and raw value bytes.
 type Result struct {
 	ResolvedSlot common.Hash
 	Value        []byte
+	Variable     *Variable // decoded value when Config.Path was used
 }
@@ -66,6 +66,11 @@ func Run(ctx context.Context, client ProofClient, cfg Config) (*Result, error) {
 	//   - Append to the Storage slice
 	// Why process these? Storage proofs prove specific slot values in the contract
 
+	// TODO: Verify the proof when a trusted root is supplied
+	// - If cfg.StateRoot is not the zero hash, call Verify(cfg.StateRoot, cfg.Account, account)
+	// - Wrap and return any error; otherwise set Result.Verified = true
+	// Why verify? Until checked against a root you trust, the proof is only a claim
+
 	// TODO: Return the complete Result
 	// - Create a Result struct with the Account field set to the AccountProof
 	// - Return the result and nil error on success
@@ -7,7 +7,13 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient/gethclient"
+	"github.com/holiman/uint256"
 )
 
 type mockProofClient struct {
@@ -81,3 +87,210 @@ func TestRunErrors(t *testing.T) {
 		t.Fatalf("expected nil client error")
 	}
 }
+
+var (
+	contractAddr = common.HexToAddress("0x000000000000000000000000000000000000c0de")
+	eoaAddr      = common.HexToAddress("0x000000000000000000000000000000000000beef")
+	missingAddr  = common.HexToAddress("0x0000000000000000000000000000000000000404")
+)
+
+// testState builds an in-memory state trie with a contract (code + storage),
+// a plain EOA and enough filler accounts to give the trie some depth.
+func testState(t *testing.T) (state.Database, common.Hash) {
+	t.Helper()
+	db := state.NewDatabase(rawdb.NewMemoryDatabase())
+	sdb, err := state.New(types.EmptyRootHash, db, nil)
+	if err != nil {
+		t.Fatalf("state.New: %v", err)
+	}
+	sdb.SetNonce(contractAddr, 1)
+	sdb.SetBalance(contractAddr, uint256.NewInt(5))
+	sdb.SetCode(contractAddr, []byte{0x60, 0x00, 0x60, 0x00, 0xf3})
+	sdb.SetState(contractAddr, common.HexToHash("0x00"), common.HexToHash("0x2a"))
+	sdb.SetState(contractAddr, common.HexToHash("0x01"), common.HexToHash("0xdeadbeef"))
+	sdb.SetNonce(eoaAddr, 7)
+	sdb.SetBalance(eoaAddr, uint256.NewInt(1_000_000_000_000_000_000))
+	for i := 0; i < 100; i++ {
+		sdb.SetBalance(common.BigToAddress(big.NewInt(int64(1000+i))), uint256.NewInt(uint64(i+1)))
+	}
+	root, err := sdb.Commit(0, false)
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	return db, root
+}
+
+// proofList collects proof nodes in the hex form eth_getProof returns.
+type proofList []string
+
+func (l *proofList) Put(key []byte, value []byte) error {
+	*l = append(*l, hexutil.Encode(value))
+	return nil
+}
+
+func (l *proofList) Delete(key []byte) error { return nil }
+
+// getProof mirrors the eth_getProof handler: it proves the account in the
+// state trie and each slot in the account's storage trie.
+func getProof(t *testing.T, db state.Database, root common.Hash, addr common.Address, slots []string) *gethclient.AccountResult {
+	t.Helper()
+	sdb, err := state.New(root, db, nil)
+	if err != nil {
+		t.Fatalf("state.New: %v", err)
+	}
+	tr, err := db.OpenTrie(root)
+	if err != nil {
+		t.Fatalf("open trie: %v", err)
+	}
+	var accountProof proofList
+	if err := tr.Prove(crypto.Keccak256(addr.Bytes()), &accountProof); err != nil {
+		t.Fatalf("prove account: %v", err)
+	}
+	storageRoot := sdb.GetStorageRoot(addr)
+	res := &gethclient.AccountResult{
+		Address:      addr,
+		AccountProof: accountProof,
+		Balance:      sdb.GetBalance(addr).ToBig(),
+		Nonce:        sdb.GetNonce(addr),
+		CodeHash:     sdb.GetCodeHash(addr),
+		StorageHash:  storageRoot,
+	}
+	if res.CodeHash == (common.Hash{}) {
+		res.CodeHash = types.EmptyCodeHash
+		res.StorageHash = types.EmptyRootHash
+	}
+	for _, s := range slots {
+		key := common.HexToHash(s)
+		sp := gethclient.StorageResult{Key: s, Value: sdb.GetState(addr, key).Big()}
+		if storageRoot != types.EmptyRootHash && storageRoot != (common.Hash{}) {
+			st, err := db.OpenStorageTrie(root, addr, storageRoot, tr)
+			if err != nil {
+				t.Fatalf("open storage trie: %v", err)
+			}
+			var storageProof proofList
+			if err := st.Prove(crypto.Keccak256(key.Bytes()), &storageProof); err != nil {
+				t.Fatalf("prove slot: %v", err)
+			}
+			sp.Proof = storageProof
+		}
+		res.StorageProof = append(res.StorageProof, sp)
+	}
+	return res
+}
+
+func TestRunVerifiesAgainstStateRoot(t *testing.T) {
+	db, root := testState(t)
+	slots := []common.Hash{common.HexToHash("0x00"), common.HexToHash("0x01"), common.HexToHash("0x09")}
+	var slotStrings []string
+	for _, s := range slots {
+		slotStrings = append(slotStrings, s.Hex())
+	}
+	client := &mockProofClient{resp: getProof(t, db, root, contractAddr, slotStrings)}
+
+	res, err := Run(context.Background(), client, Config{Account: contractAddr, Slots: slots, StateRoot: root})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if !res.Verified {
+		t.Fatalf("expected Verified")
+	}
+	if res.Account.Storage[1].Value.Cmp(big.NewInt(0xdeadbeef)) != 0 || res.Account.Storage[2].Value.Sign() != 0 {
+		t.Fatalf("unexpected storage values %+v", res.Account.Storage)
+	}
+
+	client.resp.Balance = big.NewInt(6)
+	if _, err := Run(context.Background(), client, Config{Account: contractAddr, Slots: slots, StateRoot: root}); !errors.Is(err, ErrProofMismatch) {
+		t.Fatalf("expected mismatch from Run, got %v", err)
+	}
+}
+
+func TestVerifyAccount(t *testing.T) {
+	db, root := testState(t)
+	proofFor := func(addr common.Address) AccountProof {
+		r := getProof(t, db, root, addr, nil)
+		return AccountProof{Balance: r.Balance, Nonce: r.Nonce, CodeHash: r.CodeHash, StorageHash: r.StorageHash, ProofNodes: r.AccountProof}
+	}
+
+	if err := VerifyAccount(root, eoaAddr, proofFor(eoaAddr)); err != nil {
+		t.Fatalf("valid EOA proof rejected: %v", err)
+	}
+	if err := VerifyAccount(root, contractAddr, proofFor(contractAddr)); err != nil {
+		t.Fatalf("valid contract proof rejected: %v", err)
+	}
+	// Proof of absence: the path ends before reaching a leaf for the key.
+	if err := VerifyAccount(root, missingAddr, proofFor(missingAddr)); err != nil {
+		t.Fatalf("valid absence proof rejected: %v", err)
+	}
+
+	cases := []struct {
+		name   string
+		addr   common.Address
+		mutate func(p *AccountProof)
+		want   error
+	}{
+		{"nonce", eoaAddr, func(p *AccountProof) { p.Nonce++ }, ErrProofMismatch},
+		{"balance", eoaAddr, func(p *AccountProof) { p.Balance = new(big.Int).Add(p.Balance, big.NewInt(1)) }, ErrProofMismatch},
+		{"storage root", contractAddr, func(p *AccountProof) { p.StorageHash = common.HexToHash("0x01") }, ErrProofMismatch},
+		{"code hash", contractAddr, func(p *AccountProof) { p.CodeHash = types.EmptyCodeHash }, ErrProofMismatch},
+		{"absent account claims balance", missingAddr, func(p *AccountProof) { p.Balance = big.NewInt(1) }, ErrProofMismatch},
+		{"flipped node byte", eoaAddr, func(p *AccountProof) {
+			last := len(p.ProofNodes) - 1
+			raw := hexutil.MustDecode(p.ProofNodes[last])
+			raw[len(raw)-1] ^= 0x01
+			p.ProofNodes[last] = hexutil.Encode(raw)
+		}, ErrInvalidProof},
+		{"missing node", eoaAddr, func(p *AccountProof) { p.ProofNodes = p.ProofNodes[:len(p.ProofNodes)-1] }, ErrInvalidProof},
+		{"not hex", eoaAddr, func(p *AccountProof) { p.ProofNodes[0] = "node1" }, ErrInvalidProof},
+		{"proof for other account", eoaAddr, func(p *AccountProof) { *p = proofFor(contractAddr) }, ErrInvalidProof},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := proofFor(tc.addr)
+			tc.mutate(&p)
+			if err := VerifyAccount(root, tc.addr, p); !errors.Is(err, tc.want) {
+				t.Fatalf("got %v, want %v", err, tc.want)
+			}
+		})
+	}
+
+	if err := VerifyAccount(common.HexToHash("0x1234"), eoaAddr, proofFor(eoaAddr)); !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("untrusted root accepted: %v", err)
+	}
+}
+
+func TestVerifyStorage(t *testing.T) {
+	db, root := testState(t)
+	r := getProof(t, db, root, contractAddr, []string{"0x00", "0x01", "0x05"})
+	account := AccountProof{Balance: r.Balance, Nonce: r.Nonce, CodeHash: r.CodeHash, StorageHash: r.StorageHash, ProofNodes: r.AccountProof}
+	for _, sp := range r.StorageProof {
+		account.Storage = append(account.Storage, StorageProof{Key: common.HexToHash(sp.Key), Value: sp.Value, ProofNodes: sp.Proof})
+	}
+	if err := Verify(root, contractAddr, account); err != nil {
+		t.Fatalf("valid proof rejected: %v", err)
+	}
+
+	tampered := account
+	tampered.Storage = append([]StorageProof(nil), account.Storage...)
+	tampered.Storage[0].Value = big.NewInt(43)
+	if err := Verify(root, contractAddr, tampered); !errors.Is(err, ErrProofMismatch) {
+		t.Fatalf("tampered slot value accepted: %v", err)
+	}
+
+	// Absent slot (0x05) must prove zero; claiming a value fails.
+	tampered.Storage[0] = account.Storage[0]
+	tampered.Storage[2].Value = big.NewInt(1)
+	if err := Verify(root, contractAddr, tampered); !errors.Is(err, ErrProofMismatch) {
+		t.Fatalf("value for absent slot accepted: %v", err)
+	}
+
+	// Storage nodes are only valid under the account's own storage root.
+	if err := VerifyStorage(root, account.Storage[0]); !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("storage proof accepted under state root: %v", err)
+	}
+
+	// Accounts without storage have an empty storage root and empty proofs.
+	eoa := getProof(t, db, root, eoaAddr, []string{"0x00"})
+	if err := VerifyStorage(eoa.StorageHash, StorageProof{Key: common.Hash{}, Value: eoa.StorageProof[0].Value}); err != nil {
+		t.Fatalf("empty storage proof rejected: %v", err)
+	}
+}
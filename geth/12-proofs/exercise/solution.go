@@ -227,7 +227,29 @@ func Run(ctx context.Context, client ProofClient, cfg Config) (*Result, error) {
 	}
 
 	// ============================================================================
-	// STEP 6: Return Complete Result
+	// STEP 6: Verify Against a Trusted State Root (optional)
+	// ============================================================================
+	// Everything above is still just what the RPC node *claims*. If the caller
+	// supplies a state root from a header they already trust (their own light
+	// client, a checkpoint, a second provider), Verify (verify.go) re-derives
+	// every claimed value from the proof nodes:
+	//
+	//   1. Hash each node: keccak(node) is the key its parent uses to point at it
+	//   2. Walk from stateRoot along keccak(address) to the account leaf
+	//   3. RLP-decode [nonce, balance, storageRoot, codeHash] and compare
+	//   4. Walk from that storageRoot along keccak(slot) for each storage proof
+	//
+	// A lying node can't forge this: changing any byte changes a hash, which
+	// breaks the path from the root you trust.
+	if cfg.StateRoot != (common.Hash{}) {
+		if err := Verify(cfg.StateRoot, cfg.Account, res.Account); err != nil {
+			return nil, fmt.Errorf("verify proof: %w", err)
+		}
+		res.Verified = true
+	}
+
+	// ============================================================================
+	// STEP 7: Return Complete Result
 	// ============================================================================
 	// We return a Result containing:
 	//   - Account proof (balance, nonce, hashes, proof nodes)
@@ -249,6 +271,6 @@ func Run(ctx context.Context, client ProofClient, cfg Config) (*Result, error) {
 	//   - Module 01: Read block headers (lightweight state commitment)
 	//   - Module 11: Read storage slots (raw state data)
 	//   - Module 12: Get proofs (cryptographic verification of state)
-	//   - Future: Verify proofs, build light clients, implement bridges
+	//   - Next: Use verified proofs in light clients and bridges
 	return res, nil
 }
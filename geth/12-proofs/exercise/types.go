@@ -18,6 +18,9 @@ type Config struct {
 	Account     common.Address
 	Slots       []common.Hash
 	BlockNumber *big.Int
+	// StateRoot is a trusted state root (e.g. header.Root). When set, Run
+	// verifies the returned proofs against it.
+	StateRoot common.Hash
 }
 
 // StorageProof summarizes the storage slot proof.
@@ -39,5 +42,6 @@ type AccountProof struct {
 
 // Result is returned to callers.
 type Result struct {
-	Account AccountProof
+	Account  AccountProof
+	Verified bool // true when the proof was checked against Config.StateRoot
 }
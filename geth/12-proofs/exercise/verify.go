@@ -0,0 +1,124 @@
+package exercise
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+var (
+	// ErrInvalidProof means the proof nodes do not form a valid path from
+	// the trusted root (missing node, bad hash, malformed RLP).
+	ErrInvalidProof = errors.New("invalid proof")
+	// ErrProofMismatch means the proof is valid but proves a different value
+	// than the one the node claimed.
+	ErrProofMismatch = errors.New("proof does not match claimed value")
+)
+
+// Verify checks the account proof against a trusted state root (for example
+// header.Root from a header you already trust), then checks every storage
+// proof against the proven storage root.
+func Verify(stateRoot common.Hash, account common.Address, proof AccountProof) error {
+	if err := VerifyAccount(stateRoot, account, proof); err != nil {
+		return err
+	}
+	for _, sp := range proof.Storage {
+		if err := VerifyStorage(proof.StorageHash, sp); err != nil {
+			return fmt.Errorf("slot %s: %w", sp.Key.Hex(), err)
+		}
+	}
+	return nil
+}
+
+// VerifyAccount walks proof.ProofNodes from stateRoot along keccak(account)
+// and compares the proven nonce, balance, storage root and code hash with the
+// claimed ones. A proof of absence is accepted only if the claim describes an
+// empty account.
+func VerifyAccount(stateRoot common.Hash, account common.Address, proof AccountProof) error {
+	value, err := walk(stateRoot, crypto.Keccak256(account.Bytes()), proof.ProofNodes)
+	if err != nil {
+		return err
+	}
+
+	proven := types.NewEmptyStateAccount()
+	if value != nil {
+		if err := rlp.DecodeBytes(value, proven); err != nil {
+			return fmt.Errorf("%w: decode account: %v", ErrInvalidProof, err)
+		}
+	}
+
+	claimedBalance := proof.Balance
+	if claimedBalance == nil {
+		claimedBalance = new(big.Int)
+	}
+	switch {
+	case proven.Nonce != proof.Nonce:
+		return fmt.Errorf("%w: nonce proven %d, claimed %d", ErrProofMismatch, proven.Nonce, proof.Nonce)
+	case proven.Balance.ToBig().Cmp(claimedBalance) != 0:
+		return fmt.Errorf("%w: balance proven %s, claimed %s", ErrProofMismatch, proven.Balance.ToBig(), claimedBalance)
+	case proven.Root != proof.StorageHash:
+		return fmt.Errorf("%w: storage root proven %s, claimed %s", ErrProofMismatch, proven.Root.Hex(), proof.StorageHash.Hex())
+	case !bytes.Equal(proven.CodeHash, proof.CodeHash.Bytes()):
+		return fmt.Errorf("%w: code hash proven %x, claimed %s", ErrProofMismatch, proven.CodeHash, proof.CodeHash.Hex())
+	}
+	return nil
+}
+
+// VerifyStorage walks proof.ProofNodes from storageRoot along keccak(slot)
+// and compares the proven value with the claimed one. Absent slots prove 0.
+func VerifyStorage(storageRoot common.Hash, proof StorageProof) error {
+	value, err := walk(storageRoot, crypto.Keccak256(proof.Key.Bytes()), proof.ProofNodes)
+	if err != nil {
+		return err
+	}
+
+	proven := new(big.Int)
+	if value != nil {
+		// Storage leaves hold RLP(value with leading zeros trimmed).
+		var content []byte
+		if err := rlp.DecodeBytes(value, &content); err != nil {
+			return fmt.Errorf("%w: decode slot value: %v", ErrInvalidProof, err)
+		}
+		proven.SetBytes(content)
+	}
+
+	claimed := proof.Value
+	if claimed == nil {
+		claimed = new(big.Int)
+	}
+	if proven.Cmp(claimed) != 0 {
+		return fmt.Errorf("%w: value proven %s, claimed %s", ErrProofMismatch, proven, claimed)
+	}
+	return nil
+}
+
+// walk loads the hex-encoded nodes into a hash-keyed database and resolves
+// key from root. It returns the leaf value, or nil for a proof of absence.
+func walk(root common.Hash, key []byte, nodes []string) ([]byte, error) {
+	// An empty trie has no nodes to prove anything with; every key is absent.
+	if root == types.EmptyRootHash && len(nodes) == 0 {
+		return nil, nil
+	}
+	db := memorydb.New()
+	for i, node := range nodes {
+		raw, err := hexutil.Decode(node)
+		if err != nil {
+			return nil, fmt.Errorf("%w: node %d: %v", ErrInvalidProof, i, err)
+		}
+		db.Put(crypto.Keccak256(raw), raw)
+	}
+	value, err := trie.VerifyProof(root, key, db)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+	return value, nil
+}
@@ -0,0 +1,190 @@
+package exercise
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// RevertReason decodes revert data returned by a failed frame. It understands
+// Solidity's Error(string) and Panic(uint256) encodings.
+func RevertReason(output []byte) (string, error) {
+	return abi.UnpackRevert(output)
+}
+
+// FrameGas is the gas accounting for a single frame.
+type FrameGas struct {
+	Path    string
+	Type    string
+	To      common.Address
+	Depth   int
+	GasUsed uint64 // inclusive: the frame and everything it called
+	SelfGas uint64 // exclusive: GasUsed minus the children's GasUsed
+}
+
+// GasByFrame lists every frame in depth-first order with its inclusive and
+// exclusive gas.
+func GasByFrame(root *CallFrame) []FrameGas {
+	var out []FrameGas
+	root.Walk(func(path string, f *CallFrame) bool {
+		var children uint64
+		for _, c := range f.Calls {
+			children += c.GasUsed
+		}
+		self := uint64(0)
+		if f.GasUsed > children {
+			self = f.GasUsed - children
+		}
+		out = append(out, FrameGas{
+			Path:    path,
+			Type:    f.Type,
+			To:      f.To,
+			Depth:   f.Depth,
+			GasUsed: f.GasUsed,
+			SelfGas: self,
+		})
+		return true
+	})
+	return out
+}
+
+// Revert describes one failed frame.
+type Revert struct {
+	Path   string
+	To     common.Address
+	Error  string
+	Reason string // empty when the output is not a standard revert payload
+}
+
+// Reverts lists every failed frame in depth-first order. The innermost entry
+// is usually where the failure originated; outer frames often just bubble
+// the same data up.
+func Reverts(root *CallFrame) []Revert {
+	var out []Revert
+	root.Walk(func(path string, f *CallFrame) bool {
+		if f.Failed() {
+			out = append(out, Revert{Path: path, To: f.To, Error: f.Error, Reason: f.RevertReason})
+		}
+		return true
+	})
+	return out
+}
+
+// Contracts returns the distinct call targets in the order they were first
+// touched. For DELEGATECALL and CALLCODE this is the code address.
+func Contracts(root *CallFrame) []common.Address {
+	var out []common.Address
+	seen := make(map[common.Address]bool)
+	root.Walk(func(_ string, f *CallFrame) bool {
+		if f.To != (common.Address{}) && !seen[f.To] {
+			seen[f.To] = true
+			out = append(out, f.To)
+		}
+		return true
+	})
+	return out
+}
+
+// Transfer is an ETH movement between two accounts.
+type Transfer struct {
+	Path  string
+	From  common.Address
+	To    common.Address
+	Value *big.Int
+}
+
+// ValueFlow summarizes the ETH that actually moved during a transaction.
+type ValueFlow struct {
+	Transfers []Transfer
+	Total     *big.Int                    // sum of all transfers
+	Net       map[common.Address]*big.Int // received minus sent per account
+}
+
+// ValueFlowOf collects value transfers from the call tree. Frames that failed,
+// or sit below a failed frame, are skipped because their state changes were
+// rolled back. DELEGATECALL only forwards msg.value and moves nothing.
+func ValueFlowOf(root *CallFrame) *ValueFlow {
+	flow := &ValueFlow{Total: new(big.Int), Net: make(map[common.Address]*big.Int)}
+	root.Walk(func(path string, f *CallFrame) bool {
+		if f.Failed() {
+			return false
+		}
+		if f.Type == "DELEGATECALL" || f.Value == nil || f.Value.Sign() == 0 {
+			return true
+		}
+		to := f.To
+		if f.Type == "CALLCODE" {
+			to = f.From
+		}
+		v := new(big.Int).Set(f.Value)
+		flow.Transfers = append(flow.Transfers, Transfer{Path: path, From: f.From, To: to, Value: v})
+		flow.Total.Add(flow.Total, v)
+		flow.add(f.From, new(big.Int).Neg(v))
+		flow.add(to, v)
+		return true
+	})
+	return flow
+}
+
+func (v *ValueFlow) add(addr common.Address, delta *big.Int) {
+	if v.Net[addr] == nil {
+		v.Net[addr] = new(big.Int)
+	}
+	v.Net[addr].Add(v.Net[addr], delta)
+}
+
+// RenderFlame writes an icicle-style flame graph: one line per frame, with a
+// bar whose width is proportional to the frame's gas and which sits inside
+// its parent's bar, so siblings line up left to right like a flame graph
+// turned upside down. width is the number of columns the root bar spans.
+func RenderFlame(w io.Writer, root *CallFrame, width int) error {
+	if width <= 0 {
+		width = 60
+	}
+	total := root.GasUsed
+	if total == 0 {
+		total = 1
+	}
+	var err error
+	var render func(f *CallFrame, start, cols int)
+	render = func(f *CallFrame, start, cols int) {
+		if err != nil {
+			return
+		}
+		bar := strings.Repeat(" ", start) + "[" + strings.Repeat("=", cols) + "]" +
+			strings.Repeat(" ", width-start-cols)
+		label := fmt.Sprintf("%s %s %d (%.1f%%)", f.Type, shortAddr(f.To), f.GasUsed,
+			100*float64(f.GasUsed)/float64(total))
+		if f.Failed() {
+			label += " !" + f.Error
+		}
+		if _, err = fmt.Fprintf(w, "%s %s\n", bar, label); err != nil {
+			return
+		}
+
+		offset := start
+		for _, c := range f.Calls {
+			n := int(uint64(width) * c.GasUsed / total)
+			if offset+n > start+cols {
+				n = start + cols - offset
+			}
+			render(c, offset, n)
+			offset += n
+		}
+	}
+	render(root, 0, width)
+	return err
+}
+
+// shortAddr abbreviates an address as 0x1234…abcd.
+func shortAddr(a common.Address) string {
+	if a == (common.Address{}) {
+		return "-"
+	}
+	h := a.Hex()
+	return h[:6] + "…" + h[len(h)-4:]
+}
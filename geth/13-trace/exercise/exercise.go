@@ -5,10 +5,7 @@ package exercise
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-
-	"github.com/ethereum/go-ethereum/common"
 )
 
 /*
@@ -48,10 +45,16 @@ func Run(ctx context.Context, client TraceClient, cfg Config) (*Result, error) {
 	// - Why? json.RawMessage is []byte (slice), which is a reference type
 	// - Without copying, caller mutations would affect our internal data
 
+	// TODO: Decode the trace into a call tree
+	// - Call DecodeTrace(traceCopy) to get the root frame and detected format
+	// - Wrap and return any decoding error
+	// Why decode? Analyzers (GasByFrame, Reverts, ValueFlowOf) need typed frames
+
 	// TODO: Construct and return the Result
 	// - Create a Result struct with:
 	//   - TxHash: The transaction hash that was traced
 	//   - Trace: The copied trace data
+	//   - Format and Root: The output of DecodeTrace
 	// - Return the result and nil error on success
 
 	return nil, errors.New("not implemented")
@@ -5,6 +5,10 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -48,6 +52,9 @@ func TestRunSuccess(t *testing.T) {
 	if client.hash != hash {
 		t.Fatalf("client saw wrong hash")
 	}
+	if res.Format != FormatCallTracer || res.Root == nil || len(res.Root.Calls) != 1 {
+		t.Fatalf("call tree not decoded: format=%q root=%+v", res.Format, res.Root)
+	}
 }
 
 func TestRunErrors(t *testing.T) {
@@ -60,6 +67,260 @@ func TestRunErrors(t *testing.T) {
 	if _, err := Run(context.Background(), &mockTraceClient{err: errors.New("boom")}, Config{TxHash: common.HexToHash("0x2")}); err == nil {
 		t.Fatalf("expected upstream error")
 	}
+	if _, err := Run(context.Background(), &mockTraceClient{resp: json.RawMessage(`[1,2]`)}, Config{TxHash: common.HexToHash("0x3")}); err == nil {
+		t.Fatalf("expected decode error")
+	}
+}
+
+func loadTrace(t *testing.T, name string) (*CallFrame, string) {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	root, format, err := DecodeTrace(raw)
+	if err != nil {
+		t.Fatalf("decode %s: %v", name, err)
+	}
+	return root, format
+}
+
+func ether(milli int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(milli), big.NewInt(1e15))
+}
+
+func TestDecodeCallTracer(t *testing.T) {
+	root, format := loadTrace(t, "call_tracer_swap.json")
+	if format != FormatCallTracer {
+		t.Fatalf("format = %q", format)
+	}
+	if root.Type != "CALL" || root.GasUsed != 120000 || root.Value.Cmp(ether(1010)) != 0 {
+		t.Fatalf("root = %+v", root)
+	}
+	if len(root.Calls) != 4 || len(root.Calls[2].Calls) != 2 {
+		t.Fatalf("unexpected tree shape")
+	}
+	if d := root.Calls[2].Calls[1]; d.Type != "DELEGATECALL" || d.Depth != 2 || d.Value != nil {
+		t.Fatalf("nested frame = %+v", d)
+	}
+}
+
+func TestDecodeStructLogs(t *testing.T) {
+	root, format := loadTrace(t, "struct_logs_nested.json")
+	if format != FormatStructLogs {
+		t.Fatalf("format = %q", format)
+	}
+	if root.GasUsed != 47305 || root.Gas != 28900 || root.Failed() {
+		t.Fatalf("root = %+v", root)
+	}
+	if len(root.Calls) != 2 {
+		t.Fatalf("calls = %d, want 2", len(root.Calls))
+	}
+
+	call := root.Calls[0]
+	if call.Type != "CALL" || call.To != common.HexToAddress("0xb0b0") || call.Value.Int64() != 100 {
+		t.Fatalf("call = %+v", call)
+	}
+	if call.Gas != 26000 || call.GasUsed != 20003 || call.Failed() {
+		t.Fatalf("call gas = %d/%d err=%q", call.GasUsed, call.Gas, call.Error)
+	}
+
+	static := root.Calls[1]
+	if static.Type != "STATICCALL" || static.To != common.HexToAddress("0xc0c0") || static.Value != nil {
+		t.Fatalf("staticcall = %+v", static)
+	}
+	if static.GasUsed != 3 || static.Error != "execution reverted" {
+		t.Fatalf("staticcall gas=%d err=%q", static.GasUsed, static.Error)
+	}
+}
+
+func TestDecodeStructLogsDelegateCall(t *testing.T) {
+	// A proxy (0xa0a0) delegates to its implementation (0xb0b0), whose code
+	// sends 100 wei to 0xc0c0. The proxy is the sender and pays.
+	root, _ := loadTrace(t, "struct_logs_delegatecall.json")
+	proxy := common.HexToAddress("0xa0a0")
+	impl := common.HexToAddress("0xb0b0")
+	payee := common.HexToAddress("0xc0c0")
+
+	if len(root.Calls) != 1 || len(root.Calls[0].Calls) != 1 || len(root.Calls[0].Calls[0].Calls) != 1 {
+		t.Fatalf("unexpected tree shape")
+	}
+	delegate := root.Calls[0].Calls[0]
+	if delegate.Type != "DELEGATECALL" || delegate.From != proxy || delegate.To != impl {
+		t.Fatalf("delegatecall = %+v", delegate)
+	}
+	send := delegate.Calls[0]
+	if send.Type != "CALL" || send.From != proxy || send.To != payee || send.Value.Int64() != 100 {
+		t.Fatalf("call = %+v", send)
+	}
+
+	flow := ValueFlowOf(root)
+	if len(flow.Transfers) != 1 || flow.Total.Int64() != 100 {
+		t.Fatalf("flow = %+v", flow)
+	}
+	if flow.Net[proxy].Int64() != -100 || flow.Net[payee].Int64() != 100 {
+		t.Fatalf("net = %v", flow.Net)
+	}
+	if _, ok := flow.Net[impl]; ok {
+		t.Fatalf("implementation charged: %v", flow.Net)
+	}
+}
+
+func TestDecodeStructLogsOutOfGas(t *testing.T) {
+	// The child's SSTORE costs more than the gas it has left, which burns
+	// the child's whole allowance instead of underflowing.
+	raw := json.RawMessage(`{"gas":30000,"failed":false,"returnValue":"","structLogs":[
+		{"op":"CALL","gas":10000,"gasCost":5000,"depth":1,"stack":["0x0","0x0","0x0","0x0","0x0","0xb0b0","0x1388"]},
+		{"op":"PUSH1","gas":5000,"gasCost":3,"depth":2,"stack":[]},
+		{"op":"SSTORE","gas":4997,"gasCost":20000,"depth":2,"stack":["0x1","0x0"],"error":"out of gas"},
+		{"op":"POP","gas":5000,"gasCost":2,"depth":1,"stack":["0x0"]}
+	]}`)
+	root, _, err := DecodeTrace(raw)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(root.Calls) != 1 {
+		t.Fatalf("calls = %d, want 1", len(root.Calls))
+	}
+	if call := root.Calls[0]; call.Gas != 5000 || call.GasUsed != 5000 || call.Error != "out of gas" {
+		t.Fatalf("call gas = %d/%d err=%q", call.GasUsed, call.Gas, call.Error)
+	}
+}
+
+func TestDecodeStructLogsBadDepth(t *testing.T) {
+	raw := json.RawMessage(`{"gas":1,"structLogs":[{"op":"STOP","depth":3}]}`)
+	if _, _, err := DecodeTrace(raw); err == nil {
+		t.Fatalf("expected depth error")
+	}
+}
+
+func TestGasByFrame(t *testing.T) {
+	root, _ := loadTrace(t, "call_tracer_swap.json")
+	frames := GasByFrame(root)
+	if len(frames) != 7 {
+		t.Fatalf("frames = %d, want 7", len(frames))
+	}
+	// 120000 - (24000 + 2500 + 60000 + 0)
+	if frames[0].Path != "0" || frames[0].SelfGas != 33500 {
+		t.Fatalf("root frame = %+v", frames[0])
+	}
+	// 60000 - (30000 + 5000)
+	if f := frames[3]; f.Path != "0.2" || f.GasUsed != 60000 || f.SelfGas != 25000 {
+		t.Fatalf("swap frame = %+v", f)
+	}
+	if f := frames[5]; f.Path != "0.2.1" || f.Depth != 2 || f.SelfGas != 5000 {
+		t.Fatalf("delegatecall frame = %+v", f)
+	}
+}
+
+func TestReverts(t *testing.T) {
+	root, _ := loadTrace(t, "call_tracer_revert.json")
+	reverts := Reverts(root)
+	if len(reverts) != 2 {
+		t.Fatalf("reverts = %d, want 2", len(reverts))
+	}
+	inner := reverts[1]
+	if inner.Path != "0.1" || inner.To != common.HexToAddress("0xcccccccccccccccccccccccccccccccccccccccc") {
+		t.Fatalf("inner revert = %+v", inner)
+	}
+	for _, r := range reverts {
+		if r.Reason != "Insufficient balance" || r.Error != "execution reverted" {
+			t.Fatalf("revert = %+v", r)
+		}
+	}
+
+	swap, _ := loadTrace(t, "call_tracer_swap.json")
+	if got := Reverts(swap); len(got) != 0 {
+		t.Fatalf("successful trace reported reverts: %+v", got)
+	}
+}
+
+func TestRevertReason(t *testing.T) {
+	panicData := common.FromHex("0x4e487b71" + strings.Repeat("0", 62) + "11")
+	if got, err := RevertReason(panicData); err != nil || !strings.Contains(got, "overflow") {
+		t.Fatalf("panic reason = %q, %v", got, err)
+	}
+	if _, err := RevertReason([]byte{0x01}); err == nil {
+		t.Fatalf("expected error for short data")
+	}
+}
+
+func TestContracts(t *testing.T) {
+	root, _ := loadTrace(t, "call_tracer_swap.json")
+	want := []string{"0x2222", "0x3333", "0x4444", "0x5555", "0x6666", "0x1111"}
+	got := Contracts(root)
+	if len(got) != len(want) {
+		t.Fatalf("contracts = %v", got)
+	}
+	for i, w := range want {
+		if !strings.HasPrefix(got[i].Hex(), w) {
+			t.Fatalf("contracts[%d] = %s, want %s…", i, got[i].Hex(), w)
+		}
+	}
+}
+
+func TestValueFlow(t *testing.T) {
+	root, _ := loadTrace(t, "call_tracer_swap.json")
+	flow := ValueFlowOf(root)
+	if len(flow.Transfers) != 3 {
+		t.Fatalf("transfers = %d, want 3", len(flow.Transfers))
+	}
+	if flow.Total.Cmp(ether(2020)) != 0 {
+		t.Fatalf("total = %s", flow.Total)
+	}
+	user := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	router := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	weth := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	if flow.Net[user].Cmp(ether(-1000)) != 0 {
+		t.Fatalf("user net = %s", flow.Net[user])
+	}
+	if flow.Net[router].Sign() != 0 {
+		t.Fatalf("router net = %s", flow.Net[router])
+	}
+	if flow.Net[weth].Cmp(ether(1000)) != 0 {
+		t.Fatalf("weth net = %s", flow.Net[weth])
+	}
+
+	// A reverted transaction moves no ETH, even where inner calls succeeded.
+	reverted, _ := loadTrace(t, "call_tracer_revert.json")
+	if flow := ValueFlowOf(reverted); len(flow.Transfers) != 0 || flow.Total.Sign() != 0 {
+		t.Fatalf("reverted flow = %+v", flow)
+	}
+}
+
+func TestRenderFlame(t *testing.T) {
+	root, _ := loadTrace(t, "call_tracer_swap.json")
+	var buf bytes.Buffer
+	if err := RenderFlame(&buf, root, 40); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != 7 {
+		t.Fatalf("lines = %d, want 7:\n%s", len(lines), buf.String())
+	}
+	want := []string{
+		"[========================================] CALL 0x2222…2222 120000 (100.0%)",
+		"[========]                                 CALL 0x3333…3333 24000 (20.0%)",
+		"        []                                 STATICCALL 0x4444…4444 2500 (2.1%)",
+		"        [====================]             CALL 0x4444…4444 60000 (50.0%)",
+		"        [==========]                       CALL 0x5555…5555 30000 (25.0%)",
+		"                  [=]                      DELEGATECALL 0x6666…6666 5000 (4.2%)",
+		"                            []             CALL 0x1111…1111 0 (0.0%)",
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Fatalf("line %d:\n got %q\nwant %q", i, lines[i], want[i])
+		}
+	}
+
+	reverted, _ := loadTrace(t, "call_tracer_revert.json")
+	buf.Reset()
+	if err := RenderFlame(&buf, reverted, 20); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if !strings.Contains(buf.String(), "!execution reverted") {
+		t.Fatalf("revert not marked:\n%s", buf.String())
+	}
 }
 
 func jsonEqual(a, b json.RawMessage) bool {
@@ -146,16 +146,37 @@ func Run(ctx context.Context, client TraceClient, cfg Config) (*Result, error) {
 	copy(traceCopy, raw)
 
 	// ============================================================================
-	// STEP 4: Return Result
+	// STEP 4: Decode Into a Call Tree
+	// ============================================================================
+	// Raw JSON is hard to reason about. DecodeTrace (trace.go) turns either of
+	// the two common formats into the same *CallFrame tree:
+	//
+	//   - callTracer: already a tree of {type, from, to, gas, calls: [...]}
+	//   - structLogs: a flat list of opcode steps; we rebuild frames by watching
+	//     the depth field rise after CALL/STATICCALL/... and fall on return
+	//
+	// With a tree in hand, the analyzers in analyze.go answer the questions
+	// people actually ask of a trace: where did the gas go (GasByFrame), why did
+	// it revert (Reverts), what did it touch (Contracts), where did the ETH go
+	// (ValueFlowOf), and RenderFlame draws it all as a text flame graph.
+	root, format, err := DecodeTrace(traceCopy)
+	if err != nil {
+		return nil, fmt.Errorf("decode trace: %w", err)
+	}
+
+	// ============================================================================
+	// STEP 5: Return Result
 	// ============================================================================
 	// We return both the transaction hash and the trace data. Why both?
 	//
 	// TxHash: Confirms which transaction was traced. In batch processing scenarios,
 	// this helps callers associate traces with transactions without manual bookkeeping.
 	//
-	// Trace: The raw JSON trace data. We return json.RawMessage (not parsed structs)
-	// because trace format varies by tracer type and Geth version. By returning raw
-	// JSON, callers can parse it according to their needs:
+	// Root/Format: The decoded call tree and which format it came from.
+	//
+	// Trace: The raw JSON trace data. We still return json.RawMessage alongside
+	// the tree because trace format varies by tracer type and Geth version, and
+	// the tree only keeps what the analyzers need. Callers can parse the rest:
 	//   - Default tracer: Full opcode-level trace
 	//   - callTracer: Simplified call tree
 	//   - prestateTracer: Account state before transaction
@@ -181,5 +202,7 @@ func Run(ctx context.Context, client TraceClient, cfg Config) (*Result, error) {
 	return &Result{
 		TxHash: cfg.TxHash,
 		Trace:  traceCopy,
+		Format: format,
+		Root:   root,
 	}, nil
 }
@@ -0,0 +1,267 @@
+package exercise
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Trace formats understood by DecodeTrace.
+const (
+	FormatCallTracer = "callTracer" // {"tracer":"callTracer"} call tree
+	FormatStructLogs = "structLogs" // default opcode logger
+)
+
+// CallFrame is one message call in a transaction's call tree. The root frame
+// is the transaction itself.
+type CallFrame struct {
+	Type         string // CALL, STATICCALL, DELEGATECALL, CALLCODE, CREATE, CREATE2, SELFDESTRUCT
+	From         common.Address
+	To           common.Address
+	Value        *big.Int // nil when the frame carries no value field
+	Gas          uint64   // gas available to the frame
+	GasUsed      uint64   // gas used by the frame including its children
+	Input        []byte
+	Output       []byte
+	Error        string // non-empty if the frame failed
+	RevertReason string // decoded from Output when the frame reverted
+	Depth        int    // 0 for the root frame
+	Calls        []*CallFrame
+}
+
+// Failed reports whether the frame itself errored.
+func (f *CallFrame) Failed() bool { return f.Error != "" }
+
+// Walk visits f and its descendants depth-first. path is the index path from
+// the root, e.g. "0.2.1". Returning false skips the frame's children.
+func (f *CallFrame) Walk(visit func(path string, frame *CallFrame) bool) {
+	f.walk("0", visit)
+}
+
+func (f *CallFrame) walk(path string, visit func(string, *CallFrame) bool) {
+	if !visit(path, f) {
+		return
+	}
+	for i, c := range f.Calls {
+		c.walk(fmt.Sprintf("%s.%d", path, i), visit)
+	}
+}
+
+// DecodeTrace decodes a debug_traceTransaction payload in either the
+// callTracer or the structLogs format into a call tree. It returns the format
+// that was detected.
+func DecodeTrace(raw json.RawMessage) (*CallFrame, string, error) {
+	var probe struct {
+		StructLogs json.RawMessage `json:"structLogs"`
+	}
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return nil, "", fmt.Errorf("decode trace: %w", err)
+	}
+	if probe.StructLogs != nil {
+		root, err := decodeStructLogs(raw)
+		return root, FormatStructLogs, err
+	}
+	root, err := decodeCallTracer(raw)
+	return root, FormatCallTracer, err
+}
+
+// callTracerFrame mirrors the JSON emitted by geth's callTracer.
+type callTracerFrame struct {
+	Type         string             `json:"type"`
+	From         common.Address     `json:"from"`
+	To           common.Address     `json:"to"`
+	Value        *hexutil.Big       `json:"value"`
+	Gas          hexutil.Uint64     `json:"gas"`
+	GasUsed      hexutil.Uint64     `json:"gasUsed"`
+	Input        hexutil.Bytes      `json:"input"`
+	Output       hexutil.Bytes      `json:"output"`
+	Error        string             `json:"error"`
+	RevertReason string             `json:"revertReason"`
+	Calls        []*callTracerFrame `json:"calls"`
+}
+
+func decodeCallTracer(raw json.RawMessage) (*CallFrame, error) {
+	var top callTracerFrame
+	if err := json.Unmarshal(raw, &top); err != nil {
+		return nil, fmt.Errorf("decode call trace: %w", err)
+	}
+	return top.convert(0), nil
+}
+
+func (c *callTracerFrame) convert(depth int) *CallFrame {
+	f := &CallFrame{
+		Type:         c.Type,
+		From:         c.From,
+		To:           c.To,
+		Gas:          uint64(c.Gas),
+		GasUsed:      uint64(c.GasUsed),
+		Input:        c.Input,
+		Output:       c.Output,
+		Error:        c.Error,
+		RevertReason: c.RevertReason,
+		Depth:        depth,
+	}
+	if c.Value != nil {
+		f.Value = c.Value.ToInt()
+	}
+	if f.Failed() && f.RevertReason == "" {
+		f.RevertReason, _ = RevertReason(f.Output)
+	}
+	for _, child := range c.Calls {
+		f.Calls = append(f.Calls, child.convert(depth+1))
+	}
+	return f
+}
+
+// structLog is one opcode step of the default tracer.
+type structLog struct {
+	Op      string   `json:"op"`
+	Gas     uint64   `json:"gas"`
+	GasCost uint64   `json:"gasCost"`
+	Depth   int      `json:"depth"`
+	Stack   []string `json:"stack"`
+	Error   string   `json:"error"`
+}
+
+// decodeStructLogs rebuilds the call tree from opcode steps. A call opcode at
+// depth d followed by a step at depth d+1 opens a child frame; the child's
+// target and value come from the caller's stack. Struct logs do not carry the
+// transaction's own from/to, so the root frame leaves them zero.
+func decodeStructLogs(raw json.RawMessage) (*CallFrame, error) {
+	var trace struct {
+		Gas         uint64      `json:"gas"`
+		Failed      bool        `json:"failed"`
+		ReturnValue string      `json:"returnValue"`
+		StructLogs  []structLog `json:"structLogs"`
+	}
+	if err := json.Unmarshal(raw, &trace); err != nil {
+		return nil, fmt.Errorf("decode struct logs: %w", err)
+	}
+
+	root := &CallFrame{Type: "CALL", GasUsed: trace.Gas}
+	if len(trace.StructLogs) > 0 {
+		root.Gas = trace.StructLogs[0].Gas
+	}
+	if out, err := hexutil.Decode(ensure0x(trace.ReturnValue)); err == nil {
+		root.Output = out
+	}
+	if trace.Failed {
+		root.Error = "execution reverted"
+		root.RevertReason, _ = RevertReason(root.Output)
+	}
+
+	stack := []*CallFrame{root}
+	logs := trace.StructLogs
+	for i, step := range logs {
+		if step.Depth < 1 || step.Depth > len(stack) {
+			return nil, fmt.Errorf("decode struct logs: step %d jumps to depth %d", i, step.Depth)
+		}
+		// Returning to a shallower depth closes the finished frames.
+		stack = stack[:step.Depth]
+		frame := stack[len(stack)-1]
+
+		if step.Error != "" {
+			frame.Error = step.Error
+		}
+		if step.Depth > 1 && (i+1 == len(logs) || logs[i+1].Depth < step.Depth) {
+			// Final step of a child frame: everything not left over was used.
+			// A failing step (out of gas, invalid opcode, ...) burns the rest,
+			// and its gasCost can exceed the gas it had.
+			var left uint64
+			if step.GasCost <= step.Gas && (step.Error == "" || step.Op == "REVERT") {
+				left = step.Gas - step.GasCost
+			}
+			frame.GasUsed = frame.Gas - min(left, frame.Gas)
+			if step.Op == "REVERT" && frame.Error == "" {
+				frame.Error = "execution reverted"
+			}
+		}
+		if !isCallOp(step.Op) || i+1 == len(logs) {
+			continue
+		}
+
+		child, err := frameFromStack(step)
+		if err != nil {
+			return nil, fmt.Errorf("decode struct logs: step %d: %w", i, err)
+		}
+		// DELEGATECALL and CALLCODE run the target's code in the caller's
+		// context, so calls made from inside them are sent by that context.
+		child.From = frame.To
+		if frame.Type == "DELEGATECALL" || frame.Type == "CALLCODE" {
+			child.From = frame.From
+		}
+		child.Depth = step.Depth
+		frame.Calls = append(frame.Calls, child)
+
+		next := logs[i+1]
+		if next.Depth > step.Depth {
+			child.Gas = next.Gas
+			stack = append(stack, child)
+			continue
+		}
+		// No code ran (EOA or precompile target) or the call failed before
+		// entering; the success flag is on top of the caller's next stack.
+		if n := len(next.Stack); n > 0 && isZeroWord(next.Stack[n-1]) {
+			child.Error = "call failed"
+		}
+	}
+	return root, nil
+}
+
+func isCallOp(op string) bool {
+	switch op {
+	case "CALL", "CALLCODE", "DELEGATECALL", "STATICCALL", "CREATE", "CREATE2":
+		return true
+	}
+	return false
+}
+
+// frameFromStack reads the call target and value from the caller's stack
+// (the last element is the top of the stack).
+func frameFromStack(step structLog) (*CallFrame, error) {
+	arg := func(n int) (*big.Int, error) {
+		if n >= len(step.Stack) {
+			return nil, errors.New("stack too short for " + step.Op)
+		}
+		v, ok := new(big.Int).SetString(strings.TrimPrefix(step.Stack[len(step.Stack)-1-n], "0x"), 16)
+		if !ok {
+			return nil, fmt.Errorf("bad stack value %q", step.Stack[len(step.Stack)-1-n])
+		}
+		return v, nil
+	}
+
+	f := &CallFrame{Type: step.Op}
+	var err error
+	switch step.Op {
+	case "CALL", "CALLCODE": // gas, addr, value, ...
+		var addr *big.Int
+		if addr, err = arg(1); err == nil {
+			f.To = common.BigToAddress(addr)
+			f.Value, err = arg(2)
+		}
+	case "DELEGATECALL", "STATICCALL": // gas, addr, ...
+		var addr *big.Int
+		if addr, err = arg(1); err == nil {
+			f.To = common.BigToAddress(addr)
+		}
+	case "CREATE", "CREATE2": // value, offset, size; the new address is not in the log
+		f.Value, err = arg(0)
+	}
+	return f, err
+}
+
+func isZeroWord(s string) bool {
+	return strings.TrimLeft(strings.TrimPrefix(s, "0x"), "0") == ""
+}
+
+func ensure0x(s string) string {
+	if strings.HasPrefix(s, "0x") {
+		return s
+	}
+	return "0x" + s
+}
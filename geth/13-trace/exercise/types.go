@@ -19,9 +19,12 @@ type Config struct {
 	TxHash common.Hash
 }
 
-// Result contains the raw trace payload from the node. We intentionally
-// keep it as JSON so callers can pretty-print or further decode steps.
+// Result contains the raw trace payload from the node plus the call tree
+// decoded from it. Trace is kept so callers can still pretty-print it or
+// decode fields the call tree does not carry.
 type Result struct {
 	TxHash common.Hash
 	Trace  json.RawMessage
+	Format string     // FormatCallTracer or FormatStructLogs
+	Root   *CallFrame // the transaction's top-level call
 }
@@ -0,0 +1,51 @@
+// Command explorer serves a small local block explorer for an RPC node.
+//
+// Usage:
+//
+//	go run ./14-explorer/cmd/explorer -rpc $INFURA_RPC_URL -addr 127.0.0.1:8080
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	explorer "geth-edu/14-explorer/exercise"
+)
+
+func main() {
+	rpcURL := flag.String("rpc", os.Getenv("INFURA_RPC_URL"), "RPC endpoint (default $INFURA_RPC_URL)")
+	addr := flag.String("addr", "127.0.0.1:8080", "listen address")
+	latest := flag.Int("latest", 10, "blocks on the home page")
+	cache := flag.Int("cache", 256, "finalized blocks kept in memory")
+	flag.Parse()
+
+	if *rpcURL == "" {
+		log.Fatal("no RPC endpoint: pass -rpc or set INFURA_RPC_URL")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	client, err := ethclient.DialContext(ctx, *rpcURL)
+	cancel()
+	if err != nil {
+		log.Fatalf("dial %s: %v", *rpcURL, err)
+	}
+	defer client.Close()
+
+	ex, err := explorer.NewExplorer(client, explorer.ExplorerConfig{LatestCount: *latest, CacheSize: *cache})
+	if err != nil {
+		log.Fatal(err)
+	}
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           ex.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      30 * time.Second,
+	}
+	log.Printf("explorer listening on http://%s", *addr)
+	log.Fatal(srv.ListenAndServe())
+}
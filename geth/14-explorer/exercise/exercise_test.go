@@ -1,7 +1,473 @@
 package exercise
 
-import "testing"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var _ ExplorerClient = (*ethclient.Client)(nil)
+
+// mockChain is a canonical chain of blocks plus one side-chain block, with
+// call counters so tests can see what the cache saved.
+type mockChain struct {
+	mu        sync.Mutex
+	blocks    []*types.Block
+	side      *types.Block
+	finalized int64 // -1: node has no finalized block
+	txs       map[common.Hash]*types.Transaction
+	pending   map[common.Hash]bool
+	balances  map[common.Address]*big.Int
+	code      map[common.Address][]byte
+	err       error
+
+	blockCalls     int
+	finalizedCalls int
+}
+
+var testKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+
+func newMockChain(t *testing.T, length int) *mockChain {
+	t.Helper()
+	m := &mockChain{
+		finalized: -1,
+		txs:       make(map[common.Hash]*types.Transaction),
+		pending:   make(map[common.Hash]bool),
+		balances:  make(map[common.Address]*big.Int),
+		code:      make(map[common.Address][]byte),
+	}
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	parent := common.Hash{}
+	for i := 0; i < length; i++ {
+		to := common.HexToAddress("0xbeef")
+		tx, err := types.SignNewTx(testKey, signer, &types.DynamicFeeTx{
+			ChainID: big.NewInt(1), Nonce: uint64(i), GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(10),
+			Gas: 21000, To: &to, Value: big.NewInt(int64(i) + 1),
+		})
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		header := &types.Header{
+			Number: big.NewInt(int64(i)), ParentHash: parent, Time: uint64(1700000000 + 12*i),
+			GasLimit: 30_000_000, GasUsed: 21000, BaseFee: big.NewInt(7),
+		}
+		block := types.NewBlockWithHeader(header).WithBody([]*types.Transaction{tx}, nil)
+		m.blocks = append(m.blocks, block)
+		m.txs[tx.Hash()] = tx
+		parent = block.Hash()
+	}
+	m.side = types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), ParentHash: m.blocks[0].Hash(), Extra: []byte("uncle")})
+	return m
+}
+
+func (m *mockChain) fail() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.err
+}
+
+func (m *mockChain) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	if err := m.fail(); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.blockCalls++
+	if number == nil {
+		return m.blocks[len(m.blocks)-1], nil
+	}
+	if !number.IsUint64() || number.Uint64() >= uint64(len(m.blocks)) {
+		return nil, ethereum.NotFound
+	}
+	return m.blocks[number.Uint64()], nil
+}
+
+func (m *mockChain) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	if err := m.fail(); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.blockCalls++
+	if m.side.Hash() == hash {
+		return m.side, nil
+	}
+	for _, b := range m.blocks {
+		if b.Hash() == hash {
+			return b, nil
+		}
+	}
+	return nil, ethereum.NotFound
+}
+
+func (m *mockChain) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if err := m.fail(); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch {
+	case number == nil:
+		return m.blocks[len(m.blocks)-1].Header(), nil
+	case number.Int64() == int64(rpc.FinalizedBlockNumber):
+		m.finalizedCalls++
+		if m.finalized < 0 {
+			return nil, errors.New("finalized block not found")
+		}
+		return m.blocks[m.finalized].Header(), nil
+	}
+	return m.blocks[number.Uint64()].Header(), nil
+}
+
+func (m *mockChain) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	if err := m.fail(); err != nil {
+		return nil, false, err
+	}
+	tx, ok := m.txs[hash]
+	if !ok {
+		return nil, false, ethereum.NotFound
+	}
+	return tx, m.pending[hash], nil
+}
+
+func (m *mockChain) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	for _, b := range m.blocks {
+		for i, tx := range b.Transactions() {
+			if tx.Hash() == hash && !m.pending[hash] {
+				return &types.Receipt{
+					Status: types.ReceiptStatusSuccessful, TxHash: hash, BlockNumber: b.Number(),
+					GasUsed: 21000, CumulativeGasUsed: 21000 * uint64(i+1),
+				}, nil
+			}
+		}
+	}
+	return nil, ethereum.NotFound
+}
+
+func (m *mockChain) BalanceAt(ctx context.Context, addr common.Address, block *big.Int) (*big.Int, error) {
+	if b, ok := m.balances[addr]; ok {
+		return b, nil
+	}
+	return new(big.Int), nil
+}
+
+func (m *mockChain) NonceAt(ctx context.Context, addr common.Address, block *big.Int) (uint64, error) {
+	return 3, nil
+}
+
+func (m *mockChain) CodeAt(ctx context.Context, addr common.Address, block *big.Int) ([]byte, error) {
+	return m.code[addr], nil
+}
 
 func TestRun(t *testing.T) {
-	t.Skip("add tests for this module")
+	chain := newMockChain(t, 3)
+	res, err := Run(context.Background(), chain, Config{Number: big.NewInt(1), IncludeTxs: true})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	want := chain.blocks[1]
+	if res.Number != 1 || res.Hash != want.Hash() || res.Parent != chain.blocks[0].Hash() || res.TxCount != 1 {
+		t.Fatalf("result %+v", res)
+	}
+	if len(res.Txs) != 1 || res.Txs[0].Hash != want.Transactions()[0].Hash() || res.Txs[0].Gas != 21000 {
+		t.Fatalf("txs %+v", res.Txs)
+	}
+
+	res, err = Run(context.Background(), chain, Config{})
+	if err != nil || res.Number != 2 || res.Txs != nil {
+		t.Fatalf("latest without txs: %+v err=%v", res, err)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	if _, err := Run(context.Background(), nil, Config{}); err == nil {
+		t.Fatalf("expected nil client error")
+	}
+	chain := newMockChain(t, 1)
+	chain.err = errors.New("boom")
+	if _, err := Run(context.Background(), chain, Config{Number: big.NewInt(9)}); err == nil || !strings.Contains(err.Error(), "fetch block 9") {
+		t.Fatalf("expected wrapped upstream error, got %v", err)
+	}
+}
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newLRU[int, string](2)
+	c.Add(1, "a")
+	c.Add(2, "b")
+	c.Get(1) // 2 is now the oldest
+	c.Add(3, "c")
+	if _, ok := c.Get(2); ok {
+		t.Fatalf("2 should have been evicted")
+	}
+	if v, ok := c.Get(1); !ok || v != "a" {
+		t.Fatalf("1 = %q %v", v, ok)
+	}
+	if c.Len() != 2 {
+		t.Fatalf("len %d", c.Len())
+	}
+}
+
+func TestExplorerCachesOnlyFinalizedBlocks(t *testing.T) {
+	ctx := context.Background()
+	chain := newMockChain(t, 10)
+	chain.finalized = 5
+	ex, err := NewExplorer(chain, ExplorerConfig{})
+	if err != nil {
+		t.Fatalf("NewExplorer: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		v, err := ex.Block(ctx, big.NewInt(4))
+		if err != nil || v.Number != 4 || !v.Finalized {
+			t.Fatalf("block 4: %+v err=%v", v, err)
+		}
+	}
+	if chain.blockCalls != 1 {
+		t.Fatalf("finalized block fetched %d times, want 1", chain.blockCalls)
+	}
+
+	chain.blockCalls = 0
+	for i := 0; i < 3; i++ {
+		v, err := ex.Block(ctx, big.NewInt(8))
+		if err != nil || v.Finalized {
+			t.Fatalf("block 8: %+v err=%v", v, err)
+		}
+	}
+	if chain.blockCalls != 3 || ex.CachedBlocks() != 1 {
+		t.Fatalf("unfinalized block: %d fetches, %d cached", chain.blockCalls, ex.CachedBlocks())
+	}
+
+	// Finality advances: block 8 becomes cacheable.
+	chain.finalized = 9
+	ex.Block(ctx, big.NewInt(8))
+	chain.blockCalls = 0
+	ex.Block(ctx, big.NewInt(8))
+	if v, _ := ex.BlockByHash(ctx, chain.blocks[8].Hash()); chain.blockCalls != 0 || !v.Finalized {
+		t.Fatalf("block 8 after finality: %d fetches", chain.blockCalls)
+	}
+}
+
+func TestExplorerSideChainBlockNotIndexedByNumber(t *testing.T) {
+	ctx := context.Background()
+	chain := newMockChain(t, 4)
+	chain.finalized = 3
+	ex, _ := NewExplorer(chain, ExplorerConfig{})
+
+	side, err := ex.BlockByHash(ctx, chain.side.Hash())
+	if err != nil || side.Hash != chain.side.Hash() {
+		t.Fatalf("side block: %+v err=%v", side, err)
+	}
+	canon, err := ex.Block(ctx, big.NewInt(1))
+	if err != nil || canon.Hash != chain.blocks[1].Hash() {
+		t.Fatalf("block 1 served %v, want canonical %v (err=%v)", canon.Hash, chain.blocks[1].Hash(), err)
+	}
+}
+
+func TestExplorerCacheSizeAndNoFinality(t *testing.T) {
+	ctx := context.Background()
+	chain := newMockChain(t, 10)
+	chain.finalized = 9
+	ex, _ := NewExplorer(chain, ExplorerConfig{CacheSize: 2})
+	for _, n := range []int64{1, 2, 3} {
+		ex.Block(ctx, big.NewInt(n))
+	}
+	chain.blockCalls = 0
+	ex.Block(ctx, big.NewInt(1)) // evicted
+	ex.Block(ctx, big.NewInt(3)) // still cached
+	if chain.blockCalls != 1 || ex.CachedBlocks() != 2 {
+		t.Fatalf("after eviction: %d fetches, %d cached", chain.blockCalls, ex.CachedBlocks())
+	}
+
+	// Without a finalized block only genesis is known to be immutable.
+	chain = newMockChain(t, 10)
+	ex, _ = NewExplorer(chain, ExplorerConfig{})
+	ex.Block(ctx, big.NewInt(5))
+	ex.Block(ctx, big.NewInt(5))
+	if chain.blockCalls != 2 || ex.CachedBlocks() != 0 {
+		t.Fatalf("no finality: %d fetches, %d cached", chain.blockCalls, ex.CachedBlocks())
+	}
+}
+
+func TestExplorerLatestBlocks(t *testing.T) {
+	chain := newMockChain(t, 4)
+	chain.finalized = 1
+	ex, _ := NewExplorer(chain, ExplorerConfig{LatestCount: 10})
+	views, err := ex.LatestBlocks(context.Background())
+	if err != nil {
+		t.Fatalf("LatestBlocks: %v", err)
+	}
+	if len(views) != 4 || views[0].Number != 3 || views[3].Number != 0 {
+		t.Fatalf("got %d blocks starting at %d", len(views), views[0].Number)
+	}
+	if views[1].Finalized || !views[2].Finalized {
+		t.Fatalf("finality flags wrong: %v %v", views[1].Finalized, views[2].Finalized)
+	}
+	if chain.finalizedCalls != 1 {
+		t.Fatalf("finalized queried %d times for one page", chain.finalizedCalls)
+	}
+}
+
+func TestExplorerTransactionAndAccount(t *testing.T) {
+	ctx := context.Background()
+	chain := newMockChain(t, 3)
+	ex, _ := NewExplorer(chain, ExplorerConfig{})
+	tx := chain.blocks[2].Transactions()[0]
+
+	v, err := ex.Transaction(ctx, tx.Hash())
+	if err != nil {
+		t.Fatalf("Transaction: %v", err)
+	}
+	if v.From != crypto.PubkeyToAddress(testKey.PublicKey) || v.Value.Int64() != 3 || v.Pending {
+		t.Fatalf("tx view %+v", v)
+	}
+	if v.Receipt == nil || !v.Receipt.StatusOK || v.Receipt.BlockNumber.Int64() != 2 {
+		t.Fatalf("receipt %+v", v.Receipt)
+	}
+
+	chain.pending[tx.Hash()] = true
+	if v, err := ex.Transaction(ctx, tx.Hash()); err != nil || !v.Pending || v.Receipt != nil {
+		t.Fatalf("pending tx: %+v err=%v", v, err)
+	}
+
+	contract := common.HexToAddress("0xc0de")
+	chain.balances[contract] = big.NewInt(5e17)
+	chain.code[contract] = []byte{0x60, 0x00}
+	a, err := ex.Account(ctx, contract)
+	if err != nil || !a.Contract() || a.CodeSize != 2 || a.Balance.Cmp(big.NewInt(5e17)) != 0 || a.BlockNumber != 2 {
+		t.Fatalf("account %+v err=%v", a, err)
+	}
+}
+
+func TestExplorerReceiptView(t *testing.T) {
+	ex, _ := NewExplorer(newMockChain(t, 1), ExplorerConfig{})
+	from, to := common.HexToAddress("0xa11ce"), common.HexToAddress("0xb0b")
+	transfer := &types.Log{
+		Address: common.HexToAddress("0x7e57"),
+		Topics: []common.Hash{
+			crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)")),
+			common.BytesToHash(from.Bytes()),
+			common.BytesToHash(to.Bytes()),
+		},
+		Data: common.LeftPadBytes(big.NewInt(5).Bytes(), 32),
+	}
+	unknown := &types.Log{Topics: []common.Hash{{0x01}}, Index: 1}
+	v := ex.receiptView(&types.Receipt{
+		Status: types.ReceiptStatusSuccessful, BlockNumber: big.NewInt(7),
+		GasUsed: 50_000, EffectiveGasPrice: big.NewInt(2e9),
+		Logs: []*types.Log{transfer, unknown},
+	})
+	if !v.StatusOK || v.Fee.Cmp(big.NewInt(50_000*2e9)) != 0 || len(v.Logs) != 2 {
+		t.Fatalf("receipt view %+v", v)
+	}
+	if ev := v.Logs[0].Event; ev == nil || ev.Name != "ERC20.Transfer" {
+		t.Fatalf("transfer log %+v", v.Logs[0])
+	}
+	if v.Logs[1].Event != nil || v.Logs[1].DecodeError != "" {
+		t.Fatalf("unknown log %+v", v.Logs[1])
+	}
+
+	// Pre-London receipts carry no price, so the fee is unknown.
+	if v := ex.receiptView(&types.Receipt{GasUsed: 21_000}); v.Fee != nil || v.StatusOK {
+		t.Fatalf("pre-London receipt view %+v", v)
+	}
+}
+
+func get(t *testing.T, srv *httptest.Server, path string) (int, string) {
+	t.Helper()
+	resp, err := http.Get(srv.URL + path)
+	if err != nil {
+		t.Fatalf("GET %s: %v", path, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func TestHandlerHTML(t *testing.T) {
+	chain := newMockChain(t, 3)
+	ex, _ := NewExplorer(chain, ExplorerConfig{})
+	srv := httptest.NewServer(ex.Handler())
+	defer srv.Close()
+
+	block := chain.blocks[1]
+	tx := block.Transactions()[0]
+	for path, want := range map[string]string{
+		"/":                            `<a href="/block/2">2</a>`,
+		"/block/1":                     `<a href="/tx/` + tx.Hash().Hex() + `">`,
+		"/block/" + block.Hash().Hex(): "<h1>Block 1</h1>",
+		"/block/latest":                "<h1>Block 2</h1>",
+		"/tx/" + tx.Hash().Hex():       "0.000000000000000002 ETH",
+		"/address/0x000000000000000000000000000000000000bEEF": "externally owned account",
+	} {
+		status, body := get(t, srv, path)
+		if status != http.StatusOK || !strings.Contains(body, want) {
+			t.Errorf("GET %s: status %d, body missing %q:\n%s", path, status, want, body)
+		}
+	}
+
+	status, body := get(t, srv, "/block/%3Cscript%3E")
+	if status != http.StatusBadRequest || strings.Contains(body, "<script>") {
+		t.Fatalf("bad id: status %d, unescaped body:\n%s", status, body)
+	}
+}
+
+func TestHandlerJSON(t *testing.T) {
+	chain := newMockChain(t, 3)
+	ex, _ := NewExplorer(chain, ExplorerConfig{})
+	srv := httptest.NewServer(ex.Handler())
+	defer srv.Close()
+
+	status, body := get(t, srv, "/api/block/1")
+	var block BlockView
+	if err := json.Unmarshal([]byte(body), &block); status != http.StatusOK || err != nil {
+		t.Fatalf("block json: status %d err=%v", status, err)
+	}
+	if block.Number != 1 || block.Hash != chain.blocks[1].Hash() || len(block.Txs) != 1 || block.BaseFee.Int64() != 7 {
+		t.Fatalf("decoded block %+v", block)
+	}
+
+	status, body = get(t, srv, "/api/blocks")
+	var blocks []BlockView
+	if err := json.Unmarshal([]byte(body), &blocks); status != http.StatusOK || err != nil || len(blocks) != 3 {
+		t.Fatalf("blocks json: status %d err=%v len=%d", status, err, len(blocks))
+	}
+
+	tx := chain.blocks[0].Transactions()[0]
+	status, body = get(t, srv, "/api/tx/"+tx.Hash().Hex())
+	if status != http.StatusOK || !strings.Contains(body, `"from":"`+strings.ToLower(crypto.PubkeyToAddress(testKey.PublicKey).Hex())) {
+		t.Fatalf("tx json: status %d body %s", status, body)
+	}
+
+	for path, want := range map[string]int{
+		"/api/block/abc":                 http.StatusBadRequest,
+		"/api/tx/0x1234":                 http.StatusBadRequest,
+		"/api/address/nope":              http.StatusBadRequest,
+		"/api/block/99":                  http.StatusNotFound,
+		"/api/tx/" + common.Hash{}.Hex(): http.StatusNotFound,
+	} {
+		status, body := get(t, srv, path)
+		var e map[string]string
+		if status != want || json.Unmarshal([]byte(body), &e) != nil || e["error"] == "" {
+			t.Errorf("GET %s: status %d (want %d), body %s", path, status, want, body)
+		}
+	}
+
+	chain.err = errors.New("node down")
+	if status, _ := get(t, srv, "/api/block/latest"); status != http.StatusBadGateway {
+		t.Fatalf("upstream failure: status %d", status)
+	}
 }
@@ -0,0 +1,352 @@
+package exercise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	receipts "geth-edu/15-receipts/exercise"
+)
+
+// ExplorerClient is the subset of ethclient the explorer service needs.
+// *ethclient.Client satisfies it.
+type ExplorerClient interface {
+	RPCClient
+	receipts.ReceiptClient
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error)
+	TransactionByHash(ctx context.Context, hash common.Hash) (tx *types.Transaction, isPending bool, err error)
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+}
+
+// ExplorerConfig tunes the explorer service. Zero values use defaults.
+type ExplorerConfig struct {
+	LatestCount int // blocks listed on the home page (default 10)
+	CacheSize   int // finalized blocks kept in memory (default 256)
+
+	// Registry labels receipt logs with known events
+	// (nil = module 15's DefaultRegistry).
+	Registry *receipts.Registry
+}
+
+// BlockView is a block page: the Run summary (always with transactions)
+// plus the header fields a reader expects to see.
+type BlockView struct {
+	Result
+	Time      uint64         `json:"timestamp"`
+	Coinbase  common.Address `json:"miner"`
+	BaseFee   *big.Int       `json:"baseFeePerGas,omitempty"`
+	Finalized bool           `json:"finalized"` // at or below the node's finalized height
+}
+
+// TxView is a transaction page. Receipt is nil while the tx is pending.
+type TxView struct {
+	Hash    common.Hash     `json:"hash"`
+	Type    uint8           `json:"type"`
+	From    common.Address  `json:"from"`
+	To      *common.Address `json:"to"` // nil for contract creation
+	Value   *big.Int        `json:"value"`
+	Nonce   uint64          `json:"nonce"`
+	Gas     uint64          `json:"gas"`
+	Input   hexutil.Bytes   `json:"input"`
+	Pending bool            `json:"pending"`
+	Receipt *ReceiptView    `json:"receipt,omitempty"`
+}
+
+// ReceiptView is the outcome of a mined transaction. Fee is in wei and nil
+// when the node doesn't report effectiveGasPrice (pre-London receipts).
+type ReceiptView struct {
+	BlockNumber *big.Int              `json:"blockNumber"`
+	BlockHash   common.Hash           `json:"blockHash"`
+	StatusOK    bool                  `json:"statusOk"`
+	GasUsed     uint64                `json:"gasUsed"`
+	Fee         *big.Int              `json:"fee"` // execution fee plus blob fee
+	Contract    common.Address        `json:"contractAddress"`
+	Logs        []receipts.LogSummary `json:"logs"`
+}
+
+// AddressView is an address page, read at a single block so the fields are
+// consistent with each other.
+type AddressView struct {
+	Address     common.Address `json:"address"`
+	BlockNumber uint64         `json:"blockNumber"`
+	Balance     *big.Int       `json:"balance"`
+	Nonce       uint64         `json:"nonce"`
+	CodeSize    int            `json:"codeSize"`
+}
+
+// Contract reports whether the address has code.
+func (v *AddressView) Contract() bool { return v.CodeSize > 0 }
+
+// Explorer serves blocks, transactions and addresses from an RPC node.
+//
+// Blocks at or below the finalized block can never change, so they are kept
+// in an LRU cache and served without touching the node again. Everything
+// newer is fetched on every request: a reorg may replace it at any time.
+type Explorer struct {
+	client ExplorerClient
+	cfg    ExplorerConfig
+
+	byHash    *lru[common.Hash, *types.Block]
+	byNumber  *lru[uint64, common.Hash] // canonical finalized blocks only
+	finalized atomic.Uint64             // highest finalized number seen
+}
+
+// NewExplorer validates cfg and returns an explorer with an empty cache.
+func NewExplorer(client ExplorerClient, cfg ExplorerConfig) (*Explorer, error) {
+	if client == nil {
+		return nil, errors.New("client is nil")
+	}
+	if cfg.LatestCount < 0 || cfg.CacheSize < 0 {
+		return nil, errors.New("LatestCount and CacheSize must not be negative")
+	}
+	if cfg.LatestCount == 0 {
+		cfg.LatestCount = 10
+	}
+	if cfg.CacheSize == 0 {
+		cfg.CacheSize = 256
+	}
+	if cfg.Registry == nil {
+		cfg.Registry = receipts.DefaultRegistry()
+	}
+	return &Explorer{
+		client:   client,
+		cfg:      cfg,
+		byHash:   newLRU[common.Hash, *types.Block](cfg.CacheSize),
+		byNumber: newLRU[uint64, common.Hash](cfg.CacheSize),
+	}, nil
+}
+
+// LatestBlocks returns the newest LatestCount blocks, newest first.
+func (e *Explorer) LatestBlocks(ctx context.Context) ([]*BlockView, error) {
+	head, err := e.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("fetch head: %w", err)
+	}
+	if head == nil {
+		return nil, errors.New("nil head response")
+	}
+	e.refreshFinalized(ctx) // once for the whole page, not per block
+
+	top := head.Number.Uint64()
+	views := make([]*BlockView, 0, e.cfg.LatestCount)
+	for i := 0; i < e.cfg.LatestCount && uint64(i) <= top; i++ {
+		block, err := e.blockByNumber(ctx, new(big.Int).SetUint64(top-uint64(i)), false)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, e.blockView(block))
+	}
+	return views, nil
+}
+
+// Block returns the canonical block at number, or the latest if nil.
+func (e *Explorer) Block(ctx context.Context, number *big.Int) (*BlockView, error) {
+	block, err := e.blockByNumber(ctx, number, true)
+	if err != nil {
+		return nil, err
+	}
+	return e.blockView(block), nil
+}
+
+// BlockByHash returns the block with the given hash, canonical or not.
+func (e *Explorer) BlockByHash(ctx context.Context, hash common.Hash) (*BlockView, error) {
+	if block, ok := e.byHash.Get(hash); ok {
+		return e.blockView(block), nil
+	}
+	block, err := e.client.BlockByHash(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("fetch block %s: %w", hash.Hex(), err)
+	}
+	if block == nil {
+		return nil, errors.New("nil block response")
+	}
+	if !e.isFinal(block.NumberU64()) {
+		e.refreshFinalized(ctx)
+	}
+	if e.isFinal(block.NumberU64()) {
+		// A block's content never changes under its hash, but an old
+		// side-chain block is not canonical, so don't index it by number.
+		e.byHash.Add(hash, block)
+	}
+	return e.blockView(block), nil
+}
+
+// Transaction returns a transaction and, once mined, its receipt.
+func (e *Explorer) Transaction(ctx context.Context, hash common.Hash) (*TxView, error) {
+	tx, pending, err := e.client.TransactionByHash(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("fetch tx %s: %w", hash.Hex(), err)
+	}
+	if tx == nil {
+		return nil, errors.New("nil transaction response")
+	}
+	view := &TxView{
+		Hash:    tx.Hash(),
+		Type:    tx.Type(),
+		To:      tx.To(),
+		Value:   tx.Value(),
+		Nonce:   tx.Nonce(),
+		Gas:     tx.Gas(),
+		Input:   tx.Data(),
+		Pending: pending,
+	}
+	// Left as the zero address if the signature doesn't recover.
+	view.From, _ = types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if !pending {
+		rcpt, err := e.client.TransactionReceipt(ctx, hash)
+		if err != nil {
+			return nil, fmt.Errorf("fetch receipt %s: %w", hash.Hex(), err)
+		}
+		if rcpt == nil {
+			return nil, errors.New("nil receipt response")
+		}
+		view.Receipt = e.receiptView(rcpt)
+	}
+	return view, nil
+}
+
+// receiptView maps a receipt onto the page, labelling each log that the
+// registry knows. Unknown events stay raw.
+func (e *Explorer) receiptView(rcpt *types.Receipt) *ReceiptView {
+	view := &ReceiptView{
+		BlockNumber: rcpt.BlockNumber,
+		BlockHash:   rcpt.BlockHash,
+		StatusOK:    rcpt.Status == types.ReceiptStatusSuccessful,
+		GasUsed:     rcpt.GasUsed,
+		Contract:    rcpt.ContractAddress,
+		Logs:        make([]receipts.LogSummary, 0, len(rcpt.Logs)),
+	}
+	if rcpt.EffectiveGasPrice != nil {
+		view.Fee = new(big.Int).Mul(new(big.Int).SetUint64(rcpt.GasUsed), rcpt.EffectiveGasPrice)
+		if rcpt.BlobGasPrice != nil {
+			view.Fee.Add(view.Fee, new(big.Int).Mul(new(big.Int).SetUint64(rcpt.BlobGasUsed), rcpt.BlobGasPrice))
+		}
+	}
+	for _, lg := range rcpt.Logs {
+		summary := receipts.LogSummary{Address: lg.Address, Topics: lg.Topics, Data: lg.Data, Index: uint(lg.Index)}
+		ev, err := e.cfg.Registry.Decode(summary)
+		switch {
+		case err == nil:
+			summary.Event = ev
+		case !errors.Is(err, receipts.ErrUnknownEvent):
+			summary.DecodeError = err.Error()
+		}
+		view.Logs = append(view.Logs, summary)
+	}
+	return view
+}
+
+// Account returns the balance, nonce and code size of addr at the head.
+func (e *Explorer) Account(ctx context.Context, addr common.Address) (*AddressView, error) {
+	head, err := e.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("fetch head: %w", err)
+	}
+	if head == nil {
+		return nil, errors.New("nil head response")
+	}
+	at := head.Number
+	balance, err := e.client.BalanceAt(ctx, addr, at)
+	if err != nil {
+		return nil, fmt.Errorf("balance of %s: %w", addr.Hex(), err)
+	}
+	nonce, err := e.client.NonceAt(ctx, addr, at)
+	if err != nil {
+		return nil, fmt.Errorf("nonce of %s: %w", addr.Hex(), err)
+	}
+	code, err := e.client.CodeAt(ctx, addr, at)
+	if err != nil {
+		return nil, fmt.Errorf("code of %s: %w", addr.Hex(), err)
+	}
+	return &AddressView{Address: addr, BlockNumber: at.Uint64(), Balance: balance, Nonce: nonce, CodeSize: len(code)}, nil
+}
+
+// CachedBlocks reports how many blocks are in the finalized-block cache.
+func (e *Explorer) CachedBlocks() int { return e.byHash.Len() }
+
+// blockByNumber serves canonical blocks from the cache when it can. refresh
+// allows one extra RPC to learn whether the block has become final.
+func (e *Explorer) blockByNumber(ctx context.Context, number *big.Int, refresh bool) (*types.Block, error) {
+	if number != nil && number.IsUint64() {
+		if hash, ok := e.byNumber.Get(number.Uint64()); ok {
+			if block, ok := e.byHash.Get(hash); ok {
+				return block, nil
+			}
+		}
+	}
+	// Same error wording as Run.
+	block, err := e.client.BlockByNumber(ctx, number)
+	if err != nil {
+		target := "latest"
+		if number != nil {
+			target = number.String()
+		}
+		return nil, fmt.Errorf("fetch block %s: %w", target, err)
+	}
+	if block == nil {
+		return nil, errors.New("nil block response")
+	}
+	n := block.NumberU64()
+	if refresh && !e.isFinal(n) {
+		e.refreshFinalized(ctx)
+	}
+	if e.isFinal(n) {
+		e.byHash.Add(block.Hash(), block)
+		e.byNumber.Add(n, block.Hash())
+	}
+	return block, nil
+}
+
+func (e *Explorer) isFinal(number uint64) bool {
+	return number <= e.finalized.Load()
+}
+
+// refreshFinalized asks the node for its finalized block. Nodes without a
+// finality gadget (or errors) leave the old value, which only means fewer
+// cache hits.
+func (e *Explorer) refreshFinalized(ctx context.Context) {
+	header, err := e.client.HeaderByNumber(ctx, big.NewInt(int64(rpc.FinalizedBlockNumber)))
+	if err != nil || header == nil {
+		return
+	}
+	n := header.Number.Uint64()
+	for {
+		old := e.finalized.Load()
+		if n <= old || e.finalized.CompareAndSwap(old, n) {
+			return
+		}
+	}
+}
+
+func (e *Explorer) blockView(block *types.Block) *BlockView {
+	header := block.Header()
+	view := &BlockView{
+		Result: Result{
+			Number:   header.Number.Uint64(),
+			Hash:     block.Hash(),
+			Parent:   header.ParentHash,
+			TxCount:  len(block.Transactions()),
+			Txs:      make([]TxSummary, 0, len(block.Transactions())),
+			GasUsed:  header.GasUsed,
+			GasLimit: header.GasLimit,
+		},
+		Time:      header.Time,
+		Coinbase:  header.Coinbase,
+		BaseFee:   header.BaseFee,
+		Finalized: e.isFinal(header.Number.Uint64()),
+	}
+	for _, tx := range block.Transactions() {
+		view.Txs = append(view.Txs, TxSummary{Hash: tx.Hash(), To: tx.To(), Gas: tx.Gas()})
+	}
+	return view
+}
@@ -0,0 +1,133 @@
+package exercise
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// errBadRequest marks errors caused by the URL rather than the node.
+var errBadRequest = errors.New("bad request")
+
+// Handler returns the explorer's routes. Every HTML page has a JSON twin
+// under /api:
+//
+//	GET /                  /api/blocks             latest blocks
+//	GET /block/{id}        /api/block/{id}         block by number, "latest" or hash
+//	GET /tx/{hash}         /api/tx/{hash}          transaction and receipt
+//	GET /address/{addr}    /api/address/{addr}     balance, nonce, code size
+func (e *Explorer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	latest := func(r *http.Request) (any, error) {
+		return e.LatestBlocks(r.Context())
+	}
+	mux.HandleFunc("GET /{$}", e.html("home", latest))
+	mux.HandleFunc("GET /api/blocks", e.json(latest))
+	e.route(mux, "/block/{id}", "block", func(r *http.Request) (any, error) {
+		return e.lookupBlock(r.Context(), r.PathValue("id"))
+	})
+	e.route(mux, "/tx/{hash}", "tx", func(r *http.Request) (any, error) {
+		hash, err := parseHash(r.PathValue("hash"))
+		if err != nil {
+			return nil, err
+		}
+		return e.Transaction(r.Context(), hash)
+	})
+	e.route(mux, "/address/{addr}", "address", func(r *http.Request) (any, error) {
+		addr := r.PathValue("addr")
+		if !common.IsHexAddress(addr) {
+			return nil, fmt.Errorf("%w: %q is not an address", errBadRequest, addr)
+		}
+		return e.Account(r.Context(), common.HexToAddress(addr))
+	})
+	return mux
+}
+
+type loader func(r *http.Request) (any, error)
+
+func (e *Explorer) route(mux *http.ServeMux, path, page string, load loader) {
+	mux.HandleFunc("GET "+path, e.html(page, load))
+	mux.HandleFunc("GET /api"+path, e.json(load))
+}
+
+func (e *Explorer) html(page string, load loader) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		data, err := load(r)
+		status := http.StatusOK
+		if err != nil {
+			status, page, data = errorStatus(err), "error", err.Error()
+		}
+		// Render to a buffer so a template error can't leave half a page.
+		var buf bytes.Buffer
+		if err := pages.ExecuteTemplate(&buf, page, data); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(status)
+		w.Write(buf.Bytes())
+	}
+}
+
+func (e *Explorer) json(load loader) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		data, err := load(r)
+		status := http.StatusOK
+		if err != nil {
+			status, data = errorStatus(err), map[string]string{"error": err.Error()}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(data)
+	}
+}
+
+// errorStatus maps an error to 400 for bad input, 404 for unknown objects
+// and 502 for anything the node did wrong.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, errBadRequest):
+		return http.StatusBadRequest
+	case errors.Is(err, ethereum.NotFound):
+		return http.StatusNotFound
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusBadGateway
+}
+
+// lookupBlock accepts a decimal number, "latest" or a 0x-prefixed hash.
+func (e *Explorer) lookupBlock(ctx context.Context, id string) (*BlockView, error) {
+	if id == "latest" {
+		return e.Block(ctx, nil)
+	}
+	if strings.HasPrefix(id, "0x") {
+		hash, err := parseHash(id)
+		if err != nil {
+			return nil, err
+		}
+		return e.BlockByHash(ctx, hash)
+	}
+	n, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q is not a block number or hash", errBadRequest, id)
+	}
+	return e.Block(ctx, new(big.Int).SetUint64(n))
+}
+
+func parseHash(s string) (common.Hash, error) {
+	var h common.Hash
+	if err := h.UnmarshalText([]byte(s)); err != nil {
+		return h, fmt.Errorf("%w: %q is not a 32-byte hash", errBadRequest, s)
+	}
+	return h, nil
+}
@@ -0,0 +1,113 @@
+package exercise
+
+import (
+	"html/template"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// pages holds every HTML page. html/template escapes all data, so values
+// from the node (hex input data, error messages) can't inject markup.
+var pages = template.Must(template.New("pages").Funcs(template.FuncMap{
+	"ether":   formatEther,
+	"nonzero": func(a common.Address) bool { return a != (common.Address{}) },
+	"time":    func(unix uint64) string { return time.Unix(int64(unix), 0).UTC().Format(time.RFC3339) },
+}).Parse(pageSource))
+
+// formatEther renders a wei amount in ether without losing precision.
+func formatEther(wei *big.Int) string {
+	if wei == nil {
+		return "0"
+	}
+	return new(big.Rat).SetFrac(wei, big.NewInt(params.Ether)).FloatString(18)
+}
+
+const pageSource = `
+{{define "header"}}<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.}} · geth-edu explorer</title>
+<style>body{font-family:monospace;margin:2em}td,th{padding:2px 8px;text-align:left}</style>
+</head>
+<body>
+<p><a href="/">latest blocks</a></p>
+<h1>{{.}}</h1>
+{{end}}
+
+{{define "footer"}}</body>
+</html>
+{{end}}
+
+{{define "home"}}{{template "header" "Latest blocks"}}
+<table>
+<tr><th>Number</th><th>Hash</th><th>Time</th><th>Txs</th><th>Gas used</th><th>Finalized</th></tr>
+{{range .}}<tr>
+<td><a href="/block/{{.Number}}">{{.Number}}</a></td>
+<td><a href="/block/{{.Hash.Hex}}">{{.Hash.Hex}}</a></td>
+<td>{{time .Time}}</td>
+<td>{{.TxCount}}</td>
+<td>{{.GasUsed}} / {{.GasLimit}}</td>
+<td>{{.Finalized}}</td>
+</tr>
+{{end}}</table>
+{{template "footer"}}{{end}}
+
+{{define "block"}}{{template "header" printf "Block %d" .Number}}
+<table>
+<tr><th>Hash</th><td>{{.Hash.Hex}}</td></tr>
+<tr><th>Parent</th><td><a href="/block/{{.Parent.Hex}}">{{.Parent.Hex}}</a></td></tr>
+<tr><th>Time</th><td>{{time .Time}}</td></tr>
+<tr><th>Miner</th><td><a href="/address/{{.Coinbase.Hex}}">{{.Coinbase.Hex}}</a></td></tr>
+<tr><th>Gas used</th><td>{{.GasUsed}} / {{.GasLimit}}</td></tr>
+{{with .BaseFee}}<tr><th>Base fee</th><td>{{.}} wei</td></tr>{{end}}
+<tr><th>Finalized</th><td>{{.Finalized}}</td></tr>
+</table>
+<h2>{{.TxCount}} transactions</h2>
+<table>
+<tr><th>Hash</th><th>To</th><th>Gas</th></tr>
+{{range .Txs}}<tr>
+<td><a href="/tx/{{.Hash.Hex}}">{{.Hash.Hex}}</a></td>
+<td>{{with .To}}<a href="/address/{{.Hex}}">{{.Hex}}</a>{{else}}contract creation{{end}}</td>
+<td>{{.Gas}}</td>
+</tr>
+{{end}}</table>
+{{template "footer"}}{{end}}
+
+{{define "tx"}}{{template "header" "Transaction"}}
+<table>
+<tr><th>Hash</th><td>{{.Hash.Hex}}</td></tr>
+<tr><th>Type</th><td>{{.Type}}</td></tr>
+<tr><th>From</th><td><a href="/address/{{.From.Hex}}">{{.From.Hex}}</a></td></tr>
+<tr><th>To</th><td>{{with .To}}<a href="/address/{{.Hex}}">{{.Hex}}</a>{{else}}contract creation{{end}}</td></tr>
+<tr><th>Value</th><td>{{ether .Value}} ETH</td></tr>
+<tr><th>Nonce</th><td>{{.Nonce}}</td></tr>
+<tr><th>Gas limit</th><td>{{.Gas}}</td></tr>
+<tr><th>Input</th><td>{{.Input}}</td></tr>
+</table>
+{{with .Receipt}}<h2>Receipt</h2>
+<table>
+<tr><th>Status</th><td>{{if .StatusOK}}success{{else}}reverted{{end}}</td></tr>
+<tr><th>Block</th><td><a href="/block/{{.BlockNumber}}">{{.BlockNumber}}</a></td></tr>
+<tr><th>Gas used</th><td>{{.GasUsed}}</td></tr>
+{{if nonzero .Contract}}<tr><th>Created</th><td><a href="/address/{{.Contract.Hex}}">{{.Contract.Hex}}</a></td></tr>{{end}}
+<tr><th>Logs</th><td>{{len .Logs}}</td></tr>
+</table>
+{{else}}<p>Pending: no receipt yet.</p>
+{{end}}{{template "footer"}}{{end}}
+
+{{define "address"}}{{template "header" "Address"}}
+<table>
+<tr><th>Address</th><td>{{.Address.Hex}}</td></tr>
+<tr><th>Balance</th><td>{{ether .Balance}} ETH</td></tr>
+<tr><th>Nonce</th><td>{{.Nonce}}</td></tr>
+<tr><th>Kind</th><td>{{if .Contract}}contract ({{.CodeSize}} bytes of code){{else}}externally owned account{{end}}</td></tr>
+<tr><th>As of block</th><td><a href="/block/{{.BlockNumber}}">{{.BlockNumber}}</a></td></tr>
+</table>
+{{template "footer"}}{{end}}
+
+{{define "error"}}{{template "header" "Error"}}
+<p>{{.}}</p>
+{{template "footer"}}{{end}}
+`
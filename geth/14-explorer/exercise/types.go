@@ -0,0 +1,40 @@
+package exercise
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// RPCClient is the tiny subset of ethclient we need for this module.
+type RPCClient interface {
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+}
+
+// Config controls which block to fetch.
+// If Number is nil, fetch the latest block.
+// If IncludeTxs is true, summarize transactions; otherwise only header data is returned.
+type Config struct {
+	Number     *big.Int
+	IncludeTxs bool
+}
+
+// TxSummary captures the minimal transaction details for explorer output.
+type TxSummary struct {
+	Hash common.Hash     `json:"hash"`
+	To   *common.Address `json:"to"` // nil for contract creation
+	Gas  uint64          `json:"gas"`
+}
+
+// Result is what our explorer presents to callers.
+type Result struct {
+	Number   uint64      `json:"number"`
+	Hash     common.Hash `json:"hash"`
+	Parent   common.Hash `json:"parentHash"`
+	TxCount  int         `json:"txCount"`
+	Txs      []TxSummary `json:"transactions,omitempty"`
+	GasUsed  uint64      `json:"gasUsed"`
+	GasLimit uint64      `json:"gasLimit"`
+}
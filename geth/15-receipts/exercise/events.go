@@ -0,0 +1,227 @@
+package exercise
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ErrUnknownEvent is returned by Registry.Decode when no registered event
+// matches the log's topic0 and topic count.
+var ErrUnknownEvent = errors.New("unknown event")
+
+// knownEventsABI holds the events DefaultRegistry labels, per standard.
+// ERC-20 and ERC-721 share the Transfer and Approval signatures; only the
+// number of indexed arguments (and so the topic count) tells them apart.
+var knownEventsABI = map[string]string{
+	"ERC20": `[
+		{"type":"event","name":"Transfer","inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"value","type":"uint256"}]},
+		{"type":"event","name":"Approval","inputs":[{"name":"owner","type":"address","indexed":true},{"name":"spender","type":"address","indexed":true},{"name":"value","type":"uint256"}]}
+	]`,
+	"ERC721": `[
+		{"type":"event","name":"Transfer","inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"tokenId","type":"uint256","indexed":true}]},
+		{"type":"event","name":"Approval","inputs":[{"name":"owner","type":"address","indexed":true},{"name":"approved","type":"address","indexed":true},{"name":"tokenId","type":"uint256","indexed":true}]},
+		{"type":"event","name":"ApprovalForAll","inputs":[{"name":"owner","type":"address","indexed":true},{"name":"operator","type":"address","indexed":true},{"name":"approved","type":"bool"}]}
+	]`,
+	"ERC1155": `[
+		{"type":"event","name":"TransferSingle","inputs":[{"name":"operator","type":"address","indexed":true},{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"id","type":"uint256"},{"name":"value","type":"uint256"}]},
+		{"type":"event","name":"TransferBatch","inputs":[{"name":"operator","type":"address","indexed":true},{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"ids","type":"uint256[]"},{"name":"values","type":"uint256[]"}]}
+	]`,
+	"WETH": `[
+		{"type":"event","name":"Deposit","inputs":[{"name":"dst","type":"address","indexed":true},{"name":"wad","type":"uint256"}]},
+		{"type":"event","name":"Withdrawal","inputs":[{"name":"src","type":"address","indexed":true},{"name":"wad","type":"uint256"}]}
+	]`,
+}
+
+// EventArg is one named argument of a decoded event. Value has the Go type
+// the abi package decodes into (*big.Int, common.Address, bool, ...); an
+// indexed string, bytes or array argument is only its keccak256 topic, so
+// its Value is a common.Hash.
+type EventArg struct {
+	Name    string      `json:"name"`
+	Type    string      `json:"type"`
+	Indexed bool        `json:"indexed"`
+	Value   interface{} `json:"value"`
+}
+
+// MarshalJSON writes byte values as 0x hex instead of JSON number arrays.
+func (a EventArg) MarshalJSON() ([]byte, error) {
+	type plain EventArg
+	p := plain(a)
+	p.Value = jsonValue(a.Value)
+	return json.Marshal(p)
+}
+
+// DecodedEvent is a log labelled with the registered event it matches.
+type DecodedEvent struct {
+	Name      string     `json:"name"`      // e.g. "ERC20.Transfer"
+	Signature string     `json:"signature"` // e.g. "Transfer(address,address,uint256)"
+	Args      []EventArg `json:"args"`      // in ABI order
+}
+
+// Arg returns the value of the named argument.
+func (e *DecodedEvent) Arg(name string) (interface{}, bool) {
+	for _, a := range e.Args {
+		if a.Name == name {
+			return a.Value, true
+		}
+	}
+	return nil, false
+}
+
+type registeredEvent struct {
+	name   string
+	event  abi.Event
+	topics int // exact number of topics, including topic0
+}
+
+// Registry maps topic0 to the event ABIs registered for it.
+type Registry struct {
+	events map[common.Hash][]registeredEvent
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{events: make(map[common.Hash][]registeredEvent)}
+}
+
+// DefaultRegistry returns a registry with the ERC-20, ERC-721, ERC-1155 and
+// WETH events.
+func DefaultRegistry() *Registry {
+	r := NewRegistry()
+	for _, contract := range []string{"ERC20", "ERC721", "ERC1155", "WETH"} {
+		if err := r.RegisterABI(contract, knownEventsABI[contract]); err != nil {
+			panic(err) // the ABIs above are constants
+		}
+	}
+	return r
+}
+
+// defaultRegistry serves Configs without a Registry. It is never mutated.
+var defaultRegistry = DefaultRegistry()
+
+// RegisterABI registers every event in a contract ABI under
+// "<contract>.<Event>".
+func (r *Registry) RegisterABI(contract, abiJSON string) error {
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return fmt.Errorf("parse %s ABI: %w", contract, err)
+	}
+	for _, ev := range parsed.Events {
+		if err := r.Register(contract+"."+ev.RawName, ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Register adds one event. A later event with the same topic0 and topic
+// count replaces the earlier one. Anonymous events have no topic0 to match
+// on and are rejected.
+func (r *Registry) Register(name string, ev abi.Event) error {
+	if ev.Anonymous {
+		return fmt.Errorf("register %s: anonymous events can't be matched", name)
+	}
+	topics := 1
+	for _, in := range ev.Inputs {
+		if in.Indexed {
+			topics++
+		}
+	}
+	re := registeredEvent{name: name, event: ev, topics: topics}
+	list := r.events[ev.ID]
+	for i, existing := range list {
+		if existing.topics == topics {
+			list[i] = re
+			return nil
+		}
+	}
+	r.events[ev.ID] = append(list, re)
+	return nil
+}
+
+// Decode finds the event lg was emitted as and unpacks its arguments.
+func (r *Registry) Decode(lg LogSummary) (*DecodedEvent, error) {
+	if len(lg.Topics) == 0 {
+		return nil, fmt.Errorf("%w: log %d has no topics", ErrUnknownEvent, lg.Index)
+	}
+	for _, re := range r.events[lg.Topics[0]] {
+		if re.topics != len(lg.Topics) {
+			continue
+		}
+		args, err := unpackEvent(re.event, lg)
+		if err != nil {
+			return nil, fmt.Errorf("decode %s in log %d: %w", re.name, lg.Index, err)
+		}
+		return &DecodedEvent{Name: re.name, Signature: re.event.Sig, Args: args}, nil
+	}
+	return nil, fmt.Errorf("%w: topic %s with %d topics", ErrUnknownEvent, lg.Topics[0].Hex(), len(lg.Topics))
+}
+
+// label sets lg.Event, or lg.DecodeError if a known event fails to decode.
+// Unknown events are left alone: most logs on mainnet are not in any
+// registry, and that isn't an error.
+func (r *Registry) label(lg *LogSummary) {
+	ev, err := r.Decode(*lg)
+	switch {
+	case err == nil:
+		lg.Event = ev
+	case !errors.Is(err, ErrUnknownEvent):
+		lg.DecodeError = err.Error()
+	}
+}
+
+func unpackEvent(ev abi.Event, lg LogSummary) ([]EventArg, error) {
+	var indexed abi.Arguments
+	for _, in := range ev.Inputs {
+		if in.Indexed {
+			indexed = append(indexed, in)
+		}
+	}
+	fromTopics := make(map[string]interface{}, len(indexed))
+	if err := abi.ParseTopicsIntoMap(fromTopics, indexed, lg.Topics[1:]); err != nil {
+		return nil, fmt.Errorf("topics: %w", err)
+	}
+	fromData, err := ev.Inputs.NonIndexed().UnpackValues(lg.Data)
+	if err != nil {
+		return nil, fmt.Errorf("data: %w", err)
+	}
+
+	args := make([]EventArg, 0, len(ev.Inputs))
+	for _, in := range ev.Inputs {
+		arg := EventArg{Name: in.Name, Type: in.Type.String(), Indexed: in.Indexed}
+		if in.Indexed {
+			arg.Value = fromTopics[in.Name]
+		} else {
+			arg.Value, fromData = fromData[0], fromData[1:]
+		}
+		args = append(args, arg)
+	}
+	return args, nil
+}
+
+// jsonValue converts byte slices and arrays (bytes, bytesN) to hexutil.Bytes
+// so they encode as hex strings.
+func jsonValue(v interface{}) interface{} {
+	rv := reflect.ValueOf(v)
+	switch {
+	case !rv.IsValid():
+		return v
+	case rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8:
+		return hexutil.Bytes(rv.Bytes())
+	case rv.Kind() == reflect.Array && rv.Type().Elem().Kind() == reflect.Uint8:
+		if _, ok := v.(interface{ MarshalText() ([]byte, error) }); ok {
+			return v // common.Hash and common.Address already encode as hex
+		}
+		b := make([]byte, rv.Len())
+		reflect.Copy(reflect.ValueOf(b), rv)
+		return hexutil.Bytes(b)
+	}
+	return v
+}
@@ -6,42 +6,8 @@ package exercise
 import (
 	"context"
 	"errors"
-	"math/big"
-
-	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/types"
 )
 
-// ReceiptClient abstracts the single RPC we need.
-type ReceiptClient interface {
-	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
-}
-
-// Config identifies which receipt to fetch.
-type Config struct {
-	TxHash common.Hash
-}
-
-// LogSummary is a lightweight view of a log entry.
-type LogSummary struct {
-	Address common.Address
-	Topics  []common.Hash
-	Data    []byte
-	Index   uint
-}
-
-// Result captures decoded receipt data.
-type Result struct {
-	TxHash        common.Hash
-	BlockNumber   *big.Int
-	StatusOK      bool
-	GasUsed       uint64
-	Contract      common.Address
-	Logs          []LogSummary
-	CumulativeGas uint64
-	PostStateRoot []byte
-}
-
 // Run is the student entry point for module 15-receipts.
 func Run(ctx context.Context, client ReceiptClient, cfg Config) (*Result, error) {
 	// TODO: Validate input parameters
@@ -62,12 +28,15 @@ func Run(ctx context.Context, client ReceiptClient, cfg Config) (*Result, error)
 	//   - Copy Topics using append([]common.Hash(nil), lg.Topics...)
 	//   - Copy Data using append([]byte(nil), lg.Data...)
 	//   - Create LogSummary with Address, copied Topics, copied Data, and Index
+	//   - Label it with the registry: reg.label(&summary), where reg is
+	//     cfg.Registry or defaultRegistry when nil
 	//   - Append to logs slice
 	// Why defensive copying? Topics and Data are slices (reference types)
 
 	// TODO: Construct and return the Result
 	// - Create a Result struct with:
-	//   - TxHash: rcpt.TxHash
+	//   - TxHash: rcpt.TxHash, Type: rcpt.Type
+	//   - BlockHash: rcpt.BlockHash, TxIndex: rcpt.TransactionIndex
 	//   - BlockNumber: new(big.Int).Set(rcpt.BlockNumber) (defensive copy!)
 	//   - StatusOK: rcpt.Status == 1 (1 = success, 0 = failure)
 	//   - GasUsed: rcpt.GasUsed
@@ -75,7 +44,32 @@ func Run(ctx context.Context, client ReceiptClient, cfg Config) (*Result, error)
 	//   - Contract: rcpt.ContractAddress (if contract creation, otherwise zero address)
 	//   - Logs: The copied logs slice
 	//   - PostStateRoot: append([]byte(nil), rcpt.PostState...) (defensive copy!)
+
+	// TODO: Fill in the fee fields
+	// - Call setFees(res, rcpt): GasUsed * EffectiveGasPrice, plus
+	//   BlobGasUsed * BlobGasPrice for blob (type-3) transactions
 	// - Return the result and nil error on success
+	// Tip: put the log/result/fee steps in a helper so RunBlock can reuse them
+
+	return nil, errors.New("not implemented")
+}
+
+// RunBlock fetches and summarizes every receipt of one block.
+func RunBlock(ctx context.Context, client BlockReceiptClient, cfg BlockConfig) (*BlockResult, error) {
+	// TODO: Validate ctx and client like Run
+
+	// TODO: Build the block selector
+	// - cfg.Hash set: rpc.BlockNumberOrHashWithHash(cfg.Hash, false)
+	// - cfg.Number set: rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(n))
+	// - neither: rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+
+	// TODO: Call client.BlockReceipts(ctx, selector) and wrap errors
+
+	// TODO: Summarize each receipt exactly as Run does, in order
+	// - Sum GasUsed into BlockResult.GasUsed
+	// - Sum TotalFee into BlockResult.TotalFees; if any fee is nil, the
+	//   block total is unknown (nil)
+	// - Take BlockNumber/BlockHash from the first receipt when there is one
 
 	return nil, errors.New("not implemented")
 }
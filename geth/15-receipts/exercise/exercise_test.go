@@ -1,7 +1,330 @@
 package exercise
 
-import "testing"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	_ ReceiptClient      = (*ethclient.Client)(nil)
+	_ BlockReceiptClient = (*ethclient.Client)(nil)
+)
+
+var (
+	alice = common.HexToAddress("0x00000000000000000000000000000000000a11ce")
+	bob   = common.HexToAddress("0x0000000000000000000000000000000000000b0b")
+	token = common.HexToAddress("0x00000000000000000000000000000000000070c3")
+
+	transferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+)
+
+type mockReceiptClient struct {
+	rcpt *types.Receipt
+	err  error
+}
+
+func (m *mockReceiptClient) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	return m.rcpt, m.err
+}
+
+type mockBlockClient struct {
+	got   rpc.BlockNumberOrHash
+	rcpts []*types.Receipt
+	err   error
+}
+
+func (m *mockBlockClient) BlockReceipts(ctx context.Context, sel rpc.BlockNumberOrHash) ([]*types.Receipt, error) {
+	m.got = sel
+	return m.rcpts, m.err
+}
+
+func word(v int64) []byte {
+	return common.BigToHash(big.NewInt(v)).Bytes()
+}
+
+func erc20Transfer(from, to common.Address, value int64, index uint) *types.Log {
+	return &types.Log{
+		Address: token,
+		Topics:  []common.Hash{transferTopic, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+		Data:    word(value),
+		Index:   index,
+	}
+}
+
+func receipt(txIndex uint, gasUsed uint64, price int64, logs ...*types.Log) *types.Receipt {
+	return &types.Receipt{
+		Type:              types.DynamicFeeTxType,
+		Status:            types.ReceiptStatusSuccessful,
+		TxHash:            common.BigToHash(big.NewInt(int64(txIndex) + 1)),
+		BlockHash:         common.HexToHash("0xb10c"),
+		BlockNumber:       big.NewInt(100),
+		TransactionIndex:  txIndex,
+		GasUsed:           gasUsed,
+		CumulativeGasUsed: gasUsed,
+		EffectiveGasPrice: big.NewInt(price),
+		Logs:              logs,
+	}
+}
 
 func TestRun(t *testing.T) {
-	t.Skip("add tests for this module")
+	unknown := &types.Log{Address: token, Topics: []common.Hash{common.HexToHash("0xdead")}, Data: []byte{1, 2}, Index: 1}
+	// Right topic0 and topic count for ERC20.Transfer, but no data word.
+	malformed := &types.Log{Address: token, Topics: erc20Transfer(alice, bob, 0, 0).Topics, Index: 2}
+	rcpt := receipt(3, 50_000, 20e9, erc20Transfer(alice, bob, 1234, 0), unknown, malformed)
+	client := &mockReceiptClient{rcpt: rcpt}
+
+	res, err := Run(context.Background(), client, Config{TxHash: rcpt.TxHash})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !res.StatusOK || res.GasUsed != 50_000 || res.TxIndex != 3 || res.BlockHash != rcpt.BlockHash || res.Type != types.DynamicFeeTxType {
+		t.Fatalf("result %+v", res)
+	}
+	if res.ExecutionFee.Cmp(big.NewInt(50_000*20e9)) != 0 || res.TotalFee.Cmp(res.ExecutionFee) != 0 || res.BlobFee != nil {
+		t.Fatalf("fees: exec=%v blob=%v total=%v", res.ExecutionFee, res.BlobFee, res.TotalFee)
+	}
+
+	if len(res.Logs) != 3 {
+		t.Fatalf("got %d logs", len(res.Logs))
+	}
+	ev := res.Logs[0].Event
+	if ev == nil || ev.Name != "ERC20.Transfer" || ev.Signature != "Transfer(address,address,uint256)" {
+		t.Fatalf("transfer not labelled: %+v", ev)
+	}
+	from, _ := ev.Arg("from")
+	value, _ := ev.Arg("value")
+	if from != alice || value.(*big.Int).Int64() != 1234 || !ev.Args[1].Indexed || ev.Args[2].Indexed {
+		t.Fatalf("transfer args %+v", ev.Args)
+	}
+	if res.Logs[1].Event != nil || res.Logs[1].DecodeError != "" {
+		t.Fatalf("unknown event should stay raw: %+v", res.Logs[1])
+	}
+	if res.Logs[2].Event != nil || !strings.Contains(res.Logs[2].DecodeError, "ERC20.Transfer") {
+		t.Fatalf("malformed transfer: %+v", res.Logs[2])
+	}
+
+	// Results are copies, not views into the client's receipt.
+	res.Logs[0].Data[31] = 0
+	res.BlockNumber.SetInt64(1)
+	res.TotalFee.SetInt64(1)
+	if rcpt.Logs[0].Data[31] == 0 || rcpt.BlockNumber.Int64() != 100 || rcpt.EffectiveGasPrice.Int64() != 20e9 {
+		t.Fatalf("Run returned aliases of the receipt")
+	}
+}
+
+func TestRunFees(t *testing.T) {
+	blob := receipt(0, 21_000, 10)
+	blob.Type = types.BlobTxType
+	blob.BlobGasUsed = 2 * 131072
+	blob.BlobGasPrice = big.NewInt(3)
+	res, err := Run(context.Background(), &mockReceiptClient{rcpt: blob}, Config{TxHash: blob.TxHash})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if res.ExecutionFee.Int64() != 210_000 || res.BlobFee.Int64() != 786_432 || res.TotalFee.Int64() != 996_432 {
+		t.Fatalf("blob fees: exec=%v blob=%v total=%v", res.ExecutionFee, res.BlobFee, res.TotalFee)
+	}
+
+	old := receipt(0, 21_000, 0)
+	old.EffectiveGasPrice = nil
+	res, err = Run(context.Background(), &mockReceiptClient{rcpt: old}, Config{TxHash: old.TxHash})
+	if err != nil || res.EffectiveGasPrice != nil || res.TotalFee != nil {
+		t.Fatalf("pre-London receipt should have unknown fees: %+v err=%v", res, err)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	hash := common.HexToHash("0x1")
+	if _, err := Run(context.Background(), nil, Config{TxHash: hash}); err == nil {
+		t.Fatalf("expected nil client error")
+	}
+	if _, err := Run(context.Background(), &mockReceiptClient{}, Config{}); err == nil {
+		t.Fatalf("expected missing hash error")
+	}
+	boom := errors.New("boom")
+	if _, err := Run(context.Background(), &mockReceiptClient{err: boom}, Config{TxHash: hash}); !errors.Is(err, boom) {
+		t.Fatalf("expected wrapped upstream error, got %v", err)
+	}
+	if _, err := Run(context.Background(), &mockReceiptClient{}, Config{TxHash: hash}); err == nil {
+		t.Fatalf("expected nil receipt error")
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	reg := DefaultRegistry()
+
+	// ERC-721 Transfer has the same topic0 as ERC-20 but an indexed tokenId.
+	nft := LogSummary{Topics: []common.Hash{transferTopic, common.BytesToHash(alice.Bytes()), common.BytesToHash(bob.Bytes()), common.BigToHash(big.NewInt(7))}}
+	ev, err := reg.Decode(nft)
+	if err != nil || ev.Name != "ERC721.Transfer" {
+		t.Fatalf("nft transfer: %+v err=%v", ev, err)
+	}
+	if id, _ := ev.Arg("tokenId"); id.(*big.Int).Int64() != 7 {
+		t.Fatalf("tokenId %v", id)
+	}
+
+	if _, err := reg.Decode(LogSummary{}); !errors.Is(err, ErrUnknownEvent) {
+		t.Fatalf("no topics: %v", err)
+	}
+	if _, err := NewRegistry().Decode(LogSummary{Topics: nft.Topics}); !errors.Is(err, ErrUnknownEvent) {
+		t.Fatalf("empty registry: %v", err)
+	}
+
+	// Custom ABI: an indexed string is only its hash; bytes32 data is decoded.
+	custom := NewRegistry()
+	err = custom.RegisterABI("Registry", `[{"type":"event","name":"Named","inputs":[
+		{"name":"label","type":"string","indexed":true},
+		{"name":"node","type":"bytes32"},
+		{"name":"","type":"bool"}]}]`)
+	if err != nil {
+		t.Fatalf("RegisterABI: %v", err)
+	}
+	named := crypto.Keccak256Hash([]byte("Named(string,bytes32,bool)"))
+	labelHash := crypto.Keccak256Hash([]byte("vitalik"))
+	lg := LogSummary{Topics: []common.Hash{named, labelHash}, Data: append(word(0xabcd), word(1)...)}
+	ev, err = custom.Decode(lg)
+	if err != nil {
+		t.Fatalf("custom decode: %v", err)
+	}
+	if label, _ := ev.Arg("label"); label != labelHash {
+		t.Fatalf("indexed string should be its topic hash, got %v", label)
+	}
+	if ok, _ := ev.Arg("arg2"); ok != true {
+		t.Fatalf("unnamed bool arg: %+v", ev.Args)
+	}
+	out, _ := json.Marshal(ev.Args[1])
+	if !strings.Contains(string(out), `"value":"0x`+strings.Repeat("0", 60)+`abcd"`) {
+		t.Fatalf("bytes32 should encode as hex: %s", out)
+	}
+
+	anon := abi.NewEvent("Anon", "Anon", true, nil)
+	if err := custom.Register("X.Anon", anon); err == nil {
+		t.Fatalf("expected anonymous events to be rejected")
+	}
+	if err := custom.RegisterABI("Bad", "not json"); err == nil {
+		t.Fatalf("expected ABI parse error")
+	}
+}
+
+func TestRunWithCustomRegistry(t *testing.T) {
+	rcpt := receipt(0, 21_000, 1, erc20Transfer(alice, bob, 5, 0))
+	res, err := Run(context.Background(), &mockReceiptClient{rcpt: rcpt}, Config{TxHash: rcpt.TxHash, Registry: NewRegistry()})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if res.Logs[0].Event != nil {
+		t.Fatalf("empty registry should leave logs raw: %+v", res.Logs)
+	}
+}
+
+func TestRunBlock(t *testing.T) {
+	plain := receipt(0, 21_000, 10)
+	withLog := receipt(1, 40_000, 12, erc20Transfer(alice, bob, 9, 0))
+	client := &mockBlockClient{rcpts: []*types.Receipt{plain, withLog}}
+
+	res, err := RunBlock(context.Background(), client, BlockConfig{Number: big.NewInt(100)})
+	if err != nil {
+		t.Fatalf("RunBlock: %v", err)
+	}
+	if n, ok := client.got.Number(); !ok || n != 100 {
+		t.Fatalf("selector %v", client.got)
+	}
+	if len(res.Receipts) != 2 || res.Receipts[1].TxIndex != 1 || res.Receipts[1].Logs[0].Event == nil {
+		t.Fatalf("receipts %+v", res.Receipts)
+	}
+	if res.GasUsed != 61_000 || res.TotalFees.Int64() != 21_000*10+40_000*12 {
+		t.Fatalf("totals gas=%d fees=%v", res.GasUsed, res.TotalFees)
+	}
+	if res.BlockNumber.Int64() != 100 || res.BlockHash != plain.BlockHash {
+		t.Fatalf("block %v %v", res.BlockNumber, res.BlockHash)
+	}
+
+	// Hash wins over Number; latest when neither is set.
+	hash := common.HexToHash("0xb10c")
+	RunBlock(context.Background(), client, BlockConfig{Hash: hash, Number: big.NewInt(1)})
+	if h, ok := client.got.Hash(); !ok || h != hash {
+		t.Fatalf("selector %v", client.got)
+	}
+	RunBlock(context.Background(), client, BlockConfig{})
+	if n, ok := client.got.Number(); !ok || n != rpc.LatestBlockNumber {
+		t.Fatalf("selector %v", client.got)
+	}
+
+	// One unknown fee makes the block total unknown.
+	old := receipt(2, 21_000, 0)
+	old.EffectiveGasPrice = nil
+	client.rcpts = append(client.rcpts, old)
+	if res, err := RunBlock(context.Background(), client, BlockConfig{}); err != nil || res.TotalFees != nil {
+		t.Fatalf("unknown fee: %v err=%v", res.TotalFees, err)
+	}
+
+	empty := &mockBlockClient{}
+	if res, err := RunBlock(context.Background(), empty, BlockConfig{Number: big.NewInt(7)}); err != nil || res.BlockNumber.Int64() != 7 || res.TotalFees.Sign() != 0 {
+		t.Fatalf("empty block: %+v err=%v", res, err)
+	}
+}
+
+func TestRunBlockErrors(t *testing.T) {
+	if _, err := RunBlock(context.Background(), nil, BlockConfig{}); err == nil {
+		t.Fatalf("expected nil client error")
+	}
+	if _, err := RunBlock(context.Background(), &mockBlockClient{}, BlockConfig{Number: big.NewInt(-1)}); err == nil {
+		t.Fatalf("expected invalid number error")
+	}
+	boom := errors.New("method not found")
+	if _, err := RunBlock(context.Background(), &mockBlockClient{err: boom}, BlockConfig{}); !errors.Is(err, boom) {
+		t.Fatalf("expected wrapped upstream error, got %v", err)
+	}
+	if _, err := RunBlock(context.Background(), &mockBlockClient{rcpts: []*types.Receipt{nil}}, BlockConfig{}); err == nil {
+		t.Fatalf("expected nil receipt error")
+	}
+}
+
+func TestResultJSON(t *testing.T) {
+	rcpt := receipt(0, 21_000, 10, erc20Transfer(alice, bob, 77, 0))
+	res, err := Run(context.Background(), &mockReceiptClient{rcpt: rcpt}, Config{TxHash: rcpt.TxHash})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	out, err := json.Marshal(res)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var got struct {
+		TotalFee *big.Int `json:"totalFee"`
+		Logs     []struct {
+			Data  string `json:"data"`
+			Event struct {
+				Name string `json:"name"`
+				Args []struct {
+					Name  string          `json:"name"`
+					Value json.RawMessage `json:"value"`
+				} `json:"args"`
+			} `json:"event"`
+		} `json:"logs"`
+	}
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, out)
+	}
+	if got.TotalFee.Int64() != 210_000 || len(got.Logs) != 1 || !strings.HasPrefix(got.Logs[0].Data, "0x") {
+		t.Fatalf("json %s", out)
+	}
+	ev := got.Logs[0].Event
+	if ev.Name != "ERC20.Transfer" || ev.Args[0].Name != "from" || string(ev.Args[2].Value) != "77" {
+		t.Fatalf("event json %s", out)
+	}
+	if !strings.EqualFold(string(ev.Args[0].Value), `"`+alice.Hex()+`"`) {
+		t.Fatalf("address arg %s", ev.Args[0].Value)
+	}
 }
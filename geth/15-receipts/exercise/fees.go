@@ -0,0 +1,30 @@
+package exercise
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// setFees fills the fee fields of res from rcpt.
+//
+// What a transaction paid is gasUsed * effectiveGasPrice, where the
+// effective price is min(feeCap, baseFee+tipCap) for dynamic-fee txs and the
+// gas price for legacy ones; the node computes it for us. A blob (type-3)
+// transaction also pays for its blobs in a separate fee market:
+// blobGasUsed * blobGasPrice. Rollup L1 data fees are chain-specific and
+// not included.
+func setFees(res *Result, rcpt *types.Receipt) {
+	res.BlobGasUsed = rcpt.BlobGasUsed
+	if rcpt.EffectiveGasPrice == nil {
+		return // pre-London node: the fee isn't in the receipt
+	}
+	res.EffectiveGasPrice = new(big.Int).Set(rcpt.EffectiveGasPrice)
+	res.ExecutionFee = new(big.Int).Mul(new(big.Int).SetUint64(rcpt.GasUsed), rcpt.EffectiveGasPrice)
+	res.TotalFee = new(big.Int).Set(res.ExecutionFee)
+	if rcpt.BlobGasPrice != nil {
+		res.BlobGasPrice = new(big.Int).Set(rcpt.BlobGasPrice)
+		res.BlobFee = new(big.Int).Mul(new(big.Int).SetUint64(rcpt.BlobGasUsed), rcpt.BlobGasPrice)
+		res.TotalFee.Add(res.TotalFee, res.BlobFee)
+	}
+}
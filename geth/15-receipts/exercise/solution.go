@@ -10,6 +10,8 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 /*
@@ -80,6 +82,18 @@ func Run(ctx context.Context, client ReceiptClient, cfg Config) (*Result, error)
 		return nil, errors.New("nil receipt")
 	}
 
+	// Steps 3-6 live in summarize so RunBlock can reuse them for every
+	// receipt of a block.
+	return summarize(rcpt, cfg.Registry), nil
+}
+
+// summarize turns one receipt into a Result: copied logs labelled against
+// reg, the receipt fields, and the fee paid.
+func summarize(rcpt *types.Receipt, reg *Registry) *Result {
+	if reg == nil {
+		reg = defaultRegistry
+	}
+
 	// ============================================================================
 	// STEP 3: Process Logs with Defensive Copying
 	// ============================================================================
@@ -109,12 +123,20 @@ func Run(ctx context.Context, client ReceiptClient, cfg Config) (*Result, error)
 		dataCopy := append([]byte(nil), lg.Data...)
 
 		// Construct LogSummary with copied data.
-		logs = append(logs, LogSummary{
-			Address: lg.Address,    // common.Address is value type (array), auto-copied
-			Topics:  topicsCopy,    // Copied slice
-			Data:    dataCopy,      // Copied slice
+		summary := LogSummary{
+			Address: lg.Address,     // common.Address is value type (array), auto-copied
+			Topics:  topicsCopy,     // Copied slice
+			Data:    dataCopy,       // Copied slice
 			Index:   uint(lg.Index), // uint64 → uint conversion, value type
-		})
+		}
+
+		// Label the log: topic0 is keccak256 of the event signature, so a
+		// registry of known event ABIs can name the event and unpack its
+		// arguments (topics for indexed ones, Data for the rest). This is the
+		// same decoding as module 09, driven by ABI JSON instead of
+		// hand-written decoders. Unknown events stay raw.
+		reg.label(&summary)
+		logs = append(logs, summary)
 	}
 
 	// ============================================================================
@@ -140,18 +162,40 @@ func Run(ctx context.Context, client ReceiptClient, cfg Config) (*Result, error)
 	//   - Defensive copying from modules 01, 12, 13
 	//   - Value vs reference types from all modules
 	//   - Receipt structure understanding
-	return &Result{
-		TxHash:        rcpt.TxHash,                           // Transaction identifier (Hash is value type)
-		BlockNumber:   new(big.Int).Set(rcpt.BlockNumber),  // Defensive copy: big.Int is mutable
-		StatusOK:      rcpt.Status == 1,                    // Convert status (1/0) to bool (true/false)
-		GasUsed:       rcpt.GasUsed,                        // Actual gas consumed (uint64 is value type)
-		CumulativeGas: rcpt.CumulativeGasUsed,              // Total gas used in block up to this tx
-		Contract:      rcpt.ContractAddress,                // Contract address if creation, else zero
-		Logs:          logs,                                // Copied logs slice
+	res := &Result{
+		TxHash:        rcpt.TxHash,                            // Transaction identifier (Hash is value type)
+		Type:          rcpt.Type,                              // 0 legacy, 1 access list, 2 dynamic fee, 3 blob
+		BlockHash:     rcpt.BlockHash,                         // Which block (by content) included it
+		TxIndex:       rcpt.TransactionIndex,                  // Position within that block
+		BlockNumber:   new(big.Int).Set(rcpt.BlockNumber),     // Defensive copy: big.Int is mutable
+		StatusOK:      rcpt.Status == 1,                       // Convert status (1/0) to bool (true/false)
+		GasUsed:       rcpt.GasUsed,                           // Actual gas consumed (uint64 is value type)
+		CumulativeGas: rcpt.CumulativeGasUsed,                 // Total gas used in block up to this tx
+		Contract:      rcpt.ContractAddress,                   // Contract address if creation, else zero
+		Logs:          logs,                                   // Copied logs slice
 		PostStateRoot: append([]byte(nil), rcpt.PostState...), // Defensive copy: []byte is reference type
-	}, nil
+	}
+
 	// ============================================================================
-	// STEP 5: Complete - Understanding the Receipt Lifecycle
+	// STEP 5: Fee Accounting - What Did This Transaction Cost?
+	// ============================================================================
+	// GasUsed alone isn't a cost: it has to be multiplied by the price paid per
+	// gas. Since London the receipt carries effectiveGasPrice, the price after
+	// applying baseFee + tip capped by maxFeePerGas, so:
+	//
+	//   executionFee = gasUsed * effectiveGasPrice
+	//
+	// Blob transactions (type 3, EIP-4844) additionally buy blob gas in its own
+	// fee market, reported as blobGasUsed and blobGasPrice:
+	//
+	//   totalFee = executionFee + blobGasUsed * blobGasPrice
+	//
+	// All of this is exact big.Int arithmetic in wei: fees are money, and
+	// floats lose wei.
+	setFees(res, rcpt)
+	return res
+	// ============================================================================
+	// STEP 6: Complete - Understanding the Receipt Lifecycle
 	// ============================================================================
 	// The progression of transaction data:
 	//   1. Transaction sent (module 05-06): User creates and signs transaction
@@ -168,3 +212,76 @@ func Run(ctx context.Context, client ReceiptClient, cfg Config) (*Result, error)
 	// from transaction execution), not part of transaction data itself. This
 	// separation keeps transaction data minimal while preserving execution results.
 }
+
+/*
+RunBlock fetches every receipt of a block in one eth_getBlockReceipts call
+and summarizes each one the same way Run does.
+
+A block with 200 transactions costs one round trip instead of 200. The
+result also sums gas and fees, which is what an accounting pipeline wants
+per block.
+*/
+func RunBlock(ctx context.Context, client BlockReceiptClient, cfg BlockConfig) (*BlockResult, error) {
+	// ============================================================================
+	// STEP 7: Block Receipts - One Call for the Whole Block
+	// ============================================================================
+	// Same validation as Run. The block selector follows JSON-RPC's
+	// BlockNumberOrHash: a hash pins one exact block (safe across reorgs), a
+	// number means "whatever is canonical at that height right now".
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if client == nil {
+		return nil, errors.New("client is nil")
+	}
+	var (
+		sel    rpc.BlockNumberOrHash
+		target string
+	)
+	switch {
+	case cfg.Hash != (common.Hash{}):
+		sel, target = rpc.BlockNumberOrHashWithHash(cfg.Hash, false), cfg.Hash.Hex()
+	case cfg.Number != nil:
+		if !cfg.Number.IsInt64() || cfg.Number.Sign() < 0 {
+			return nil, fmt.Errorf("invalid block number %s", cfg.Number)
+		}
+		sel, target = rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(cfg.Number.Int64())), cfg.Number.String()
+	default:
+		sel, target = rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber), "latest"
+	}
+
+	rcpts, err := client.BlockReceipts(ctx, sel)
+	if err != nil {
+		return nil, fmt.Errorf("block receipts %s: %w", target, err)
+	}
+
+	// Summarize in transaction order, summing gas and fees. One unknown fee
+	// makes the block total unknown rather than silently too low.
+	res := &BlockResult{
+		BlockHash: cfg.Hash,
+		Receipts:  make([]*Result, 0, len(rcpts)),
+		TotalFees: new(big.Int),
+	}
+	for i, rcpt := range rcpts {
+		if rcpt == nil {
+			return nil, fmt.Errorf("nil receipt at index %d", i)
+		}
+		r := summarize(rcpt, cfg.Registry)
+		res.Receipts = append(res.Receipts, r)
+		res.GasUsed += r.GasUsed
+		if r.TotalFee == nil || res.TotalFees == nil {
+			res.TotalFees = nil
+		} else {
+			res.TotalFees.Add(res.TotalFees, r.TotalFee)
+		}
+	}
+	// The receipts know which block they came from; an empty block only
+	// has what the caller asked for.
+	if len(res.Receipts) > 0 {
+		res.BlockNumber = res.Receipts[0].BlockNumber
+		res.BlockHash = res.Receipts[0].BlockHash
+	} else if cfg.Number != nil {
+		res.BlockNumber = new(big.Int).Set(cfg.Number)
+	}
+	return res, nil
+}
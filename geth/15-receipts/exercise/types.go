@@ -0,0 +1,80 @@
+package exercise
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ReceiptClient abstracts the single RPC we need.
+type ReceiptClient interface {
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+// BlockReceiptClient is implemented by clients that support
+// eth_getBlockReceipts (*ethclient.Client does).
+type BlockReceiptClient interface {
+	BlockReceipts(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]*types.Receipt, error)
+}
+
+// Config identifies which receipt to fetch.
+type Config struct {
+	TxHash   common.Hash
+	Registry *Registry // event ABIs to label logs with (nil = DefaultRegistry)
+}
+
+// BlockConfig identifies a block whose receipts to fetch. Hash wins over
+// Number; with neither set the latest block is used.
+type BlockConfig struct {
+	Number   *big.Int
+	Hash     common.Hash
+	Registry *Registry // nil = DefaultRegistry
+}
+
+// LogSummary is a lightweight view of a log entry. Event is set when the
+// registry knows the log's event; DecodeError when it knows the event but
+// the log doesn't match its ABI.
+type LogSummary struct {
+	Address     common.Address `json:"address"`
+	Topics      []common.Hash  `json:"topics"`
+	Data        hexutil.Bytes  `json:"data"`
+	Index       uint           `json:"logIndex"`
+	Event       *DecodedEvent  `json:"event,omitempty"`
+	DecodeError string         `json:"decodeError,omitempty"`
+}
+
+// Result captures decoded receipt data. Fee fields are in wei and nil when
+// the node doesn't report effectiveGasPrice (pre-London receipts).
+type Result struct {
+	TxHash        common.Hash    `json:"transactionHash"`
+	Type          uint8          `json:"type"`
+	BlockNumber   *big.Int       `json:"blockNumber"`
+	BlockHash     common.Hash    `json:"blockHash"`
+	TxIndex       uint           `json:"transactionIndex"`
+	StatusOK      bool           `json:"statusOk"`
+	GasUsed       uint64         `json:"gasUsed"`
+	Contract      common.Address `json:"contractAddress"`
+	Logs          []LogSummary   `json:"logs"`
+	CumulativeGas uint64         `json:"cumulativeGasUsed"`
+	PostStateRoot hexutil.Bytes  `json:"root,omitempty"`
+
+	EffectiveGasPrice *big.Int `json:"effectiveGasPrice"`
+	ExecutionFee      *big.Int `json:"executionFee"` // GasUsed * EffectiveGasPrice
+	BlobGasUsed       uint64   `json:"blobGasUsed,omitempty"`
+	BlobGasPrice      *big.Int `json:"blobGasPrice,omitempty"`
+	BlobFee           *big.Int `json:"blobFee,omitempty"` // BlobGasUsed * BlobGasPrice, type-3 only
+	TotalFee          *big.Int `json:"totalFee"`          // ExecutionFee + BlobFee
+}
+
+// BlockResult is every receipt of one block, in transaction order.
+type BlockResult struct {
+	BlockNumber *big.Int    `json:"blockNumber"`
+	BlockHash   common.Hash `json:"blockHash"`
+	Receipts    []*Result   `json:"receipts"`
+	GasUsed     uint64      `json:"gasUsed"`
+	TotalFees   *big.Int    `json:"totalFees"` // nil if any receipt's fee is unknown
+}
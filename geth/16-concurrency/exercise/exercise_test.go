@@ -1,7 +1,361 @@
 package exercise
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// scriptedProber sleeps for a per-endpoint latency and then fails or
+// reports a head, as scripted.
+type scriptedProber struct {
+	mu      sync.Mutex
+	latency map[string]time.Duration
+	fail    map[string]error
+	heads   map[string]uint64
+	probes  map[string]int
+}
+
+func newScriptedProber() *scriptedProber {
+	return &scriptedProber{
+		latency: make(map[string]time.Duration),
+		fail:    make(map[string]error),
+		heads:   make(map[string]uint64),
+		probes:  make(map[string]int),
+	}
+}
+
+func (s *scriptedProber) script(endpoint string, latency time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency[endpoint] = latency
+	s.fail[endpoint] = err
+}
+
+func (s *scriptedProber) count(endpoint string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.probes[endpoint]
+}
+
+func (s *scriptedProber) Probe(ctx context.Context, endpoint string) error {
+	s.mu.Lock()
+	s.probes[endpoint]++
+	latency, err := s.latency[endpoint], s.fail[endpoint]
+	s.mu.Unlock()
+	select {
+	case <-time.After(latency):
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// headProber adds ProbeHead on top of the script.
+type headProber struct{ *scriptedProber }
+
+func (h headProber) ProbeHead(ctx context.Context, endpoint string) (uint64, error) {
+	if err := h.Probe(ctx, endpoint); err != nil {
+		return 0, err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.heads[endpoint], nil
+}
+
+// fakeClock only moves when told to; After never fires.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(time.Duration) <-chan time.Time { return nil }
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+// readyClock's timers have always fired.
+type readyClock struct{ fakeClock }
+
+func (c *readyClock) After(time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	ch <- c.Now()
+	return ch
+}
+
+func statsFor(t *testing.T, p *EndpointPool, endpoint string) EndpointStats {
+	t.Helper()
+	for _, s := range p.Stats() {
+		if s.Endpoint == endpoint {
+			return s
+		}
+	}
+	t.Fatalf("no stats for %s", endpoint)
+	return EndpointStats{}
+}
 
 func TestRun(t *testing.T) {
-	t.Skip("add tests for this module")
+	prober := newScriptedProber()
+	prober.script("down", 0, errors.New("connection refused"))
+	res, err := Run(context.Background(), prober, Config{Endpoints: []string{"a", "b", "down"}, Workers: 2})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(res.Successes) != 2 || len(res.Failures) != 1 || res.Failures["down"] == nil {
+		t.Fatalf("result %+v", res)
+	}
+	if _, err := Run(context.Background(), nil, Config{}); err == nil {
+		t.Fatalf("expected nil prober error")
+	}
+}
+
+func TestNewEndpointPoolValidation(t *testing.T) {
+	prober := newScriptedProber()
+	for name, cfg := range map[string]PoolConfig{
+		"no endpoints": {},
+		"duplicate":    {Endpoints: []string{"a", "a"}},
+		"alpha":        {Endpoints: []string{"a"}, Alpha: 1.5},
+		"hedge":        {Endpoints: []string{"a"}, HedgeDelay: -time.Second},
+	} {
+		if _, err := NewEndpointPool(prober, cfg); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if _, err := NewEndpointPool(nil, PoolConfig{Endpoints: []string{"a"}}); err == nil {
+		t.Fatalf("expected nil prober error")
+	}
+}
+
+func TestPoolPrefersLowLatency(t *testing.T) {
+	prober := newScriptedProber()
+	prober.script("slow", 40*time.Millisecond, nil)
+	prober.script("fast", time.Millisecond, nil)
+	pool, _ := NewEndpointPool(prober, PoolConfig{Endpoints: []string{"slow", "fast"}})
+
+	if best, _ := pool.Best(); best != "slow" {
+		t.Fatalf("before probing, config order should win; got %s", best)
+	}
+	pool.ProbeAll(context.Background())
+	pool.ProbeAll(context.Background())
+	if best, _ := pool.Best(); best != "fast" {
+		t.Fatalf("best = %s, want fast", best)
+	}
+	stats := pool.Stats()
+	if stats[0].Endpoint != "fast" || stats[0].Score != 1 || stats[1].Score >= 0.5 || stats[1].Latency < 40*time.Millisecond {
+		t.Fatalf("stats %+v", stats)
+	}
+}
+
+func TestPoolScoresErrorRateAndFreshness(t *testing.T) {
+	prober := newScriptedProber()
+	prober.heads["a"], prober.heads["b"], prober.heads["c"] = 100, 99, 97
+	clock := &fakeClock{now: time.Unix(0, 0)} // frozen: equal latencies
+	pool, _ := NewEndpointPool(headProber{prober}, PoolConfig{Endpoints: []string{"c", "b", "a"}, MaxLag: 3, Clock: clock})
+	pool.ProbeAll(context.Background())
+
+	stats := pool.Stats()
+	if stats[0].Endpoint != "a" || stats[1].Endpoint != "b" || stats[2].Endpoint != "c" {
+		t.Fatalf("order %+v", stats)
+	}
+	if stats[1].Lag != 1 || stats[2].Lag != 3 || stats[2].Score != 0 {
+		t.Fatalf("lag scoring %+v", stats)
+	}
+
+	// b catches up while a fails once: a's error rate now costs it the lead.
+	prober.script("a", 0, errors.New("503"))
+	prober.heads["b"] = 100
+	pool.ProbeAll(context.Background())
+	if a := statsFor(t, pool, "a"); a.ErrorRate != 0.3 || a.Breaker != BreakerClosed {
+		t.Fatalf("a after one failure %+v", a)
+	}
+	if best, _ := pool.Best(); best != "b" {
+		t.Fatalf("best = %s, want b", best)
+	}
+}
+
+func TestPoolCircuitBreaker(t *testing.T) {
+	prober := newScriptedProber()
+	prober.script("flaky", 0, errors.New("timeout"))
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	pool, _ := NewEndpointPool(prober, PoolConfig{
+		Endpoints: []string{"flaky", "ok"}, FailureLimit: 2, BreakerCooldown: time.Minute, Clock: clock,
+	})
+	ctx := context.Background()
+
+	pool.ProbeAll(ctx)
+	pool.ProbeAll(ctx)
+	if s := statsFor(t, pool, "flaky"); s.Breaker != BreakerOpen || s.Score != 0 {
+		t.Fatalf("breaker should be open: %+v", s)
+	}
+
+	// Open: neither probes nor calls reach it.
+	pool.ProbeAll(ctx)
+	var used []string
+	for i := 0; i < 3; i++ {
+		pool.Do(ctx, func(ctx context.Context, ep string) error { used = append(used, ep); return nil })
+	}
+	if prober.count("flaky") != 2 || len(used) != 3 || used[0] != "ok" || used[2] != "ok" {
+		t.Fatalf("open breaker leaked: probes=%d calls=%v", prober.count("flaky"), used)
+	}
+
+	// Cooldown over: one trial. It fails, so the breaker reopens at once.
+	clock.Advance(time.Minute)
+	pool.ProbeAll(ctx)
+	if s := statsFor(t, pool, "flaky"); s.Breaker != BreakerOpen || prober.count("flaky") != 3 {
+		t.Fatalf("failed trial should reopen: %+v probes=%d", s, prober.count("flaky"))
+	}
+
+	// Next trial succeeds and closes it.
+	clock.Advance(time.Minute)
+	prober.script("flaky", 0, nil)
+	pool.ProbeAll(ctx)
+	if s := statsFor(t, pool, "flaky"); s.Breaker != BreakerClosed {
+		t.Fatalf("successful trial should close: %+v", s)
+	}
+}
+
+func TestPoolHalfOpenAllowsOneTrial(t *testing.T) {
+	prober := newScriptedProber()
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	pool, _ := NewEndpointPool(prober, PoolConfig{Endpoints: []string{"a"}, FailureLimit: 1, BreakerCooldown: time.Second, Clock: clock})
+	ctx := context.Background()
+	pool.Do(ctx, func(context.Context, string) error { return errors.New("down") })
+	clock.Advance(time.Second)
+
+	release := make(chan struct{})
+	trial := make(chan error)
+	go func() {
+		trial <- pool.Do(ctx, func(ctx context.Context, _ string) error { <-release; return nil })
+	}()
+	// Wait until the trial holds the slot, then a second call must be refused.
+	for statsFor(t, pool, "a").Breaker != BreakerHalfOpen {
+		time.Sleep(time.Millisecond)
+	}
+	if err := pool.Do(ctx, func(context.Context, string) error { return nil }); !errors.Is(err, ErrNoEndpoints) {
+		t.Fatalf("second call during trial: %v", err)
+	}
+	close(release)
+	if err := <-trial; err != nil {
+		t.Fatalf("trial: %v", err)
+	}
+	if s := statsFor(t, pool, "a"); s.Breaker != BreakerClosed {
+		t.Fatalf("after trial %+v", s)
+	}
+}
+
+func TestPoolFailover(t *testing.T) {
+	pool, _ := NewEndpointPool(newScriptedProber(), PoolConfig{Endpoints: []string{"a", "b", "c"}})
+	var tried []string
+	err := pool.Do(context.Background(), func(ctx context.Context, ep string) error {
+		tried = append(tried, ep)
+		if ep == "c" {
+			return nil
+		}
+		return errors.New(ep + " down")
+	})
+	if err != nil || len(tried) != 3 {
+		t.Fatalf("failover: err=%v tried=%v", err, tried)
+	}
+	if a := statsFor(t, pool, "a"); a.Failures != 1 || a.Requests != 1 {
+		t.Fatalf("a stats %+v", a)
+	}
+
+	boom := errors.New("boom")
+	err = pool.Do(context.Background(), func(ctx context.Context, ep string) error { return boom })
+	if !errors.Is(err, boom) {
+		t.Fatalf("all failed: %v", err)
+	}
+
+	limited, _ := NewEndpointPool(newScriptedProber(), PoolConfig{Endpoints: []string{"a", "b", "c"}, MaxAttempts: 2})
+	tried = nil
+	limited.Do(context.Background(), func(ctx context.Context, ep string) error { tried = append(tried, ep); return boom })
+	if len(tried) != 2 {
+		t.Fatalf("MaxAttempts 2 tried %v", tried)
+	}
+}
+
+func TestPoolPermanentErrorStopsFailover(t *testing.T) {
+	pool, _ := NewEndpointPool(newScriptedProber(), PoolConfig{Endpoints: []string{"a", "b"}})
+	revert := errors.New("execution reverted")
+	calls := 0
+	err := pool.Do(context.Background(), func(ctx context.Context, ep string) error {
+		calls++
+		return Permanent(revert)
+	})
+	if err != revert || calls != 1 {
+		t.Fatalf("err=%v calls=%d", err, calls)
+	}
+	if a := statsFor(t, pool, "a"); a.Failures != 0 || a.ErrorRate != 0 {
+		t.Fatalf("permanent error penalized the endpoint: %+v", a)
+	}
+}
+
+func TestPoolHedgedRequest(t *testing.T) {
+	pool, _ := NewEndpointPool(newScriptedProber(), PoolConfig{Endpoints: []string{"stuck", "quick"}, HedgeDelay: 10 * time.Millisecond})
+	var mu sync.Mutex
+	started := map[string]bool{}
+	start := time.Now()
+	err := pool.Do(context.Background(), func(ctx context.Context, ep string) error {
+		mu.Lock()
+		started[ep] = true
+		mu.Unlock()
+		if ep == "stuck" {
+			<-ctx.Done() // only the hedge can finish this call
+			return ctx.Err()
+		}
+		return nil
+	})
+	if err != nil || !started["quick"] || time.Since(start) > time.Second {
+		t.Fatalf("hedge: err=%v started=%v", err, started)
+	}
+	// The cancelled loser is not blamed.
+	if s := statsFor(t, pool, "stuck"); s.Failures != 0 || s.Breaker != BreakerClosed {
+		t.Fatalf("loser penalized: %+v", s)
+	}
+
+	// Without hedging the stuck endpoint holds the call until the deadline.
+	plain, _ := NewEndpointPool(newScriptedProber(), PoolConfig{Endpoints: []string{"stuck", "quick"}})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = plain.Do(ctx, func(ctx context.Context, ep string) error { <-ctx.Done(); return ctx.Err() })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("no hedge: %v", err)
+	}
+}
+
+func TestPoolRunStopsWhenCancelled(t *testing.T) {
+	prober := newScriptedProber()
+	pool, _ := NewEndpointPool(prober, PoolConfig{Endpoints: []string{"a"}, Clock: &readyClock{}})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := pool.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run: %v", err)
+	}
+	if n := prober.count("a"); n != 0 {
+		t.Fatalf("probed %d times after cancellation", n)
+	}
+}
+
+func TestPoolRunProbesInBackground(t *testing.T) {
+	prober := newScriptedProber()
+	pool, _ := NewEndpointPool(prober, PoolConfig{Endpoints: []string{"a", "b"}, ProbeInterval: 5 * time.Millisecond})
+	ctx, cancel := context.WithTimeout(context.Background(), 40*time.Millisecond)
+	defer cancel()
+	if err := pool.Run(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Run: %v", err)
+	}
+	if prober.count("a") < 2 || prober.count("b") < 2 {
+		t.Fatalf("probes a=%d b=%d", prober.count("a"), prober.count("b"))
+	}
 }
@@ -0,0 +1,477 @@
+package exercise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Circuit breaker states, as reported in EndpointStats.Breaker.
+const (
+	BreakerClosed   = "closed"    // healthy: calls flow
+	BreakerOpen     = "open"      // failing: no calls until the cooldown ends
+	BreakerHalfOpen = "half-open" // cooled down: one trial call decides
+)
+
+// ErrNoEndpoints is returned by Do when every breaker is open.
+var ErrNoEndpoints = errors.New("no endpoint available")
+
+// HeadProber is optionally implemented by Probers that can also report the
+// endpoint's head block number. The pool then scores head freshness and
+// stops preferring nodes that have fallen behind.
+type HeadProber interface {
+	ProbeHead(ctx context.Context, endpoint string) (uint64, error)
+}
+
+// Clock abstracts time so tests can step through breaker cooldowns.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// PoolConfig tunes the endpoint pool. Zero values use defaults.
+type PoolConfig struct {
+	Endpoints       []string
+	ProbeInterval   time.Duration // background probe period (default 10s)
+	ProbeTimeout    time.Duration // per-probe timeout (default 2s)
+	Alpha           float64       // EWMA weight of the newest sample, (0,1] (default 0.3)
+	MaxLag          uint64        // blocks behind the best head at which freshness is 0 (default 3)
+	FailureLimit    int           // consecutive failures that open a breaker (default 3)
+	BreakerCooldown time.Duration // how long a breaker stays open (default 30s)
+	MaxAttempts     int           // endpoints tried per Do call (default: all)
+	HedgeDelay      time.Duration // start the next endpoint if no answer by then (0 = no hedging)
+	Clock           Clock
+}
+
+// EndpointStats is a snapshot of one endpoint's health.
+type EndpointStats struct {
+	Endpoint  string
+	Score     float64       // 0 (unusable) to 1 (best in pool)
+	Latency   time.Duration // EWMA of successful probes and calls
+	ErrorRate float64       // EWMA of failures, 0 to 1
+	Head      uint64        // last head reported by a HeadProber
+	Lag       uint64        // blocks behind the best head in the pool
+	Breaker   string
+	Requests  uint64 // probes and calls
+	Failures  uint64
+}
+
+type endpointState struct {
+	name      string
+	latency   float64 // EWMA in nanoseconds; 0 until the first success
+	errRate   float64
+	head      uint64
+	requests  uint64
+	failures  uint64
+	breaker   string
+	fails     int // consecutive
+	openedAt  time.Time
+	trialBusy bool // a half-open trial is in flight
+}
+
+// EndpointPool routes calls to the healthiest of several RPC endpoints.
+//
+// Every endpoint is scored from three signals, each in [0,1] and multiplied:
+// latency (best EWMA in the pool / this endpoint's EWMA), reliability
+// (1 - error-rate EWMA), and freshness (how close its head is to the best
+// head seen). Probes in the background and real calls both feed the scores.
+//
+// A per-endpoint circuit breaker opens after FailureLimit consecutive
+// failures, so a dead node stops costing a timeout on every call. After
+// BreakerCooldown one trial (probe or call) is let through: success closes
+// the breaker, failure reopens it.
+type EndpointPool struct {
+	prober Prober
+	cfg    PoolConfig
+
+	mu        sync.Mutex
+	endpoints []*endpointState
+	byName    map[string]*endpointState
+}
+
+// NewEndpointPool validates cfg and returns a pool with every endpoint
+// unprobed and its breaker closed.
+func NewEndpointPool(p Prober, cfg PoolConfig) (*EndpointPool, error) {
+	if p == nil {
+		return nil, errors.New("prober is nil")
+	}
+	if len(cfg.Endpoints) == 0 {
+		return nil, errors.New("no endpoints")
+	}
+	if cfg.Alpha < 0 || cfg.Alpha > 1 {
+		return nil, fmt.Errorf("alpha %v outside (0,1]", cfg.Alpha)
+	}
+	if cfg.Alpha == 0 {
+		cfg.Alpha = 0.3
+	}
+	if cfg.ProbeInterval <= 0 {
+		cfg.ProbeInterval = 10 * time.Second
+	}
+	if cfg.ProbeTimeout <= 0 {
+		cfg.ProbeTimeout = 2 * time.Second
+	}
+	if cfg.MaxLag == 0 {
+		cfg.MaxLag = 3
+	}
+	if cfg.FailureLimit <= 0 {
+		cfg.FailureLimit = 3
+	}
+	if cfg.BreakerCooldown <= 0 {
+		cfg.BreakerCooldown = 30 * time.Second
+	}
+	if cfg.MaxAttempts <= 0 || cfg.MaxAttempts > len(cfg.Endpoints) {
+		cfg.MaxAttempts = len(cfg.Endpoints)
+	}
+	if cfg.HedgeDelay < 0 {
+		return nil, fmt.Errorf("negative hedge delay %s", cfg.HedgeDelay)
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = realClock{}
+	}
+	pool := &EndpointPool{prober: p, cfg: cfg, byName: make(map[string]*endpointState)}
+	for _, name := range cfg.Endpoints {
+		if _, dup := pool.byName[name]; dup {
+			return nil, fmt.Errorf("duplicate endpoint %q", name)
+		}
+		st := &endpointState{name: name, breaker: BreakerClosed}
+		pool.endpoints = append(pool.endpoints, st)
+		pool.byName[name] = st
+	}
+	return pool, nil
+}
+
+// Run probes every endpoint each ProbeInterval until ctx is cancelled.
+func (p *EndpointPool) Run(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for {
+		// No further round of probes once cancelled, even when
+		// ProbeInterval has already fired.
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		p.ProbeAll(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-p.cfg.Clock.After(p.cfg.ProbeInterval):
+		}
+	}
+}
+
+// ProbeAll probes every endpoint whose breaker allows it, concurrently, and
+// waits for all of them. Run calls it on every interval; tests can call it
+// directly.
+func (p *EndpointPool) ProbeAll(ctx context.Context) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var wg sync.WaitGroup
+	for _, name := range p.cfg.Endpoints {
+		if !p.acquire(name) {
+			continue
+		}
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			pctx, cancel := context.WithTimeout(ctx, p.cfg.ProbeTimeout)
+			defer cancel()
+
+			start := p.cfg.Clock.Now()
+			var (
+				head    uint64
+				hasHead bool
+				err     error
+			)
+			if hp, ok := p.prober.(HeadProber); ok {
+				head, err = hp.ProbeHead(pctx, name)
+				hasHead = err == nil
+			} else {
+				err = p.prober.Probe(pctx, name)
+			}
+			if err != nil && ctx.Err() != nil {
+				p.release(name) // we were cancelled; not the endpoint's fault
+				return
+			}
+			p.observe(name, p.cfg.Clock.Now().Sub(start), err)
+			if hasHead {
+				p.mu.Lock()
+				p.byName[name].head = head
+				p.mu.Unlock()
+			}
+		}(name)
+	}
+	wg.Wait()
+}
+
+// permanentError wraps an error that is the call's answer, not an endpoint
+// fault.
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as the real answer to a call (a revert, an invalid
+// argument): Do returns it without trying another endpoint, and the
+// endpoint that produced it is not penalized.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err}
+}
+
+type attempt struct {
+	endpoint string
+	err      error
+}
+
+// Do runs call against the best endpoint. If it fails, the next best is
+// tried, up to MaxAttempts endpoints. With HedgeDelay set, an endpoint that
+// hasn't answered within the delay gets company: the next endpoint is
+// started in parallel and the first success wins; the rest are cancelled
+// and not counted against their endpoints.
+func (p *EndpointPool) Do(ctx context.Context, call func(ctx context.Context, endpoint string) error) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if call == nil {
+		return errors.New("call is nil")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	candidates := p.ranked()
+	results := make(chan attempt, len(candidates))
+	next, started, inflight := 0, 0, 0
+	launch := func() bool {
+		for next < len(candidates) && started < p.cfg.MaxAttempts {
+			name := candidates[next]
+			next++
+			if !p.acquire(name) {
+				continue
+			}
+			started++
+			inflight++
+			go func() {
+				start := p.cfg.Clock.Now()
+				err := call(ctx, name)
+				var perm permanentError
+				switch {
+				case err == nil || errors.As(err, &perm):
+					p.observe(name, p.cfg.Clock.Now().Sub(start), nil)
+				case ctx.Err() != nil:
+					p.release(name) // lost a hedge race or caller gave up
+				default:
+					p.observe(name, 0, err)
+				}
+				results <- attempt{name, err}
+			}()
+			return true
+		}
+		return false
+	}
+
+	if !launch() {
+		return ErrNoEndpoints
+	}
+	var hedge <-chan time.Time
+	if p.cfg.HedgeDelay > 0 {
+		hedge = p.cfg.Clock.After(p.cfg.HedgeDelay)
+	}
+	var errs []error
+	for inflight > 0 {
+		select {
+		case r := <-results:
+			inflight--
+			var perm permanentError
+			if r.err == nil {
+				return nil
+			}
+			if errors.As(r.err, &perm) {
+				return perm.err
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", r.endpoint, r.err))
+			if ctx.Err() == nil {
+				launch() // fail over right away, don't wait for the hedge timer
+			}
+		case <-hedge:
+			hedge = nil
+			if ctx.Err() == nil && launch() {
+				hedge = p.cfg.Clock.After(p.cfg.HedgeDelay)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return fmt.Errorf("all %d attempts failed: %w", len(errs), errors.Join(errs...))
+}
+
+// Best returns the endpoint Do would try first.
+func (p *EndpointPool) Best() (string, bool) {
+	for _, name := range p.ranked() {
+		p.mu.Lock()
+		ok := p.available(p.byName[name], p.cfg.Clock.Now())
+		p.mu.Unlock()
+		if ok {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// Stats returns every endpoint's health, best first.
+func (p *EndpointPool) Stats() []EndpointStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	scores, best := p.scores()
+	out := make([]EndpointStats, 0, len(p.endpoints))
+	for _, st := range p.endpoints {
+		s := EndpointStats{
+			Endpoint:  st.name,
+			Score:     scores[st.name],
+			Latency:   time.Duration(st.latency),
+			ErrorRate: st.errRate,
+			Head:      st.head,
+			Breaker:   st.breaker,
+			Requests:  st.requests,
+			Failures:  st.failures,
+		}
+		if st.head > 0 && best > st.head {
+			s.Lag = best - st.head
+		}
+		out = append(out, s)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	return out
+}
+
+// ranked returns endpoint names by descending score, config order on ties.
+func (p *EndpointPool) ranked() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	scores, _ := p.scores()
+	names := make([]string, len(p.endpoints))
+	for i, st := range p.endpoints {
+		names[i] = st.name
+	}
+	sort.SliceStable(names, func(i, j int) bool { return scores[names[i]] > scores[names[j]] })
+	return names
+}
+
+// scores computes every endpoint's score and the best head. Callers hold mu.
+func (p *EndpointPool) scores() (map[string]float64, uint64) {
+	var bestLatency float64
+	var bestHead uint64
+	for _, st := range p.endpoints {
+		if st.latency > 0 && (bestLatency == 0 || st.latency < bestLatency) {
+			bestLatency = st.latency
+		}
+		if st.head > bestHead {
+			bestHead = st.head
+		}
+	}
+	now := p.cfg.Clock.Now()
+	out := make(map[string]float64, len(p.endpoints))
+	for _, st := range p.endpoints {
+		if st.breaker == BreakerOpen && now.Sub(st.openedAt) < p.cfg.BreakerCooldown {
+			out[st.name] = 0
+			continue
+		}
+		latency := 1.0
+		if st.latency > 0 {
+			latency = bestLatency / st.latency
+		}
+		fresh := 1.0
+		if st.head > 0 && bestHead > st.head {
+			lag := bestHead - st.head
+			if lag >= p.cfg.MaxLag {
+				fresh = 0
+			} else {
+				fresh = 1 - float64(lag)/float64(p.cfg.MaxLag)
+			}
+		}
+		out[st.name] = latency * (1 - st.errRate) * fresh
+	}
+	return out, bestHead
+}
+
+// available reports whether the breaker would let a request through.
+// Callers hold mu.
+func (p *EndpointPool) available(st *endpointState, now time.Time) bool {
+	switch st.breaker {
+	case BreakerOpen:
+		return now.Sub(st.openedAt) >= p.cfg.BreakerCooldown
+	case BreakerHalfOpen:
+		return !st.trialBusy
+	}
+	return true
+}
+
+// acquire asks the breaker for permission to send one request, claiming
+// the half-open trial slot if that's what lets it through.
+func (p *EndpointPool) acquire(name string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	st := p.byName[name]
+	if !p.available(st, p.cfg.Clock.Now()) {
+		return false
+	}
+	if st.breaker != BreakerClosed {
+		st.breaker = BreakerHalfOpen
+		st.trialBusy = true
+	}
+	return true
+}
+
+// release gives back a trial slot whose request was cancelled before it
+// could tell us anything.
+func (p *EndpointPool) release(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.byName[name].trialBusy = false
+}
+
+// observe records one request's outcome in the EWMAs and the breaker.
+func (p *EndpointPool) observe(name string, latency time.Duration, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	st := p.byName[name]
+	a := p.cfg.Alpha
+	st.requests++
+	st.trialBusy = false
+
+	// The error rate starts at 0 (innocent until proven guilty), so one
+	// early failure dents the score instead of zeroing it.
+	failed := 0.0
+	if err != nil {
+		failed = 1
+	}
+	st.errRate = a*failed + (1-a)*st.errRate
+
+	if err != nil {
+		st.failures++
+		st.fails++
+		if st.breaker == BreakerHalfOpen || st.fails >= p.cfg.FailureLimit {
+			st.breaker = BreakerOpen
+			st.openedAt = p.cfg.Clock.Now()
+		}
+		return
+	}
+	st.fails = 0
+	st.breaker = BreakerClosed
+	if st.latency == 0 {
+		st.latency = float64(latency)
+	} else {
+		st.latency = a*float64(latency) + (1-a)*st.latency
+	}
+}
@@ -3,5 +3,38 @@
 
 package exercise
 
-// Run currently acts as a placeholder; see module README for full implementation steps.
-func Run() {}
+import (
+	"context"
+	"errors"
+)
+
+/*
+Problem: Index ERC20 Transfer events into a Store, resuming from a checkpoint.
+
+Module 09 decoded Transfer logs for one block range in one call. Real indexers
+cannot do that: providers cap eth_getLogs ranges, processes crash, and a
+restart must not re-index (or skip) blocks. The Indexer in indexer.go walks the
+range in batches and commits each batch together with a checkpoint.
+
+Computer science principles highlighted:
+  - Batching: bounded work per request keeps RPC calls within provider limits
+  - Checkpointing: persisted progress makes long jobs restartable
+  - Atomic commits: events and checkpoint move together or not at all
+*/
+func Run(ctx context.Context, client LogClient, store Store, cfg Config) (*Result, error) {
+	// TODO: Validate input parameters
+	// - Default a nil ctx to context.Background()
+	// - NewIndexer already rejects a nil client/store, a zero token and an
+	//   inverted block range, so you only need to surface its error
+
+	// TODO: Build the indexer
+	// - Call NewIndexer(client, store, cfg)
+	// - BatchSize 0 falls back to DefaultBatchSize
+
+	// TODO: Run it
+	// - Indexer.Run reads store.Checkpoint() and resumes after it
+	// - Each batch: FilterLogs -> decodeTransferLog -> store.Commit(events, lastBlock)
+	// - Return the Result (batches, events, whether the run resumed)
+
+	return nil, errors.New("not implemented")
+}
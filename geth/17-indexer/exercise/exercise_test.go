@@ -1,7 +1,340 @@
 package exercise
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
 
-func TestRun(t *testing.T) {
-	t.Skip("add tests for this module")
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	testToken = common.HexToAddress("0x000000000000000000000000000000000000dead")
+	otherTok  = common.HexToAddress("0x000000000000000000000000000000000000beef")
+)
+
+// mockLogClient serves a synthetic chain: every third block carries a
+// Transfer from testToken, every fifth block one from otherTok (noise the
+// address filter must drop).
+type mockLogClient struct {
+	head     uint64
+	calls    []ethereum.FilterQuery
+	failCall int // 1-based call number that returns an error (0 => never)
+}
+
+func (m *mockLogClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	m.calls = append(m.calls, q)
+	if m.failCall != 0 && len(m.calls) == m.failCall {
+		return nil, errors.New("rpc unavailable")
+	}
+	from, to := q.FromBlock.Uint64(), q.ToBlock.Uint64()
+	if to > m.head {
+		to = m.head
+	}
+	var logs []types.Log
+	for n := from; n <= to; n++ {
+		for _, lg := range syntheticLogs(n) {
+			if matchesAddress(q.Addresses, lg.Address) {
+				logs = append(logs, lg)
+			}
+		}
+	}
+	return logs, nil
+}
+
+func syntheticLogs(n uint64) []types.Log {
+	var out []types.Log
+	mk := func(token common.Address, idx uint) types.Log {
+		from := common.BigToAddress(new(big.Int).SetUint64(n))
+		to := common.BigToAddress(new(big.Int).SetUint64(n + 1))
+		return types.Log{
+			Address:     token,
+			Topics:      []common.Hash{transferSigHash, addressTopic(from), addressTopic(to)},
+			Data:        common.LeftPadBytes(new(big.Int).SetUint64(n*10).Bytes(), 32),
+			BlockNumber: n,
+			BlockHash:   common.BigToHash(new(big.Int).SetUint64(n)),
+			TxHash:      common.BigToHash(new(big.Int).SetUint64(n<<8 | uint64(idx))),
+			Index:       idx,
+		}
+	}
+	if n%3 == 0 {
+		out = append(out, mk(testToken, 0))
+	}
+	if n%5 == 0 {
+		out = append(out, mk(otherTok, 1))
+	}
+	return out
+}
+
+func addressTopic(addr common.Address) common.Hash {
+	return common.BytesToHash(common.LeftPadBytes(addr.Bytes(), 32))
+}
+
+func matchesAddress(addrs []common.Address, a common.Address) bool {
+	if len(addrs) == 0 {
+		return true
+	}
+	for _, x := range addrs {
+		if x == a {
+			return true
+		}
+	}
+	return false
+}
+
+func expectedTransfers(from, to uint64) int {
+	count := 0
+	for n := from; n <= to; n++ {
+		if n%3 == 0 {
+			count++
+		}
+	}
+	return count
+}
+
+func assertContiguous(t *testing.T, events []TransferEvent) {
+	t.Helper()
+	seen := make(map[common.Hash]bool, len(events))
+	for i, ev := range events {
+		if seen[ev.TxHash] {
+			t.Fatalf("duplicate event %s", ev.TxHash.Hex())
+		}
+		seen[ev.TxHash] = true
+		if i > 0 && ev.BlockNumber <= events[i-1].BlockNumber {
+			t.Fatalf("events out of order at %d: %d after %d", i, ev.BlockNumber, events[i-1].BlockNumber)
+		}
+		if ev.Value.Uint64() != ev.BlockNumber*10 {
+			t.Fatalf("block %d decoded value %s", ev.BlockNumber, ev.Value)
+		}
+	}
+}
+
+func TestIndexerWalksRangeInBatches(t *testing.T) {
+	client := &mockLogClient{head: 6000}
+	store := NewMemoryStore()
+	ix, err := NewIndexer(client, store, Config{Token: testToken, FromBlock: 1, ToBlock: 5000, BatchSize: 500})
+	if err != nil {
+		t.Fatalf("NewIndexer: %v", err)
+	}
+
+	res, err := ix.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if res.Batches != 10 || res.Resumed {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	want := expectedTransfers(1, 5000)
+	if res.Events != want {
+		t.Fatalf("expected %d events, got %d", want, res.Events)
+	}
+
+	next := uint64(1)
+	for i, q := range client.calls {
+		if q.FromBlock.Uint64() != next {
+			t.Fatalf("call %d starts at %d, want %d", i, q.FromBlock.Uint64(), next)
+		}
+		if q.Topics[0][0] != transferSigHash || q.Addresses[0] != testToken {
+			t.Fatalf("call %d missing filter: %+v", i, q)
+		}
+		next = q.ToBlock.Uint64() + 1
+	}
+	if next != 5001 {
+		t.Fatalf("scan stopped at %d", next-1)
+	}
+
+	events, _ := store.Events()
+	if len(events) != want {
+		t.Fatalf("store holds %d events, want %d", len(events), want)
+	}
+	assertContiguous(t, events)
+	if cp, ok, _ := store.Checkpoint(); !ok || cp != 5000 {
+		t.Fatalf("checkpoint = %d (%v), want 5000", cp, ok)
+	}
+}
+
+func TestIndexerResumesAfterFailure(t *testing.T) {
+	store := NewMemoryStore()
+	cfg := Config{Token: testToken, FromBlock: 100, ToBlock: 4099, BatchSize: 250}
+
+	failing := &mockLogClient{head: 5000, failCall: 5}
+	ix, _ := NewIndexer(failing, store, cfg)
+	res, err := ix.Run(context.Background())
+	if err == nil {
+		t.Fatalf("expected error from failing client")
+	}
+	if res.Batches != 4 {
+		t.Fatalf("expected 4 committed batches before failure, got %d", res.Batches)
+	}
+	if cp, _, _ := store.Checkpoint(); cp != 100+4*250-1 {
+		t.Fatalf("checkpoint = %d after failure", cp)
+	}
+
+	healthy := &mockLogClient{head: 5000}
+	ix, _ = NewIndexer(healthy, store, cfg)
+	res, err = ix.Run(context.Background())
+	if err != nil {
+		t.Fatalf("resume Run: %v", err)
+	}
+	if !res.Resumed || res.StartBlock != 1100 {
+		t.Fatalf("expected resume from 1100, got %+v", res)
+	}
+	if got := healthy.calls[0].FromBlock.Uint64(); got != 1100 {
+		t.Fatalf("first resumed query starts at %d", got)
+	}
+
+	events, _ := store.Events()
+	if want := expectedTransfers(100, 4099); len(events) != want {
+		t.Fatalf("expected %d events after resume, got %d", want, len(events))
+	}
+	assertContiguous(t, events)
+
+	// A third run has nothing left to do.
+	idle := &mockLogClient{head: 5000}
+	ix, _ = NewIndexer(idle, store, cfg)
+	res, err = ix.Run(context.Background())
+	if err != nil || res.Batches != 0 || len(idle.calls) != 0 {
+		t.Fatalf("expected no-op run, got %+v err=%v calls=%d", res, err, len(idle.calls))
+	}
+}
+
+func TestJSONLStoreSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	cfg := Config{Token: testToken, FromBlock: 0, ToBlock: 2999, BatchSize: 400}
+
+	store, err := OpenJSONLStore(dir)
+	if err != nil {
+		t.Fatalf("OpenJSONLStore: %v", err)
+	}
+	ix, _ := NewIndexer(&mockLogClient{head: 3000, failCall: 3}, store, cfg)
+	if _, err := ix.Run(context.Background()); err == nil {
+		t.Fatalf("expected failure on third batch")
+	}
+
+	// Reopen from disk as a restarted process would.
+	store, err = OpenJSONLStore(dir)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if cp, ok, _ := store.Checkpoint(); !ok || cp != 799 {
+		t.Fatalf("checkpoint after restart = %d (%v)", cp, ok)
+	}
+	ix, _ = NewIndexer(&mockLogClient{head: 3000}, store, cfg)
+	res, err := ix.Run(context.Background())
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if !res.Resumed || res.StartBlock != 800 {
+		t.Fatalf("unexpected resume result %+v", res)
+	}
+
+	events, err := store.Events()
+	if err != nil {
+		t.Fatalf("Events: %v", err)
+	}
+	if want := expectedTransfers(0, 2999); len(events) != want {
+		t.Fatalf("expected %d events, got %d", want, len(events))
+	}
+	assertContiguous(t, events)
+}
+
+func TestJSONLStoreTrimsUncommittedTail(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := OpenJSONLStore(dir)
+	committed := TransferEvent{BlockNumber: 10, TxHash: common.HexToHash("0x01"), Value: big.NewInt(1)}
+	if err := store.Commit([]TransferEvent{committed}, 10); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	// Simulate a crash between appending events and saving the checkpoint.
+	f, err := os.OpenFile(filepath.Join(dir, eventsFile), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("open events: %v", err)
+	}
+	f.WriteString(`{"blockNumber":11,"txHash":"0x0000000000000000000000000000000000000000000000000000000000000002","value":2}` + "\n")
+	f.Close()
+
+	store, err = OpenJSONLStore(dir)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	events, _ := store.Events()
+	if len(events) != 1 || events[0].BlockNumber != 10 {
+		t.Fatalf("expected only committed event, got %+v", events)
+	}
+}
+
+func TestJSONLStoreCommitFailureLeavesNoEvents(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := OpenJSONLStore(dir)
+	first := TransferEvent{BlockNumber: 10, TxHash: common.HexToHash("0x01"), Value: big.NewInt(1)}
+	if err := store.Commit([]TransferEvent{first}, 10); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	// A directory in place of the checkpoint makes its rename fail after
+	// the events were already appended.
+	cpPath := filepath.Join(dir, checkpointFile)
+	if err := os.Remove(cpPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(cpPath, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	second := TransferEvent{BlockNumber: 20, TxHash: common.HexToHash("0x02"), Value: big.NewInt(2)}
+	if err := store.Commit([]TransferEvent{second}, 20); err == nil {
+		t.Fatalf("expected checkpoint write to fail")
+	}
+	if events, _ := store.Events(); len(events) != 1 {
+		t.Fatalf("failed commit left events behind: %+v", events)
+	}
+
+	// Retrying in the same process must not duplicate the batch.
+	os.Remove(cpPath)
+	if err := store.Commit([]TransferEvent{second}, 20); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	events, _ := store.Events()
+	if len(events) != 2 || events[1].BlockNumber != 20 {
+		t.Fatalf("events after retry = %+v", events)
+	}
+}
+
+func TestNewIndexerValidation(t *testing.T) {
+	client := &mockLogClient{}
+	store := NewMemoryStore()
+	if _, err := NewIndexer(nil, store, Config{Token: testToken}); err == nil {
+		t.Fatalf("expected nil client error")
+	}
+	if _, err := NewIndexer(client, nil, Config{Token: testToken}); err == nil {
+		t.Fatalf("expected nil store error")
+	}
+	if _, err := NewIndexer(client, store, Config{}); err == nil {
+		t.Fatalf("expected missing token error")
+	}
+	if _, err := NewIndexer(client, store, Config{Token: testToken, FromBlock: 10, ToBlock: 5}); err == nil {
+		t.Fatalf("expected invalid range error")
+	}
+}
+
+func TestRunIndexesIntoStore(t *testing.T) {
+	store := NewMemoryStore()
+	res, err := Run(context.Background(), &mockLogClient{head: 2000}, store, Config{Token: testToken, ToBlock: 1999})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if res.Batches != 2 {
+		t.Fatalf("expected default batch size to yield 2 batches, got %d", res.Batches)
+	}
+	if res.Events != expectedTransfers(0, 1999) {
+		t.Fatalf("unexpected event count %d", res.Events)
+	}
+	if _, err := Run(context.Background(), nil, store, Config{Token: testToken}); err == nil {
+		t.Fatalf("expected nil client error")
+	}
 }
@@ -3,5 +3,54 @@
 
 package exercise
 
-// Run currently acts as a placeholder; see module README for full implementation steps.
-func Run() {}
+import (
+	"context"
+	"fmt"
+)
+
+/*
+Problem: Index ERC20 Transfer events into a Store, resuming from a checkpoint.
+
+Module 09 decoded Transfer logs for one block range in one call. Real indexers
+cannot do that: providers cap eth_getLogs ranges, processes crash, and a
+restart must not re-index (or skip) blocks. The Indexer in indexer.go walks the
+range in batches and commits each batch together with a checkpoint.
+
+Computer science principles highlighted:
+  - Batching: bounded work per request keeps RPC calls within provider limits
+  - Checkpointing: persisted progress makes long jobs restartable
+  - Atomic commits: events and checkpoint move together or not at all
+*/
+func Run(ctx context.Context, client LogClient, store Store, cfg Config) (*Result, error) {
+	// ============================================================================
+	// STEP 1: Input Validation - Defensive Programming Pattern
+	// ============================================================================
+	// Same pattern as every previous module. NewIndexer owns the config checks
+	// (client, store, token, range) so Run and library callers share them.
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// ============================================================================
+	// STEP 2: Build the Indexer
+	// ============================================================================
+	// The Store is injected rather than created here. Tests use MemoryStore;
+	// a CLI would use OpenJSONLStore(dir) so progress survives restarts.
+	ix, err := NewIndexer(client, store, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("new indexer: %w", err)
+	}
+
+	// ============================================================================
+	// STEP 3: Run Batches Until ToBlock
+	// ============================================================================
+	// The indexer:
+	//   1. Loads the checkpoint and resumes at checkpoint+1 if present
+	//   2. Requests [from, from+BatchSize-1] via FilterLogs (eth_getLogs)
+	//   3. Decodes Transfer logs exactly like module 09
+	//   4. Commits events + checkpoint, then moves to the next batch
+	//
+	// If step 2 fails halfway through the range, everything before the failing
+	// batch is already committed. Calling Run again continues from there.
+	return ix.Run(ctx)
+}
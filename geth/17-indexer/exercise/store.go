@@ -0,0 +1,280 @@
+package exercise
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// MemoryStore is a Store backed by a slice. Useful for tests and one-off scans.
+type MemoryStore struct {
+	mu         sync.Mutex
+	events     []TransferEvent
+	checkpoint uint64
+	hasCP      bool
+}
+
+// NewMemoryStore returns an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Checkpoint implements Store.
+func (s *MemoryStore) Checkpoint() (uint64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.checkpoint, s.hasCP, nil
+}
+
+// Commit implements Store.
+func (s *MemoryStore) Commit(events []TransferEvent, block uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ev := range events {
+		s.events = append(s.events, copyEvent(ev))
+	}
+	s.checkpoint = block
+	s.hasCP = true
+	return nil
+}
+
+// Events implements Store.
+func (s *MemoryStore) Events() ([]TransferEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]TransferEvent, len(s.events))
+	for i, ev := range s.events {
+		out[i] = copyEvent(ev)
+	}
+	return out, nil
+}
+
+const (
+	eventsFile     = "events.jsonl"
+	checkpointFile = "checkpoint.json"
+)
+
+// JSONLStore is a Store that appends one JSON object per event to
+// <dir>/events.jsonl and keeps the checkpoint in <dir>/checkpoint.json.
+//
+// Events are appended and synced before the checkpoint is replaced via
+// rename, so a crash can only leave events *beyond* the checkpoint. Those are
+// trimmed when the store is reopened, which keeps Commit effectively atomic.
+type JSONLStore struct {
+	mu  sync.Mutex
+	dir string
+}
+
+type checkpointRecord struct {
+	Block uint64 `json:"block"`
+}
+
+// OpenJSONLStore opens (creating if needed) a file-backed store in dir.
+func OpenJSONLStore(dir string) (*JSONLStore, error) {
+	if dir == "" {
+		return nil, errors.New("store directory required")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create store dir: %w", err)
+	}
+	s := &JSONLStore{dir: dir}
+	if err := s.trimUncommitted(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Checkpoint implements Store.
+func (s *JSONLStore) Checkpoint() (uint64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.readCheckpoint()
+}
+
+// Commit implements Store. If any step fails, the events file is truncated
+// back to its size before the call, so a retry in the same process doesn't
+// append the batch twice.
+func (s *JSONLStore) Commit(events []TransferEvent, block uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	offset, err := s.eventsSize()
+	if err != nil {
+		return err
+	}
+	if err := s.appendEvents(events); err != nil {
+		return s.rollback(offset, err)
+	}
+	data, err := json.Marshal(checkpointRecord{Block: block})
+	if err != nil {
+		return s.rollback(offset, err)
+	}
+	if err := writeFileAtomic(s.path(checkpointFile), data); err != nil {
+		return s.rollback(offset, fmt.Errorf("write checkpoint: %w", err))
+	}
+	return nil
+}
+
+func (s *JSONLStore) appendEvents(events []TransferEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	f, err := os.OpenFile(s.path(eventsFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open events: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, ev := range events {
+		if err := enc.Encode(ev); err != nil {
+			f.Close()
+			return fmt.Errorf("encode event: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return fmt.Errorf("write events: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("sync events: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close events: %w", err)
+	}
+	return nil
+}
+
+// eventsSize returns the length of the events file, which after a
+// successful Commit ends exactly at the checkpoint.
+func (s *JSONLStore) eventsSize() (int64, error) {
+	fi, err := os.Stat(s.path(eventsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("stat events: %w", err)
+	}
+	return fi.Size(), nil
+}
+
+// rollback drops whatever a failed Commit appended past offset.
+func (s *JSONLStore) rollback(offset int64, cause error) error {
+	err := os.Truncate(s.path(eventsFile), offset)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w (truncate events: %v)", cause, err)
+	}
+	return cause
+}
+
+// Events implements Store.
+func (s *JSONLStore) Events() ([]TransferEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.readEvents()
+}
+
+func (s *JSONLStore) path(name string) string {
+	return filepath.Join(s.dir, name)
+}
+
+func (s *JSONLStore) readCheckpoint() (uint64, bool, error) {
+	data, err := os.ReadFile(s.path(checkpointFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("read checkpoint: %w", err)
+	}
+	var rec checkpointRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return 0, false, fmt.Errorf("decode checkpoint: %w", err)
+	}
+	return rec.Block, true, nil
+}
+
+func (s *JSONLStore) readEvents() ([]TransferEvent, error) {
+	f, err := os.Open(s.path(eventsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open events: %w", err)
+	}
+	defer f.Close()
+
+	var events []TransferEvent
+	dec := json.NewDecoder(bufio.NewReader(f))
+	for dec.More() {
+		var ev TransferEvent
+		if err := dec.Decode(&ev); err != nil {
+			return nil, fmt.Errorf("decode event %d: %w", len(events), err)
+		}
+		events = append(events, ev)
+	}
+	return events, nil
+}
+
+// trimUncommitted drops events written after the last checkpoint, i.e. the
+// tail of a Commit that crashed before its checkpoint was saved.
+func (s *JSONLStore) trimUncommitted() error {
+	cp, ok, err := s.readCheckpoint()
+	if err != nil {
+		return err
+	}
+	events, err := s.readEvents()
+	if err != nil {
+		return err
+	}
+	kept := events[:0]
+	for _, ev := range events {
+		if ok && ev.BlockNumber <= cp {
+			kept = append(kept, ev)
+		}
+	}
+	if len(kept) == len(events) {
+		return nil
+	}
+
+	var buf []byte
+	for _, ev := range kept {
+		line, err := json.Marshal(ev)
+		if err != nil {
+			return err
+		}
+		buf = append(append(buf, line...), '\n')
+	}
+	return writeFileAtomic(s.path(eventsFile), buf)
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func copyEvent(ev TransferEvent) TransferEvent {
+	if ev.Value != nil {
+		ev.Value = new(big.Int).Set(ev.Value)
+	}
+	return ev
+}
@@ -0,0 +1,57 @@
+package exercise
+
+import (
+	"context"
+	"math/big"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// LogClient captures the subset of ethclient capabilities needed to filter logs.
+type LogClient interface {
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
+}
+
+// Store persists decoded events together with the indexing checkpoint.
+//
+// Commit must be atomic from the indexer's point of view: after a crash the
+// store either contains the whole batch and the new checkpoint, or neither.
+type Store interface {
+	// Checkpoint returns the last fully indexed block. ok is false when
+	// nothing has been indexed yet.
+	Checkpoint() (block uint64, ok bool, err error)
+	// Commit appends events and advances the checkpoint to block.
+	Commit(events []TransferEvent, block uint64) error
+	// Events returns every stored event in indexing order.
+	Events() ([]TransferEvent, error)
+}
+
+// Config configures the indexing run.
+type Config struct {
+	Token     common.Address
+	FromBlock uint64
+	ToBlock   uint64
+	BatchSize uint64 // blocks per FilterLogs call (0 => DefaultBatchSize)
+}
+
+// TransferEvent represents a decoded Transfer log.
+type TransferEvent struct {
+	BlockNumber uint64         `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	TxHash      common.Hash    `json:"txHash"`
+	LogIndex    uint           `json:"logIndex"`
+	From        common.Address `json:"from"`
+	To          common.Address `json:"to"`
+	Value       *big.Int       `json:"value"`
+}
+
+// Result summarizes one indexing run.
+type Result struct {
+	StartBlock uint64 // first block scanned in this run
+	EndBlock   uint64 // last block scanned in this run
+	Resumed    bool   // true if StartBlock came from a stored checkpoint
+	Batches    int
+	Events     int
+}
@@ -3,5 +3,38 @@
 
 package exercise
 
-// Run currently acts as a placeholder; see module README for full implementation steps.
-func Run() {}
+import (
+	"context"
+	"errors"
+)
+
+/*
+Problem: Detect chain reorganizations and tell consumers how to undo and replay.
+
+Indexers (module 17) persist data keyed by block. If the block they indexed
+is replaced by a competing block, their data is wrong until they roll back to
+the common ancestor and re-apply the new branch. ReorgTracker (tracker.go)
+keeps a bounded window of (number, hash) pairs and turns header polling into
+Rollback/Apply events.
+
+Computer science principles highlighted:
+  - Hash chains: each header commits to its parent, so one comparison detects a fork
+  - Bounded memory: a sliding window caps state while covering realistic reorg depths
+  - Event sourcing: consumers rebuild state from an ordered stream of undo/redo events
+*/
+func Run(ctx context.Context, client HeaderClient, cfg Config) (*Result, error) {
+	// TODO: Validate input parameters
+	// - Default a nil ctx to context.Background()
+	// - NewReorgTracker rejects a nil client; surface its error
+
+	// TODO: Create a tracker and poll once
+	// - tracker.Poll(ctx) fetches the head and walks StartBlock..head
+	// - For each header, compare ParentHash to the stored hash of number-1
+	// - On mismatch, walk back to the common ancestor and emit Rollback{From, To}
+
+	// TODO: Build the Result
+	// - Copy the events, record the tip number from tracker.Tip()
+	// - Set Reorged if any event is a Rollback
+
+	return nil, errors.New("not implemented")
+}
@@ -1,7 +1,333 @@
 package exercise
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
 
-func TestRun(t *testing.T) {
-	t.Skip("add tests for this module")
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// mockChain is a scriptable canonical chain. fork replaces the top blocks
+// with a competing branch so tests can reorg to any depth.
+type mockChain struct {
+	headers []*types.Header // index == block number
+	err     error
+	calls   int
+}
+
+func newMockChain(tip uint64) *mockChain {
+	c := &mockChain{}
+	c.extend(int(tip)+1, 0)
+	return c
+}
+
+// extend appends n blocks; salt makes hashes differ between branches.
+func (c *mockChain) extend(n int, salt byte) {
+	for i := 0; i < n; i++ {
+		num := uint64(len(c.headers))
+		h := &types.Header{
+			Number: new(big.Int).SetUint64(num),
+			Time:   num * 12,
+			Extra:  []byte{salt},
+		}
+		if num > 0 {
+			h.ParentHash = c.headers[num-1].Hash()
+		}
+		c.headers = append(c.headers, h)
+	}
+}
+
+// fork drops the top depth blocks and appends length new ones.
+func (c *mockChain) fork(depth, length int, salt byte) {
+	c.headers = c.headers[:len(c.headers)-depth]
+	c.extend(length, salt)
+}
+
+func (c *mockChain) tip() uint64 { return uint64(len(c.headers) - 1) }
+
+func (c *mockChain) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	if number == nil {
+		return c.headers[len(c.headers)-1], nil
+	}
+	n := number.Uint64()
+	if n >= uint64(len(c.headers)) {
+		return nil, errors.New("not found")
+	}
+	return c.headers[n], nil
+}
+
+// replica rebuilds canonical hashes purely from tracker events, as an
+// indexer would.
+type replica struct {
+	hashes map[uint64]common.Hash
+}
+
+func (r *replica) apply(t *testing.T, events []Event) {
+	t.Helper()
+	for _, ev := range events {
+		switch e := ev.(type) {
+		case Rollback:
+			if e.To >= e.From {
+				t.Fatalf("rollback must move backwards: %+v", e)
+			}
+			for k := e.From; k > e.To; k-- {
+				if _, ok := r.hashes[k]; !ok {
+					t.Fatalf("rollback of unknown block %d", k)
+				}
+				delete(r.hashes, k)
+			}
+		case Apply:
+			n := e.Header.Number.Uint64()
+			if n > 0 {
+				if prev, ok := r.hashes[n-1]; ok && prev != e.Header.ParentHash {
+					t.Fatalf("apply %d does not extend replica", n)
+				}
+			}
+			r.hashes[n] = e.Header.Hash()
+		}
+	}
+}
+
+func (r *replica) assertMatches(t *testing.T, c *mockChain, from uint64) {
+	t.Helper()
+	for n := from; n <= c.tip(); n++ {
+		if r.hashes[n] != c.headers[n].Hash() {
+			t.Fatalf("replica diverges at %d", n)
+		}
+	}
+	for n := range r.hashes {
+		if n > c.tip() {
+			t.Fatalf("replica keeps orphaned block %d", n)
+		}
+	}
+}
+
+func countEvents(events []Event) (rollbacks, applies int) {
+	for _, ev := range events {
+		switch ev.(type) {
+		case Rollback:
+			rollbacks++
+		case Apply:
+			applies++
+		}
+	}
+	return
+}
+
+func TestTrackerFollowsExtensions(t *testing.T) {
+	chain := newMockChain(20)
+	tr, err := NewReorgTracker(chain, Config{StartBlock: 10})
+	if err != nil {
+		t.Fatalf("NewReorgTracker: %v", err)
+	}
+	events, err := tr.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if rb, ap := countEvents(events); rb != 0 || ap != 11 {
+		t.Fatalf("expected 11 applies, got %d rollbacks %d applies", rb, ap)
+	}
+
+	events, err = tr.Poll(context.Background())
+	if err != nil || len(events) != 0 {
+		t.Fatalf("expected idle poll, got %v err=%v", events, err)
+	}
+
+	chain.extend(3, 0)
+	events, _ = tr.Poll(context.Background())
+	if _, ap := countEvents(events); ap != 3 {
+		t.Fatalf("expected 3 applies, got %d", ap)
+	}
+	if tip, hash, _ := tr.Tip(); tip != 23 || hash != chain.headers[23].Hash() {
+		t.Fatalf("unexpected tip %d", tip)
+	}
+}
+
+func TestTrackerRollsBackToCommonAncestor(t *testing.T) {
+	cases := []struct {
+		name          string
+		depth, length int
+	}{
+		{"one block, longer branch", 1, 2},
+		{"deep, same length", 7, 7},
+		{"deep, shorter branch", 5, 2},
+		{"replace tip only", 1, 1},
+	}
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			chain := newMockChain(50)
+			tr, _ := NewReorgTracker(chain, Config{StartBlock: 30, Window: 16})
+			rep := &replica{hashes: map[uint64]common.Hash{}}
+			events, _ := tr.Poll(context.Background())
+			rep.apply(t, events)
+
+			oldTip := chain.tip()
+			chain.fork(tc.depth, tc.length, byte(i+1))
+			events, err := tr.Poll(context.Background())
+			if err != nil {
+				t.Fatalf("Poll after fork: %v", err)
+			}
+			rb, ok := events[0].(Rollback)
+			if !ok {
+				t.Fatalf("expected leading Rollback, got %T", events[0])
+			}
+			if rb.From != oldTip || rb.To != oldTip-uint64(tc.depth) {
+				t.Fatalf("rollback %+v, want From=%d To=%d", rb, oldTip, oldTip-uint64(tc.depth))
+			}
+			if _, ap := countEvents(events); ap != tc.length {
+				t.Fatalf("expected %d applies, got %d", tc.length, ap)
+			}
+			rep.apply(t, events)
+			rep.assertMatches(t, chain, 30)
+		})
+	}
+}
+
+func TestTrackerHandlesRepeatedForks(t *testing.T) {
+	chain := newMockChain(100)
+	tr, _ := NewReorgTracker(chain, Config{StartBlock: 60, Window: 32})
+	rep := &replica{hashes: map[uint64]common.Hash{}}
+
+	// Every fork replaces at least one block; a head that merely moves back
+	// onto our own chain is a lagging node, not a reorg.
+	script := []struct{ depth, length int }{
+		{0, 5}, {2, 3}, {0, 1}, {4, 6}, {1, 1}, {10, 12}, {3, 1}, {0, 4},
+	}
+	for i, step := range script {
+		chain.fork(step.depth, step.length, byte(i+1))
+		events, err := tr.Poll(context.Background())
+		if err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+		rep.apply(t, events)
+		rep.assertMatches(t, chain, 60)
+	}
+}
+
+// flipClient serves the head from one chain and numbered headers from
+// another, like a load-balanced endpoint whose backends disagree. Queued
+// heads are served first, one per head request.
+type flipClient struct {
+	head, body *mockChain
+	heads      []*mockChain
+	calls      int
+}
+
+func (f *flipClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if f.calls++; f.calls > 1000 {
+		return nil, errors.New("poll does not terminate")
+	}
+	if number == nil {
+		if len(f.heads) > 0 {
+			c := f.heads[0]
+			f.heads = f.heads[1:]
+			return c.HeaderByNumber(ctx, nil)
+		}
+		return f.head.HeaderByNumber(ctx, nil)
+	}
+	return f.body.HeaderByNumber(ctx, number)
+}
+
+func TestTrackerNodeFlipsBetweenBranches(t *testing.T) {
+	a := newMockChain(10)
+	client := &flipClient{head: a, body: a}
+	tr, _ := NewReorgTracker(client, Config{StartBlock: 5})
+	rep := &replica{hashes: map[uint64]common.Hash{}}
+	events, _ := tr.Poll(context.Background())
+	rep.apply(t, events)
+
+	// b replaces block 10 and grows to 12; a grows to 12 on its own branch.
+	b := &mockChain{headers: append([]*types.Header(nil), a.headers...)}
+	b.fork(1, 3, 1)
+	a.extend(2, 0)
+
+	// The head comes from b, but by-number reads are back on a.
+	client.head, client.body = b, a
+	events, err := tr.Poll(context.Background())
+	if !errors.Is(err, ErrHeadUnstable) {
+		t.Fatalf("err = %v, want ErrHeadUnstable", err)
+	}
+	if rb, ap := countEvents(events); rb != 0 || ap != 1 {
+		t.Fatalf("expected the single apply of a's block 11, got %d rollbacks %d applies", rb, ap)
+	}
+	if tip, hash, _ := tr.Tip(); tip != 11 || hash != a.headers[11].Hash() {
+		t.Fatalf("tip = %d %s, want a's block 11", tip, hash.Hex())
+	}
+	rep.apply(t, events)
+
+	// Once the node settles, each flip is an ordinary reorg.
+	for i, c := range []*mockChain{b, a, b} {
+		client.head, client.body, client.calls = c, c, 0
+		events, err := tr.Poll(context.Background())
+		if err != nil {
+			t.Fatalf("flip %d: %v", i, err)
+		}
+		if rb, _ := countEvents(events); rb != 1 {
+			t.Fatalf("flip %d: expected one rollback, got %d", i, rb)
+		}
+		rep.apply(t, events)
+		rep.assertMatches(t, c, 5)
+	}
+
+	// The first head comes from a third branch, then the node settles on a.
+	// After rolling back onto a the tracker must re-read the head rather
+	// than apply the stale one.
+	c := &mockChain{headers: append([]*types.Header(nil), a.headers[:10]...)}
+	c.extend(4, 2)
+	a.extend(1, 0)
+	client.head, client.body, client.heads, client.calls = a, a, []*mockChain{c}, 0
+	events, err = tr.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	rep.apply(t, events)
+	rep.assertMatches(t, a, 5)
+}
+
+func TestTrackerReorgBeyondWindow(t *testing.T) {
+	chain := newMockChain(40)
+	tr, _ := NewReorgTracker(chain, Config{StartBlock: 1, Window: 8})
+	if _, err := tr.Poll(context.Background()); err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	chain.fork(12, 13, 9)
+	if _, err := tr.Poll(context.Background()); !errors.Is(err, ErrReorgTooDeep) {
+		t.Fatalf("expected ErrReorgTooDeep, got %v", err)
+	}
+	if _, ok := tr.CanonicalHash(40 - 8); ok {
+		t.Fatalf("window should not retain block %d", 40-8)
+	}
+}
+
+func TestTrackerErrors(t *testing.T) {
+	if _, err := NewReorgTracker(nil, Config{}); err == nil {
+		t.Fatalf("expected nil client error")
+	}
+	chain := newMockChain(3)
+	chain.err = errors.New("boom")
+	tr, _ := NewReorgTracker(chain, Config{})
+	if _, err := tr.Poll(context.Background()); err == nil {
+		t.Fatalf("expected client error")
+	}
+}
+
+func TestRunReportsApplies(t *testing.T) {
+	chain := newMockChain(9)
+	res, err := Run(context.Background(), chain, Config{StartBlock: 5})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if len(res.Events) != 5 || res.TipNum != 9 || res.Reorged {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	if _, err := Run(context.Background(), nil, Config{}); err == nil {
+		t.Fatalf("expected nil client error")
+	}
 }
@@ -3,5 +3,61 @@
 
 package exercise
 
-// Run currently acts as a placeholder; see module README for full implementation steps.
-func Run() {}
+import (
+	"context"
+	"fmt"
+)
+
+/*
+Problem: Detect chain reorganizations and tell consumers how to undo and replay.
+
+Indexers (module 17) persist data keyed by block. If the block they indexed
+is replaced by a competing block, their data is wrong until they roll back to
+the common ancestor and re-apply the new branch. ReorgTracker (tracker.go)
+keeps a bounded window of (number, hash) pairs and turns header polling into
+Rollback/Apply events.
+
+Computer science principles highlighted:
+  - Hash chains: each header commits to its parent, so one comparison detects a fork
+  - Bounded memory: a sliding window caps state while covering realistic reorg depths
+  - Event sourcing: consumers rebuild state from an ordered stream of undo/redo events
+*/
+func Run(ctx context.Context, client HeaderClient, cfg Config) (*Result, error) {
+	// ============================================================================
+	// STEP 1: Input Validation
+	// ============================================================================
+	// Same defensive pattern as modules 01-17. The tracker constructor checks
+	// the client and window so long-running callers get the same validation.
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	tracker, err := NewReorgTracker(client, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("new tracker: %w", err)
+	}
+
+	// ============================================================================
+	// STEP 2: Poll the Chain
+	// ============================================================================
+	// A single Poll walks from StartBlock to the head. In a real service you
+	// would call Poll on every new head (module 10) and keep the tracker alive,
+	// so later polls can detect forks against the stored window.
+	events, err := tracker.Poll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("poll: %w", err)
+	}
+
+	// ============================================================================
+	// STEP 3: Summarize
+	// ============================================================================
+	// Type switches are the idiomatic way to consume a closed set of event
+	// types. Rollback means "undo down to To"; Apply means "process Header".
+	res := &Result{Events: events}
+	res.TipNum, _, _ = tracker.Tip()
+	for _, ev := range events {
+		if _, ok := ev.(Rollback); ok {
+			res.Reorged = true
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,192 @@
+package exercise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DefaultWindow is how many recent canonical hashes the tracker remembers.
+// Mainnet reorgs are almost always 1-2 blocks; 64 covers two epochs of
+// post-merge finality lag with room to spare.
+const DefaultWindow = 64
+
+// ErrReorgTooDeep is returned when no common ancestor exists inside the
+// tracked window. The caller must resync from a trusted checkpoint.
+var ErrReorgTooDeep = errors.New("reorg deeper than tracked window")
+
+// ErrHeadUnstable is returned when the node switches branches while a Poll
+// walks up to its head, so the headers it served don't form one chain.
+// Events returned alongside it are valid; polling again picks up from there.
+var ErrHeadUnstable = errors.New("node switched branches during poll")
+
+// ReorgTracker follows the canonical chain by (number, hash) pairs. Each Poll
+// walks from the last tracked block to the node's head, checking that every
+// header's ParentHash matches the hash stored for the previous number. On a
+// mismatch it walks backwards to the common ancestor and emits a Rollback
+// followed by Apply events for the new branch.
+type ReorgTracker struct {
+	client HeaderClient
+	window int
+	start  uint64
+
+	hashes  map[uint64]common.Hash
+	tip     uint64
+	started bool
+}
+
+// NewReorgTracker returns a tracker that has not yet seen any headers.
+func NewReorgTracker(client HeaderClient, cfg Config) (*ReorgTracker, error) {
+	if client == nil {
+		return nil, errors.New("client is nil")
+	}
+	if cfg.Window < 0 {
+		return nil, fmt.Errorf("invalid window %d", cfg.Window)
+	}
+	if cfg.Window == 0 {
+		cfg.Window = DefaultWindow
+	}
+	return &ReorgTracker{
+		client: client,
+		window: cfg.Window,
+		start:  cfg.StartBlock,
+		hashes: make(map[uint64]common.Hash, cfg.Window),
+	}, nil
+}
+
+// Tip returns the highest tracked canonical block.
+func (t *ReorgTracker) Tip() (uint64, common.Hash, bool) {
+	if !t.started {
+		return 0, common.Hash{}, false
+	}
+	return t.tip, t.hashes[t.tip], true
+}
+
+// CanonicalHash returns the tracked hash for number, if it is inside the window.
+func (t *ReorgTracker) CanonicalHash(number uint64) (common.Hash, bool) {
+	h, ok := t.hashes[number]
+	return h, ok
+}
+
+// Poll fetches the node's head and returns the events needed to bring a
+// consumer from the previous tip to it. Events are ordered: at most one
+// Rollback per fork, each followed by the Applies for the new branch.
+func (t *ReorgTracker) Poll(ctx context.Context) ([]Event, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	head, err := t.header(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	headNum := head.Number.Uint64()
+
+	var events []Event
+	next := t.start
+	switch {
+	case !t.started && t.start == 0:
+		next = headNum
+	case !t.started && t.start > headNum:
+		return nil, nil // node has not reached StartBlock yet
+	case t.started && headNum <= t.tip:
+		if stored, ok := t.hashes[headNum]; ok && stored == head.Hash() {
+			return nil, nil // no new blocks, or a lagging node on our chain
+		}
+		// The head moved to a branch no longer than ours.
+		if headNum == 0 {
+			return nil, fmt.Errorf("genesis mismatch: %w", ErrReorgTooDeep)
+		}
+		anc, err := t.findAncestor(ctx, headNum-1)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, t.rollback(anc))
+		next = anc + 1
+	case t.started:
+		next = t.tip + 1
+	}
+
+	for n := next; n <= headNum; n++ {
+		h := head
+		if n != headNum {
+			if h, err = t.header(ctx, new(big.Int).SetUint64(n)); err != nil {
+				return events, err
+			}
+		}
+		if t.started && h.ParentHash != t.hashes[t.tip] {
+			anc, err := t.findAncestor(ctx, t.tip)
+			if err != nil {
+				return events, err
+			}
+			if anc == t.tip {
+				// Our tip is canonical again: h came from a branch the
+				// node has already left.
+				return events, fmt.Errorf("block %d: %w", n, ErrHeadUnstable)
+			}
+			events = append(events, t.rollback(anc))
+			// The head we started with may be on the branch just left.
+			if head, err = t.header(ctx, nil); err != nil {
+				return events, err
+			}
+			headNum = head.Number.Uint64()
+			n = anc // loop increment resumes at anc+1
+			continue
+		}
+		t.record(h)
+		events = append(events, Apply{Header: h})
+	}
+	return events, nil
+}
+
+// findAncestor walks down from number until the node's header matches the
+// stored hash. Every block it passes is part of the orphaned branch.
+func (t *ReorgTracker) findAncestor(ctx context.Context, number uint64) (uint64, error) {
+	lowest := t.tip + 1 - uint64(len(t.hashes))
+	for k := number; k >= lowest; k-- {
+		h, err := t.header(ctx, new(big.Int).SetUint64(k))
+		if err != nil {
+			return 0, err
+		}
+		if h.Hash() == t.hashes[k] {
+			return k, nil
+		}
+		if k == 0 {
+			break
+		}
+	}
+	return 0, fmt.Errorf("no common ancestor at or above block %d: %w", lowest, ErrReorgTooDeep)
+}
+
+func (t *ReorgTracker) rollback(ancestor uint64) Rollback {
+	rb := Rollback{From: t.tip, To: ancestor}
+	for k := t.tip; k > ancestor; k-- {
+		delete(t.hashes, k)
+	}
+	t.tip = ancestor
+	return rb
+}
+
+func (t *ReorgTracker) record(h *types.Header) {
+	n := h.Number.Uint64()
+	t.hashes[n] = h.Hash()
+	t.tip = n
+	t.started = true
+	if n >= uint64(t.window) {
+		delete(t.hashes, n-uint64(t.window))
+	}
+}
+
+func (t *ReorgTracker) header(ctx context.Context, number *big.Int) (*types.Header, error) {
+	h, err := t.client.HeaderByNumber(ctx, number)
+	if err != nil {
+		return nil, fmt.Errorf("header %v: %w", number, err)
+	}
+	if h == nil || h.Number == nil {
+		return nil, fmt.Errorf("header %v: empty response", number)
+	}
+	return h, nil
+}
@@ -0,0 +1,48 @@
+package exercise
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// HeaderClient captures the single ethclient call the tracker needs.
+type HeaderClient interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// Config tunes the reorg tracker.
+type Config struct {
+	StartBlock uint64 // first block to track (0 => start at the current head)
+	Window     int    // canonical hashes kept for ancestor search (0 => DefaultWindow)
+}
+
+// Event is emitted by the tracker as the canonical chain changes.
+// It is either a Rollback or an Apply.
+type Event interface {
+	isEvent()
+}
+
+// Rollback reports that blocks To+1..From are no longer canonical.
+// From is the previous tip, To is the common ancestor (still canonical).
+// Consumers should undo From, From-1, ..., To+1 in that order.
+type Rollback struct {
+	From uint64
+	To   uint64
+}
+
+// Apply reports a header that became canonical, in ascending order.
+type Apply struct {
+	Header *types.Header
+}
+
+func (Rollback) isEvent() {}
+func (Apply) isEvent()    {}
+
+// Result is returned by Run after a single poll.
+type Result struct {
+	Events  []Event
+	TipNum  uint64
+	Reorged bool
+}
@@ -0,0 +1,208 @@
+package exercise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Defaults applied by NewDevnet when Config fields are left zero.
+const (
+	DefaultAccounts    = 3
+	DefaultTokenName   = "Devnet Token"
+	DefaultTokenSymbol = "DEV"
+)
+
+var (
+	// DefaultBalance funds each account with 1000 ETH.
+	DefaultBalance = new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))
+	// DefaultTokenSupply mints one million tokens (18 decimals) to Accounts[0].
+	DefaultTokenSupply = new(big.Int).Mul(big.NewInt(1_000_000), big.NewInt(params.Ether))
+)
+
+// Devnet is an in-process Ethereum chain backed by go-ethereum's simulated
+// backend. It starts with pre-funded accounts and a deployed ERC-20.
+//
+// Client() satisfies the client interfaces from earlier modules (TXClient,
+// FeeClient, CallClient, LogClient, ReceiptClient, ...), so their Run
+// functions can execute against a real EVM without network access.
+// Transactions are only mined when Commit is called.
+type Devnet struct {
+	backend  *simulated.Backend
+	ChainID  *big.Int
+	Accounts []Account
+	Token    common.Address
+}
+
+// NewDevnet starts a devnet and deploys the sample token from Accounts[0].
+// Callers must Close it.
+func NewDevnet(ctx context.Context, cfg Config) (*Devnet, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if cfg.Accounts < 0 {
+		return nil, fmt.Errorf("invalid account count %d", cfg.Accounts)
+	}
+	if cfg.Accounts == 0 {
+		cfg.Accounts = DefaultAccounts
+	}
+	if cfg.Balance == nil {
+		cfg.Balance = DefaultBalance
+	}
+	if cfg.TokenName == "" {
+		cfg.TokenName = DefaultTokenName
+	}
+	if cfg.TokenSymbol == "" {
+		cfg.TokenSymbol = DefaultTokenSymbol
+	}
+	if cfg.TokenSupply == nil {
+		cfg.TokenSupply = DefaultTokenSupply
+	}
+
+	alloc := make(types.GenesisAlloc, cfg.Accounts)
+	accounts := make([]Account, cfg.Accounts)
+	for i := range accounts {
+		accounts[i] = devAccount(i)
+		alloc[accounts[i].Address] = types.Account{Balance: new(big.Int).Set(cfg.Balance)}
+	}
+
+	d := &Devnet{backend: simulated.NewBackend(alloc), Accounts: accounts}
+	chainID, err := d.Client().ChainID(ctx)
+	if err != nil {
+		d.Close()
+		return nil, fmt.Errorf("chain id: %w", err)
+	}
+	d.ChainID = chainID
+
+	token, err := d.DeployERC20(ctx, accounts[0], cfg.TokenName, cfg.TokenSymbol, cfg.TokenSupply)
+	if err != nil {
+		d.Close()
+		return nil, fmt.Errorf("deploy token: %w", err)
+	}
+	d.Token = token
+	return d, nil
+}
+
+// devAccount derives a deterministic key so addresses are stable across runs.
+// These keys are public knowledge; never fund them on a real network.
+func devAccount(i int) Account {
+	seed := crypto.Keccak256([]byte(fmt.Sprintf("geth-edu devnet account %d", i)))
+	key, err := crypto.ToECDSA(seed)
+	if err != nil {
+		panic(err) // keccak output is always a valid secp256k1 scalar in practice
+	}
+	return Account{Key: key, Address: crypto.PubkeyToAddress(key.PublicKey)}
+}
+
+// Client returns an RPC client connected to the devnet.
+func (d *Devnet) Client() simulated.Client {
+	return d.backend.Client()
+}
+
+// Commit mines pending transactions into a new block and returns its hash.
+func (d *Devnet) Commit() common.Hash {
+	return d.backend.Commit()
+}
+
+// Fork rewinds the pending chain to parent so tests can build a side chain
+// (see module 18). The side chain becomes canonical once it is longer.
+func (d *Devnet) Fork(parent common.Hash) error {
+	return d.backend.Fork(parent)
+}
+
+// Close stops the devnet. It is safe to call more than once.
+func (d *Devnet) Close() error {
+	if d.backend == nil {
+		return nil
+	}
+	err := d.backend.Close()
+	d.backend = nil
+	return err
+}
+
+// DeployERC20 deploys a new sample token minting supply to from.
+func (d *Devnet) DeployERC20(ctx context.Context, from Account, name, symbol string, supply *big.Int) (common.Address, error) {
+	code, err := erc20InitCode(name, symbol, supply)
+	if err != nil {
+		return common.Address{}, err
+	}
+	rcpt, err := d.SendAndCommit(ctx, from, nil, nil, code)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return rcpt.ContractAddress, nil
+}
+
+// TransferERC20 calls transfer(to, amount) on token from the given account.
+func (d *Devnet) TransferERC20(ctx context.Context, from Account, token, to common.Address, amount *big.Int) (*types.Receipt, error) {
+	data := append(crypto.Keccak256([]byte("transfer(address,uint256)"))[:4], common.LeftPadBytes(to.Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(amount.Bytes(), 32)...)
+	return d.SendAndCommit(ctx, from, &token, nil, data)
+}
+
+// SendAndCommit signs a dynamic-fee transaction, mines it and returns the
+// receipt. A nil to deploys a contract. Reverted transactions return an error.
+func (d *Devnet) SendAndCommit(ctx context.Context, from Account, to *common.Address, value *big.Int, data []byte) (*types.Receipt, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if d.backend == nil {
+		return nil, errors.New("devnet is closed")
+	}
+	client := d.Client()
+
+	nonce, err := client.PendingNonceAt(ctx, from.Address)
+	if err != nil {
+		return nil, fmt.Errorf("nonce: %w", err)
+	}
+	tip, err := client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tip: %w", err)
+	}
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("head: %w", err)
+	}
+	gas, err := client.EstimateGas(ctx, ethereum.CallMsg{From: from.Address, To: to, Value: value, Data: data})
+	if err != nil {
+		return nil, fmt.Errorf("estimate gas: %w", err)
+	}
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	tx, err := types.SignNewTx(from.Key, types.LatestSignerForChainID(d.ChainID), &types.DynamicFeeTx{
+		ChainID:   d.ChainID,
+		Nonce:     nonce,
+		GasTipCap: tip,
+		GasFeeCap: new(big.Int).Add(tip, new(big.Int).Mul(head.BaseFee, big.NewInt(2))),
+		Gas:       gas,
+		To:        to,
+		Value:     value,
+		Data:      data,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("sign: %w", err)
+	}
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		return nil, fmt.Errorf("send: %w", err)
+	}
+	d.Commit()
+
+	rcpt, err := client.TransactionReceipt(ctx, tx.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("receipt: %w", err)
+	}
+	if rcpt.Status != types.ReceiptStatusSuccessful {
+		return rcpt, fmt.Errorf("tx %s reverted", tx.Hash().Hex())
+	}
+	return rcpt, nil
+}
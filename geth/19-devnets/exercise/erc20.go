@@ -0,0 +1,199 @@
+package exercise
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// The sample token is assembled straight from opcodes so the devnet needs no
+// solc toolchain. Storage follows the OpenZeppelin ERC20 layout, so slot math
+// from module 11 applies unchanged:
+//
+//	slot 0: mapping(address => uint256) balances
+//	slot 2: uint256 totalSupply
+//
+// Supported calls: name(), symbol(), decimals(), totalSupply(),
+// balanceOf(address), transfer(address,uint256). transfer emits the standard
+// Transfer event and reverts on insufficient balance.
+
+const (
+	erc20BalancesSlot    = 0
+	erc20TotalSupplySlot = 2
+	erc20Decimals        = 18
+)
+
+var transferSigHash = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// erc20InitCode returns deployable bytecode that mints supply to the deployer.
+func erc20InitCode(name, symbol string, supply *big.Int) ([]byte, error) {
+	if supply == nil || supply.Sign() < 0 || supply.BitLen() > 256 {
+		return nil, fmt.Errorf("invalid supply %v", supply)
+	}
+	runtime := erc20Runtime(name, symbol)
+	supplyWord := common.LeftPadBytes(supply.Bytes(), 32)
+
+	a := newAssembler()
+	// balances[msg.sender] = supply
+	a.op(vm.CALLER).pushInt(0).op(vm.MSTORE)
+	a.pushInt(erc20BalancesSlot).pushInt(32).op(vm.MSTORE)
+	a.push(supplyWord).pushInt(64).pushInt(0).op(vm.KECCAK256, vm.SSTORE)
+	// totalSupply = supply
+	a.push(supplyWord).pushInt(erc20TotalSupplySlot).op(vm.SSTORE)
+	// emit Transfer(address(0), msg.sender, supply)
+	a.push(supplyWord).pushInt(0).op(vm.MSTORE)
+	a.op(vm.CALLER).pushInt(0).push(transferSigHash.Bytes()).pushInt(32).pushInt(0).op(vm.LOG3)
+	// return runtime code
+	a.pushInt(uint64(len(runtime))).pushLabel("runtime").pushInt(0).op(vm.CODECOPY)
+	a.pushInt(uint64(len(runtime))).pushInt(0).op(vm.RETURN)
+	a.data("runtime", runtime)
+	return a.assemble(), nil
+}
+
+func erc20Runtime(name, symbol string) []byte {
+	nameData, symbolData := abiString(name), abiString(symbol)
+
+	a := newAssembler()
+	// Dispatch on the 4-byte selector.
+	a.pushInt(0).op(vm.CALLDATALOAD).pushInt(0xe0).op(vm.SHR)
+	for _, fn := range []string{
+		"name()", "symbol()", "decimals()", "totalSupply()",
+		"balanceOf(address)", "transfer(address,uint256)",
+	} {
+		a.op(vm.DUP1).push(crypto.Keccak256([]byte(fn))[:4]).op(vm.EQ).pushLabel(fn).op(vm.JUMPI)
+	}
+	a.label("revert").pushInt(0).op(vm.DUP1, vm.REVERT)
+
+	a.label("name()")
+	a.pushInt(uint64(len(nameData))).pushLabel("nameData").pushInt(0).op(vm.CODECOPY)
+	a.pushInt(uint64(len(nameData))).pushInt(0).op(vm.RETURN)
+
+	a.label("symbol()")
+	a.pushInt(uint64(len(symbolData))).pushLabel("symbolData").pushInt(0).op(vm.CODECOPY)
+	a.pushInt(uint64(len(symbolData))).pushInt(0).op(vm.RETURN)
+
+	a.label("decimals()")
+	a.pushInt(erc20Decimals).pushInt(0).op(vm.MSTORE)
+	a.pushInt(32).pushInt(0).op(vm.RETURN)
+
+	a.label("totalSupply()")
+	a.pushInt(erc20TotalSupplySlot).op(vm.SLOAD).pushInt(0).op(vm.MSTORE)
+	a.pushInt(32).pushInt(0).op(vm.RETURN)
+
+	a.label("balanceOf(address)")
+	a.pushInt(4).op(vm.CALLDATALOAD).pushInt(0).op(vm.MSTORE)
+	a.pushInt(erc20BalancesSlot).pushInt(32).op(vm.MSTORE)
+	a.pushInt(64).pushInt(0).op(vm.KECCAK256, vm.SLOAD).pushInt(0).op(vm.MSTORE)
+	a.pushInt(32).pushInt(0).op(vm.RETURN)
+
+	a.label("transfer(address,uint256)")
+	// fromSlot := keccak256(msg.sender . 0); stack: [fromSlot]
+	a.op(vm.CALLER).pushInt(0).op(vm.MSTORE)
+	a.pushInt(erc20BalancesSlot).pushInt(32).op(vm.MSTORE)
+	a.pushInt(64).pushInt(0).op(vm.KECCAK256)
+	// [amt, fromBal, fromSlot]
+	a.op(vm.DUP1, vm.SLOAD).pushInt(36).op(vm.CALLDATALOAD)
+	// if fromBal < amt: revert
+	a.op(vm.DUP1, vm.DUP3, vm.LT).pushLabel("revert").op(vm.JUMPI)
+	// sstore(fromSlot, fromBal - amt); stack afterwards: [amt]
+	a.op(vm.DUP2, vm.DUP2, vm.SWAP1, vm.SUB, vm.DUP4, vm.SSTORE)
+	a.op(vm.SWAP2, vm.POP, vm.POP)
+	// toSlot := keccak256(to . 0); sstore(toSlot, sload(toSlot) + amt)
+	a.pushInt(4).op(vm.CALLDATALOAD).pushInt(0).op(vm.MSTORE)
+	a.pushInt(64).pushInt(0).op(vm.KECCAK256)
+	a.op(vm.DUP1, vm.SLOAD, vm.DUP3, vm.ADD, vm.SWAP1, vm.SSTORE)
+	// emit Transfer(msg.sender, to, amt)
+	a.pushInt(0).op(vm.MSTORE)
+	a.pushInt(4).op(vm.CALLDATALOAD, vm.CALLER).push(transferSigHash.Bytes()).pushInt(32).pushInt(0).op(vm.LOG3)
+	// return true
+	a.pushInt(1).pushInt(0).op(vm.MSTORE)
+	a.pushInt(32).pushInt(0).op(vm.RETURN)
+
+	a.data("nameData", nameData)
+	a.data("symbolData", symbolData)
+	return a.assemble()
+}
+
+// abiString ABI-encodes s as a single dynamic string return value.
+func abiString(s string) []byte {
+	out := make([]byte, 64, 64+(len(s)+31)/32*32)
+	out[31] = 0x20
+	binary.BigEndian.PutUint64(out[56:64], uint64(len(s)))
+	out = append(out, s...)
+	return append(out, make([]byte, (32-len(s)%32)%32)...)
+}
+
+// assembler is a minimal EVM assembler: opcodes, pushes, jump labels and
+// trailing data segments. Label references are always PUSH2.
+type assembler struct {
+	code   []byte
+	labels map[string]int
+	refs   map[int]string
+	blobs  []blob
+}
+
+type blob struct {
+	name string
+	data []byte
+}
+
+func newAssembler() *assembler {
+	return &assembler{labels: map[string]int{}, refs: map[int]string{}}
+}
+
+func (a *assembler) op(ops ...vm.OpCode) *assembler {
+	for _, o := range ops {
+		a.code = append(a.code, byte(o))
+	}
+	return a
+}
+
+func (a *assembler) push(b []byte) *assembler {
+	if len(b) == 0 {
+		b = []byte{0}
+	}
+	a.code = append(a.code, byte(vm.PUSH1)+byte(len(b)-1))
+	a.code = append(a.code, b...)
+	return a
+}
+
+func (a *assembler) pushInt(v uint64) *assembler {
+	return a.push(new(big.Int).SetUint64(v).Bytes())
+}
+
+func (a *assembler) pushLabel(name string) *assembler {
+	a.code = append(a.code, byte(vm.PUSH2))
+	a.refs[len(a.code)] = name
+	a.code = append(a.code, 0, 0)
+	return a
+}
+
+func (a *assembler) label(name string) *assembler {
+	a.labels[name] = len(a.code)
+	return a.op(vm.JUMPDEST)
+}
+
+func (a *assembler) data(name string, b []byte) *assembler {
+	a.blobs = append(a.blobs, blob{name: name, data: b})
+	return a
+}
+
+func (a *assembler) assemble() []byte {
+	out := append([]byte(nil), a.code...)
+	for _, b := range a.blobs {
+		a.labels[b.name] = len(out)
+		out = append(out, b.data...)
+	}
+	for pos, name := range a.refs {
+		target, ok := a.labels[name]
+		if !ok {
+			panic("assembler: undefined label " + name)
+		}
+		binary.BigEndian.PutUint16(out[pos:], uint16(target))
+	}
+	return out
+}
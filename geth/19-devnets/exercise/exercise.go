@@ -3,5 +3,38 @@
 
 package exercise
 
-// Run currently acts as a placeholder; see module README for full implementation steps.
-func Run() {}
+import (
+	"context"
+	"errors"
+)
+
+/*
+Problem: Bring up an in-process devnet and inspect its genesis state.
+
+Every earlier module tested against hand-written mocks. Mocks are fast but
+only check that we call the right methods; they cannot tell us whether a
+signed transaction is actually valid or whether an eth_call decodes real EVM
+output. go-ethereum's simulated backend runs a full node in memory. Devnet
+(devnet.go) wraps it with funded accounts and a sample ERC-20.
+
+Computer science principles highlighted:
+  - Test doubles vs integration environments: fidelity vs speed
+  - Deterministic fixtures: seeded keys give stable addresses across runs
+  - Explicit time: blocks are mined only when Commit is called
+*/
+func Run(ctx context.Context, cfg Config) (*Result, error) {
+	// TODO: Start the devnet
+	// - Default a nil ctx to context.Background()
+	// - Call NewDevnet(ctx, cfg) and defer Close()
+
+	// TODO: Read chain state through the RPC client
+	// - client := dev.Client()
+	// - Head: client.BlockNumber(ctx)
+	// - Balances: client.BalanceAt(ctx, account.Address, nil) for each account
+
+	// TODO: Read the token balance of Accounts[0]
+	// - eth_call balanceOf(address) on dev.Token via client.CallContract
+	// - The return value is a single 32-byte uint256
+
+	return nil, errors.New("not implemented")
+}
@@ -1,7 +1,151 @@
+//go:build devnet
+// +build devnet
+
 package exercise
 
-import "testing"
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func newTestDevnet(t *testing.T, cfg Config) *Devnet {
+	t.Helper()
+	dev, err := NewDevnet(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewDevnet: %v", err)
+	}
+	t.Cleanup(func() { dev.Close() })
+	return dev
+}
+
+func callUint(t *testing.T, dev *Devnet, sig string, args ...common.Address) *big.Int {
+	t.Helper()
+	data := crypto.Keccak256([]byte(sig))[:4]
+	for _, a := range args {
+		data = append(data, common.LeftPadBytes(a.Bytes(), 32)...)
+	}
+	out, err := dev.Client().CallContract(context.Background(), ethereum.CallMsg{To: &dev.Token, Data: data}, nil)
+	if err != nil {
+		t.Fatalf("call %s: %v", sig, err)
+	}
+	return new(big.Int).SetBytes(out)
+}
+
+func TestDevnetGenesisAndToken(t *testing.T) {
+	supply := big.NewInt(5_000)
+	dev := newTestDevnet(t, Config{Accounts: 4, Balance: big.NewInt(1e18), TokenSupply: supply})
+	ctx := context.Background()
+
+	if dev.ChainID.Uint64() != 1337 {
+		t.Fatalf("unexpected chain id %s", dev.ChainID)
+	}
+	if len(dev.Accounts) != 4 {
+		t.Fatalf("expected 4 accounts, got %d", len(dev.Accounts))
+	}
+	if again := devAccount(1); again.Address != dev.Accounts[1].Address {
+		t.Fatalf("account derivation is not deterministic")
+	}
+	for i, acct := range dev.Accounts[1:] {
+		bal, err := dev.Client().BalanceAt(ctx, acct.Address, nil)
+		if err != nil || bal.Cmp(big.NewInt(1e18)) != 0 {
+			t.Fatalf("account %d balance %v err=%v", i+1, bal, err)
+		}
+	}
+
+	code, err := dev.Client().CodeAt(ctx, dev.Token, nil)
+	if err != nil || len(code) == 0 {
+		t.Fatalf("token has no code (err=%v)", err)
+	}
+	if got := callUint(t, dev, "totalSupply()"); got.Cmp(supply) != 0 {
+		t.Fatalf("totalSupply = %s", got)
+	}
+	if got := callUint(t, dev, "decimals()"); got.Uint64() != erc20Decimals {
+		t.Fatalf("decimals = %s", got)
+	}
+	if got := callUint(t, dev, "balanceOf(address)", dev.Accounts[0].Address); got.Cmp(supply) != 0 {
+		t.Fatalf("deployer balance = %s", got)
+	}
+
+	// Storage matches the OpenZeppelin layout: totalSupply lives in slot 2.
+	raw, err := dev.Client().StorageAt(ctx, dev.Token, common.BigToHash(big.NewInt(erc20TotalSupplySlot)), nil)
+	if err != nil || new(big.Int).SetBytes(raw).Cmp(supply) != 0 {
+		t.Fatalf("slot 2 = %x err=%v", raw, err)
+	}
+}
+
+func TestDevnetTokenStrings(t *testing.T) {
+	dev := newTestDevnet(t, Config{TokenName: "A token name longer than thirty-two bytes", TokenSymbol: "LONG"})
+	out, err := dev.Client().CallContract(context.Background(), ethereum.CallMsg{To: &dev.Token, Data: crypto.Keccak256([]byte("name()"))[:4]}, nil)
+	if err != nil {
+		t.Fatalf("name(): %v", err)
+	}
+	if want := abiString("A token name longer than thirty-two bytes"); string(out) != string(want) {
+		t.Fatalf("name() returned %x", out)
+	}
+}
+
+func TestDevnetTransferEmitsLog(t *testing.T) {
+	dev := newTestDevnet(t, Config{})
+	ctx := context.Background()
+	alice, bob := dev.Accounts[0], dev.Accounts[1]
+
+	rcpt, err := dev.TransferERC20(ctx, alice, dev.Token, bob.Address, big.NewInt(250))
+	if err != nil {
+		t.Fatalf("TransferERC20: %v", err)
+	}
+	if len(rcpt.Logs) != 1 {
+		t.Fatalf("expected 1 log, got %d", len(rcpt.Logs))
+	}
+	lg := rcpt.Logs[0]
+	if lg.Topics[0] != transferSigHash ||
+		common.BytesToAddress(lg.Topics[1].Bytes()) != alice.Address ||
+		common.BytesToAddress(lg.Topics[2].Bytes()) != bob.Address ||
+		new(big.Int).SetBytes(lg.Data).Int64() != 250 {
+		t.Fatalf("unexpected Transfer log %+v", lg)
+	}
+	if got := callUint(t, dev, "balanceOf(address)", bob.Address); got.Int64() != 250 {
+		t.Fatalf("bob balance = %s", got)
+	}
+	want := new(big.Int).Sub(DefaultTokenSupply, big.NewInt(250))
+	if got := callUint(t, dev, "balanceOf(address)", alice.Address); got.Cmp(want) != 0 {
+		t.Fatalf("alice balance = %s", got)
+	}
+
+	// Mint + transfer are both visible to eth_getLogs.
+	logs, err := dev.Client().FilterLogs(ctx, ethereum.FilterQuery{Addresses: []common.Address{dev.Token}})
+	if err != nil || len(logs) != 2 {
+		t.Fatalf("expected 2 logs, got %d err=%v", len(logs), err)
+	}
+}
+
+func TestDevnetTransferRevertsOnInsufficientBalance(t *testing.T) {
+	dev := newTestDevnet(t, Config{})
+	ctx := context.Background()
+	if _, err := dev.TransferERC20(ctx, dev.Accounts[1], dev.Token, dev.Accounts[2].Address, big.NewInt(1)); err == nil {
+		t.Fatalf("expected revert for empty sender")
+	}
+	to := dev.Accounts[2].Address
+	rcpt, err := dev.SendAndCommit(ctx, dev.Accounts[1], &to, big.NewInt(1234), nil)
+	if err != nil || rcpt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("plain ETH transfer failed: %v", err)
+	}
+}
 
-func TestRun(t *testing.T) {
-	t.Skip("add tests for this module")
+func TestRunReportsGenesis(t *testing.T) {
+	res, err := Run(context.Background(), Config{Accounts: 2})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if len(res.Accounts) != 2 || res.Head != 1 || res.Token == (common.Address{}) {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	if res.TokenBalance.Cmp(DefaultTokenSupply) != 0 {
+		t.Fatalf("token balance = %s", res.TokenBalance)
+	}
 }
@@ -0,0 +1,83 @@
+//go:build solution && devnet
+// +build solution,devnet
+
+package exercise
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+
+	txnonces "geth-edu/05-tx-nonces/exercise"
+	eip1559 "geth-edu/06-eip1559/exercise"
+	ethcall "geth-edu/07-eth-call/exercise"
+	events "geth-edu/09-events/exercise"
+	receipts "geth-edu/15-receipts/exercise"
+)
+
+// The devnet client must satisfy every module's client interface.
+var (
+	_ txnonces.TXClient      = simulated.Client(nil)
+	_ eip1559.FeeClient      = simulated.Client(nil)
+	_ ethcall.CallClient     = simulated.Client(nil)
+	_ events.LogClient       = simulated.Client(nil)
+	_ receipts.ReceiptClient = simulated.Client(nil)
+)
+
+// TestModulesEndToEnd runs the reference solutions of earlier modules
+// against a real EVM instead of their mocks.
+func TestModulesEndToEnd(t *testing.T) {
+	dev := newTestDevnet(t, Config{TokenName: "Edu", TokenSymbol: "EDU"})
+	ctx := context.Background()
+	client := dev.Client()
+	alice, bob := dev.Accounts[0], dev.Accounts[1]
+
+	// 07: read token metadata through the JSON-ABI codec.
+	meta, err := ethcall.Run(ctx, client, ethcall.Config{Contract: dev.Token})
+	if err != nil {
+		t.Fatalf("07 Run: %v", err)
+	}
+	if meta.Name != "Edu" || meta.Symbol != "EDU" || meta.Decimals != 18 || meta.TotalSupply.Cmp(DefaultTokenSupply) != 0 {
+		t.Fatalf("07 metadata %+v", meta)
+	}
+
+	// 05: legacy value transfer.
+	legacy, err := txnonces.Run(ctx, client, txnonces.Config{PrivateKey: alice.Key, To: bob.Address, AmountWei: big.NewInt(1)})
+	if err != nil {
+		t.Fatalf("05 Run: %v", err)
+	}
+	dev.Commit()
+
+	// 06: dynamic-fee transfer; nonce must follow the legacy tx.
+	dyn, err := eip1559.Run(ctx, client, eip1559.Config{PrivateKey: alice.Key, To: bob.Address, AmountWei: big.NewInt(2)})
+	if err != nil {
+		t.Fatalf("06 Run: %v", err)
+	}
+	if dyn.Nonce != legacy.Nonce+1 {
+		t.Fatalf("06 nonce %d after 05 nonce %d", dyn.Nonce, legacy.Nonce)
+	}
+	dev.Commit()
+
+	// 15: both transactions have successful receipts.
+	for name, tx := range map[string]*types.Transaction{"05": legacy.Tx, "06": dyn.Tx} {
+		rc, err := receipts.Run(ctx, client, receipts.Config{TxHash: tx.Hash()})
+		if err != nil || !rc.StatusOK {
+			t.Fatalf("15 Run for %s tx: %+v err=%v", name, rc, err)
+		}
+	}
+
+	// 09: token transfers show up as decoded Transfer events.
+	if _, err := dev.TransferERC20(ctx, alice, dev.Token, bob.Address, big.NewInt(42)); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	evs, err := events.Run(ctx, client, events.Config{Token: dev.Token, ToHolder: &bob.Address})
+	if err != nil {
+		t.Fatalf("09 Run: %v", err)
+	}
+	if len(evs.Events) != 1 || evs.Events[0].From != alice.Address || evs.Events[0].Value.Int64() != 42 {
+		t.Fatalf("09 events %+v", evs.Events)
+	}
+}
@@ -0,0 +1,33 @@
+package exercise
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Config tunes the devnet genesis and sample token. Zero values use defaults.
+type Config struct {
+	Accounts    int      // number of pre-funded accounts
+	Balance     *big.Int // wei per account
+	TokenName   string
+	TokenSymbol string
+	TokenSupply *big.Int // minted to Accounts[0]
+}
+
+// Account is a funded devnet key pair.
+type Account struct {
+	Key     *ecdsa.PrivateKey
+	Address common.Address
+}
+
+// Result summarizes the devnet Run brought up.
+type Result struct {
+	ChainID      *big.Int
+	Head         uint64
+	Accounts     []common.Address
+	Balances     []*big.Int
+	Token        common.Address
+	TokenBalance *big.Int // Accounts[0] token balance after deployment
+}
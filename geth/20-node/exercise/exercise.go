@@ -3,5 +3,36 @@
 
 package exercise
 
-// Run currently acts as a placeholder; see module README for full implementation steps.
-func Run() {}
+import (
+	"context"
+	"errors"
+)
+
+/*
+Problem: Combine node health signals into one report with a single verdict.
+
+Modules 01 and 21-24 each answer one question (right chain? synced? peers?
+mempool? fresh head?). On-call engineers want one answer: is this node healthy?
+CheckNode (health.go) runs every check, grades each against Thresholds as
+OK/WARN/FAIL, and derives the overall status from the worst result.
+
+Computer science principles highlighted:
+  - Interface composition: NodeClient embeds the per-module client interfaces
+  - Severity lattice: overall status is the max over an ordered set
+  - Failure isolation: one failing RPC degrades one check, not the whole report
+*/
+func Run(ctx context.Context, client NodeClient, cfg Config) (*NodeReport, error) {
+	// TODO: Validate input parameters
+	// - Default a nil ctx to context.Background()
+	// - Return an error if client is nil
+
+	// TODO: Build the report
+	// - CheckNode(ctx, client, cfg) runs chain, sync, peers, mempool and head checks
+	// - cfg.Thresholds == nil uses DefaultThresholds()
+	// - RPC errors are reported as FAIL checks, not returned as errors
+
+	// TODO: Return the report
+	// - report.String() for terminals, report.JSON() for dashboards
+
+	return nil, errors.New("not implemented")
+}
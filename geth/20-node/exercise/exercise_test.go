@@ -1,7 +1,188 @@
 package exercise
 
-import "testing"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
 
-func TestRun(t *testing.T) {
-	t.Skip("add tests for this module")
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type mockNodeClient struct {
+	chainID   *big.Int
+	networkID *big.Int
+	progress  *ethereum.SyncProgress
+	peers     uint64
+	pending   uint
+	headTime  time.Time
+	peerErr   error
+	headerErr error
+}
+
+func (m *mockNodeClient) ChainID(ctx context.Context) (*big.Int, error) { return m.chainID, nil }
+
+func (m *mockNodeClient) NetworkID(ctx context.Context) (*big.Int, error) {
+	return m.networkID, nil
+}
+
+func (m *mockNodeClient) SyncProgress(ctx context.Context) (*ethereum.SyncProgress, error) {
+	return m.progress, nil
+}
+
+func (m *mockNodeClient) PeerCount(ctx context.Context) (uint64, error) { return m.peers, m.peerErr }
+
+func (m *mockNodeClient) PendingTransactionCount(ctx context.Context) (uint, error) {
+	return m.pending, nil
+}
+
+func (m *mockNodeClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if m.headerErr != nil {
+		return nil, m.headerErr
+	}
+	return &types.Header{Number: big.NewInt(19_000_000), Time: uint64(m.headTime.Unix())}, nil
+}
+
+var testNow = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func healthyNode() *mockNodeClient {
+	return &mockNodeClient{
+		chainID:   big.NewInt(1),
+		networkID: big.NewInt(1),
+		peers:     25,
+		pending:   150,
+		headTime:  testNow.Add(-12 * time.Second),
+	}
+}
+
+func checkByName(t *testing.T, r *NodeReport, name string) CheckResult {
+	t.Helper()
+	for _, c := range r.Checks {
+		if c.Name == name {
+			return c
+		}
+	}
+	t.Fatalf("report has no %q check", name)
+	return CheckResult{}
+}
+
+func TestCheckNodeHealthy(t *testing.T) {
+	report := CheckNode(context.Background(), healthyNode(), Config{
+		Thresholds: &Thresholds{ExpectedChainID: big.NewInt(1), PeersWarn: 5, PeersFail: 1, LagWarn: time.Minute},
+		Now:        func() time.Time { return testNow },
+	})
+	if report.Status != StatusOK {
+		t.Fatalf("expected OK, got %s:\n%s", report.Status, report)
+	}
+	if len(report.Checks) != 5 {
+		t.Fatalf("expected 5 checks, got %d", len(report.Checks))
+	}
+	if !report.GeneratedAt.Equal(testNow) {
+		t.Fatalf("report time %v", report.GeneratedAt)
+	}
+}
+
+func TestCheckNodeWorstStatusWins(t *testing.T) {
+	now := func() time.Time { return testNow }
+	cases := []struct {
+		name   string
+		mutate func(m *mockNodeClient)
+		check  string
+		want   Status
+	}{
+		{"few peers warns", func(m *mockNodeClient) { m.peers = 3 }, "peers", StatusWarn},
+		{"no peers fails", func(m *mockNodeClient) { m.peers = 0 }, "peers", StatusFail},
+		{"wrong chain fails", func(m *mockNodeClient) { m.chainID = big.NewInt(5) }, "chain", StatusFail},
+		{"slightly behind warns", func(m *mockNodeClient) {
+			m.progress = &ethereum.SyncProgress{CurrentBlock: 990, HighestBlock: 1000}
+		}, "sync", StatusWarn},
+		{"far behind fails", func(m *mockNodeClient) {
+			m.progress = &ethereum.SyncProgress{CurrentBlock: 10, HighestBlock: 5000}
+		}, "sync", StatusFail},
+		{"busy mempool warns", func(m *mockNodeClient) { m.pending = 20_000 }, "mempool", StatusWarn},
+		{"stale head warns", func(m *mockNodeClient) { m.headTime = testNow.Add(-2 * time.Minute) }, "head", StatusWarn},
+		{"dead head fails", func(m *mockNodeClient) { m.headTime = testNow.Add(-time.Hour) }, "head", StatusFail},
+		{"rpc error fails", func(m *mockNodeClient) { m.peerErr = errors.New("method not found") }, "peers", StatusFail},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := healthyNode()
+			tc.mutate(client)
+			th := DefaultThresholds()
+			th.ExpectedChainID = big.NewInt(1)
+			report := CheckNode(context.Background(), client, Config{Thresholds: th, Now: now})
+
+			if got := checkByName(t, report, tc.check).Status; got != tc.want {
+				t.Fatalf("%s check = %s, want %s", tc.check, got, tc.want)
+			}
+			if report.Status != tc.want {
+				t.Fatalf("overall = %s, want %s", report.Status, tc.want)
+			}
+		})
+	}
+}
+
+func TestCheckNodeIsolatesFailures(t *testing.T) {
+	client := healthyNode()
+	client.headerErr = errors.New("timeout")
+	report := CheckNode(context.Background(), client, Config{Now: func() time.Time { return testNow }})
+	if report.Status != StatusFail {
+		t.Fatalf("expected FAIL, got %s", report.Status)
+	}
+	if got := checkByName(t, report, "peers").Status; got != StatusOK {
+		t.Fatalf("peers check should still run, got %s", got)
+	}
+	if msg := checkByName(t, report, "head").Message; !strings.Contains(msg, "timeout") {
+		t.Fatalf("head message %q should carry the RPC error", msg)
+	}
+}
+
+func TestReportRendering(t *testing.T) {
+	client := healthyNode()
+	client.peers = 2
+	report := CheckNode(context.Background(), client, Config{Now: func() time.Time { return testNow }})
+
+	text := report.String()
+	if !strings.HasPrefix(text, "node status: WARN") || !strings.Contains(text, "[WARN] peers    2 peers") {
+		t.Fatalf("unexpected text report:\n%s", text)
+	}
+
+	raw, err := report.JSON()
+	if err != nil {
+		t.Fatalf("JSON: %v", err)
+	}
+	var decoded struct {
+		Status string `json:"status"`
+		Checks []struct {
+			Name   string `json:"name"`
+			Status string `json:"status"`
+			Value  any    `json:"value"`
+		} `json:"checks"`
+	}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, raw)
+	}
+	if decoded.Status != "WARN" || len(decoded.Checks) != 5 || decoded.Checks[2].Name != "peers" {
+		t.Fatalf("unexpected JSON:\n%s", raw)
+	}
+	if decoded.Checks[2].Value.(float64) != 2 {
+		t.Fatalf("peer value %v", decoded.Checks[2].Value)
+	}
+}
+
+func TestRunValidatesClient(t *testing.T) {
+	if _, err := Run(context.Background(), nil, Config{}); err == nil {
+		t.Fatalf("expected nil client error")
+	}
+	report, err := Run(context.Background(), healthyNode(), Config{Now: func() time.Time { return testNow }})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if report.Status != StatusOK {
+		t.Fatalf("expected OK, got %s", report.Status)
+	}
 }
@@ -0,0 +1,205 @@
+package exercise
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultThresholds returns conservative limits for a mainnet-style node.
+func DefaultThresholds() *Thresholds {
+	return &Thresholds{
+		PeersWarn:      5,
+		PeersFail:      1,
+		SyncBlocksWarn: 1,
+		SyncBlocksFail: 1000,
+		PendingWarn:    10_000,
+		LagWarn:        60 * time.Second,
+		LagFail:        5 * time.Minute,
+	}
+}
+
+// severity orders statuses so the report can take the worst one.
+func (s Status) severity() int {
+	switch s {
+	case StatusOK:
+		return 0
+	case StatusWarn:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Worse returns whichever of s and other is more severe.
+func (s Status) Worse(other Status) Status {
+	if other.severity() > s.severity() {
+		return other
+	}
+	return s
+}
+
+// CheckNode runs every check and aggregates them into a NodeReport. RPC
+// failures become FAIL results rather than errors, so one broken endpoint
+// never hides the rest of the report.
+func CheckNode(ctx context.Context, client NodeClient, cfg Config) *NodeReport {
+	th := cfg.Thresholds
+	if th == nil {
+		th = DefaultThresholds()
+	}
+	now := cfg.Now
+	if now == nil {
+		now = time.Now
+	}
+
+	report := &NodeReport{Status: StatusOK, GeneratedAt: now().UTC()}
+	for _, check := range []CheckResult{
+		checkChain(ctx, client, th),
+		checkSync(ctx, client, th),
+		checkPeers(ctx, client, th),
+		checkMempool(ctx, client, th),
+		checkHeadLag(ctx, client, th, now),
+	} {
+		report.Checks = append(report.Checks, check)
+		report.Status = report.Status.Worse(check.Status)
+	}
+	return report
+}
+
+func checkChain(ctx context.Context, client ChainClient, th *Thresholds) CheckResult {
+	res := CheckResult{Name: "chain"}
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return failed(res, "chain id", err)
+	}
+	networkID, err := client.NetworkID(ctx)
+	if err != nil {
+		return failed(res, "network id", err)
+	}
+	res.Value = map[string]string{"chainId": chainID.String(), "networkId": networkID.String()}
+	if th.ExpectedChainID != nil && chainID.Cmp(th.ExpectedChainID) != 0 {
+		res.Status = StatusFail
+		res.Message = fmt.Sprintf("chain %s, expected %s", chainID, th.ExpectedChainID)
+		return res
+	}
+	res.Status = StatusOK
+	res.Message = fmt.Sprintf("chain %s, network %s", chainID, networkID)
+	return res
+}
+
+func checkSync(ctx context.Context, client SyncClient, th *Thresholds) CheckResult {
+	res := CheckResult{Name: "sync"}
+	progress, err := client.SyncProgress(ctx)
+	if err != nil {
+		return failed(res, "sync progress", err)
+	}
+	if progress == nil {
+		res.Status = StatusOK
+		res.Message = "synced"
+		res.Value = uint64(0)
+		return res
+	}
+	var behind uint64
+	if progress.HighestBlock > progress.CurrentBlock {
+		behind = progress.HighestBlock - progress.CurrentBlock
+	}
+	res.Value = behind
+	res.Status = grade(behind, th.SyncBlocksWarn, th.SyncBlocksFail)
+	res.Message = fmt.Sprintf("syncing: %d blocks behind (current %d, highest %d)",
+		behind, progress.CurrentBlock, progress.HighestBlock)
+	return res
+}
+
+func checkPeers(ctx context.Context, client PeerClient, th *Thresholds) CheckResult {
+	res := CheckResult{Name: "peers"}
+	peers, err := client.PeerCount(ctx)
+	if err != nil {
+		return failed(res, "peer count", err)
+	}
+	res.Value = peers
+	switch {
+	case th.PeersFail > 0 && peers < th.PeersFail:
+		res.Status = StatusFail
+	case peers < th.PeersWarn:
+		res.Status = StatusWarn
+	default:
+		res.Status = StatusOK
+	}
+	res.Message = fmt.Sprintf("%d peers", peers)
+	return res
+}
+
+func checkMempool(ctx context.Context, client MempoolClient, th *Thresholds) CheckResult {
+	res := CheckResult{Name: "mempool"}
+	pending, err := client.PendingTransactionCount(ctx)
+	if err != nil {
+		return failed(res, "pending tx count", err)
+	}
+	res.Value = pending
+	res.Status = grade(uint64(pending), uint64(th.PendingWarn), uint64(th.PendingFail))
+	res.Message = fmt.Sprintf("%d pending txs", pending)
+	return res
+}
+
+func checkHeadLag(ctx context.Context, client MonitorClient, th *Thresholds, now func() time.Time) CheckResult {
+	res := CheckResult{Name: "head"}
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return failed(res, "latest header", err)
+	}
+	if header == nil || header.Number == nil {
+		res.Status = StatusFail
+		res.Message = "latest header: empty response"
+		return res
+	}
+	lag := now().Sub(time.Unix(int64(header.Time), 0))
+	if lag < 0 {
+		lag = 0 // clock skew: block timestamp slightly in our future
+	}
+	res.Value = int64(lag / time.Second)
+	switch {
+	case th.LagFail > 0 && lag >= th.LagFail:
+		res.Status = StatusFail
+	case th.LagWarn > 0 && lag >= th.LagWarn:
+		res.Status = StatusWarn
+	default:
+		res.Status = StatusOK
+	}
+	res.Message = fmt.Sprintf("block %d is %s old", header.Number.Uint64(), lag.Truncate(time.Second))
+	return res
+}
+
+// grade applies "at or above" thresholds; zero disables a level.
+func grade(v, warn, fail uint64) Status {
+	switch {
+	case fail > 0 && v >= fail:
+		return StatusFail
+	case warn > 0 && v >= warn:
+		return StatusWarn
+	default:
+		return StatusOK
+	}
+}
+
+func failed(res CheckResult, what string, err error) CheckResult {
+	res.Status = StatusFail
+	res.Message = fmt.Sprintf("%s: %v", what, err)
+	return res
+}
+
+// String renders the report for humans, one check per line.
+func (r *NodeReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "node status: %s (%s)\n", r.Status, r.GeneratedAt.Format(time.RFC3339))
+	for _, c := range r.Checks {
+		fmt.Fprintf(&b, "  [%-4s] %-8s %s\n", c.Status, c.Name, c.Message)
+	}
+	return b.String()
+}
+
+// JSON renders the report for dashboards and scrapers.
+func (r *NodeReport) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
@@ -3,5 +3,48 @@
 
 package exercise
 
-// Run currently acts as a placeholder; see module README for full implementation steps.
-func Run() {}
+import (
+	"context"
+	"errors"
+)
+
+/*
+Problem: Combine node health signals into one report with a single verdict.
+
+Modules 01 and 21-24 each answer one question (right chain? synced? peers?
+mempool? fresh head?). On-call engineers want one answer: is this node healthy?
+CheckNode (health.go) runs every check, grades each against Thresholds as
+OK/WARN/FAIL, and derives the overall status from the worst result.
+
+Computer science principles highlighted:
+  - Interface composition: NodeClient embeds the per-module client interfaces
+  - Severity lattice: overall status is the max over an ordered set
+  - Failure isolation: one failing RPC degrades one check, not the whole report
+*/
+func Run(ctx context.Context, client NodeClient, cfg Config) (*NodeReport, error) {
+	// ============================================================================
+	// STEP 1: Input Validation
+	// ============================================================================
+	// A nil client is a programming error, so it is returned as an error.
+	// Everything after this point is a runtime condition and lands in the report.
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if client == nil {
+		return nil, errors.New("client is nil")
+	}
+
+	// ============================================================================
+	// STEP 2: Run All Checks
+	// ============================================================================
+	// Each check mirrors an earlier module:
+	//   chain   -> 01 ChainID/NetworkID
+	//   sync    -> 21 SyncProgress (nil = synced)
+	//   peers   -> 22 PeerCount
+	//   mempool -> 23 PendingTransactionCount
+	//   head    -> 24 HeaderByNumber(nil) lag vs wall clock
+	//
+	// The overall status is the worst individual status: one FAIL makes the
+	// node FAIL, otherwise one WARN makes it WARN.
+	return CheckNode(ctx, client, cfg), nil
+}
@@ -0,0 +1,94 @@
+package exercise
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ChainClient mirrors the identity calls from module 01.
+type ChainClient interface {
+	ChainID(ctx context.Context) (*big.Int, error)
+	NetworkID(ctx context.Context) (*big.Int, error)
+}
+
+// SyncClient mirrors module 21.
+type SyncClient interface {
+	SyncProgress(ctx context.Context) (*ethereum.SyncProgress, error)
+}
+
+// PeerClient mirrors module 22.
+type PeerClient interface {
+	PeerCount(ctx context.Context) (uint64, error)
+}
+
+// MempoolClient mirrors module 23.
+type MempoolClient interface {
+	PendingTransactionCount(ctx context.Context) (uint, error)
+}
+
+// MonitorClient mirrors module 24.
+type MonitorClient interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// NodeClient is everything the health aggregator needs. *ethclient.Client
+// satisfies it.
+type NodeClient interface {
+	ChainClient
+	SyncClient
+	PeerClient
+	MempoolClient
+	MonitorClient
+}
+
+// Thresholds decide when a check warns or fails. A zero fail threshold
+// disables the failing level for that check.
+type Thresholds struct {
+	ExpectedChainID *big.Int // nil => accept any chain
+
+	PeersWarn uint64 // warn below this many peers
+	PeersFail uint64 // fail below this many peers
+
+	SyncBlocksWarn uint64 // warn when syncing and at least this far behind
+	SyncBlocksFail uint64 // fail when syncing and at least this far behind
+
+	PendingWarn uint // warn at or above this many pending txs
+	PendingFail uint // fail at or above this many pending txs
+
+	LagWarn time.Duration // warn when the head is this old
+	LagFail time.Duration // fail when the head is this old
+}
+
+// Config controls a health check run.
+type Config struct {
+	Thresholds *Thresholds      // nil => DefaultThresholds()
+	Now        func() time.Time // nil => time.Now; injectable for tests
+}
+
+// Status is the outcome of a single check or of the whole report.
+type Status string
+
+const (
+	StatusOK   Status = "OK"
+	StatusWarn Status = "WARN"
+	StatusFail Status = "FAIL"
+)
+
+// CheckResult is one line of the report.
+type CheckResult struct {
+	Name    string `json:"name"`
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+	Value   any    `json:"value,omitempty"`
+}
+
+// NodeReport is the aggregated node health. Status is the worst check status.
+type NodeReport struct {
+	Status      Status        `json:"status"`
+	GeneratedAt time.Time     `json:"generatedAt"`
+	Checks      []CheckResult `json:"checks"`
+}
@@ -1,7 +1,301 @@
 package exercise
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+)
+
+var start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// fakeClock advances only when told to, or when After is called so Run
+// loops complete instantly.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.advance(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.Now()
+	return ch
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+type step struct {
+	progress *ethereum.SyncProgress
+	err      error
+}
+
+// scriptedSync returns its steps in order and repeats the last one.
+type scriptedSync struct {
+	mu    sync.Mutex
+	steps []step
+	calls int
+}
+
+func (s *scriptedSync) SyncProgress(ctx context.Context) (*ethereum.SyncProgress, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.calls
+	if i >= len(s.steps) {
+		i = len(s.steps) - 1
+	}
+	s.calls++
+	return s.steps[i].progress, s.steps[i].err
+}
+
+func blocks(current, highest uint64) step {
+	return step{progress: &ethereum.SyncProgress{CurrentBlock: current, HighestBlock: highest}}
+}
+
+func approx(got, want float64) bool {
+	return math.Abs(got-want) < 1e-9
+}
+
+func newTestTracker(t *testing.T, client SyncClient, cfg TrackerConfig) (*Tracker, *fakeClock) {
+	t.Helper()
+	clock := &fakeClock{now: start}
+	cfg.Clock = clock
+	tr, err := NewTracker(client, cfg)
+	if err != nil {
+		t.Fatalf("NewTracker: %v", err)
+	}
+	return tr, clock
+}
 
 func TestRun(t *testing.T) {
-	t.Skip("add tests for this module")
+	res, err := Run(context.Background(), &scriptedSync{steps: []step{{}}}, Config{})
+	if err != nil || res.IsSyncing || res.Progress != nil {
+		t.Fatalf("synced node: res=%+v err=%v", res, err)
+	}
+	res, err = Run(context.Background(), &scriptedSync{steps: []step{blocks(10, 20)}}, Config{})
+	if err != nil || !res.IsSyncing || res.Progress.CurrentBlock != 10 {
+		t.Fatalf("syncing node: res=%+v err=%v", res, err)
+	}
+	boom := errors.New("boom")
+	if _, err := Run(context.Background(), &scriptedSync{steps: []step{{err: boom}}}, Config{}); !errors.Is(err, boom) {
+		t.Fatalf("expected wrapped boom, got %v", err)
+	}
+	if _, err := Run(context.Background(), nil, Config{}); err == nil {
+		t.Fatal("expected error for nil client")
+	}
+}
+
+func TestDetectPhase(t *testing.T) {
+	tests := []struct {
+		name string
+		p    *ethereum.SyncProgress
+		want SyncPhase
+	}{
+		{"synced", nil, PhaseSynced},
+		{"full sync", &ethereum.SyncProgress{CurrentBlock: 10, HighestBlock: 100}, PhaseFull},
+		{"snap state", &ethereum.SyncProgress{HighestBlock: 100, SyncedAccounts: 5, SyncedStorage: 9}, PhaseSnapState},
+		{"snap healing", &ethereum.SyncProgress{HighestBlock: 100, SyncedAccounts: 5, HealedTrienodes: 3, HealingTrienodes: 40}, PhaseSnapHealing},
+		{"healing bytecode only", &ethereum.SyncProgress{HighestBlock: 100, HealingBytecode: 1}, PhaseSnapHealing},
+		{"catching up after snap", &ethereum.SyncProgress{CurrentBlock: 90, HighestBlock: 100, SyncedAccounts: 5, HealedTrienodes: 3}, PhaseFull},
+		{"tx indexing", &ethereum.SyncProgress{CurrentBlock: 100, HighestBlock: 100, TxIndexRemainingBlocks: 7}, PhaseTxIndexing},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectPhase(tt.p); got != tt.want {
+				t.Fatalf("DetectPhase = %s, want %s", got, tt.want)
+			}
+		})
+	}
+	if !PhaseSnapHealing.Snap() || PhaseFull.Snap() {
+		t.Fatal("Snap() misclassifies phases")
+	}
+}
+
+func TestTrackerRatesAndETA(t *testing.T) {
+	// Every 10s the node imports 100 blocks while the head moves 10.
+	client := &scriptedSync{steps: []step{
+		blocks(0, 1000),
+		blocks(100, 1010),
+		blocks(200, 1020),
+		blocks(1200, 1030), // burst: 1000 blocks in 10s
+	}}
+	tr, clock := newTestTracker(t, client, TrackerConfig{Alpha: 0.5})
+
+	r := tr.Tick(context.Background())
+	if r.ETAKnown || r.Phase != PhaseFull || r.Remaining != 1000 {
+		t.Fatalf("first sample has no rates yet: %+v", r)
+	}
+
+	clock.advance(10 * time.Second)
+	r = tr.Tick(context.Background())
+	if !approx(r.BlocksPerSec, 10) || !approx(r.HeadPerSec, 1) {
+		t.Fatalf("rates = %v blocks/s, %v head/s", r.BlocksPerSec, r.HeadPerSec)
+	}
+	// 910 blocks behind, closing the gap at 10-1 = 9 blocks/s.
+	remaining := 910.0
+	if want := time.Duration(remaining / 9 * float64(time.Second)); !r.ETAKnown || r.ETA != want {
+		t.Fatalf("ETA = %v (known=%v)", r.ETA, r.ETAKnown)
+	}
+	if !approx(r.Percent, 100.0/1010*100) {
+		t.Fatalf("Percent = %v", r.Percent)
+	}
+
+	clock.advance(10 * time.Second)
+	tr.Tick(context.Background())
+	clock.advance(10 * time.Second)
+	r = tr.Tick(context.Background())
+	// The burst is smoothed: 0.5*100 + 0.5*10 rather than 100.
+	if !approx(r.BlocksPerSec, 55) {
+		t.Fatalf("smoothed rate = %v, want 55", r.BlocksPerSec)
+	}
+	if r.Remaining != 0 || r.ETA != 0 || !r.ETAKnown {
+		t.Fatalf("caught up: %+v", r)
+	}
+	if tr.Last().Time != r.Time {
+		t.Fatal("Last should return the latest report")
+	}
+}
+
+func TestTrackerETAUnknownWhenHeadOutruns(t *testing.T) {
+	client := &scriptedSync{steps: []step{blocks(0, 1000), blocks(10, 1020)}}
+	tr, clock := newTestTracker(t, client, TrackerConfig{})
+	tr.Tick(context.Background())
+	clock.advance(10 * time.Second)
+	if r := tr.Tick(context.Background()); r.ETAKnown {
+		t.Fatalf("head grows faster than import, ETA should be unknown: %+v", r)
+	}
+}
+
+func TestTrackerStallDetection(t *testing.T) {
+	boom := errors.New("connection refused")
+	client := &scriptedSync{steps: []step{
+		blocks(100, 1000),
+		blocks(100, 1010), // head moves, we don't
+		blocks(100, 1020),
+		{err: boom},
+		blocks(150, 1030), // import resumes
+	}}
+	tr, clock := newTestTracker(t, client, TrackerConfig{StallWindow: time.Minute})
+
+	var reports []Report
+	for i := 0; i < 5; i++ {
+		reports = append(reports, tr.Tick(context.Background()))
+		clock.advance(30 * time.Second)
+	}
+	for i, want := range []bool{false, false, true, true, false} {
+		if reports[i].Stalled != want {
+			t.Fatalf("report %d stalled=%v want %v (for %v)", i, reports[i].Stalled, want, reports[i].StalledFor)
+		}
+	}
+	if reports[3].StalledFor != 90*time.Second || !errors.Is(reports[3].Err, boom) {
+		t.Fatalf("errors keep the stall clock running: %+v", reports[3])
+	}
+	if reports[3].Phase != PhaseFull {
+		t.Fatalf("error report should keep the last phase, got %s", reports[3].Phase)
+	}
+}
+
+func TestTrackerSnapPhases(t *testing.T) {
+	client := &scriptedSync{steps: []step{
+		{progress: &ethereum.SyncProgress{HighestBlock: 1000, SyncedAccounts: 1000, SyncedStorage: 4000}},
+		{progress: &ethereum.SyncProgress{HighestBlock: 1010, SyncedAccounts: 2000, SyncedStorage: 8000}},
+		{progress: &ethereum.SyncProgress{HighestBlock: 1020, SyncedAccounts: 2000, SyncedStorage: 8000, HealedTrienodes: 500, HealingTrienodes: 300}},
+		{progress: &ethereum.SyncProgress{HighestBlock: 1030, SyncedAccounts: 2000, SyncedStorage: 8000, HealedTrienodes: 900, HealedBytecodes: 100, HealingTrienodes: 20}},
+		{progress: &ethereum.SyncProgress{CurrentBlock: 1000, HighestBlock: 1040, SyncedAccounts: 2000, SyncedStorage: 8000, HealedTrienodes: 900, HealedBytecodes: 100}},
+		{},
+	}}
+	tr, clock := newTestTracker(t, client, TrackerConfig{Alpha: 1})
+
+	var reports []Report
+	for i := 0; i < 6; i++ {
+		reports = append(reports, tr.Tick(context.Background()))
+		clock.advance(10 * time.Second)
+	}
+	phases := []SyncPhase{PhaseSnapState, PhaseSnapState, PhaseSnapHealing, PhaseSnapHealing, PhaseFull, PhaseSynced}
+	for i, want := range phases {
+		if reports[i].Phase != want {
+			t.Fatalf("report %d phase=%s want %s", i, reports[i].Phase, want)
+		}
+		if reports[i].Stalled {
+			t.Fatalf("report %d: state and healing progress is not a stall", i)
+		}
+	}
+	if !approx(reports[1].StatePerSec, 500) {
+		t.Fatalf("state rate = %v, want 500 items/s", reports[1].StatePerSec)
+	}
+	if !approx(reports[3].HealPerSec, 50) {
+		t.Fatalf("heal rate = %v, want 50 nodes/s", reports[3].HealPerSec)
+	}
+	if r := reports[5]; r.Progress != nil || r.BlocksPerSec != 0 || r.ETAKnown {
+		t.Fatalf("synced report should be empty: %+v", r)
+	}
+}
+
+func TestTrackerSnapWithoutHealing(t *testing.T) {
+	state := func(current, highest, accounts uint64) step {
+		return step{progress: &ethereum.SyncProgress{CurrentBlock: current, HighestBlock: highest, SyncedAccounts: accounts}}
+	}
+	client := &scriptedSync{steps: []step{
+		state(100, 1000, 1000),
+		state(200, 1000, 2000),
+		state(900, 1010, 2000), // state done, nothing to heal, blocks importing
+		state(900, 1010, 2000), // a stall doesn't bring the state phase back
+		{progress: &ethereum.SyncProgress{CurrentBlock: 1010, HighestBlock: 1010, SyncedAccounts: 2000, TxIndexRemainingBlocks: 50}},
+	}}
+	tr, clock := newTestTracker(t, client, TrackerConfig{})
+
+	phases := []SyncPhase{PhaseSnapState, PhaseSnapState, PhaseFull, PhaseFull, PhaseTxIndexing}
+	for i, want := range phases {
+		if got := tr.Tick(context.Background()).Phase; got != want {
+			t.Fatalf("report %d phase=%s want %s", i, got, want)
+		}
+		clock.advance(10 * time.Second)
+	}
+}
+
+func TestTrackerRunLoop(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	client := &scriptedSync{}
+	for i := uint64(0); i < 5; i++ {
+		client.steps = append(client.steps, blocks(i*50, 1000))
+	}
+	tr, _ := newTestTracker(t, client, TrackerConfig{Interval: 5 * time.Second})
+	done := make(chan error, 1)
+	go func() { done <- tr.Run(ctx) }()
+	deadline := time.After(5 * time.Second)
+	for tr.Last().BlocksPerSec == 0 {
+		select {
+		case <-deadline:
+			t.Fatal("Run never produced rates")
+		default:
+			time.Sleep(time.Millisecond)
+		}
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run returned %v", err)
+	}
+}
+
+func TestNewTrackerValidation(t *testing.T) {
+	if _, err := NewTracker(nil, TrackerConfig{}); err == nil {
+		t.Fatal("expected error for nil client")
+	}
+	if _, err := NewTracker(&scriptedSync{}, TrackerConfig{Alpha: 1.5}); err == nil {
+		t.Fatal("expected error for alpha > 1")
+	}
 }
@@ -0,0 +1,305 @@
+package exercise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+)
+
+// SyncPhase is what a syncing node is busy with, derived from the fields
+// eth_syncing reports.
+type SyncPhase string
+
+const (
+	PhaseSynced      SyncPhase = "synced"       // eth_syncing returned false
+	PhaseSnapState   SyncPhase = "snap-state"   // downloading accounts, storage and bytecodes at the pivot
+	PhaseSnapHealing SyncPhase = "snap-healing" // fixing trie nodes that changed while the state downloaded
+	PhaseFull        SyncPhase = "full"         // importing blocks (full sync, or catching up after snap)
+	PhaseTxIndexing  SyncPhase = "tx-indexing"  // at head, still indexing transactions by hash
+)
+
+// DetectPhase classifies a progress snapshot. A nil snapshot means synced.
+//
+// Snap sync fills the Synced* counters while it downloads the state, then
+// reports pending trie nodes in Healing* until the state is consistent.
+// After that, or when snap sync was never used, the node imports blocks one
+// by one and only the block numbers move.
+//
+// A state download that needed no healing leaves the same snapshot as one
+// still in progress, so DetectPhase reports PhaseSnapState for both. Tracker
+// compares consecutive samples to tell them apart.
+func DetectPhase(p *ethereum.SyncProgress) SyncPhase {
+	switch {
+	case p == nil:
+		return PhaseSynced
+	case p.HealingTrienodes > 0 || p.HealingBytecode > 0:
+		return PhaseSnapHealing
+	case stateStarted(p) && p.HealedTrienodes == 0 && p.HealedBytecodes == 0:
+		return PhaseSnapState
+	}
+	return blockPhase(p)
+}
+
+// blockPhase is the phase of a node whose state is complete.
+func blockPhase(p *ethereum.SyncProgress) SyncPhase {
+	if p.CurrentBlock >= p.HighestBlock && p.TxIndexRemainingBlocks > 0 {
+		return PhaseTxIndexing
+	}
+	return PhaseFull
+}
+
+// Snap reports whether the phase only occurs in snap sync.
+func (p SyncPhase) Snap() bool {
+	return p == PhaseSnapState || p == PhaseSnapHealing
+}
+
+func stateStarted(p *ethereum.SyncProgress) bool {
+	return p.SyncedAccounts > 0 || p.SyncedStorage > 0 || p.SyncedBytecodes > 0 || p.PulledStates > 0
+}
+
+// Clock abstracts time so tests can replay hours of sync instantly.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// TrackerConfig tunes the tracker. Zero values use defaults.
+type TrackerConfig struct {
+	Interval    time.Duration // poll period for Run (default 10s)
+	Alpha       float64       // EWMA weight of the newest rate, (0,1] (default 0.2)
+	StallWindow time.Duration // no progress for this long is a stall (default 2m)
+	Clock       Clock
+}
+
+// Report is one evaluation of sync progress.
+type Report struct {
+	Time     time.Time
+	Phase    SyncPhase
+	Progress *ethereum.SyncProgress // nil when synced or on error
+	Err      error
+
+	Remaining uint64  // HighestBlock - CurrentBlock
+	Percent   float64 // CurrentBlock / HighestBlock * 100
+
+	BlocksPerSec float64 // smoothed import rate
+	HeadPerSec   float64 // smoothed rate at which HighestBlock moves
+	HealPerSec   float64 // smoothed healed trie nodes + bytecodes per second
+	StatePerSec  float64 // smoothed accounts + storage slots + bytecodes per second
+
+	ETA      time.Duration // time to reach the head at the current rates
+	ETAKnown bool          // false until two samples, or while the head outruns the import
+
+	Stalled    bool
+	StalledFor time.Duration // time since any counter last moved
+}
+
+// Tracker samples SyncProgress over time and turns the raw counters into
+// rates, an ETA and stall detection. Rates are exponentially weighted so a
+// burst of fast blocks doesn't swing the ETA. The ETA accounts for the head
+// moving on: it divides the remaining blocks by how much faster the node
+// imports than the chain grows.
+type Tracker struct {
+	client SyncClient
+	cfg    TrackerConfig
+
+	mu           sync.Mutex
+	prev         *ethereum.SyncProgress
+	prevAt       time.Time
+	lastProgress time.Time
+	rated        bool // rates hold at least one measurement
+	stateDone    bool // state download finished without healing
+	blockRate    float64
+	headRate     float64
+	healRate     float64
+	stateRate    float64
+	last         Report
+}
+
+// NewTracker validates cfg and returns a tracker with no samples.
+func NewTracker(client SyncClient, cfg TrackerConfig) (*Tracker, error) {
+	if client == nil {
+		return nil, errors.New("client is nil")
+	}
+	if cfg.Alpha < 0 || cfg.Alpha > 1 {
+		return nil, fmt.Errorf("alpha %v outside (0,1]", cfg.Alpha)
+	}
+	if cfg.Alpha == 0 {
+		cfg.Alpha = 0.2
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = 10 * time.Second
+	}
+	if cfg.StallWindow <= 0 {
+		cfg.StallWindow = 2 * time.Minute
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = realClock{}
+	}
+	return &Tracker{client: client, cfg: cfg}, nil
+}
+
+// Run samples every Interval until ctx is cancelled. Use Last to read the
+// latest report.
+func (t *Tracker) Run(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for {
+		// A cancelled tracker takes no further sample, even if the
+		// next Interval has already elapsed.
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		t.Tick(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.cfg.Clock.After(t.cfg.Interval):
+		}
+	}
+}
+
+// Tick takes one sample and updates the rates. Run calls it on every
+// interval; tests can call it directly.
+func (t *Tracker) Tick(ctx context.Context) Report {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	now := t.cfg.Clock.Now()
+	p, err := t.client.SyncProgress(ctx)
+	if err != nil {
+		err = fmt.Errorf("sync progress: %w", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	r := t.record(now, p, err)
+	t.last = r
+	return r
+}
+
+// Last returns the most recent report.
+func (t *Tracker) Last() Report {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.last
+}
+
+func (t *Tracker) record(now time.Time, p *ethereum.SyncProgress, err error) Report {
+	r := Report{Time: now}
+	if t.lastProgress.IsZero() {
+		t.lastProgress = now
+	}
+
+	switch {
+	case err != nil:
+		// An unreachable node makes no progress; the stall clock keeps running.
+		r.Err = err
+		r.Phase = t.last.Phase
+	case p == nil:
+		// Synced: drop the history so a later resync starts fresh.
+		t.prev, t.rated, t.stateDone = nil, false, false
+		t.blockRate, t.headRate, t.healRate, t.stateRate = 0, 0, 0, 0
+		t.lastProgress = now
+		r.Phase = PhaseSynced
+		return r
+	default:
+		cp := *p
+		r.Progress = &cp
+		r.Phase = DetectPhase(p)
+		if t.prev != nil {
+			t.updateStateDone(t.prev, p)
+		}
+		if r.Phase == PhaseSnapState && t.stateDone {
+			r.Phase = blockPhase(p)
+		}
+		if t.prev != nil {
+			if moved(t.prev, p) {
+				t.lastProgress = now
+			}
+			if dt := now.Sub(t.prevAt).Seconds(); dt > 0 {
+				t.updateRates(t.prev, p, dt)
+			}
+		}
+		t.prev, t.prevAt = &cp, now
+	}
+
+	r.BlocksPerSec, r.HeadPerSec = t.blockRate, t.headRate
+	r.HealPerSec, r.StatePerSec = t.healRate, t.stateRate
+	if p := t.prev; p != nil {
+		if p.HighestBlock > p.CurrentBlock {
+			r.Remaining = p.HighestBlock - p.CurrentBlock
+		}
+		if p.HighestBlock > 0 {
+			r.Percent = float64(p.CurrentBlock) / float64(p.HighestBlock) * 100
+		}
+		if net := t.blockRate - t.headRate; t.rated && net > 0 {
+			r.ETA = time.Duration(float64(r.Remaining) / net * float64(time.Second))
+			r.ETAKnown = true
+		}
+	}
+	r.StalledFor = now.Sub(t.lastProgress)
+	r.Stalled = r.StalledFor >= t.cfg.StallWindow
+	return r
+}
+
+func (t *Tracker) updateRates(prev, cur *ethereum.SyncProgress, dt float64) {
+	blocks := float64(delta(prev.CurrentBlock, cur.CurrentBlock)) / dt
+	head := float64(delta(prev.HighestBlock, cur.HighestBlock)) / dt
+	heal := float64(delta(prev.HealedTrienodes+prev.HealedBytecodes, cur.HealedTrienodes+cur.HealedBytecodes)) / dt
+	state := float64(delta(stateItems(prev), stateItems(cur))) / dt
+	if !t.rated {
+		t.blockRate, t.headRate, t.healRate, t.stateRate = blocks, head, heal, state
+		t.rated = true
+		return
+	}
+	a := t.cfg.Alpha
+	t.blockRate = a*blocks + (1-a)*t.blockRate
+	t.headRate = a*head + (1-a)*t.headRate
+	t.healRate = a*heal + (1-a)*t.healRate
+	t.stateRate = a*state + (1-a)*t.stateRate
+}
+
+// updateStateDone notices a state download that ended without healing:
+// the state counters stop while blocks import again. It stays set until the
+// counters move (or reset, after a node restart).
+func (t *Tracker) updateStateDone(prev, cur *ethereum.SyncProgress) {
+	switch {
+	case stateItems(cur) != stateItems(prev):
+		t.stateDone = false
+	case cur.CurrentBlock > prev.CurrentBlock:
+		t.stateDone = true
+	}
+}
+
+func stateItems(p *ethereum.SyncProgress) uint64 {
+	return p.SyncedAccounts + p.SyncedStorage + p.SyncedBytecodes + p.PulledStates
+}
+
+// moved reports whether any counter that only grows while work gets done
+// went up. HighestBlock is left out: peers announcing new blocks isn't
+// progress on our side.
+func moved(prev, cur *ethereum.SyncProgress) bool {
+	return cur.CurrentBlock > prev.CurrentBlock ||
+		stateItems(cur) > stateItems(prev) ||
+		cur.HealedTrienodes+cur.HealedBytecodes > prev.HealedTrienodes+prev.HealedBytecodes ||
+		cur.TxIndexFinishedBlocks > prev.TxIndexFinishedBlocks
+}
+
+// delta is cur-prev, or 0 if the counter went backwards (a node restart
+// resets the snap counters).
+func delta(prev, cur uint64) uint64 {
+	if cur < prev {
+		return 0
+	}
+	return cur - prev
+}
@@ -0,0 +1,65 @@
+// Command peers prints a node's peer inventory from admin_peers and,
+// optionally, the churn over an interval.
+//
+// The admin API is normally only served over IPC:
+//
+//	go run ./22-peers/cmd/peers -rpc ~/.ethereum/geth.ipc
+//	go run ./22-peers/cmd/peers -rpc ~/.ethereum/geth.ipc -churn 1m -format json
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+
+	peers "geth-edu/22-peers/exercise"
+)
+
+func main() {
+	endpoint := flag.String("rpc", "http://127.0.0.1:8545", "node endpoint with the admin API (IPC path, http or ws URL)")
+	format := flag.String("format", "table", "output format: table or json")
+	churn := flag.Duration("churn", 0, "take a second snapshot after this long and print joined/left peers")
+	flag.Parse()
+
+	f, err := peers.ParseFormat(*format)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	c, err := rpc.DialContext(ctx, *endpoint)
+	cancel()
+	if err != nil {
+		log.Fatalf("dial %s: %v", *endpoint, err)
+	}
+	client := peers.NewAdminClient(c)
+	defer client.Close()
+
+	before, err := inspect(client)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *churn <= 0 {
+		if err := before.Render(os.Stdout, f); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	time.Sleep(*churn)
+	after, err := inspect(client)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := peers.Diff(before, after).Render(os.Stdout, f); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func inspect(client peers.PeerClient) (*peers.Inventory, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return peers.Inspect(ctx, client)
+}
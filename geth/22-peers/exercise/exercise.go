@@ -47,8 +47,15 @@ func Run(ctx context.Context, client PeerClient, cfg Config) (*Result, error) {
 	// - Most public RPCs hide peer details for privacy/security
 	// - Why: Understanding limitations helps set realistic expectations
 
+	// TODO: Optionally build the peer inventory
+	// - Only when cfg.Inventory is set: admin_peers needs the admin API
+	// - Call Inspect(ctx, client) and return its error unchanged
+	// - Why: client diversity, direction and protocol versions say far more
+	//   about connectivity than a bare count
+
 	// TODO: Construct and return the Result struct
 	// - Store the peer count in the Result
+	// - Store the inventory (nil when not requested)
 	// - Return the result with nil error on success
 	// - No defensive copying needed: uint64 is a primitive type (copied by value)
 
@@ -1,7 +1,283 @@
 package exercise
 
-import "testing"
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var _ PeerClient = (*AdminClient)(nil)
+
+type mockPeerClient struct {
+	count     uint64
+	countErr  error
+	peers     []*p2p.PeerInfo
+	peersErr  error
+	peerCalls int
+}
+
+func (m *mockPeerClient) PeerCount(ctx context.Context) (uint64, error) {
+	return m.count, m.countErr
+}
+
+func (m *mockPeerClient) Peers(ctx context.Context) ([]*p2p.PeerInfo, error) {
+	m.peerCalls++
+	return m.peers, m.peersErr
+}
+
+func loadPeers(t *testing.T, name string) []*p2p.PeerInfo {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	var peers []*p2p.PeerInfo
+	if err := json.Unmarshal(raw, &peers); err != nil {
+		t.Fatalf("decode fixture: %v", err)
+	}
+	return peers
+}
+
+func inspectFixture(t *testing.T, name string) *Inventory {
+	t.Helper()
+	inv, err := Inspect(context.Background(), &mockPeerClient{peers: loadPeers(t, name)})
+	if err != nil {
+		t.Fatalf("Inspect: %v", err)
+	}
+	return inv
+}
+
+func id(c byte) string { return strings.Repeat(string(c), 64) }
 
 func TestRun(t *testing.T) {
-	t.Skip("add tests for this module")
+	client := &mockPeerClient{count: 6, peers: loadPeers(t, "admin_peers_before.json")}
+	res, err := Run(context.Background(), client, Config{})
+	if err != nil || res.PeerCount != 6 || res.Inventory != nil {
+		t.Fatalf("count only: res=%+v err=%v", res, err)
+	}
+	if client.peerCalls != 0 {
+		t.Fatal("admin_peers must not be called unless Inventory is set")
+	}
+
+	res, err = Run(context.Background(), client, Config{Inventory: true})
+	if err != nil || res.Inventory == nil || res.Inventory.Total != 6 {
+		t.Fatalf("with inventory: res=%+v err=%v", res, err)
+	}
+
+	denied := errors.New("the method admin_peers does not exist/is not available")
+	_, err = Run(context.Background(), &mockPeerClient{peersErr: denied}, Config{Inventory: true})
+	if !errors.Is(err, denied) {
+		t.Fatalf("expected wrapped admin error, got %v", err)
+	}
+	boom := errors.New("boom")
+	if _, err := Run(context.Background(), &mockPeerClient{countErr: boom}, Config{}); !errors.Is(err, boom) {
+		t.Fatalf("expected wrapped count error, got %v", err)
+	}
+	if _, err := Run(context.Background(), nil, Config{}); err == nil {
+		t.Fatal("expected error for nil client")
+	}
+}
+
+func TestParseName(t *testing.T) {
+	tests := []struct {
+		name, client, version string
+	}{
+		{"Geth/v1.13.14-stable-2bd6bd01/linux-amd64/go1.21.7", "geth", "v1.13.14"},
+		{"Geth/my-node/v1.13.14-stable/linux-arm64/go1.21.7", "geth", "v1.13.14"},
+		{"Nethermind/v1.25.4+20b10b35/linux-x64/dotnet8.0.2", "nethermind", "v1.25.4"},
+		{"reth/v0.1.0-alpha.21-4c8e6d9/x86_64-unknown-linux-gnu", "reth", "v0.1.0"},
+		{"besu", "besu", ""},
+		{"", "unknown", ""},
+	}
+	for _, tt := range tests {
+		client, version := parseName(tt.name)
+		if client != tt.client || version != tt.version {
+			t.Errorf("parseName(%q) = %q, %q; want %q, %q", tt.name, client, version, tt.client, tt.version)
+		}
+	}
+}
+
+func TestInspectFixture(t *testing.T) {
+	inv := inspectFixture(t, "admin_peers_before.json")
+
+	if inv.Total != 6 || inv.Inbound != 2 || inv.Outbound != 4 {
+		t.Fatalf("direction: total=%d in=%d out=%d", inv.Total, inv.Inbound, inv.Outbound)
+	}
+	if inv.InboundRatio != 2.0/6 {
+		t.Fatalf("inbound ratio = %v", inv.InboundRatio)
+	}
+
+	var clients []string
+	for _, c := range inv.Clients {
+		clients = append(clients, c.Client)
+	}
+	if want := []string{"geth", "besu", "erigon", "nethermind"}; !reflect.DeepEqual(clients, want) {
+		t.Fatalf("client order = %v, want %v", clients, want)
+	}
+	geth := inv.Clients[0]
+	if geth.Count != 3 || geth.Share != 0.5 {
+		t.Fatalf("geth share = %+v", geth)
+	}
+	if want := []VersionCount{{"v1.13.14", 2}, {"v1.13.12", 1}}; !reflect.DeepEqual(geth.Versions, want) {
+		t.Fatalf("geth versions = %+v", geth.Versions)
+	}
+
+	want := []ProtocolSpread{
+		{Protocol: "eth", Peers: 6, Versions: []VersionCount{{"68", 5}, {"67", 1}}},
+		{Protocol: "snap", Peers: 3, Versions: []VersionCount{{"1", 3}}},
+	}
+	if !reflect.DeepEqual(inv.Protocols, want) {
+		t.Fatalf("protocols = %+v", inv.Protocols)
+	}
+
+	// besu is still in the eth handshake: its highest advertised version counts.
+	besu := inv.Peers[len(inv.Peers)-1]
+	if besu.ID != id('f') || besu.Protocols["eth"] != 68 {
+		t.Fatalf("handshake peer = %+v", besu)
+	}
+	if inv.Peers[0].ID != id('a') || inv.Peers[0].RemoteAddress != "1.2.3.4:30303" || inv.Peers[0].Direction() != "out" {
+		t.Fatalf("first peer = %+v", inv.Peers[0])
+	}
+}
+
+func TestInspectEmpty(t *testing.T) {
+	inv, err := Inspect(context.Background(), &mockPeerClient{})
+	if err != nil {
+		t.Fatalf("Inspect: %v", err)
+	}
+	if inv.Total != 0 || inv.InboundRatio != 0 {
+		t.Fatalf("empty inventory = %+v", inv)
+	}
+	out, _ := json.Marshal(inv)
+	if !strings.Contains(string(out), `"peers":[]`) || !strings.Contains(string(out), `"clients":[]`) {
+		t.Fatalf("empty lists should encode as [], got %s", out)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	before := inspectFixture(t, "admin_peers_before.json")
+	after := inspectFixture(t, "admin_peers_after.json")
+	churn := Diff(before, after)
+
+	ids := func(peers []Peer) []string {
+		var out []string
+		for _, p := range peers {
+			out = append(out, p.ID)
+		}
+		return out
+	}
+	if got, want := ids(churn.Joined), []string{id('1'), id('2')}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("joined = %v", got)
+	}
+	if got, want := ids(churn.Left), []string{id('e'), id('f')}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("left = %v", got)
+	}
+	if churn.Stayed != 4 {
+		t.Fatalf("stayed = %d", churn.Stayed)
+	}
+
+	if c := Diff(nil, before); len(c.Joined) != 6 || len(c.Left) != 0 {
+		t.Fatalf("diff from nothing = %+v", c)
+	}
+	if c := Diff(before, before); len(c.Joined)+len(c.Left) != 0 || c.Stayed != 6 {
+		t.Fatalf("diff with itself = %+v", c)
+	}
+}
+
+func TestRenderTable(t *testing.T) {
+	inv := inspectFixture(t, "admin_peers_before.json")
+	var buf bytes.Buffer
+	if err := inv.Render(&buf, FormatTable); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"INBOUND RATIO  33.3%",
+		"geth        3      50.0%  v1.13.14 (2), v1.13.12 (1)",
+		"eth       6      68 (5), 67 (1)",
+		"aaaaaaaaaaaaaaaa  geth        v1.13.14  out  1.2.3.4:30303",
+		"eth/68 snap/1",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("table missing %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	churn := Diff(inv, inspectFixture(t, "admin_peers_after.json"))
+	if err := churn.Render(&buf, FormatTable); err != nil {
+		t.Fatalf("Render churn: %v", err)
+	}
+	out = buf.String()
+	for _, want := range []string{"JOINED  2", "LEFT    2", "STAYED  4", "+  1111111111111111  reth", "-  ffffffffffffffff  besu"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("churn table missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderJSON(t *testing.T) {
+	inv := inspectFixture(t, "admin_peers_before.json")
+	var buf bytes.Buffer
+	if err := inv.Render(&buf, FormatJSON); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	var back Inventory
+	if err := json.Unmarshal(buf.Bytes(), &back); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !reflect.DeepEqual(&back, inv) {
+		t.Fatalf("JSON round trip changed the inventory:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), `"inboundRatio"`) || !strings.Contains(buf.String(), `"remoteAddress": "1.2.3.4:30303"`) {
+		t.Fatalf("unexpected JSON field names:\n%s", buf.String())
+	}
+
+	if err := inv.Render(&buf, Format("yaml")); err == nil {
+		t.Fatal("expected error for unknown format")
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if f, err := ParseFormat(""); err != nil || f != FormatTable {
+		t.Fatalf("empty = %q, %v", f, err)
+	}
+	if f, err := ParseFormat("JSON"); err != nil || f != FormatJSON {
+		t.Fatalf("JSON = %q, %v", f, err)
+	}
+	if _, err := ParseFormat("csv"); err == nil {
+		t.Fatal("expected error for csv")
+	}
+}
+
+// adminAPI serves admin_peers from a fixture over an in-process RPC server.
+type adminAPI struct{ peers []*p2p.PeerInfo }
+
+func (a *adminAPI) Peers() []*p2p.PeerInfo { return a.peers }
+
+func TestAdminClient(t *testing.T) {
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("admin", &adminAPI{peers: loadPeers(t, "admin_peers_after.json")}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	client := NewAdminClient(rpc.DialInProc(server))
+	defer client.Close()
+
+	inv, err := Inspect(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Inspect: %v", err)
+	}
+	if inv.Total != 6 || inv.Clients[0].Client != "geth" {
+		t.Fatalf("inventory over RPC = %+v", inv)
+	}
 }
@@ -0,0 +1,122 @@
+package exercise
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Format selects how Inventory and Churn are rendered.
+type Format string
+
+const (
+	FormatTable Format = "table"
+	FormatJSON  Format = "json"
+)
+
+// ParseFormat validates a -format value. The empty string means table.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case "":
+		return FormatTable, nil
+	case FormatTable, FormatJSON:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown output format %q (valid: table, json)", s)
+	}
+}
+
+// Render writes the inventory as aligned tables (summary, clients,
+// protocols, peers) or as indented JSON.
+func (inv *Inventory) Render(w io.Writer, f Format) error {
+	if f == FormatJSON {
+		return writeJSON(w, inv)
+	}
+	if f != FormatTable {
+		return fmt.Errorf("unknown output format %q", f)
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "PEERS\t%d\n", inv.Total)
+	fmt.Fprintf(tw, "INBOUND\t%d\n", inv.Inbound)
+	fmt.Fprintf(tw, "OUTBOUND\t%d\n", inv.Outbound)
+	fmt.Fprintf(tw, "INBOUND RATIO\t%.1f%%\n", inv.InboundRatio*100)
+
+	fmt.Fprintln(tw, "\nCLIENT\tPEERS\tSHARE\tVERSIONS")
+	for _, c := range inv.Clients {
+		fmt.Fprintf(tw, "%s\t%d\t%.1f%%\t%s\n", c.Client, c.Count, c.Share*100, versionList(c.Versions))
+	}
+
+	fmt.Fprintln(tw, "\nPROTOCOL\tPEERS\tVERSIONS")
+	for _, p := range inv.Protocols {
+		fmt.Fprintf(tw, "%s\t%d\t%s\n", p.Protocol, p.Peers, versionList(p.Versions))
+	}
+
+	fmt.Fprintln(tw, "\nID\tCLIENT\tVERSION\tDIR\tREMOTE\tPROTOCOLS")
+	for _, p := range inv.Peers {
+		fmt.Fprintln(tw, peerRow(p))
+	}
+	return tw.Flush()
+}
+
+// Render writes the churn as a summary line plus one row per joined (+) or
+// left (-) peer, or as indented JSON.
+func (c *Churn) Render(w io.Writer, f Format) error {
+	if f == FormatJSON {
+		return writeJSON(w, c)
+	}
+	if f != FormatTable {
+		return fmt.Errorf("unknown output format %q", f)
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "JOINED\t%d\n", len(c.Joined))
+	fmt.Fprintf(tw, "LEFT\t%d\n", len(c.Left))
+	fmt.Fprintf(tw, "STAYED\t%d\n", c.Stayed)
+	if len(c.Joined)+len(c.Left) > 0 {
+		fmt.Fprintln(tw, "\n\tID\tCLIENT\tVERSION\tDIR\tREMOTE\tPROTOCOLS")
+		for _, p := range c.Joined {
+			fmt.Fprintf(tw, "+\t%s\n", peerRow(p))
+		}
+		for _, p := range c.Left {
+			fmt.Fprintf(tw, "-\t%s\n", peerRow(p))
+		}
+	}
+	return tw.Flush()
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func peerRow(p Peer) string {
+	id := p.ID
+	if len(id) > 16 {
+		id = id[:16]
+	}
+	names := make([]string, 0, len(p.Protocols))
+	for name := range p.Protocols {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	protos := make([]string, len(names))
+	for i, name := range names {
+		protos[i] = fmt.Sprintf("%s/%d", name, p.Protocols[name])
+	}
+	version := p.Version
+	if version == "" {
+		version = "-"
+	}
+	return strings.Join([]string{id, p.Client, version, p.Direction(), p.RemoteAddress, strings.Join(protos, " ")}, "\t")
+}
+
+func versionList(vs []VersionCount) string {
+	parts := make([]string, len(vs))
+	for i, v := range vs {
+		parts[i] = fmt.Sprintf("%s (%d)", v.Version, v.Count)
+	}
+	return strings.Join(parts, ", ")
+}
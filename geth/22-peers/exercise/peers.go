@@ -0,0 +1,293 @@
+package exercise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// AdminClient adapts a raw *rpc.Client to PeerClient. ethclient has no
+// wrapper for the admin namespace, so Peers calls admin_peers directly.
+type AdminClient struct {
+	*ethclient.Client
+	rpc *rpc.Client
+}
+
+// NewAdminClient wraps c.
+func NewAdminClient(c *rpc.Client) *AdminClient {
+	return &AdminClient{Client: ethclient.NewClient(c), rpc: c}
+}
+
+// Peers calls admin_peers.
+func (c *AdminClient) Peers(ctx context.Context) ([]*p2p.PeerInfo, error) {
+	var peers []*p2p.PeerInfo
+	if err := c.rpc.CallContext(ctx, &peers, "admin_peers"); err != nil {
+		return nil, err
+	}
+	return peers, nil
+}
+
+// Peer is the part of an admin_peers entry the analytics use.
+type Peer struct {
+	ID            string          `json:"id"`
+	Enode         string          `json:"enode"`
+	Name          string          `json:"name"`      // full client string, e.g. "Geth/v1.13.14-stable-2bd6bd01/linux-amd64/go1.21.7"
+	Client        string          `json:"client"`    // lower-cased implementation, e.g. "geth"
+	Version       string          `json:"version"`   // release without build suffix, e.g. "v1.13.14"
+	Caps          []string        `json:"caps"`      // advertised, e.g. ["eth/67", "eth/68", "snap/1"]
+	Protocols     map[string]uint `json:"protocols"` // negotiated version per running protocol
+	Inbound       bool            `json:"inbound"`
+	RemoteAddress string          `json:"remoteAddress"`
+}
+
+// Direction is "in" or "out".
+func (p Peer) Direction() string {
+	if p.Inbound {
+		return "in"
+	}
+	return "out"
+}
+
+// key identifies a peer across snapshots.
+func (p Peer) key() string {
+	if p.ID != "" {
+		return p.ID
+	}
+	return p.Enode
+}
+
+// ParsePeer extracts a Peer from an admin_peers entry.
+func ParsePeer(info *p2p.PeerInfo) Peer {
+	p := Peer{
+		ID:            info.ID,
+		Enode:         info.Enode,
+		Name:          info.Name,
+		Caps:          append([]string(nil), info.Caps...),
+		Protocols:     make(map[string]uint, len(info.Protocols)),
+		Inbound:       info.Network.Inbound,
+		RemoteAddress: info.Network.RemoteAddress,
+	}
+	p.Client, p.Version = parseName(info.Name)
+	for name, meta := range info.Protocols {
+		if v, ok := protocolVersion(name, meta, info.Caps); ok {
+			p.Protocols[name] = v
+		}
+	}
+	return p
+}
+
+// parseName splits a devp2p client string. The usual shape is
+// Client/Version/OS/Runtime, but operators may insert an identity
+// (Geth/my-node/v1.13.14-stable/...), so the version is the first segment
+// after the client that looks like vN.
+func parseName(name string) (client, version string) {
+	parts := strings.Split(name, "/")
+	client = strings.ToLower(strings.TrimSpace(parts[0]))
+	if client == "" {
+		client = "unknown"
+	}
+	for _, part := range parts[1:] {
+		if len(part) > 1 && part[0] == 'v' && part[1] >= '0' && part[1] <= '9' {
+			if i := strings.IndexAny(part, "-+"); i > 0 {
+				part = part[:i]
+			}
+			return client, part
+		}
+	}
+	return client, ""
+}
+
+// protocolVersion reads the negotiated version from a running protocol's
+// metadata ({"version": 68}). A peer still in the handshake reports a bare
+// string instead; the highest advertised version is the best guess then.
+func protocolVersion(name string, meta interface{}, caps []string) (uint, bool) {
+	if m, ok := meta.(map[string]interface{}); ok {
+		if v, ok := m["version"].(float64); ok && v >= 0 {
+			return uint(v), true
+		}
+	}
+	var best uint
+	found := false
+	for _, c := range caps {
+		capName, ver, ok := strings.Cut(c, "/")
+		if !ok || capName != name {
+			continue
+		}
+		if v, err := strconv.ParseUint(ver, 10, 32); err == nil && (!found || uint(v) > best) {
+			best, found = uint(v), true
+		}
+	}
+	return best, found
+}
+
+// Inventory is an analyzed admin_peers snapshot.
+type Inventory struct {
+	Peers        []Peer           `json:"peers"` // sorted by ID
+	Total        int              `json:"total"`
+	Inbound      int              `json:"inbound"`
+	Outbound     int              `json:"outbound"`
+	InboundRatio float64          `json:"inboundRatio"` // inbound / total, 0 without peers
+	Clients      []ClientShare    `json:"clients"`      // most common first
+	Protocols    []ProtocolSpread `json:"protocols"`    // by protocol name
+}
+
+// ClientShare is how many peers run one client implementation.
+type ClientShare struct {
+	Client   string         `json:"client"`
+	Count    int            `json:"count"`
+	Share    float64        `json:"share"`    // fraction of all peers
+	Versions []VersionCount `json:"versions"` // most common first
+}
+
+// ProtocolSpread is how many peers negotiated each version of a protocol.
+type ProtocolSpread struct {
+	Protocol string         `json:"protocol"`
+	Peers    int            `json:"peers"`
+	Versions []VersionCount `json:"versions"` // most common first
+}
+
+// VersionCount is one bucket of a version breakdown.
+type VersionCount struct {
+	Version string `json:"version"`
+	Count   int    `json:"count"`
+}
+
+// Inspect fetches admin_peers and analyzes it.
+func Inspect(ctx context.Context, client PeerClient) (*Inventory, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if client == nil {
+		return nil, errors.New("client is nil")
+	}
+	infos, err := client.Peers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("admin peers: %w", err)
+	}
+	peers := make([]Peer, 0, len(infos))
+	for _, info := range infos {
+		if info != nil {
+			peers = append(peers, ParsePeer(info))
+		}
+	}
+	return Analyze(peers), nil
+}
+
+// Analyze computes the diversity, direction and protocol breakdowns.
+func Analyze(peers []Peer) *Inventory {
+	inv := &Inventory{
+		Peers:     append(make([]Peer, 0, len(peers)), peers...),
+		Total:     len(peers),
+		Clients:   []ClientShare{},
+		Protocols: []ProtocolSpread{},
+	}
+	sort.Slice(inv.Peers, func(i, j int) bool { return inv.Peers[i].key() < inv.Peers[j].key() })
+
+	clients := make(map[string]map[string]int)
+	protocols := make(map[string]map[string]int)
+	for _, p := range inv.Peers {
+		if p.Inbound {
+			inv.Inbound++
+		} else {
+			inv.Outbound++
+		}
+		if clients[p.Client] == nil {
+			clients[p.Client] = make(map[string]int)
+		}
+		version := p.Version
+		if version == "" {
+			version = "unknown"
+		}
+		clients[p.Client][version]++
+		for name, v := range p.Protocols {
+			if protocols[name] == nil {
+				protocols[name] = make(map[string]int)
+			}
+			protocols[name][strconv.FormatUint(uint64(v), 10)]++
+		}
+	}
+	if inv.Total > 0 {
+		inv.InboundRatio = float64(inv.Inbound) / float64(inv.Total)
+	}
+
+	for name, versions := range clients {
+		share := ClientShare{Client: name, Versions: countVersions(versions)}
+		for _, v := range share.Versions {
+			share.Count += v.Count
+		}
+		share.Share = float64(share.Count) / float64(inv.Total)
+		inv.Clients = append(inv.Clients, share)
+	}
+	sort.Slice(inv.Clients, func(i, j int) bool {
+		a, b := inv.Clients[i], inv.Clients[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Client < b.Client
+	})
+
+	for name, versions := range protocols {
+		spread := ProtocolSpread{Protocol: name, Versions: countVersions(versions)}
+		for _, v := range spread.Versions {
+			spread.Peers += v.Count
+		}
+		inv.Protocols = append(inv.Protocols, spread)
+	}
+	sort.Slice(inv.Protocols, func(i, j int) bool { return inv.Protocols[i].Protocol < inv.Protocols[j].Protocol })
+	return inv
+}
+
+func countVersions(m map[string]int) []VersionCount {
+	out := make([]VersionCount, 0, len(m))
+	for v, n := range m {
+		out = append(out, VersionCount{Version: v, Count: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Version < out[j].Version
+	})
+	return out
+}
+
+// Churn is the peer set change between two snapshots.
+type Churn struct {
+	Joined []Peer `json:"joined"` // in cur but not prev, sorted by ID
+	Left   []Peer `json:"left"`   // in prev but not cur, sorted by ID
+	Stayed int    `json:"stayed"`
+}
+
+// Diff compares two snapshots by node ID. Either may be nil (no peers).
+func Diff(prev, cur *Inventory) *Churn {
+	before := make(map[string]Peer)
+	if prev != nil {
+		for _, p := range prev.Peers {
+			before[p.key()] = p
+		}
+	}
+	c := &Churn{Joined: []Peer{}, Left: []Peer{}}
+	if cur != nil {
+		for _, p := range cur.Peers {
+			if _, ok := before[p.key()]; ok {
+				c.Stayed++
+				delete(before, p.key())
+			} else {
+				c.Joined = append(c.Joined, p)
+			}
+		}
+	}
+	for _, p := range before {
+		c.Left = append(c.Left, p)
+	}
+	sort.Slice(c.Joined, func(i, j int) bool { return c.Joined[i].key() < c.Joined[j].key() })
+	sort.Slice(c.Left, func(i, j int) bool { return c.Left[i].key() < c.Left[j].key() })
+	return c
+}
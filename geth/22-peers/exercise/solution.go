@@ -117,7 +117,31 @@ func Run(ctx context.Context, client PeerClient, cfg Config) (*Result, error) {
 	// count (even if zero means "no peers").
 
 	// ============================================================================
-	// STEP 4: Return Result - Primitive Type Handling
+	// STEP 4: Optional Peer Inventory - admin_peers Analytics
+	// ============================================================================
+	// The count says how many peers we have, not who they are. admin_peers
+	// lists every connection: client name and version, negotiated protocols,
+	// direction and remote address. Inspect turns that into a client diversity
+	// breakdown, inbound/outbound split and protocol version spread.
+	//
+	// This is opt-in because the admin namespace is rarely exposed: geth only
+	// serves it over IPC unless started with --http.api admin. Asking a
+	// public RPC for it just fails.
+	//
+	// Why client diversity matters: if one client implementation is most of
+	// your peers, a consensus bug in that client can pull your node onto a
+	// bad fork with its peers. The same breakdown across the whole network
+	// is what client-diversity dashboards track.
+	var inventory *Inventory
+	if cfg.Inventory {
+		inventory, err = Inspect(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// ============================================================================
+	// STEP 5: Return Result - Primitive Type Handling
 	// ============================================================================
 	// We package the peer count into our Result struct. This is simpler than
 	// modules 01 and 21 because we're dealing with a primitive type (uint64),
@@ -148,5 +172,6 @@ func Run(ctx context.Context, client PeerClient, cfg Config) (*Result, error) {
 	//   - Pattern: All use Result structs for consistent API design
 	return &Result{
 		PeerCount: peerCount, // Primitive type, automatically copied by value
+		Inventory: inventory, // Freshly built by Inspect, not shared
 	}, nil
 }
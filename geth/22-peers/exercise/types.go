@@ -2,21 +2,30 @@ package exercise
 
 import (
 	"context"
+
+	"github.com/ethereum/go-ethereum/p2p"
 )
 
-// PeerClient captures the ethclient calls needed for module 22 (peer counting).
+// PeerClient captures the calls needed for module 22 (peer counting and
+// inventory). *ethclient.Client only has PeerCount; AdminClient adds Peers.
 type PeerClient interface {
 	// PeerCount returns the number of connected peers
 	PeerCount(ctx context.Context) (uint64, error)
+	// Peers returns admin_peers: one entry per connected peer
+	Peers(ctx context.Context) ([]*p2p.PeerInfo, error)
 }
 
 // Config allows configuration for peer count query.
 type Config struct {
-	// No config needed for basic peer count
+	// Inventory also fetches admin_peers and analyzes it. The admin API is
+	// usually only exposed on IPC or localhost.
+	Inventory bool
 }
 
 // Result summarizes the peer connectivity of the node.
 type Result struct {
 	// PeerCount is the number of currently connected peers
 	PeerCount uint64
+	// Inventory holds per-peer details and analytics (nil unless Config.Inventory)
+	Inventory *Inventory
 }
@@ -0,0 +1,104 @@
+// Command txpool analyzes a node's txpool, or watches it and reports how
+// long transactions take to be included.
+//
+// The txpool namespace must be enabled (geth --http.api eth,txpool):
+//
+//	go run ./23-mempool/cmd/txpool -rpc http://127.0.0.1:8545
+//	go run ./23-mempool/cmd/txpool -rpc http://127.0.0.1:8545 -watch -interval 2s
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	mempool "geth-edu/23-mempool/exercise"
+)
+
+func main() {
+	endpoint := flag.String("rpc", "http://127.0.0.1:8545", "node endpoint with the txpool API")
+	summary := flag.Bool("summary", false, "use txpool_inspect instead of txpool_content")
+	top := flag.Int("top", 10, "senders and recipients to list")
+	watch := flag.Bool("watch", false, "poll the pool and print txs as they leave it")
+	interval := flag.Duration("interval", 2*time.Second, "poll interval in watch mode")
+	flag.Parse()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	dialCtx, dialCancel := context.WithTimeout(ctx, 10*time.Second)
+	c, err := rpc.DialContext(dialCtx, *endpoint)
+	dialCancel()
+	if err != nil {
+		log.Fatalf("dial %s: %v", *endpoint, err)
+	}
+	client := mempool.NewRPCClient(c)
+	defer client.Close()
+
+	if *watch {
+		runWatch(ctx, client, *interval)
+		return
+	}
+	a, err := mempool.Inspect(ctx, client, mempool.InspectConfig{Summary: *summary, Top: *top})
+	if err != nil {
+		log.Fatal(err)
+	}
+	printAnalysis(a)
+}
+
+func runWatch(ctx context.Context, client mempool.TxPoolClient, interval time.Duration) {
+	w, err := mempool.NewWatcher(client, mempool.WatchConfig{Interval: interval})
+	if err != nil {
+		log.Fatal(err)
+	}
+	w.Run(ctx, func(ev mempool.TxEvent) {
+		note := ""
+		if ev.SeenAtStart {
+			note = " (already pooled at start)"
+		}
+		fmt.Printf("%s %-8s %s nonce %d after %s%s\n",
+			ev.Gone.Format(time.TimeOnly), ev.Kind, ev.From.Hex(), ev.Nonce, ev.Latency.Round(time.Second), note)
+	})
+	s := w.Stats()
+	fmt.Printf("\nincluded %d, dropped %d, replaced %d; latency median %s, p90 %s, max %s\n",
+		s.Included, s.Dropped, s.Replaced, s.Median, s.P90, s.Max)
+}
+
+func printAnalysis(a *mempool.Analysis) {
+	fmt.Printf("pending %d, queued %d, senders %d, contract creations %d\n", a.Pending, a.Queued, a.Senders, a.Creations)
+	if a.BaseFee != nil {
+		fmt.Printf("base fee %s gwei\n", gwei(a.BaseFee))
+	}
+	fmt.Printf("\nnonce gaps (%d):\n", len(a.NonceGaps))
+	for _, g := range a.NonceGaps {
+		fmt.Printf("  %s waiting for nonce %d, %d missing, %d queued\n", g.Sender.Hex(), g.NextNonce, g.Missing, g.Stuck)
+	}
+	fmt.Printf("\nbelow base fee (%d):\n", len(a.Underpriced))
+	for _, u := range a.Underpriced {
+		state := "pending"
+		if u.Queued {
+			state = "queued"
+		}
+		fmt.Printf("  %s nonce %d %s: cap %s gwei, short %s gwei\n", u.Tx.From.Hex(), u.Tx.Nonce, state, gwei(u.Tx.FeeCap()), gwei(u.Shortfall))
+	}
+	fmt.Println("\ntop senders:")
+	for _, s := range a.TopSenders {
+		fmt.Printf("  %s %d\n", s.Address.Hex(), s.Count)
+	}
+	fmt.Println("\ntop recipients:")
+	for _, r := range a.TopRecipients {
+		fmt.Printf("  %s %d\n", r.Address.Hex(), r.Count)
+	}
+}
+
+func gwei(wei *big.Int) string {
+	f := new(big.Float).SetInt(wei)
+	return f.Quo(f, big.NewFloat(params.GWei)).Text('f', 2)
+}
@@ -0,0 +1,276 @@
+package exercise
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// NonceGap is a sender whose queued txs can't run because nonces are
+// missing. Nothing after the gap is executable until NextNonce..FirstQueued-1
+// arrive.
+type NonceGap struct {
+	Sender      common.Address
+	NextNonce   uint64 // the nonce the pool is waiting for
+	FirstQueued uint64 // lowest queued nonce
+	Missing     uint64 // FirstQueued - NextNonce
+	Stuck       int    // queued txs held back
+}
+
+// UnderpricedTx is a tx whose fee cap is below the current base fee. It
+// can't be included until the base fee drops below Shortfall + base fee.
+type UnderpricedTx struct {
+	Tx        PoolTx
+	Queued    bool
+	Shortfall *big.Int // base fee - fee cap, per gas
+}
+
+// AddressCount is one entry of a top-N ranking.
+type AddressCount struct {
+	Address common.Address
+	Count   int
+}
+
+// Analysis summarizes a txpool snapshot.
+type Analysis struct {
+	Pending       int
+	Queued        int
+	Senders       int
+	BaseFee       *big.Int        // nil before London
+	NonceGaps     []NonceGap      // most stuck txs first
+	Underpriced   []UnderpricedTx // largest shortfall first
+	TopSenders    []AddressCount
+	TopRecipients []AddressCount
+	Creations     int // contract deployments (no recipient)
+}
+
+// AnalyzeConfig tunes Analyze. Zero values use defaults.
+type AnalyzeConfig struct {
+	// BaseFee is the current base fee; nil skips the underpriced check.
+	BaseFee *big.Int
+	// ChainNonces holds the confirmed nonce of senders with only queued
+	// txs. Without it their next nonce is unknown and no gap is reported.
+	ChainNonces map[common.Address]uint64
+	// Top is the length of the sender and recipient rankings (default 10).
+	Top int
+}
+
+// InspectConfig tunes Inspect. Zero values use defaults.
+type InspectConfig struct {
+	// Summary reads txpool_inspect instead of txpool_content. It is much
+	// smaller on a busy pool, but transactions come without hashes.
+	Summary bool
+	Top     int
+	// Concurrency caps the NonceAt calls in flight (default 8).
+	Concurrency int
+}
+
+// Inspect reads the txpool, the latest base fee and the nonces Analyze
+// needs, then analyzes the snapshot.
+func Inspect(ctx context.Context, client TxPoolClient, cfg InspectConfig) (*Analysis, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if client == nil {
+		return nil, errors.New("client is nil")
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 8
+	}
+	var (
+		snap *Snapshot
+		err  error
+	)
+	if cfg.Summary {
+		snap, err = client.TxPoolInspect(ctx)
+	} else {
+		snap, err = client.TxPoolContent(ctx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("txpool: %w", err)
+	}
+	if snap == nil {
+		return nil, errors.New("txpool: empty response")
+	}
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("latest header: %w", err)
+	}
+	// No header or no base fee (pre-London) skips the underpriced check.
+	var baseFee *big.Int
+	if head != nil {
+		baseFee = head.BaseFee
+	}
+	var senders []common.Address
+	for from := range snap.Queued {
+		if len(snap.Pending[from]) == 0 { // else the pending txs tell us the next nonce
+			senders = append(senders, from)
+		}
+	}
+	nonces, err := chainNonces(ctx, client, senders, cfg.Concurrency)
+	if err != nil {
+		return nil, err
+	}
+	return Analyze(snap, AnalyzeConfig{BaseFee: baseFee, ChainNonces: nonces, Top: cfg.Top}), nil
+}
+
+// chainNonces reads the confirmed nonce of every sender with at most limit
+// calls in flight. A busy pool has thousands of queued-only senders, too
+// many to read one by one. The first error cancels the calls still running.
+func chainNonces(ctx context.Context, client TxPoolClient, senders []common.Address, limit int) (map[common.Address]uint64, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	found := make([]uint64, len(senders))
+	sem := make(chan struct{}, limit)
+	for i, from := range senders {
+		sem <- struct{}{}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int, from common.Address) {
+			defer func() { <-sem; wg.Done() }()
+			n, err := client.NonceAt(ctx, from, nil)
+			if err != nil {
+				once.Do(func() {
+					firstErr = fmt.Errorf("nonce of %s: %w", from.Hex(), err)
+					cancel()
+				})
+				return
+			}
+			found[i] = n
+		}(i, from)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	nonces := make(map[common.Address]uint64, len(senders))
+	for i, from := range senders {
+		nonces[from] = found[i]
+	}
+	return nonces, nil
+}
+
+// Analyze flags nonce gaps and underpriced txs and ranks senders and
+// recipients.
+func Analyze(snap *Snapshot, cfg AnalyzeConfig) *Analysis {
+	if cfg.Top <= 0 {
+		cfg.Top = 10
+	}
+	a := &Analysis{NonceGaps: []NonceGap{}, Underpriced: []UnderpricedTx{}}
+	if cfg.BaseFee != nil {
+		a.BaseFee = new(big.Int).Set(cfg.BaseFee)
+	}
+	a.Pending, a.Queued = snap.Len()
+
+	senders := make(map[common.Address]int)
+	recipients := make(map[common.Address]int)
+	visit := func(txs []PoolTx, queued bool) {
+		for _, tx := range txs {
+			senders[tx.From]++
+			if tx.To == nil {
+				a.Creations++
+			} else {
+				recipients[*tx.To]++
+			}
+			if fee := tx.FeeCap(); a.BaseFee != nil && fee != nil && fee.Cmp(a.BaseFee) < 0 {
+				a.Underpriced = append(a.Underpriced, UnderpricedTx{
+					Tx:        tx,
+					Queued:    queued,
+					Shortfall: new(big.Int).Sub(a.BaseFee, fee),
+				})
+			}
+		}
+	}
+	for _, txs := range snap.Pending {
+		visit(txs, false)
+	}
+	for from, txs := range snap.Queued {
+		visit(txs, true)
+		if gap, ok := nonceGap(from, snap.Pending[from], txs, cfg.ChainNonces); ok {
+			a.NonceGaps = append(a.NonceGaps, gap)
+		}
+	}
+	a.Senders = len(senders)
+	a.TopSenders = topN(senders, cfg.Top)
+	a.TopRecipients = topN(recipients, cfg.Top)
+
+	sort.Slice(a.NonceGaps, func(i, j int) bool {
+		x, y := a.NonceGaps[i], a.NonceGaps[j]
+		if x.Stuck != y.Stuck {
+			return x.Stuck > y.Stuck
+		}
+		return bytes.Compare(x.Sender[:], y.Sender[:]) < 0
+	})
+	sort.Slice(a.Underpriced, func(i, j int) bool {
+		x, y := a.Underpriced[i], a.Underpriced[j]
+		if c := x.Shortfall.Cmp(y.Shortfall); c != 0 {
+			return c > 0
+		}
+		if c := bytes.Compare(x.Tx.From[:], y.Tx.From[:]); c != 0 {
+			return c < 0
+		}
+		return x.Tx.Nonce < y.Tx.Nonce
+	})
+	return a
+}
+
+// nonceGap checks whether a sender's queued txs are held back by missing
+// nonces. The pool's next nonce follows the last pending tx; with nothing
+// pending it is the confirmed account nonce.
+func nonceGap(from common.Address, pending, queued []PoolTx, chainNonces map[common.Address]uint64) (NonceGap, bool) {
+	if len(queued) == 0 {
+		return NonceGap{}, false
+	}
+	var next uint64
+	if len(pending) > 0 {
+		next = pending[len(pending)-1].Nonce + 1
+	} else if n, ok := chainNonces[from]; ok {
+		next = n
+	} else {
+		return NonceGap{}, false
+	}
+	first := queued[0].Nonce
+	if first <= next {
+		return NonceGap{}, false // queued for another reason, e.g. balance
+	}
+	return NonceGap{
+		Sender:      from,
+		NextNonce:   next,
+		FirstQueued: first,
+		Missing:     first - next,
+		Stuck:       len(queued),
+	}, true
+}
+
+func topN(counts map[common.Address]int, n int) []AddressCount {
+	out := make([]AddressCount, 0, len(counts))
+	for addr, c := range counts {
+		out = append(out, AddressCount{Address: addr, Count: c})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return bytes.Compare(out[i].Address[:], out[j].Address[:]) < 0
+	})
+	if len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
@@ -1,7 +1,505 @@
 package exercise
 
-import "testing"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var _ TxPoolClient = (*RPCClient)(nil)
+
+var (
+	gwei    = big.NewInt(1_000_000_000)
+	start   = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	senderA = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	senderB = common.HexToAddress("0x2222222222222222222222222222222222222222")
+	senderC = common.HexToAddress("0x3333333333333333333333333333333333333333")
+	senderD = common.HexToAddress("0x4444444444444444444444444444444444444444")
+	router  = common.HexToAddress("0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D")
+	token   = common.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7")
+)
+
+func gweis(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), gwei) }
+
+type mockPool struct {
+	mu         sync.Mutex
+	count      uint
+	countErr   error
+	content    *Snapshot
+	contentErr error
+	inspect    *Snapshot
+	baseFee    *big.Int
+	nonces     map[common.Address]uint64
+	nonceCalls []common.Address
+	noHeader   bool          // HeaderByNumber answers nil, nil
+	nonceDelay time.Duration // how long each NonceAt takes
+	inflight   int           // NonceAt calls running now
+	maxFlight  int           // most NonceAt calls seen running at once
+}
+
+func (m *mockPool) PendingTransactionCount(ctx context.Context) (uint, error) {
+	return m.count, m.countErr
+}
+
+func (m *mockPool) TxPoolContent(ctx context.Context) (*Snapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.content, m.contentErr
+}
+
+func (m *mockPool) TxPoolInspect(ctx context.Context) (*Snapshot, error) {
+	return m.inspect, nil
+}
+
+func (m *mockPool) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if m.noHeader {
+		return nil, nil
+	}
+	return &types.Header{Number: big.NewInt(100), BaseFee: m.baseFee}, nil
+}
+
+func (m *mockPool) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	m.mu.Lock()
+	m.inflight++
+	m.maxFlight = max(m.maxFlight, m.inflight)
+	m.mu.Unlock()
+	time.Sleep(m.nonceDelay)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inflight--
+	m.nonceCalls = append(m.nonceCalls, account)
+	n, ok := m.nonces[account]
+	if !ok {
+		return 0, errors.New("unknown account")
+	}
+	return n, nil
+}
+
+func (m *mockPool) set(content *Snapshot, nonces map[common.Address]uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.content, m.nonces = content, nonces
+}
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	return raw
+}
+
+func fixturePool(t *testing.T) *mockPool {
+	t.Helper()
+	content, err := ParseContent(readFixture(t, "txpool_content.json"))
+	if err != nil {
+		t.Fatalf("ParseContent: %v", err)
+	}
+	inspect, err := ParseInspect(readFixture(t, "txpool_inspect.json"))
+	if err != nil {
+		t.Fatalf("ParseInspect: %v", err)
+	}
+	return &mockPool{
+		content: content,
+		inspect: inspect,
+		baseFee: gweis(10),
+		nonces:  map[common.Address]uint64{senderC: 10},
+	}
+}
 
 func TestRun(t *testing.T) {
-	t.Skip("add tests for this module")
+	res, err := Run(context.Background(), &mockPool{count: 42}, Config{})
+	if err != nil || res.PendingCount != 42 {
+		t.Fatalf("res=%+v err=%v", res, err)
+	}
+	boom := errors.New("boom")
+	if _, err := Run(context.Background(), &mockPool{countErr: boom}, Config{}); !errors.Is(err, boom) {
+		t.Fatalf("expected wrapped boom, got %v", err)
+	}
+	if _, err := Run(context.Background(), nil, Config{}); err == nil {
+		t.Fatal("expected error for nil client")
+	}
+}
+
+func TestParseContentFixture(t *testing.T) {
+	snap := fixturePool(t).content
+	if p, q := snap.Len(); p != 5 || q != 5 {
+		t.Fatalf("Len = %d pending, %d queued", p, q)
+	}
+	a := snap.Pending[senderA]
+	if len(a) != 3 || a[0].Nonce != 5 || a[2].Nonce != 7 {
+		t.Fatalf("sender A pending not sorted by nonce: %+v", a)
+	}
+	if a[0].Hash == (common.Hash{}) || a[0].From != senderA || *a[0].To != router || a[0].Type != 2 {
+		t.Fatalf("decoded tx = %+v", a[0])
+	}
+	if a[0].GasTipCap.Cmp(gweis(2)) != 0 || a[0].FeeCap().Cmp(gweis(30)) != 0 {
+		t.Fatalf("fees: tip=%v cap=%v", a[0].GasTipCap, a[0].FeeCap())
+	}
+	legacy := snap.Pending[senderB][0]
+	if legacy.GasFeeCap != nil || legacy.FeeCap().Cmp(gweis(8)) != 0 {
+		t.Fatalf("legacy fee cap should be the gas price: %+v", legacy)
+	}
+	if snap.Pending[senderD][0].To != nil {
+		t.Fatal("contract creation should have no recipient")
+	}
+	if _, err := ParseContent([]byte(`{"pending": 1}`)); err == nil {
+		t.Fatal("expected decode error")
+	}
+}
+
+func TestParseInspectMatchesContent(t *testing.T) {
+	pool := fixturePool(t)
+	for _, pair := range [][2]map[common.Address][]PoolTx{
+		{pool.content.Pending, pool.inspect.Pending},
+		{pool.content.Queued, pool.inspect.Queued},
+	} {
+		full, summary := pair[0], pair[1]
+		if len(full) != len(summary) {
+			t.Fatalf("sender count %d vs %d", len(full), len(summary))
+		}
+		for from, txs := range full {
+			for i, tx := range txs {
+				got := summary[from][i]
+				if got.Nonce != tx.Nonce || got.Gas != tx.Gas || got.Value.Cmp(tx.Value) != 0 ||
+					got.FeeCap().Cmp(tx.FeeCap()) != 0 || !reflect.DeepEqual(got.To, tx.To) {
+					t.Fatalf("%s/%d: inspect %+v vs content %+v", from.Hex(), tx.Nonce, got, tx)
+				}
+			}
+		}
+	}
+}
+
+func TestParseInspectErrors(t *testing.T) {
+	for _, body := range []string{
+		`{"pending":{"0x1111111111111111111111111111111111111111":{"x":"contract creation: 0 wei + 1 gas × 1 wei"}}}`,
+		`{"pending":{"0x1111111111111111111111111111111111111111":{"1":"nonsense"}}}`,
+		`{"queued":{"0x1111111111111111111111111111111111111111":{"1":"0xzz: 0 wei + 1 gas × 1 wei"}}}`,
+		`{"queued":{"0x1111111111111111111111111111111111111111":{"1":"contract creation: lots wei + 1 gas × 1 wei"}}}`,
+	} {
+		if _, err := ParseInspect([]byte(body)); err == nil {
+			t.Errorf("expected error for %s", body)
+		}
+	}
+}
+
+func TestInspectAnalysis(t *testing.T) {
+	for _, summary := range []bool{false, true} {
+		pool := fixturePool(t)
+		a, err := Inspect(context.Background(), pool, InspectConfig{Summary: summary, Top: 3})
+		if err != nil {
+			t.Fatalf("Inspect: %v", err)
+		}
+		if a.Pending != 5 || a.Queued != 5 || a.Senders != 4 || a.Creations != 1 || a.BaseFee.Cmp(gweis(10)) != 0 {
+			t.Fatalf("summary=%v totals: %+v", summary, a)
+		}
+		// Only C has queued txs and nothing pending to infer its nonce from.
+		if !reflect.DeepEqual(pool.nonceCalls, []common.Address{senderC}) {
+			t.Fatalf("NonceAt called for %v", pool.nonceCalls)
+		}
+
+		wantGaps := []NonceGap{
+			{Sender: senderA, NextNonce: 8, FirstQueued: 9, Missing: 1, Stuck: 2},
+			{Sender: senderC, NextNonce: 10, FirstQueued: 12, Missing: 2, Stuck: 2},
+		}
+		if !reflect.DeepEqual(a.NonceGaps, wantGaps) {
+			t.Fatalf("gaps = %+v", a.NonceGaps)
+		}
+
+		// D's queued nonce 4 follows its pending nonce 3: no gap, but its
+		// 5 gwei cap is below the 10 gwei base fee.
+		if len(a.Underpriced) != 2 {
+			t.Fatalf("underpriced = %+v", a.Underpriced)
+		}
+		first, second := a.Underpriced[0], a.Underpriced[1]
+		if first.Tx.From != senderD || !first.Queued || first.Shortfall.Cmp(gweis(5)) != 0 {
+			t.Fatalf("first underpriced = %+v", first)
+		}
+		if second.Tx.From != senderB || second.Queued || second.Shortfall.Cmp(gweis(2)) != 0 {
+			t.Fatalf("second underpriced = %+v", second)
+		}
+
+		wantSenders := []AddressCount{{senderA, 5}, {senderC, 2}, {senderD, 2}}
+		if !reflect.DeepEqual(a.TopSenders, wantSenders) {
+			t.Fatalf("top senders = %+v", a.TopSenders)
+		}
+		wantRecipients := []AddressCount{{router, 5}, {token, 4}}
+		if !reflect.DeepEqual(a.TopRecipients, wantRecipients) {
+			t.Fatalf("top recipients = %+v", a.TopRecipients)
+		}
+	}
+}
+
+func TestInspectWithoutHeader(t *testing.T) {
+	pool := fixturePool(t)
+	pool.noHeader = true
+	a, err := Inspect(context.Background(), pool, InspectConfig{})
+	if err != nil {
+		t.Fatalf("Inspect: %v", err)
+	}
+	if a.BaseFee != nil || len(a.Underpriced) != 0 || len(a.NonceGaps) != 2 {
+		t.Fatalf("no header should only skip the base fee check: %+v", a)
+	}
+}
+
+func TestInspectNonceConcurrency(t *testing.T) {
+	snap := &Snapshot{Pending: map[common.Address][]PoolTx{}, Queued: map[common.Address][]PoolTx{}}
+	nonces := make(map[common.Address]uint64)
+	for i := 0; i < 20; i++ {
+		from := common.BigToAddress(big.NewInt(int64(i + 1)))
+		snap.Queued[from] = []PoolTx{{From: from, Nonce: 5}}
+		nonces[from] = 3
+	}
+	pool := &mockPool{content: snap, nonces: nonces, nonceDelay: 5 * time.Millisecond}
+	a, err := Inspect(context.Background(), pool, InspectConfig{Concurrency: 4})
+	if err != nil {
+		t.Fatalf("Inspect: %v", err)
+	}
+	if len(pool.nonceCalls) != 20 || len(a.NonceGaps) != 20 {
+		t.Fatalf("calls=%d gaps=%d", len(pool.nonceCalls), len(a.NonceGaps))
+	}
+	if pool.maxFlight < 2 || pool.maxFlight > 4 {
+		t.Fatalf("NonceAt ran %d at once, want 2..4", pool.maxFlight)
+	}
+}
+
+func TestAnalyzeWithoutBaseFeeOrNonces(t *testing.T) {
+	a := Analyze(fixturePool(t).content, AnalyzeConfig{})
+	if a.BaseFee != nil || len(a.Underpriced) != 0 {
+		t.Fatalf("pre-London pool has no underpriced check: %+v", a.Underpriced)
+	}
+	// C's next nonce is unknown without ChainNonces; only A's gap is certain.
+	if len(a.NonceGaps) != 1 || a.NonceGaps[0].Sender != senderA {
+		t.Fatalf("gaps = %+v", a.NonceGaps)
+	}
+}
+
+func TestInspectErrors(t *testing.T) {
+	boom := errors.New("the method txpool_content does not exist/is not available")
+	if _, err := Inspect(context.Background(), &mockPool{contentErr: boom}, InspectConfig{}); !errors.Is(err, boom) {
+		t.Fatalf("expected wrapped txpool error, got %v", err)
+	}
+	pool := fixturePool(t)
+	pool.nonces = nil
+	if _, err := Inspect(context.Background(), pool, InspectConfig{}); err == nil {
+		t.Fatal("expected nonce lookup error")
+	}
+	if _, err := Inspect(context.Background(), nil, InspectConfig{}); err == nil {
+		t.Fatal("expected error for nil client")
+	}
+}
+
+// fakeClock advances only when told to, or when After is called so Run
+// loops complete instantly.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.advance(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.Now()
+	return ch
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+func ptx(from common.Address, nonce uint64, hash byte) PoolTx {
+	return PoolTx{From: from, Nonce: nonce, Hash: common.Hash{hash}, To: &router, GasPrice: gweis(20), Value: new(big.Int)}
+}
+
+func pendingOf(txs ...PoolTx) *Snapshot {
+	s := &Snapshot{Pending: map[common.Address][]PoolTx{}, Queued: map[common.Address][]PoolTx{}}
+	for _, tx := range txs {
+		s.Pending[tx.From] = append(s.Pending[tx.From], tx)
+	}
+	return s
+}
+
+func TestWatcherInclusionLatency(t *testing.T) {
+	clock := &fakeClock{now: start}
+	pool := &mockPool{}
+	w, err := NewWatcher(pool, WatchConfig{Clock: clock})
+	if err != nil {
+		t.Fatalf("NewWatcher: %v", err)
+	}
+	tick := func(content *Snapshot, nonces map[common.Address]uint64) []TxEvent {
+		t.Helper()
+		pool.set(content, nonces)
+		events, err := w.Tick(context.Background())
+		if err != nil {
+			t.Fatalf("Tick: %v", err)
+		}
+		clock.advance(12 * time.Second)
+		return events
+	}
+	kinds := func(events []TxEvent) []string {
+		var out []string
+		for _, ev := range events {
+			out = append(out, ev.Kind)
+		}
+		return out
+	}
+
+	// t=0: A5, A6 and B0 are already pooled.
+	if ev := tick(pendingOf(ptx(senderA, 5, 1), ptx(senderA, 6, 2), ptx(senderB, 0, 3)), nil); len(ev) != 0 {
+		t.Fatalf("first snapshot has nothing to diff: %+v", ev)
+	}
+
+	// t=12: A5 mined, B0 sped up with a new hash, C1 arrives.
+	ev := tick(pendingOf(ptx(senderA, 6, 2), ptx(senderB, 0, 4), ptx(senderC, 1, 5)), map[common.Address]uint64{senderA: 6})
+	if got := kinds(ev); !reflect.DeepEqual(got, []string{EventIncluded, EventReplaced}) {
+		t.Fatalf("t=12 events = %v", got)
+	}
+	if !ev[0].SeenAtStart || ev[0].Latency != 12*time.Second || ev[0].Hash != (common.Hash{1}) {
+		t.Fatalf("A5 event = %+v", ev[0])
+	}
+	if ev[1].Hash != (common.Hash{3}) || ev[1].From != senderB {
+		t.Fatalf("replacement should report the old hash: %+v", ev[1])
+	}
+
+	// t=24: A6 vanishes unmined (evicted), C1 is mined.
+	ev = tick(pendingOf(ptx(senderB, 0, 4)), map[common.Address]uint64{senderA: 6, senderC: 2})
+	if got := kinds(ev); !reflect.DeepEqual(got, []string{EventDropped, EventIncluded}) {
+		t.Fatalf("t=24 events = %v", got)
+	}
+	if ev[1].SeenAtStart || ev[1].Latency != 12*time.Second {
+		t.Fatalf("C1 event = %+v", ev[1])
+	}
+
+	// t=36: the B0 replacement is mined 24s after it appeared.
+	ev = tick(pendingOf(), map[common.Address]uint64{senderB: 1})
+	if len(ev) != 1 || ev[0].Kind != EventIncluded || ev[0].Latency != 24*time.Second || ev[0].Hash != (common.Hash{4}) {
+		t.Fatalf("t=36 events = %+v", ev)
+	}
+
+	want := WatchStats{Included: 3, Dropped: 1, Replaced: 1, Median: 12 * time.Second, P90: 12 * time.Second, Max: 24 * time.Second}
+	if got := w.Stats(); got != want {
+		t.Fatalf("stats = %+v, want %+v", got, want)
+	}
+}
+
+func TestWatcherRetriesUnknownNonce(t *testing.T) {
+	clock := &fakeClock{now: start}
+	pool := &mockPool{}
+	w, _ := NewWatcher(pool, WatchConfig{Clock: clock})
+	pool.set(pendingOf(ptx(senderA, 0, 1)), nil)
+	w.Tick(context.Background())
+
+	// The tx is gone but the nonce lookup fails: keep tracking it.
+	pool.set(pendingOf(), nil)
+	if ev, _ := w.Tick(context.Background()); len(ev) != 0 || w.Stats().Tracked != 1 {
+		t.Fatalf("events=%+v stats=%+v", ev, w.Stats())
+	}
+	pool.set(pendingOf(), map[common.Address]uint64{senderA: 1})
+	if ev, _ := w.Tick(context.Background()); len(ev) != 1 || ev[0].Kind != EventIncluded {
+		t.Fatalf("events=%+v", ev)
+	}
+}
+
+func TestWatcherEmptyResponse(t *testing.T) {
+	clock := &fakeClock{now: start}
+	pool := &mockPool{}
+	w, _ := NewWatcher(pool, WatchConfig{Clock: clock})
+	pool.set(pendingOf(ptx(senderA, 0, 1)), nil)
+	w.Tick(context.Background())
+
+	// A nil snapshot is an error, not an empty pool: nothing was included.
+	pool.set(nil, map[common.Address]uint64{senderA: 1})
+	if ev, err := w.Tick(context.Background()); err == nil || len(ev) != 0 || w.Stats().Tracked != 1 {
+		t.Fatalf("events=%+v err=%v stats=%+v", ev, err, w.Stats())
+	}
+}
+
+func TestWatcherRun(t *testing.T) {
+	clock := &fakeClock{now: start}
+	pool := &mockPool{content: pendingOf(ptx(senderA, 0, 1)), nonces: map[common.Address]uint64{senderA: 1}}
+	w, _ := NewWatcher(pool, WatchConfig{Interval: 12 * time.Second, Clock: clock})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	got := make(chan TxEvent, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- w.Run(ctx, func(ev TxEvent) {
+			select {
+			case got <- ev:
+			default:
+			}
+		})
+	}()
+	// Let Run take the first snapshot, then empty the pool.
+	for w.Stats().Tracked == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	pool.set(pendingOf(), pool.nonces)
+
+	select {
+	case ev := <-got:
+		if ev.Kind != EventIncluded || ev.From != senderA {
+			t.Fatalf("event = %+v", ev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run never reported the inclusion")
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run returned %v", err)
+	}
+}
+
+// txpoolAPI serves the fixtures over an in-process RPC server.
+type txpoolAPI struct{ content, inspect json.RawMessage }
+
+func (a *txpoolAPI) Content() json.RawMessage { return a.content }
+func (a *txpoolAPI) Inspect() json.RawMessage { return a.inspect }
+
+func TestRPCClient(t *testing.T) {
+	server := rpc.NewServer()
+	defer server.Stop()
+	api := &txpoolAPI{content: readFixture(t, "txpool_content.json"), inspect: readFixture(t, "txpool_inspect.json")}
+	if err := server.RegisterName("txpool", api); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	client := NewRPCClient(rpc.DialInProc(server))
+	defer client.Close()
+
+	content, err := client.TxPoolContent(context.Background())
+	if err != nil {
+		t.Fatalf("TxPoolContent: %v", err)
+	}
+	inspect, err := client.TxPoolInspect(context.Background())
+	if err != nil {
+		t.Fatalf("TxPoolInspect: %v", err)
+	}
+	if p, q := content.Len(); p != 5 || q != 5 {
+		t.Fatalf("content Len = %d, %d", p, q)
+	}
+	if p, q := inspect.Len(); p != 5 || q != 5 {
+		t.Fatalf("inspect Len = %d, %d", p, q)
+	}
 }
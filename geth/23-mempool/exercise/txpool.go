@@ -0,0 +1,210 @@
+package exercise
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// PoolTx is one transaction waiting in the txpool.
+type PoolTx struct {
+	Hash      common.Hash // zero when decoded from txpool_inspect
+	From      common.Address
+	To        *common.Address // nil for contract creation
+	Nonce     uint64
+	Gas       uint64
+	Type      uint64
+	GasPrice  *big.Int // legacy gas price, or maxFeePerGas for dynamic-fee txs
+	GasFeeCap *big.Int // nil for legacy txs
+	GasTipCap *big.Int // nil for legacy txs
+	Value     *big.Int
+}
+
+// FeeCap is the most the transaction pays per gas: maxFeePerGas, or the
+// gas price for legacy transactions.
+func (tx PoolTx) FeeCap() *big.Int {
+	if tx.GasFeeCap != nil {
+		return tx.GasFeeCap
+	}
+	return tx.GasPrice
+}
+
+// Snapshot is the txpool split the way geth keeps it. Pending txs are
+// executable in nonce order; queued txs wait for something (usually a
+// missing nonce). Each sender's txs are sorted by nonce.
+type Snapshot struct {
+	Pending map[common.Address][]PoolTx
+	Queued  map[common.Address][]PoolTx
+}
+
+// Len returns the number of pending and queued transactions.
+func (s *Snapshot) Len() (pending, queued int) {
+	for _, txs := range s.Pending {
+		pending += len(txs)
+	}
+	for _, txs := range s.Queued {
+		queued += len(txs)
+	}
+	return pending, queued
+}
+
+// RPCClient adapts a raw *rpc.Client to TxPoolClient. ethclient has no
+// wrappers for the txpool namespace, so those calls go through rpc directly.
+type RPCClient struct {
+	*ethclient.Client
+	rpc *rpc.Client
+}
+
+// NewRPCClient wraps c.
+func NewRPCClient(c *rpc.Client) *RPCClient {
+	return &RPCClient{Client: ethclient.NewClient(c), rpc: c}
+}
+
+type rpcPoolTx struct {
+	Hash      common.Hash     `json:"hash"`
+	To        *common.Address `json:"to"`
+	Nonce     hexutil.Uint64  `json:"nonce"`
+	Gas       hexutil.Uint64  `json:"gas"`
+	Type      hexutil.Uint64  `json:"type"`
+	GasPrice  *hexutil.Big    `json:"gasPrice"`
+	GasFeeCap *hexutil.Big    `json:"maxFeePerGas"`
+	GasTipCap *hexutil.Big    `json:"maxPriorityFeePerGas"`
+	Value     *hexutil.Big    `json:"value"`
+}
+
+// TxPoolContent calls txpool_content: every transaction in full.
+func (c *RPCClient) TxPoolContent(ctx context.Context) (*Snapshot, error) {
+	var raw json.RawMessage
+	if err := c.rpc.CallContext(ctx, &raw, "txpool_content"); err != nil {
+		return nil, err
+	}
+	return ParseContent(raw)
+}
+
+// TxPoolInspect calls txpool_inspect: a one-line summary per transaction,
+// much smaller than txpool_content but without hashes or tip caps.
+func (c *RPCClient) TxPoolInspect(ctx context.Context) (*Snapshot, error) {
+	var raw json.RawMessage
+	if err := c.rpc.CallContext(ctx, &raw, "txpool_inspect"); err != nil {
+		return nil, err
+	}
+	return ParseInspect(raw)
+}
+
+// ParseContent decodes a txpool_content result.
+func ParseContent(data []byte) (*Snapshot, error) {
+	var raw struct {
+		Pending map[common.Address]map[string]*rpcPoolTx `json:"pending"`
+		Queued  map[common.Address]map[string]*rpcPoolTx `json:"queued"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("decode txpool content: %w", err)
+	}
+	convert := func(in map[common.Address]map[string]*rpcPoolTx) map[common.Address][]PoolTx {
+		out := make(map[common.Address][]PoolTx, len(in))
+		for from, byNonce := range in {
+			for _, r := range byNonce {
+				if r == nil {
+					continue
+				}
+				tx := PoolTx{
+					Hash:      r.Hash,
+					From:      from,
+					To:        r.To,
+					Nonce:     uint64(r.Nonce),
+					Gas:       uint64(r.Gas),
+					Type:      uint64(r.Type),
+					GasPrice:  (*big.Int)(r.GasPrice),
+					GasFeeCap: (*big.Int)(r.GasFeeCap),
+					GasTipCap: (*big.Int)(r.GasTipCap),
+					Value:     (*big.Int)(r.Value),
+				}
+				out[from] = append(out[from], tx)
+			}
+			sortByNonce(out[from])
+		}
+		return out
+	}
+	return &Snapshot{Pending: convert(raw.Pending), Queued: convert(raw.Queued)}, nil
+}
+
+// ParseInspect decodes a txpool_inspect result. Geth formats each entry as
+//
+//	0xTo: <value> wei + <gas> gas × <price> wei
+//	contract creation: <value> wei + <gas> gas × <price> wei
+func ParseInspect(data []byte) (*Snapshot, error) {
+	var raw struct {
+		Pending map[common.Address]map[string]string `json:"pending"`
+		Queued  map[common.Address]map[string]string `json:"queued"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("decode txpool inspect: %w", err)
+	}
+	convert := func(in map[common.Address]map[string]string) (map[common.Address][]PoolTx, error) {
+		out := make(map[common.Address][]PoolTx, len(in))
+		for from, byNonce := range in {
+			for nonceStr, summary := range byNonce {
+				nonce, err := strconv.ParseUint(nonceStr, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("inspect %s: bad nonce %q", from.Hex(), nonceStr)
+				}
+				tx, err := parseInspect(summary)
+				if err != nil {
+					return nil, fmt.Errorf("inspect %s nonce %d: %w", from.Hex(), nonce, err)
+				}
+				tx.From, tx.Nonce = from, nonce
+				out[from] = append(out[from], tx)
+			}
+			sortByNonce(out[from])
+		}
+		return out, nil
+	}
+	p, err := convert(raw.Pending)
+	if err != nil {
+		return nil, err
+	}
+	q, err := convert(raw.Queued)
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{Pending: p, Queued: q}, nil
+}
+
+func parseInspect(s string) (PoolTx, error) {
+	var tx PoolTx
+	target, rest, ok := strings.Cut(s, ": ")
+	if !ok {
+		return tx, fmt.Errorf("unexpected summary %q", s)
+	}
+	if target != "contract creation" {
+		if !common.IsHexAddress(target) {
+			return tx, fmt.Errorf("bad recipient %q", target)
+		}
+		to := common.HexToAddress(target)
+		tx.To = &to
+	}
+	var value, price string
+	if _, err := fmt.Sscanf(rest, "%s wei + %d gas × %s wei", &value, &tx.Gas, &price); err != nil {
+		return tx, fmt.Errorf("unexpected summary %q: %v", s, err)
+	}
+	var okV, okP bool
+	tx.Value, okV = new(big.Int).SetString(value, 10)
+	tx.GasPrice, okP = new(big.Int).SetString(price, 10)
+	if !okV || !okP {
+		return tx, fmt.Errorf("unexpected summary %q", s)
+	}
+	return tx, nil
+}
+
+func sortByNonce(txs []PoolTx) {
+	sort.Slice(txs, func(i, j int) bool { return txs[i].Nonce < txs[j].Nonce })
+}
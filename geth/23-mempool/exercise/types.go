@@ -2,7 +2,9 @@ package exercise
 
 import (
 	"context"
+	"math/big"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 )
 
@@ -12,6 +14,16 @@ type MempoolClient interface {
 	PendingTransactionCount(ctx context.Context) (uint, error)
 }
 
+// TxPoolClient adds the txpool namespace (see RPCClient) and the chain reads
+// the analyses need: the base fee from the latest header and account nonces.
+type TxPoolClient interface {
+	MempoolClient
+	TxPoolContent(ctx context.Context) (*Snapshot, error)
+	TxPoolInspect(ctx context.Context) (*Snapshot, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+}
+
 // Config allows configuration for mempool inspection.
 type Config struct {
 	// Limit specifies the maximum number of transactions to return (0 = no limit)
@@ -22,6 +34,6 @@ type Config struct {
 type Result struct {
 	// PendingCount is the total number of pending transactions
 	PendingCount uint
-	// Note: Full transaction details would require additional RPC methods
-	// like eth_pendingTransactions or txpool_content (often restricted)
+	// Note: Full transaction details need txpool_content (often restricted);
+	// see Inspect and Watcher
 }
@@ -0,0 +1,259 @@
+package exercise
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Watch event kinds.
+const (
+	EventIncluded = "included" // left the pool and the sender's nonce moved past it
+	EventDropped  = "dropped"  // left the pool without being mined (evicted or expired)
+	EventReplaced = "replaced" // another tx took its sender and nonce (speed-up or cancel)
+)
+
+// Clock abstracts time so tests can replay many polls instantly.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// WatchConfig tunes the watcher. Zero values use defaults.
+type WatchConfig struct {
+	Interval time.Duration // poll period for Run (default 2s)
+	Clock    Clock
+}
+
+// TxEvent is a tx leaving the pool, found by diffing two snapshots.
+type TxEvent struct {
+	Kind      string
+	Hash      common.Hash
+	From      common.Address
+	Nonce     uint64
+	FirstSeen time.Time
+	Gone      time.Time     // snapshot in which it was missing
+	Latency   time.Duration // Gone - FirstSeen
+	// SeenAtStart marks txs already pooled when watching began, whose
+	// latency is only a lower bound.
+	SeenAtStart bool
+}
+
+// WatchStats summarizes inclusion latency over the events so far. Only
+// txs first seen after the watcher started count toward the latencies.
+type WatchStats struct {
+	Tracked  int // txs currently in the pool
+	Included int
+	Dropped  int
+	Replaced int
+	Median   time.Duration
+	P90      time.Duration
+	Max      time.Duration
+}
+
+type txKey struct {
+	from  common.Address
+	nonce uint64
+}
+
+type trackedTx struct {
+	hash        common.Hash
+	firstSeen   time.Time
+	seenAtStart bool
+}
+
+// maxLatencies bounds the samples kept for WatchStats.
+const maxLatencies = 4096
+
+// Watcher polls txpool_content and diffs successive snapshots. A tx is
+// identified by sender and nonce, since that is what a replacement keeps.
+// When it disappears, the sender's confirmed nonce says whether it was
+// mined or dropped. Latencies are only as precise as the poll interval.
+type Watcher struct {
+	client TxPoolClient
+	cfg    WatchConfig
+
+	mu        sync.Mutex
+	started   bool
+	txs       map[txKey]*trackedTx
+	latencies []time.Duration
+	stats     WatchStats
+}
+
+// NewWatcher returns a watcher with no snapshot yet.
+func NewWatcher(client TxPoolClient, cfg WatchConfig) (*Watcher, error) {
+	if client == nil {
+		return nil, errors.New("client is nil")
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = 2 * time.Second
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = realClock{}
+	}
+	return &Watcher{client: client, cfg: cfg, txs: make(map[txKey]*trackedTx)}, nil
+}
+
+// Run polls every Interval until ctx is cancelled, passing each event to
+// fn (which may be nil). Poll errors are skipped; the next poll diffs
+// against the last good snapshot.
+func (w *Watcher) Run(ctx context.Context, fn func(TxEvent)) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for {
+		// Don't call txpool_content again once ctx is cancelled, even
+		// if the poll timer is ready too.
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		events, err := w.Tick(ctx)
+		if err == nil && fn != nil {
+			for _, ev := range events {
+				fn(ev)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-w.cfg.Clock.After(w.cfg.Interval):
+		}
+	}
+}
+
+// Tick takes one snapshot and returns the txs that left the pool since the
+// previous one, sorted by sender and nonce.
+func (w *Watcher) Tick(ctx context.Context) ([]TxEvent, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	now := w.cfg.Clock.Now()
+	snap, err := w.client.TxPoolContent(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("txpool content: %w", err)
+	}
+	if snap == nil {
+		return nil, errors.New("txpool content: empty response")
+	}
+
+	current := make(map[txKey]PoolTx)
+	for _, group := range []map[common.Address][]PoolTx{snap.Pending, snap.Queued} {
+		for _, txs := range group {
+			for _, tx := range txs {
+				current[txKey{tx.From, tx.Nonce}] = tx
+			}
+		}
+	}
+
+	// Look up the confirmed nonce of every sender with a vanished tx
+	// before taking the lock, so Stats isn't blocked on RPCs.
+	w.mu.Lock()
+	senders := make(map[common.Address]bool)
+	for key := range w.txs {
+		if _, ok := current[key]; !ok {
+			senders[key.from] = true
+		}
+	}
+	w.mu.Unlock()
+	nonces := make(map[common.Address]uint64, len(senders))
+	for from := range senders {
+		// Without the nonce we can't classify; retry on the next poll.
+		if n, err := w.client.NonceAt(ctx, from, nil); err == nil {
+			nonces[from] = n
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var events []TxEvent
+	for key, tx := range current {
+		tracked, ok := w.txs[key]
+		switch {
+		case !ok:
+			w.txs[key] = &trackedTx{hash: tx.Hash, firstSeen: now, seenAtStart: !w.started}
+		case tracked.hash != tx.Hash:
+			events = append(events, w.event(EventReplaced, key, tracked, now))
+			w.txs[key] = &trackedTx{hash: tx.Hash, firstSeen: now}
+		}
+	}
+	for key, tracked := range w.txs {
+		if _, ok := current[key]; ok {
+			continue
+		}
+		confirmed, ok := nonces[key.from]
+		if !ok {
+			continue
+		}
+		kind := EventDropped
+		if confirmed > key.nonce {
+			kind = EventIncluded
+		}
+		events = append(events, w.event(kind, key, tracked, now))
+		delete(w.txs, key)
+	}
+	w.started = true
+	w.stats.Tracked = len(w.txs)
+
+	sort.Slice(events, func(i, j int) bool {
+		a, b := events[i], events[j]
+		if c := bytes.Compare(a.From[:], b.From[:]); c != 0 {
+			return c < 0
+		}
+		return a.Nonce < b.Nonce
+	})
+	return events, nil
+}
+
+func (w *Watcher) event(kind string, key txKey, t *trackedTx, now time.Time) TxEvent {
+	ev := TxEvent{
+		Kind:        kind,
+		Hash:        t.hash,
+		From:        key.from,
+		Nonce:       key.nonce,
+		FirstSeen:   t.firstSeen,
+		Gone:        now,
+		Latency:     now.Sub(t.firstSeen),
+		SeenAtStart: t.seenAtStart,
+	}
+	switch kind {
+	case EventIncluded:
+		w.stats.Included++
+		if !t.seenAtStart {
+			w.latencies = append(w.latencies, ev.Latency)
+			if len(w.latencies) > maxLatencies {
+				w.latencies = w.latencies[len(w.latencies)-maxLatencies:]
+			}
+		}
+	case EventDropped:
+		w.stats.Dropped++
+	case EventReplaced:
+		w.stats.Replaced++
+	}
+	return ev
+}
+
+// Stats returns event counts and inclusion latency percentiles.
+func (w *Watcher) Stats() WatchStats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	s := w.stats
+	if n := len(w.latencies); n > 0 {
+		sorted := append([]time.Duration(nil), w.latencies...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		s.Median = sorted[(n-1)/2]
+		s.P90 = sorted[(n-1)*9/10]
+		s.Max = sorted[n-1]
+	}
+	return s
+}
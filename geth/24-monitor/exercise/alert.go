@@ -0,0 +1,86 @@
+package exercise
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Alert describes a monitor state change.
+type Alert struct {
+	From        string        `json:"from"`
+	To          string        `json:"to"`
+	At          time.Time     `json:"at"`
+	BlockNumber uint64        `json:"blockNumber"`
+	Lag         time.Duration `json:"lagNanos"`
+	Reason      string        `json:"reason"`
+}
+
+// Alerter receives state changes. Errors are counted in Snapshot.AlertErrors
+// and never stop the monitor.
+type Alerter interface {
+	Alert(ctx context.Context, a Alert) error
+}
+
+// AlerterFunc adapts a function to the Alerter interface.
+type AlerterFunc func(ctx context.Context, a Alert) error
+
+// Alert implements Alerter.
+func (f AlerterFunc) Alert(ctx context.Context, a Alert) error { return f(ctx, a) }
+
+// LogAlerter writes one line per state change.
+type LogAlerter struct {
+	Logger *log.Logger // nil => log.Default()
+}
+
+// Alert implements Alerter.
+func (l LogAlerter) Alert(ctx context.Context, a Alert) error {
+	logger := l.Logger
+	if logger == nil {
+		logger = log.Default()
+	}
+	logger.Printf("node %s -> %s at block %d (%s)", a.From, a.To, a.BlockNumber, a.Reason)
+	return nil
+}
+
+// WebhookAlerter POSTs the Alert as JSON to URL, e.g. a local relay that
+// forwards to chat or paging.
+type WebhookAlerter struct {
+	URL    string
+	Client *http.Client // nil => client with a 5s timeout
+}
+
+// Alert implements Alerter.
+func (w WebhookAlerter) Alert(ctx context.Context, a Alert) error {
+	if w.URL == "" {
+		return errors.New("webhook url is empty")
+	}
+	body, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := w.Client
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
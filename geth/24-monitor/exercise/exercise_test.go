@@ -1,7 +1,301 @@
 package exercise
 
-import "testing"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
 
-func TestRun(t *testing.T) {
-	t.Skip("add tests for this module")
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var genesisTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// fakeClock advances only when After is called, so Run loops complete
+// instantly while simulating real intervals.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	now := c.now
+	c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	ch <- now
+	return ch
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+// stallChain produces a block every 12s except during [stallFrom, stallTo).
+type stallChain struct {
+	clock     *fakeClock
+	stallFrom time.Time
+	stallTo   time.Time
+	err       error
+	calls     int
+	onCall    func(n int)
+}
+
+func (c *stallChain) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	c.calls++
+	if c.onCall != nil {
+		c.onCall(c.calls)
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	now := c.clock.Now()
+	last := now
+	if !now.Before(c.stallFrom) && now.Before(c.stallTo) {
+		last = c.stallFrom
+	}
+	elapsed := last.Sub(genesisTime) / (12 * time.Second)
+	blockTime := genesisTime.Add(elapsed * 12 * time.Second)
+	return &types.Header{Number: big.NewInt(int64(elapsed)), Time: uint64(blockTime.Unix())}, nil
+}
+
+type recordingAlerter struct {
+	mu     sync.Mutex
+	alerts []Alert
+}
+
+func (r *recordingAlerter) Alert(ctx context.Context, a Alert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.alerts = append(r.alerts, a)
+	return nil
+}
+
+func TestMonitorSimulatedStall(t *testing.T) {
+	clock := &fakeClock{now: genesisTime}
+	chain := &stallChain{
+		clock:     clock,
+		stallFrom: genesisTime.Add(time.Hour),
+		stallTo:   genesisTime.Add(2 * time.Hour),
+	}
+	rec := &recordingAlerter{}
+	m, err := NewMonitor(chain, MonitorConfig{
+		MaxLag:       60 * time.Second,
+		StaleAfter:   2,
+		RecoverAfter: 3,
+		Alerters:     []Alerter{rec},
+		Clock:        clock,
+	})
+	if err != nil {
+		t.Fatalf("NewMonitor: %v", err)
+	}
+
+	// Three simulated hours at one sample per slot.
+	for clock.Now().Before(genesisTime.Add(3 * time.Hour)) {
+		m.Tick(context.Background())
+		clock.advance(12 * time.Second)
+	}
+
+	if len(rec.alerts) != 2 {
+		t.Fatalf("expected exactly 2 alerts, got %d: %+v", len(rec.alerts), rec.alerts)
+	}
+	down, up := rec.alerts[0], rec.alerts[1]
+	if down.From != StateOK || down.To != StateStale || up.From != StateStale || up.To != StateOK {
+		t.Fatalf("unexpected transitions %+v", rec.alerts)
+	}
+	// Lag first reaches 60s at stall+60s, second breaching sample is 12s later.
+	if want := genesisTime.Add(time.Hour + 72*time.Second); !down.At.Equal(want) {
+		t.Fatalf("STALE at %v, want %v", down.At, want)
+	}
+	// Recovery needs three healthy samples after blocks resume.
+	if want := genesisTime.Add(2*time.Hour + 24*time.Second); !up.At.Equal(want) {
+		t.Fatalf("OK at %v, want %v", up.At, want)
+	}
+
+	snap := m.Snapshot()
+	if snap.State != StateOK || snap.StateChanges != 2 || snap.Samples != 900 {
+		t.Fatalf("unexpected snapshot %+v", snap)
+	}
+	if len(m.History()) != 300 {
+		t.Fatalf("history should be capped at 300, got %d", len(m.History()))
+	}
+}
+
+func TestMonitorHysteresisPreventsFlapping(t *testing.T) {
+	clock := &fakeClock{now: genesisTime}
+	lags := []time.Duration{}
+	client := headerFunc(func() (*types.Header, error) {
+		lag := lags[0]
+		lags = lags[1:]
+		return &types.Header{Number: big.NewInt(1), Time: uint64(clock.Now().Add(-lag).Unix())}, nil
+	})
+	rec := &recordingAlerter{}
+	m, _ := NewMonitor(client, MonitorConfig{MaxLag: 60 * time.Second, RecoverLag: 30 * time.Second, Alerters: []Alerter{rec}, Clock: clock})
+
+	// Alternating around MaxLag never gives two breaching samples in a row.
+	for i := 0; i < 50; i++ {
+		lags = append(lags, 70*time.Second, 50*time.Second)
+	}
+	for len(lags) > 0 {
+		m.Tick(context.Background())
+	}
+	if m.State() != StateOK || len(rec.alerts) != 0 {
+		t.Fatalf("flapped: state=%s alerts=%d", m.State(), len(rec.alerts))
+	}
+
+	// Go stale, then hover between RecoverLag and MaxLag: must stay STALE.
+	lags = append(lags, 90*time.Second, 90*time.Second)
+	for i := 0; i < 50; i++ {
+		lags = append(lags, 40*time.Second, 20*time.Second)
+	}
+	for len(lags) > 0 {
+		m.Tick(context.Background())
+	}
+	if m.State() != StateStale || len(rec.alerts) != 1 {
+		t.Fatalf("expected to remain STALE with one alert, got %s / %d", m.State(), len(rec.alerts))
+	}
+}
+
+type headerFunc func() (*types.Header, error)
+
+func (f headerFunc) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return f()
+}
+
+func TestMonitorTreatsRPCErrorsAsStale(t *testing.T) {
+	clock := &fakeClock{now: genesisTime}
+	chain := &stallChain{clock: clock, err: errors.New("connection refused")}
+	failing := AlerterFunc(func(ctx context.Context, a Alert) error { return errors.New("pager down") })
+	rec := &recordingAlerter{}
+	m, _ := NewMonitor(chain, MonitorConfig{Clock: clock, Alerters: []Alerter{failing, rec}})
+
+	m.Tick(context.Background())
+	m.Tick(context.Background())
+	if m.State() != StateStale {
+		t.Fatalf("expected STALE after repeated RPC errors")
+	}
+	if len(rec.alerts) != 1 || !strings.Contains(rec.alerts[0].Reason, "connection refused") {
+		t.Fatalf("unexpected alerts %+v", rec.alerts)
+	}
+	snap := m.Snapshot()
+	if snap.Errors != 2 || snap.AlertErrors != 1 {
+		t.Fatalf("unexpected counters %+v", snap)
+	}
+}
+
+func TestMonitorRunLoop(t *testing.T) {
+	clock := &fakeClock{now: genesisTime}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	chain := &stallChain{
+		clock:     clock,
+		stallFrom: genesisTime.Add(10 * time.Minute),
+		stallTo:   genesisTime.Add(20 * time.Minute),
+		onCall: func(n int) {
+			if n == 150 { // 30 simulated minutes
+				cancel()
+			}
+		},
+	}
+	rec := &recordingAlerter{}
+	m, _ := NewMonitor(chain, MonitorConfig{Clock: clock, Alerters: []Alerter{rec}})
+
+	if err := m.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run returned %v", err)
+	}
+	if chain.calls != 150 || len(rec.alerts) != 2 {
+		t.Fatalf("calls=%d alerts=%d", chain.calls, len(rec.alerts))
+	}
+}
+
+func TestWebhookAlerter(t *testing.T) {
+	var got Alert
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	alert := Alert{From: StateOK, To: StateStale, BlockNumber: 42, Lag: 90 * time.Second, Reason: "head lag 1m30s"}
+	if err := (WebhookAlerter{URL: srv.URL}).Alert(context.Background(), alert); err != nil {
+		t.Fatalf("webhook: %v", err)
+	}
+	if got.To != StateStale || got.BlockNumber != 42 || got.Lag != 90*time.Second {
+		t.Fatalf("server received %+v", got)
+	}
+
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer bad.Close()
+	if err := (WebhookAlerter{URL: bad.URL}).Alert(context.Background(), alert); err == nil {
+		t.Fatalf("expected error for 500 response")
+	}
+}
+
+func TestMetricsHandler(t *testing.T) {
+	clock := &fakeClock{now: genesisTime.Add(time.Hour)}
+	chain := &stallChain{clock: clock, stallFrom: genesisTime, stallTo: genesisTime.Add(2 * time.Hour)}
+	m, _ := NewMonitor(chain, MonitorConfig{Clock: clock})
+	m.Tick(context.Background())
+	m.Tick(context.Background())
+
+	rr := httptest.NewRecorder()
+	m.MetricsHandler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rr.Body.String()
+	for _, want := range []string{
+		"# TYPE geth_monitor_head_lag_seconds gauge\ngeth_monitor_head_lag_seconds 3600\n",
+		"geth_monitor_stale 1\n",
+		"geth_monitor_samples_total 2\n",
+		"geth_monitor_state_changes_total 1\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("metrics missing %q:\n%s", want, body)
+		}
+	}
+	if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Fatalf("content type %q", ct)
+	}
+}
+
+func TestNewMonitorValidation(t *testing.T) {
+	if _, err := NewMonitor(nil, MonitorConfig{}); err == nil {
+		t.Fatalf("expected nil client error")
+	}
+	client := &stallChain{clock: &fakeClock{}}
+	if _, err := NewMonitor(client, MonitorConfig{MaxLag: time.Second, RecoverLag: time.Minute}); err == nil {
+		t.Fatalf("expected recover > max error")
+	}
+}
+
+func TestRunSingleCheck(t *testing.T) {
+	client := headerFunc(func() (*types.Header, error) {
+		return &types.Header{Number: big.NewInt(7), Time: uint64(time.Now().Add(-5 * time.Minute).Unix())}, nil
+	})
+	res, err := Run(context.Background(), client, Config{MaxLagSeconds: 60})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if res.Status != StateStale || res.BlockNumber != 7 {
+		t.Fatalf("unexpected result %+v", res)
+	}
 }
@@ -0,0 +1,38 @@
+package exercise
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// MetricsHandler serves the monitor's state in the Prometheus text exposition
+// format. Mount it with http.Handle("/metrics", m.MetricsHandler()).
+func (m *Monitor) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		m.WriteMetrics(w)
+	})
+}
+
+// WriteMetrics writes every metric in the Prometheus text format.
+func (m *Monitor) WriteMetrics(w io.Writer) {
+	snap := m.Snapshot()
+	stale := 0
+	if snap.State == StateStale {
+		stale = 1
+	}
+
+	metric(w, "geth_monitor_head_lag_seconds", "gauge", "Age of the latest head block in seconds.", snap.Last.Lag.Seconds())
+	metric(w, "geth_monitor_head_lag_max_seconds", "gauge", "Largest head lag in the retained history.", snap.MaxLag.Seconds())
+	metric(w, "geth_monitor_head_block", "gauge", "Latest observed head block number.", float64(snap.Last.BlockNumber))
+	metric(w, "geth_monitor_stale", "gauge", "1 if the node is considered stale, 0 otherwise.", float64(stale))
+	metric(w, "geth_monitor_samples_total", "counter", "Head samples taken.", float64(snap.Samples))
+	metric(w, "geth_monitor_errors_total", "counter", "Head samples that failed with an RPC error.", float64(snap.Errors))
+	metric(w, "geth_monitor_state_changes_total", "counter", "OK/STALE transitions.", float64(snap.StateChanges))
+	metric(w, "geth_monitor_alert_errors_total", "counter", "Alerter calls that returned an error.", float64(snap.AlertErrors))
+}
+
+func metric(w io.Writer, name, kind, help string, value float64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %g\n", name, help, name, kind, name, value)
+}
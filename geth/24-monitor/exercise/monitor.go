@@ -0,0 +1,307 @@
+package exercise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Monitor states. They match the Status strings returned by Run.
+const (
+	StateOK    = "OK"
+	StateStale = "STALE"
+)
+
+// Clock abstracts time so tests can simulate hours of stalls instantly.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// HeadSubscriber is optionally implemented by clients that support
+// eth_subscribe (websocket/IPC). The monitor uses it when available.
+type HeadSubscriber interface {
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+}
+
+// MonitorConfig tunes the continuous monitor. Zero values use defaults.
+type MonitorConfig struct {
+	Interval     time.Duration // evaluation period (default 12s, one slot)
+	MaxLag       time.Duration // lag at or above this is breaching (default 60s)
+	RecoverLag   time.Duration // lag below this is healthy (default MaxLag/2)
+	StaleAfter   int           // consecutive breaching samples before STALE (default 2)
+	RecoverAfter int           // consecutive healthy samples before OK (default 3)
+	HistorySize  int           // samples kept for History/metrics (default 300)
+	Alerters     []Alerter
+	Clock        Clock
+}
+
+// Sample is one evaluation of head lag.
+type Sample struct {
+	Time        time.Time
+	BlockNumber uint64
+	Lag         time.Duration
+	Err         error
+}
+
+// Snapshot is a point-in-time copy of the monitor's state.
+type Snapshot struct {
+	State        string
+	Last         Sample
+	Samples      uint64
+	Errors       uint64
+	StateChanges uint64
+	AlertErrors  uint64
+	MaxLag       time.Duration // largest lag in the retained history
+}
+
+// Monitor polls (or subscribes to) the chain head on an interval and tracks
+// lag over time. Hysteresis keeps the state from flapping: it only turns
+// STALE after StaleAfter breaching samples in a row, and only recovers after
+// RecoverAfter samples below the lower RecoverLag threshold. Every state
+// change is sent to the configured Alerters.
+type Monitor struct {
+	client MonitorClient
+	cfg    MonitorConfig
+
+	mu       sync.Mutex
+	state    string
+	breaches int
+	healthy  int
+	history  []Sample // ring buffer
+	next     int
+	snap     Snapshot
+	latest   *types.Header // from subscription, if any
+}
+
+// NewMonitor validates cfg and returns a monitor in the OK state.
+func NewMonitor(client MonitorClient, cfg MonitorConfig) (*Monitor, error) {
+	if client == nil {
+		return nil, errors.New("client is nil")
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = 12 * time.Second
+	}
+	if cfg.MaxLag <= 0 {
+		cfg.MaxLag = 60 * time.Second
+	}
+	if cfg.RecoverLag <= 0 {
+		cfg.RecoverLag = cfg.MaxLag / 2
+	}
+	if cfg.RecoverLag > cfg.MaxLag {
+		return nil, fmt.Errorf("recover lag %s exceeds max lag %s", cfg.RecoverLag, cfg.MaxLag)
+	}
+	if cfg.StaleAfter <= 0 {
+		cfg.StaleAfter = 2
+	}
+	if cfg.RecoverAfter <= 0 {
+		cfg.RecoverAfter = 3
+	}
+	if cfg.HistorySize <= 0 {
+		cfg.HistorySize = 300
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = realClock{}
+	}
+	m := &Monitor{client: client, cfg: cfg, state: StateOK, history: make([]Sample, 0, cfg.HistorySize)}
+	m.snap.State = StateOK
+	return m, nil
+}
+
+// Run evaluates the head every Interval until ctx is cancelled. If the
+// client implements HeadSubscriber, heads are pushed over a subscription and
+// polling is only used when no head has arrived yet or the subscription dies.
+func (m *Monitor) Run(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if sub, ok := m.client.(HeadSubscriber); ok {
+		if stop := m.subscribe(ctx, sub); stop != nil {
+			defer stop()
+		}
+	}
+	for {
+		// An elapsed Interval and a cancelled ctx can both be ready at
+		// once; cancellation wins, so no sample is taken after it.
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		m.Tick(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-m.cfg.Clock.After(m.cfg.Interval):
+		}
+	}
+}
+
+func (m *Monitor) subscribe(ctx context.Context, client HeadSubscriber) func() {
+	heads := make(chan *types.Header, 16)
+	sub, err := client.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		return nil // fall back to polling
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case h := <-heads:
+				m.mu.Lock()
+				m.latest = h
+				m.mu.Unlock()
+			case <-sub.Err():
+				m.mu.Lock()
+				m.latest = nil // stale push data must not mask a dead node
+				m.mu.Unlock()
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return func() {
+		sub.Unsubscribe()
+		<-done
+	}
+}
+
+// Tick takes one sample and advances the state machine. Run calls it on
+// every interval; tests can call it directly.
+func (m *Monitor) Tick(ctx context.Context) Sample {
+	now := m.cfg.Clock.Now()
+	sample := Sample{Time: now}
+
+	m.mu.Lock()
+	header := m.latest
+	m.mu.Unlock()
+	if header == nil {
+		var err error
+		header, err = m.client.HeaderByNumber(ctx, nil)
+		if err == nil && (header == nil || header.Number == nil) {
+			err = errors.New("empty header response")
+		}
+		sample.Err = err
+	}
+	if sample.Err == nil {
+		sample.BlockNumber = header.Number.Uint64()
+		sample.Lag = now.Sub(time.Unix(int64(header.Time), 0))
+		if sample.Lag < 0 {
+			sample.Lag = 0 // accept slight clock skew
+		}
+	}
+
+	if alert, changed := m.record(sample); changed {
+		m.dispatch(ctx, alert)
+	}
+	return sample
+}
+
+// record appends the sample and applies hysteresis. It returns the alert to
+// send when the state changed.
+func (m *Monitor) record(s Sample) (Alert, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.history) < m.cfg.HistorySize {
+		m.history = append(m.history, s)
+	} else {
+		m.history[m.next] = s
+	}
+	m.next = (m.next + 1) % m.cfg.HistorySize
+
+	m.snap.Samples++
+	m.snap.Last = s
+	if s.Err != nil {
+		m.snap.Errors++
+	}
+
+	// An unreachable node is treated like a stalled one.
+	breaching := s.Err != nil || s.Lag >= m.cfg.MaxLag
+	healthy := s.Err == nil && s.Lag < m.cfg.RecoverLag
+	if breaching {
+		m.breaches++
+	} else {
+		m.breaches = 0
+	}
+	if healthy {
+		m.healthy++
+	} else {
+		m.healthy = 0
+	}
+
+	prev := m.state
+	switch {
+	case m.state == StateOK && m.breaches >= m.cfg.StaleAfter:
+		m.state = StateStale
+	case m.state == StateStale && m.healthy >= m.cfg.RecoverAfter:
+		m.state = StateOK
+	}
+	m.snap.State = m.state
+	if m.state == prev {
+		return Alert{}, false
+	}
+	m.snap.StateChanges++
+
+	alert := Alert{From: prev, To: m.state, At: s.Time, BlockNumber: s.BlockNumber, Lag: s.Lag}
+	if s.Err != nil {
+		alert.Reason = s.Err.Error()
+	} else {
+		alert.Reason = fmt.Sprintf("head lag %s", s.Lag.Truncate(time.Second))
+	}
+	return alert, true
+}
+
+func (m *Monitor) dispatch(ctx context.Context, alert Alert) {
+	for _, a := range m.cfg.Alerters {
+		if err := a.Alert(ctx, alert); err != nil {
+			m.mu.Lock()
+			m.snap.AlertErrors++
+			m.mu.Unlock()
+		}
+	}
+}
+
+// State returns the current state (StateOK or StateStale).
+func (m *Monitor) State() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.state
+}
+
+// Snapshot returns a copy of the counters and the latest sample.
+func (m *Monitor) Snapshot() Snapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snap := m.snap
+	for _, s := range m.history {
+		if s.Lag > snap.MaxLag {
+			snap.MaxLag = s.Lag
+		}
+	}
+	return snap
+}
+
+// History returns retained samples, oldest first.
+func (m *Monitor) History() []Sample {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]Sample, 0, len(m.history))
+	if len(m.history) == m.cfg.HistorySize {
+		out = append(out, m.history[m.next:]...)
+		out = append(out, m.history[:m.next]...)
+	} else {
+		out = append(out, m.history...)
+	}
+	return out
+}
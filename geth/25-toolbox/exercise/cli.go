@@ -0,0 +1,63 @@
+package exercise
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ParseArgs turns a command line (without the program name) into a Config.
+// The --output (or -o) flag may appear anywhere, as "--output json" or
+// "--output=json":
+//
+//	toolbox --output json balance 0xabc... 19000000
+//	toolbox logs 0xabc... 100 200 -o csv
+func ParseArgs(argv []string) (Config, error) {
+	var cfg Config
+	var rest []string
+	for i := 0; i < len(argv); i++ {
+		arg := argv[i]
+		name, value, hasValue := strings.Cut(arg, "=")
+		if name != "--output" && name != "-output" && name != "-o" {
+			rest = append(rest, arg)
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(argv) {
+				return Config{}, fmt.Errorf("%s needs a value", name)
+			}
+			i++
+			value = argv[i]
+		}
+		format, err := ParseFormat(value)
+		if err != nil {
+			return Config{}, err
+		}
+		cfg.Output = format
+	}
+	if len(rest) > 0 {
+		cfg.Command, cfg.Args = rest[0], rest[1:]
+	}
+	return cfg, nil
+}
+
+// Main parses argv, runs the command against the default registry and
+// renders the result to w. An empty command, "help", -h or --help prints
+// the command list instead.
+func Main(ctx context.Context, client ToolboxClient, argv []string, w io.Writer) error {
+	reg := DefaultRegistry()
+	cfg, err := ParseArgs(argv)
+	if err != nil {
+		return err
+	}
+	switch cfg.Command {
+	case "", "help", "-h", "--help":
+		return reg.WriteHelp(w)
+	}
+	res, err := Run(ctx, client, cfg)
+	if err != nil {
+		return err
+	}
+	return Render(w, res)
+}
@@ -0,0 +1,669 @@
+package exercise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	tracing "geth-edu/13-trace/exercise"
+)
+
+// Shared argument descriptions.
+var (
+	argAddress = Arg{Name: "address", Help: "0x-prefixed 20-byte address"}
+	argTxHash  = Arg{Name: "hash", Help: "0x-prefixed transaction hash"}
+	argSlot    = Arg{Name: "slot", Help: "storage slot, decimal or 0x hex"}
+	argBlock   = Arg{Name: "block", Help: "block number (decimal or 0x hex) or latest", Optional: true}
+)
+
+func builtinCommands() []Command {
+	return []Command{
+		{Name: "status", Summary: "chain id, head, sync state and peers (modules 01, 21, 22)", Handler: cmdStatus},
+		{Name: "block", Summary: "block header summary (module 24)", Args: []Arg{
+			{Name: "block", Help: "block number (decimal or 0x hex) or latest"},
+		}, Handler: cmdBlock},
+		{Name: "tx", Summary: "transaction details (module 24)", Args: []Arg{argTxHash}, Handler: cmdTx},
+		{Name: "balance", Summary: "account balance in wei and ether (module 04)", Args: []Arg{argAddress, argBlock}, Handler: cmdBalance},
+		{Name: "nonce", Summary: "account nonce (module 05)", Args: []Arg{argAddress,
+			{Name: "block", Help: "block number, latest or pending", Optional: true},
+		}, Handler: cmdNonce},
+		{Name: "call", Summary: "read-only eth_call (module 07)", Args: []Arg{argAddress,
+			{Name: "data", Help: "0x calldata or a no-argument signature such as totalSupply()"},
+			argBlock,
+		}, Handler: cmdCall},
+		{Name: "logs", Summary: "logs emitted by a contract (module 09)", Args: []Arg{argAddress,
+			{Name: "from", Help: "first block"},
+			{Name: "to", Help: "last block or latest"},
+			{Name: "topic0", Help: "event topic hash or signature such as Transfer(address,address,uint256)", Optional: true},
+		}, Handler: cmdLogs},
+		{Name: "storage", Summary: "raw storage slot (module 11)", Args: []Arg{argAddress, argSlot, argBlock}, Handler: cmdStorage},
+		{Name: "proof", Summary: "eth_getProof account and slot proof (module 12)", Args: []Arg{argAddress,
+			{Name: "slot", Help: "storage slot to prove, decimal or 0x hex", Optional: true},
+			argBlock,
+		}, Handler: cmdProof},
+		{Name: "receipt", Summary: "receipt status, gas and fee (module 15)", Args: []Arg{argTxHash}, Handler: cmdReceipt},
+		{Name: "trace", Summary: "debug_traceTransaction call tree summary, struct logger or callTracer (module 13)", Args: []Arg{argTxHash}, Handler: cmdTrace},
+		{Name: "fees", Summary: "base fee, tip and suggested max fee (module 06)", Handler: cmdFees},
+	}
+}
+
+// StatusOutput is the result of "status".
+type StatusOutput struct {
+	ChainID     string `json:"chainId"`
+	NetworkID   string `json:"networkId"`
+	BlockNumber uint64 `json:"blockNumber"`
+	BlockHash   string `json:"blockHash"`
+	Syncing     bool   `json:"syncing"`
+	PeerCount   uint64 `json:"peerCount"`
+}
+
+func (o StatusOutput) Table() ([]string, [][]string) { return recordTable(o) }
+func (StatusOutput) record()                         {}
+
+func cmdStatus(ctx context.Context, client ToolboxClient, args []string) (Output, error) {
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("chain id: %w", err)
+	}
+	networkID, err := client.NetworkID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("network id: %w", err)
+	}
+	header, err := latestHeader(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := client.SyncProgress(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sync progress: %w", err)
+	}
+	peers, err := client.PeerCount(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("peer count: %w", err)
+	}
+	return StatusOutput{
+		ChainID:     chainID.String(),
+		NetworkID:   networkID.String(),
+		BlockNumber: header.Number.Uint64(),
+		BlockHash:   header.Hash().Hex(),
+		Syncing:     progress != nil,
+		PeerCount:   peers,
+	}, nil
+}
+
+// BlockOutput is the result of "block".
+type BlockOutput struct {
+	Number     uint64 `json:"number"`
+	Hash       string `json:"hash"`
+	ParentHash string `json:"parentHash"`
+	Timestamp  uint64 `json:"timestamp"`
+	TxCount    int    `json:"txCount"`
+	GasUsed    uint64 `json:"gasUsed"`
+	GasLimit   uint64 `json:"gasLimit"`
+	BaseFee    string `json:"baseFee"`
+}
+
+func (o BlockOutput) Table() ([]string, [][]string) { return recordTable(o) }
+func (BlockOutput) record()                         {}
+
+func cmdBlock(ctx context.Context, client ToolboxClient, args []string) (Output, error) {
+	number, err := parseBlock(args[0])
+	if err != nil {
+		return nil, err
+	}
+	block, err := client.BlockByNumber(ctx, number)
+	if err != nil {
+		return nil, fmt.Errorf("fetch block: %w", err)
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %s not found", args[0])
+	}
+	out := BlockOutput{
+		Number:     block.NumberU64(),
+		Hash:       block.Hash().Hex(),
+		ParentHash: block.ParentHash().Hex(),
+		Timestamp:  block.Time(),
+		TxCount:    len(block.Transactions()),
+		GasUsed:    block.GasUsed(),
+		GasLimit:   block.GasLimit(),
+	}
+	if block.BaseFee() != nil {
+		out.BaseFee = block.BaseFee().String()
+	}
+	return out, nil
+}
+
+// TxOutput is the result of "tx". To is empty for contract creations.
+type TxOutput struct {
+	Hash     string `json:"hash"`
+	Nonce    uint64 `json:"nonce"`
+	To       string `json:"to"`
+	Value    string `json:"value"`
+	Gas      uint64 `json:"gas"`
+	GasPrice string `json:"gasPrice"`
+	Pending  bool   `json:"pending"`
+}
+
+func (o TxOutput) Table() ([]string, [][]string) { return recordTable(o) }
+func (TxOutput) record()                         {}
+
+func cmdTx(ctx context.Context, client ToolboxClient, args []string) (Output, error) {
+	hash, err := parseHash(args[0])
+	if err != nil {
+		return nil, err
+	}
+	tx, pending, err := client.TransactionByHash(ctx, hash.Hex())
+	if err != nil {
+		return nil, fmt.Errorf("fetch transaction: %w", err)
+	}
+	if tx == nil {
+		return nil, fmt.Errorf("transaction %s not found", hash.Hex())
+	}
+	out := TxOutput{
+		Hash:     tx.Hash().Hex(),
+		Nonce:    tx.Nonce(),
+		Value:    tx.Value().String(),
+		Gas:      tx.Gas(),
+		GasPrice: tx.GasPrice().String(),
+		Pending:  pending,
+	}
+	if tx.To() != nil {
+		out.To = tx.To().Hex()
+	}
+	return out, nil
+}
+
+// BalanceOutput is the result of "balance".
+type BalanceOutput struct {
+	Address string `json:"address"`
+	Block   string `json:"block"`
+	Wei     string `json:"wei"`
+	Ether   string `json:"ether"`
+}
+
+func (o BalanceOutput) Table() ([]string, [][]string) { return recordTable(o) }
+func (BalanceOutput) record()                         {}
+
+func cmdBalance(ctx context.Context, client ToolboxClient, args []string) (Output, error) {
+	addr, err := parseAddress(args[0])
+	if err != nil {
+		return nil, err
+	}
+	number, err := parseBlock(optional(args, 1))
+	if err != nil {
+		return nil, err
+	}
+	wei, err := client.BalanceAt(ctx, addr, number)
+	if err != nil {
+		return nil, fmt.Errorf("balance: %w", err)
+	}
+	return BalanceOutput{Address: addr.Hex(), Block: blockLabel(number), Wei: wei.String(), Ether: formatEther(wei)}, nil
+}
+
+// NonceOutput is the result of "nonce".
+type NonceOutput struct {
+	Address string `json:"address"`
+	Block   string `json:"block"`
+	Nonce   uint64 `json:"nonce"`
+}
+
+func (o NonceOutput) Table() ([]string, [][]string) { return recordTable(o) }
+func (NonceOutput) record()                         {}
+
+func cmdNonce(ctx context.Context, client ToolboxClient, args []string) (Output, error) {
+	addr, err := parseAddress(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if optional(args, 1) == "pending" {
+		nonce, err := client.PendingNonceAt(ctx, addr)
+		if err != nil {
+			return nil, fmt.Errorf("pending nonce: %w", err)
+		}
+		return NonceOutput{Address: addr.Hex(), Block: "pending", Nonce: nonce}, nil
+	}
+	number, err := parseBlock(optional(args, 1))
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := client.NonceAt(ctx, addr, number)
+	if err != nil {
+		return nil, fmt.Errorf("nonce: %w", err)
+	}
+	return NonceOutput{Address: addr.Hex(), Block: blockLabel(number), Nonce: nonce}, nil
+}
+
+// CallOutput is the result of "call". Result is the raw return data.
+type CallOutput struct {
+	To     string `json:"to"`
+	Block  string `json:"block"`
+	Data   string `json:"data"`
+	Result string `json:"result"`
+}
+
+func (o CallOutput) Table() ([]string, [][]string) { return recordTable(o) }
+func (CallOutput) record()                         {}
+
+func cmdCall(ctx context.Context, client ToolboxClient, args []string) (Output, error) {
+	to, err := parseAddress(args[0])
+	if err != nil {
+		return nil, err
+	}
+	data, err := parseCalldata(args[1])
+	if err != nil {
+		return nil, err
+	}
+	number, err := parseBlock(optional(args, 2))
+	if err != nil {
+		return nil, err
+	}
+	ret, err := client.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, number)
+	if err != nil {
+		return nil, fmt.Errorf("call: %w", err)
+	}
+	return CallOutput{To: to.Hex(), Block: blockLabel(number), Data: hexutil.Encode(data), Result: hexutil.Encode(ret)}, nil
+}
+
+// LogEntry is one row of "logs".
+type LogEntry struct {
+	BlockNumber uint64   `json:"blockNumber"`
+	TxHash      string   `json:"txHash"`
+	LogIndex    uint     `json:"logIndex"`
+	Address     string   `json:"address"`
+	Topics      []string `json:"topics"`
+	Data        string   `json:"data"`
+}
+
+// LogsOutput is the result of "logs".
+type LogsOutput []LogEntry
+
+func (o LogsOutput) Table() ([]string, [][]string) {
+	header := []string{"blockNumber", "txHash", "logIndex", "address", "topics", "data"}
+	rows := make([][]string, 0, len(o))
+	for _, l := range o {
+		rows = append(rows, []string{
+			fmt.Sprint(l.BlockNumber), l.TxHash, fmt.Sprint(l.LogIndex), l.Address, strings.Join(l.Topics, " "), l.Data,
+		})
+	}
+	return header, rows
+}
+
+func cmdLogs(ctx context.Context, client ToolboxClient, args []string) (Output, error) {
+	addr, err := parseAddress(args[0])
+	if err != nil {
+		return nil, err
+	}
+	from, err := parseBlock(args[1])
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseBlock(args[2])
+	if err != nil {
+		return nil, err
+	}
+	q := ethereum.FilterQuery{FromBlock: from, ToBlock: to, Addresses: []common.Address{addr}}
+	if topic := optional(args, 3); topic != "" {
+		t, err := parseTopic(topic)
+		if err != nil {
+			return nil, err
+		}
+		q.Topics = [][]common.Hash{{t}}
+	}
+	logs, err := client.FilterLogs(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("filter logs: %w", err)
+	}
+	out := make(LogsOutput, 0, len(logs))
+	for _, l := range logs {
+		topics := make([]string, len(l.Topics))
+		for i, t := range l.Topics {
+			topics[i] = t.Hex()
+		}
+		out = append(out, LogEntry{
+			BlockNumber: l.BlockNumber,
+			TxHash:      l.TxHash.Hex(),
+			LogIndex:    l.Index,
+			Address:     l.Address.Hex(),
+			Topics:      topics,
+			Data:        hexutil.Encode(l.Data),
+		})
+	}
+	return out, nil
+}
+
+// StorageOutput is the result of "storage".
+type StorageOutput struct {
+	Address string `json:"address"`
+	Slot    string `json:"slot"`
+	Block   string `json:"block"`
+	Value   string `json:"value"`
+}
+
+func (o StorageOutput) Table() ([]string, [][]string) { return recordTable(o) }
+func (StorageOutput) record()                         {}
+
+func cmdStorage(ctx context.Context, client ToolboxClient, args []string) (Output, error) {
+	addr, err := parseAddress(args[0])
+	if err != nil {
+		return nil, err
+	}
+	slot, err := parseSlot(args[1])
+	if err != nil {
+		return nil, err
+	}
+	number, err := parseBlock(optional(args, 2))
+	if err != nil {
+		return nil, err
+	}
+	value, err := client.StorageAt(ctx, addr, slot, number)
+	if err != nil {
+		return nil, fmt.Errorf("storage: %w", err)
+	}
+	return StorageOutput{Address: addr.Hex(), Slot: slot.Hex(), Block: blockLabel(number), Value: common.BytesToHash(value).Hex()}, nil
+}
+
+// ProofOutput is the result of "proof". Slot fields are empty when no slot
+// was requested.
+type ProofOutput struct {
+	Address        string `json:"address"`
+	Block          string `json:"block"`
+	Balance        string `json:"balance"`
+	Nonce          uint64 `json:"nonce"`
+	CodeHash       string `json:"codeHash"`
+	StorageHash    string `json:"storageHash"`
+	AccountNodes   int    `json:"accountProofNodes"`
+	Slot           string `json:"slot,omitempty"`
+	SlotValue      string `json:"slotValue,omitempty"`
+	SlotProofNodes int    `json:"slotProofNodes,omitempty"`
+}
+
+func (o ProofOutput) Table() ([]string, [][]string) { return recordTable(o) }
+func (ProofOutput) record()                         {}
+
+func cmdProof(ctx context.Context, client ToolboxClient, args []string) (Output, error) {
+	addr, err := parseAddress(args[0])
+	if err != nil {
+		return nil, err
+	}
+	var slots []string
+	if s := optional(args, 1); s != "" {
+		slot, err := parseSlot(s)
+		if err != nil {
+			return nil, err
+		}
+		slots = []string{slot.Hex()}
+	}
+	number, err := parseBlock(optional(args, 2))
+	if err != nil {
+		return nil, err
+	}
+	res, err := client.GetProof(ctx, addr, slots, number)
+	if err != nil {
+		return nil, fmt.Errorf("get proof: %w", err)
+	}
+	if res == nil {
+		return nil, errors.New("empty proof response")
+	}
+	out := ProofOutput{
+		Address:      addr.Hex(),
+		Block:        blockLabel(number),
+		Nonce:        res.Nonce,
+		CodeHash:     res.CodeHash.Hex(),
+		StorageHash:  res.StorageHash.Hex(),
+		AccountNodes: len(res.AccountProof),
+	}
+	if res.Balance != nil {
+		out.Balance = res.Balance.String()
+	}
+	if len(slots) > 0 {
+		if len(res.StorageProof) == 0 {
+			return nil, errors.New("node returned no storage proof")
+		}
+		sp := res.StorageProof[0]
+		out.Slot = slots[0]
+		out.SlotValue = "0"
+		if sp.Value != nil {
+			out.SlotValue = sp.Value.String()
+		}
+		out.SlotProofNodes = len(sp.Proof)
+	}
+	return out, nil
+}
+
+// ReceiptOutput is the result of "receipt". Fee is GasUsed times
+// EffectiveGasPrice in wei.
+type ReceiptOutput struct {
+	TxHash            string `json:"txHash"`
+	Status            string `json:"status"`
+	BlockNumber       uint64 `json:"blockNumber"`
+	GasUsed           uint64 `json:"gasUsed"`
+	EffectiveGasPrice string `json:"effectiveGasPrice"`
+	Fee               string `json:"fee"`
+	ContractAddress   string `json:"contractAddress"`
+	Logs              int    `json:"logs"`
+}
+
+func (o ReceiptOutput) Table() ([]string, [][]string) { return recordTable(o) }
+func (ReceiptOutput) record()                         {}
+
+func cmdReceipt(ctx context.Context, client ToolboxClient, args []string) (Output, error) {
+	hash, err := parseHash(args[0])
+	if err != nil {
+		return nil, err
+	}
+	r, err := client.TransactionReceipt(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("receipt: %w", err)
+	}
+	if r == nil {
+		return nil, fmt.Errorf("receipt %s not found", hash.Hex())
+	}
+	out := ReceiptOutput{
+		TxHash:  hash.Hex(),
+		Status:  "failed",
+		GasUsed: r.GasUsed,
+		Logs:    len(r.Logs),
+	}
+	if r.Status == types.ReceiptStatusSuccessful {
+		out.Status = "success"
+	}
+	if r.BlockNumber != nil {
+		out.BlockNumber = r.BlockNumber.Uint64()
+	}
+	if r.EffectiveGasPrice != nil {
+		out.EffectiveGasPrice = r.EffectiveGasPrice.String()
+		out.Fee = new(big.Int).Mul(r.EffectiveGasPrice, new(big.Int).SetUint64(r.GasUsed)).String()
+	}
+	if r.ContractAddress != (common.Address{}) {
+		out.ContractAddress = r.ContractAddress.Hex()
+	}
+	return out, nil
+}
+
+// TraceOutput is the result of "trace": the call tree decoded by module 13,
+// from either the default struct logger or callTracer output.
+type TraceOutput struct {
+	TxHash       string `json:"txHash"`
+	Format       string `json:"format"` // structLogs or callTracer
+	GasUsed      uint64 `json:"gasUsed"`
+	Failed       bool   `json:"failed"`
+	RevertReason string `json:"revertReason"`
+	Calls        int    `json:"calls"` // frames below the transaction itself
+	MaxDepth     int    `json:"maxDepth"`
+}
+
+func (o TraceOutput) Table() ([]string, [][]string) { return recordTable(o) }
+func (TraceOutput) record()                         {}
+
+func cmdTrace(ctx context.Context, client ToolboxClient, args []string) (Output, error) {
+	hash, err := parseHash(args[0])
+	if err != nil {
+		return nil, err
+	}
+	raw, err := client.TraceTransaction(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("trace: %w", err)
+	}
+	root, format, err := tracing.DecodeTrace(raw)
+	if err != nil {
+		return nil, err
+	}
+	// Other tracers (prestateTracer, 4byteTracer, ...) decode without error
+	// into a frame with no call type.
+	if root.Type == "" {
+		return nil, errors.New("decode trace: unsupported tracer output; use the default struct logger or callTracer")
+	}
+	out := TraceOutput{
+		TxHash:       hash.Hex(),
+		Format:       format,
+		GasUsed:      root.GasUsed,
+		Failed:       root.Failed(),
+		RevertReason: root.RevertReason,
+	}
+	root.Walk(func(_ string, f *tracing.CallFrame) bool {
+		if f != root {
+			out.Calls++
+		}
+		out.MaxDepth = max(out.MaxDepth, f.Depth)
+		return true
+	})
+	return out, nil
+}
+
+// FeesOutput is the result of "fees". MaxFee follows module 06:
+// 2*baseFee + tipCap, which survives several full blocks of base fee growth.
+type FeesOutput struct {
+	Block   uint64 `json:"block"`
+	BaseFee string `json:"baseFee"`
+	TipCap  string `json:"tipCap"`
+	MaxFee  string `json:"maxFee"`
+}
+
+func (o FeesOutput) Table() ([]string, [][]string) { return recordTable(o) }
+func (FeesOutput) record()                         {}
+
+func cmdFees(ctx context.Context, client ToolboxClient, args []string) (Output, error) {
+	header, err := latestHeader(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	if header.BaseFee == nil {
+		return nil, errors.New("chain has no base fee (pre-London)")
+	}
+	tip, err := client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tip cap: %w", err)
+	}
+	maxFee := new(big.Int).Mul(header.BaseFee, big.NewInt(2))
+	maxFee.Add(maxFee, tip)
+	return FeesOutput{
+		Block:   header.Number.Uint64(),
+		BaseFee: header.BaseFee.String(),
+		TipCap:  tip.String(),
+		MaxFee:  maxFee.String(),
+	}, nil
+}
+
+// latestHeader fetches the head header. A node that answers with neither a
+// header nor an error is reported instead of dereferenced.
+func latestHeader(ctx context.Context, client ToolboxClient) (*types.Header, error) {
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("header: %w", err)
+	}
+	if header == nil || header.Number == nil {
+		return nil, errors.New("header: empty response")
+	}
+	return header, nil
+}
+
+func optional(args []string, i int) string {
+	if i < len(args) {
+		return args[i]
+	}
+	return ""
+}
+
+// parseBlock accepts "", "latest", a decimal number or 0x hex. nil means latest.
+func parseBlock(s string) (*big.Int, error) {
+	if s == "" || s == "latest" {
+		return nil, nil
+	}
+	n, ok := new(big.Int).SetString(s, 0)
+	if !ok || n.Sign() < 0 {
+		return nil, fmt.Errorf("invalid block %q", s)
+	}
+	return n, nil
+}
+
+func blockLabel(n *big.Int) string {
+	if n == nil {
+		return "latest"
+	}
+	return n.String()
+}
+
+func parseAddress(s string) (common.Address, error) {
+	if !common.IsHexAddress(s) {
+		return common.Address{}, fmt.Errorf("invalid address %q", s)
+	}
+	return common.HexToAddress(s), nil
+}
+
+func parseHash(s string) (common.Hash, error) {
+	b, err := hexutil.Decode(s)
+	if err != nil || len(b) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("invalid hash %q", s)
+	}
+	return common.BytesToHash(b), nil
+}
+
+func parseSlot(s string) (common.Hash, error) {
+	n, ok := new(big.Int).SetString(s, 0)
+	if !ok || n.Sign() < 0 || n.BitLen() > 256 {
+		return common.Hash{}, fmt.Errorf("invalid slot %q", s)
+	}
+	return common.BigToHash(n), nil
+}
+
+// parseTopic accepts a topic hash or an event signature.
+func parseTopic(s string) (common.Hash, error) {
+	if strings.HasPrefix(s, "0x") {
+		return parseHash(s)
+	}
+	if !strings.Contains(s, "(") {
+		return common.Hash{}, fmt.Errorf("invalid topic %q", s)
+	}
+	return crypto.Keccak256Hash([]byte(s)), nil
+}
+
+// parseCalldata accepts raw 0x calldata or a no-argument function
+// signature, which is reduced to its 4-byte selector.
+func parseCalldata(s string) ([]byte, error) {
+	if strings.HasPrefix(s, "0x") {
+		data, err := hexutil.Decode(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid calldata: %w", err)
+		}
+		return data, nil
+	}
+	if !strings.HasSuffix(s, "()") {
+		return nil, fmt.Errorf("invalid calldata %q (use 0x data or a signature like name())", s)
+	}
+	return crypto.Keccak256([]byte(s))[:4], nil
+}
+
+// formatEther renders wei as a decimal ether string without trailing zeros.
+func formatEther(wei *big.Int) string {
+	s := new(big.Rat).SetFrac(wei, big.NewInt(1e18)).FloatString(18)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
@@ -1,7 +1,423 @@
 package exercise
 
-import "testing"
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
+)
+
+var (
+	holder   = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	token    = common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	txHash   = common.HexToHash("0x1111111111111111111111111111111111111111111111111111111111111111")
+	transfer = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+)
+
+// mockToolbox implements ToolboxClient with canned answers and records the
+// arguments of the last call so tests can check argument parsing.
+type mockToolbox struct {
+	lastBlock *big.Int
+	lastQuery ethereum.FilterQuery
+	lastCall  ethereum.CallMsg
+	lastSlots []string
+	err       error
+	noHeader  bool            // HeaderByNumber answers nil, nil
+	trace     json.RawMessage // overrides the struct logger trace
+}
+
+func (m *mockToolbox) ChainID(ctx context.Context) (*big.Int, error)   { return big.NewInt(1), m.err }
+func (m *mockToolbox) NetworkID(ctx context.Context) (*big.Int, error) { return big.NewInt(1), nil }
+
+func (m *mockToolbox) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if m.noHeader {
+		return nil, nil
+	}
+	return &types.Header{Number: big.NewInt(19_000_000), BaseFee: big.NewInt(20_000_000_000)}, nil
+}
+
+func (m *mockToolbox) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	m.lastBlock = blockNumber
+	if m.err != nil {
+		return nil, m.err
+	}
+	wei, _ := new(big.Int).SetString("1500000000000000000", 10)
+	return wei, nil
+}
+
+func (m *mockToolbox) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	m.lastBlock = blockNumber
+	return 7, nil
+}
+
+func (m *mockToolbox) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return 9, nil
+}
+
+func (m *mockToolbox) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1_000_000_000), nil
+}
+
+func (m *mockToolbox) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	m.lastCall, m.lastBlock = msg, blockNumber
+	return common.LeftPadBytes([]byte{0x2a}, 32), nil
+}
+
+func (m *mockToolbox) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	m.lastQuery = q
+	return []types.Log{
+		{Address: token, Topics: []common.Hash{transfer, common.BytesToHash(holder.Bytes())}, Data: []byte{1}, BlockNumber: 100, TxHash: txHash, Index: 0},
+		{Address: token, Topics: []common.Hash{transfer}, BlockNumber: 101, TxHash: txHash, Index: 3},
+	}, nil
+}
+
+func (m *mockToolbox) StorageAt(ctx context.Context, contract common.Address, slot common.Hash, blockNumber *big.Int) ([]byte, error) {
+	return slot.Bytes(), nil // echo the slot so the test can check parsing
+}
+
+func (m *mockToolbox) GetProof(ctx context.Context, account common.Address, slots []string, blockNumber *big.Int) (*gethclient.AccountResult, error) {
+	m.lastSlots = slots
+	res := &gethclient.AccountResult{
+		Address:      account,
+		AccountProof: []string{"0x01", "0x02", "0x03"},
+		Balance:      big.NewInt(5),
+		Nonce:        2,
+	}
+	for _, s := range slots {
+		res.StorageProof = append(res.StorageProof, gethclient.StorageResult{Key: s, Value: big.NewInt(42), Proof: []string{"0x04"}})
+	}
+	return res, nil
+}
+
+func (m *mockToolbox) TraceTransaction(ctx context.Context, hash common.Hash) (json.RawMessage, error) {
+	if m.trace != nil {
+		return m.trace, nil
+	}
+	return json.RawMessage(`{"gas":21000,"failed":false,"returnValue":"","structLogs":[{"op":"PUSH1","depth":1},{"op":"STOP","depth":1}]}`), nil
+}
+
+func (m *mockToolbox) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	return &types.Receipt{
+		Status:            types.ReceiptStatusSuccessful,
+		GasUsed:           21000,
+		EffectiveGasPrice: big.NewInt(30_000_000_000),
+		BlockNumber:       big.NewInt(100),
+		Logs:              []*types.Log{{}},
+	}, nil
+}
+
+func (m *mockToolbox) SyncProgress(ctx context.Context) (*ethereum.SyncProgress, error) {
+	return nil, nil
+}
+
+func (m *mockToolbox) PeerCount(ctx context.Context) (uint64, error) { return 12, nil }
+
+func (m *mockToolbox) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	m.lastBlock = number
+	header := &types.Header{Number: number, GasLimit: 30_000_000, GasUsed: 21000, Time: 1700000000, BaseFee: big.NewInt(7)}
+	if number == nil {
+		header.Number = big.NewInt(19_000_000)
+	}
+	return types.NewBlockWithHeader(header), nil
+}
+
+func (m *mockToolbox) TransactionByHash(ctx context.Context, hash string) (*types.Transaction, bool, error) {
+	tx := types.NewTransaction(4, token, big.NewInt(10), 21000, big.NewInt(1), nil)
+	return tx, true, nil
+}
+
+func TestBuiltinCommands(t *testing.T) {
+	reg := DefaultRegistry()
+	cases := []struct {
+		args  []string
+		check func(t *testing.T, m *mockToolbox, out Output)
+	}{
+		{[]string{"status"}, func(t *testing.T, m *mockToolbox, out Output) {
+			o := out.(StatusOutput)
+			if o.ChainID != "1" || o.BlockNumber != 19_000_000 || o.PeerCount != 12 || o.Syncing {
+				t.Fatalf("status %+v", o)
+			}
+		}},
+		{[]string{"block", "0x10"}, func(t *testing.T, m *mockToolbox, out Output) {
+			if o := out.(BlockOutput); o.Number != 16 || o.BaseFee != "7" || m.lastBlock.Int64() != 16 {
+				t.Fatalf("block %+v", o)
+			}
+		}},
+		{[]string{"tx", txHash.Hex()}, func(t *testing.T, m *mockToolbox, out Output) {
+			if o := out.(TxOutput); o.Nonce != 4 || o.To != token.Hex() || !o.Pending {
+				t.Fatalf("tx %+v", o)
+			}
+		}},
+		{[]string{"balance", holder.Hex()}, func(t *testing.T, m *mockToolbox, out Output) {
+			if o := out.(BalanceOutput); o.Ether != "1.5" || o.Block != "latest" || m.lastBlock != nil {
+				t.Fatalf("balance %+v", o)
+			}
+		}},
+		{[]string{"balance", holder.Hex(), "123"}, func(t *testing.T, m *mockToolbox, out Output) {
+			if o := out.(BalanceOutput); o.Block != "123" || m.lastBlock.Int64() != 123 {
+				t.Fatalf("balance at block %+v", o)
+			}
+		}},
+		{[]string{"nonce", holder.Hex(), "pending"}, func(t *testing.T, m *mockToolbox, out Output) {
+			if o := out.(NonceOutput); o.Nonce != 9 || o.Block != "pending" {
+				t.Fatalf("nonce %+v", o)
+			}
+		}},
+		{[]string{"nonce", holder.Hex()}, func(t *testing.T, m *mockToolbox, out Output) {
+			if o := out.(NonceOutput); o.Nonce != 7 {
+				t.Fatalf("nonce %+v", o)
+			}
+		}},
+		{[]string{"call", token.Hex(), "totalSupply()"}, func(t *testing.T, m *mockToolbox, out Output) {
+			o := out.(CallOutput)
+			if o.Data != "0x18160ddd" || *m.lastCall.To != token || !strings.HasSuffix(o.Result, "2a") {
+				t.Fatalf("call %+v", o)
+			}
+		}},
+		{[]string{"logs", token.Hex(), "100", "latest", "Transfer(address,address,uint256)"}, func(t *testing.T, m *mockToolbox, out Output) {
+			o := out.(LogsOutput)
+			q := m.lastQuery
+			if len(o) != 2 || q.FromBlock.Int64() != 100 || q.ToBlock != nil || q.Topics[0][0] != transfer {
+				t.Fatalf("logs %+v query %+v", o, q)
+			}
+		}},
+		{[]string{"storage", token.Hex(), "5"}, func(t *testing.T, m *mockToolbox, out Output) {
+			if o := out.(StorageOutput); o.Value != common.BigToHash(big.NewInt(5)).Hex() {
+				t.Fatalf("storage %+v", o)
+			}
+		}},
+		{[]string{"proof", token.Hex(), "0x2"}, func(t *testing.T, m *mockToolbox, out Output) {
+			o := out.(ProofOutput)
+			if o.AccountNodes != 3 || o.SlotValue != "42" || o.SlotProofNodes != 1 || len(m.lastSlots) != 1 {
+				t.Fatalf("proof %+v", o)
+			}
+		}},
+		{[]string{"proof", token.Hex()}, func(t *testing.T, m *mockToolbox, out Output) {
+			if o := out.(ProofOutput); o.Slot != "" || len(m.lastSlots) != 0 || o.Balance != "5" {
+				t.Fatalf("account-only proof %+v", o)
+			}
+		}},
+		{[]string{"receipt", txHash.Hex()}, func(t *testing.T, m *mockToolbox, out Output) {
+			if o := out.(ReceiptOutput); o.Status != "success" || o.Fee != "630000000000000" || o.Logs != 1 {
+				t.Fatalf("receipt %+v", o)
+			}
+		}},
+		{[]string{"trace", txHash.Hex()}, func(t *testing.T, m *mockToolbox, out Output) {
+			if o := out.(TraceOutput); o.Format != "structLogs" || o.GasUsed != 21000 || o.Calls != 0 || o.Failed {
+				t.Fatalf("trace %+v", o)
+			}
+		}},
+		{[]string{"fees"}, func(t *testing.T, m *mockToolbox, out Output) {
+			if o := out.(FeesOutput); o.MaxFee != "41000000000" {
+				t.Fatalf("fees %+v", o)
+			}
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			m := &mockToolbox{}
+			res, err := reg.Run(context.Background(), m, Config{Command: tc.args[0], Args: tc.args[1:]})
+			if err != nil {
+				t.Fatalf("run: %v", err)
+			}
+			if res.Command != tc.args[0] || res.Status != "success" || res.Format != FormatTable {
+				t.Fatalf("unexpected result %+v", res)
+			}
+			tc.check(t, m, res.Output)
+		})
+	}
+}
+
+func TestTraceCallTracer(t *testing.T) {
+	// A reverted router call, as geth's callTracer reports it: the router
+	// called two contracts, and the second delegated to a third.
+	m := &mockToolbox{trace: json.RawMessage(`{"type":"CALL","from":"0x0000000000000000000000000000000000000001","to":"0x0000000000000000000000000000000000000002","gas":"0x30000","gasUsed":"0x1d4c0",
+		"error":"execution reverted","output":"0x",
+		"calls":[
+			{"type":"STATICCALL","from":"0x0000000000000000000000000000000000000002","to":"0x0000000000000000000000000000000000000003","gas":"0x1000","gasUsed":"0x100"},
+			{"type":"CALL","from":"0x0000000000000000000000000000000000000002","to":"0x0000000000000000000000000000000000000004","gas":"0x2000","gasUsed":"0x200","calls":[
+				{"type":"DELEGATECALL","from":"0x0000000000000000000000000000000000000004","to":"0x0000000000000000000000000000000000000005","gas":"0x1000","gasUsed":"0x100"}
+			]}
+		]}`)}
+	res, err := DefaultRegistry().Run(context.Background(), m, Config{Command: "trace", Args: []string{txHash.Hex()}})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	o := res.Output.(TraceOutput)
+	if o.Format != "callTracer" || o.GasUsed != 120000 || !o.Failed || o.Calls != 3 || o.MaxDepth != 2 {
+		t.Fatalf("trace %+v", o)
+	}
+}
+
+func TestRegistryErrors(t *testing.T) {
+	reg := DefaultRegistry()
+	cases := []struct {
+		cfg  Config
+		want string
+	}{
+		{Config{}, "command is required"},
+		{Config{Command: "bogus"}, "valid: balance, block, call, fees"},
+		{Config{Command: "balance"}, "usage: balance <address> [block]"},
+		{Config{Command: "fees", Args: []string{"extra"}}, "usage: fees"},
+		{Config{Command: "balance", Args: []string{"0x12"}}, "invalid address"},
+		{Config{Command: "block", Args: []string{"-1"}}, "invalid block"},
+		{Config{Command: "call", Args: []string{token.Hex(), "balanceOf"}}, "invalid calldata"},
+		{Config{Command: "status", Output: "xml"}, "unknown output format"},
+	}
+	for _, tc := range cases {
+		_, err := reg.Run(context.Background(), &mockToolbox{}, tc.cfg)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%+v: got %v, want %q", tc.cfg, err, tc.want)
+		}
+	}
+
+	for _, tc := range []struct {
+		m    *mockToolbox
+		cfg  Config
+		want string
+	}{
+		{&mockToolbox{noHeader: true}, Config{Command: "status"}, "header: empty response"},
+		{&mockToolbox{noHeader: true}, Config{Command: "fees"}, "header: empty response"},
+		{&mockToolbox{trace: json.RawMessage(`{"0x01":{"balance":"0x0"}}`)}, Config{Command: "trace", Args: []string{txHash.Hex()}}, "unsupported tracer output"},
+	} {
+		_, err := reg.Run(context.Background(), tc.m, tc.cfg)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%+v: got %v, want %q", tc.cfg, err, tc.want)
+		}
+	}
+
+	_, err := reg.Run(context.Background(), &mockToolbox{err: errors.New("boom")}, Config{Command: "balance", Args: []string{holder.Hex()}})
+	if err == nil || err.Error() != "balance: balance: boom" {
+		t.Fatalf("handler errors should be wrapped with the command name, got %v", err)
+	}
+}
+
+func TestRegistryRegister(t *testing.T) {
+	reg := NewRegistry()
+	handler := func(ctx context.Context, client ToolboxClient, args []string) (Output, error) {
+		return NonceOutput{Address: args[0]}, nil
+	}
+	if err := reg.Register(Command{Name: "echo", Args: []Arg{{Name: "x"}}, Handler: handler}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if err := reg.Register(Command{Name: "echo", Handler: handler}); err == nil {
+		t.Fatalf("expected duplicate error")
+	}
+	if err := reg.Register(Command{Name: "bad", Args: []Arg{{Name: "a", Optional: true}, {Name: "b"}}, Handler: handler}); err == nil {
+		t.Fatalf("expected ordering error")
+	}
+	res, err := reg.Run(context.Background(), &mockToolbox{}, Config{Command: "echo", Args: []string{"hi"}})
+	if err != nil || res.Output.(NonceOutput).Address != "hi" {
+		t.Fatalf("custom command: %v %+v", err, res)
+	}
+}
+
+func TestRender(t *testing.T) {
+	reg := DefaultRegistry()
+	run := func(format Format, args ...string) string {
+		t.Helper()
+		res, err := reg.Run(context.Background(), &mockToolbox{}, Config{Command: args[0], Args: args[1:], Output: format})
+		if err != nil {
+			t.Fatalf("run: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := Render(&buf, res); err != nil {
+			t.Fatalf("render: %v", err)
+		}
+		return buf.String()
+	}
+
+	table := run(FormatTable, "balance", holder.Hex())
+	if !strings.Contains(table, "ether    1.5\n") || !strings.HasPrefix(table, "address  "+holder.Hex()) {
+		t.Fatalf("record table:\n%s", table)
+	}
+
+	logsTable := run(FormatTable, "logs", token.Hex(), "100", "200")
+	lines := strings.Split(strings.TrimSpace(logsTable), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "BLOCKNUMBER") || !strings.HasPrefix(lines[2], "101") {
+		t.Fatalf("list table:\n%s", logsTable)
+	}
+
+	var balance BalanceOutput
+	if err := json.Unmarshal([]byte(run(FormatJSON, "balance", holder.Hex())), &balance); err != nil || balance.Wei != "1500000000000000000" {
+		t.Fatalf("json: %v %+v", err, balance)
+	}
+	var logs []LogEntry
+	if err := json.Unmarshal([]byte(run(FormatJSON, "logs", token.Hex(), "100", "200")), &logs); err != nil || len(logs[0].Topics) != 2 {
+		t.Fatalf("json logs: %v %+v", err, logs)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(run(FormatCSV, "logs", token.Hex(), "100", "200"))).ReadAll()
+	if err != nil || len(records) != 3 || records[0][0] != "blockNumber" || records[1][4] != transfer.Hex()+" "+common.BytesToHash(holder.Bytes()).Hex() {
+		t.Fatalf("csv: %v %q", err, records)
+	}
+	records, err = csv.NewReader(strings.NewReader(run(FormatCSV, "fees"))).ReadAll()
+	if err != nil || len(records) != 2 || records[0][3] != "maxFee" {
+		t.Fatalf("csv record: %v %q", err, records)
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	cases := []struct {
+		argv   []string
+		cmd    string
+		args   int
+		format Format
+	}{
+		{[]string{"status"}, "status", 0, ""},
+		{[]string{"--output", "json", "balance", "0xaa"}, "balance", 1, FormatJSON},
+		{[]string{"logs", "0xbb", "1", "2", "-o", "csv"}, "logs", 3, FormatCSV},
+		{[]string{"--output=CSV", "fees"}, "fees", 0, FormatCSV},
+	}
+	for _, tc := range cases {
+		cfg, err := ParseArgs(tc.argv)
+		if err != nil || cfg.Command != tc.cmd || len(cfg.Args) != tc.args || cfg.Output != tc.format {
+			t.Fatalf("%v: %v %+v", tc.argv, err, cfg)
+		}
+	}
+	if _, err := ParseArgs([]string{"status", "--output"}); err == nil {
+		t.Fatalf("expected missing value error")
+	}
+	if _, err := ParseArgs([]string{"-o", "yaml", "status"}); err == nil {
+		t.Fatalf("expected bad format error")
+	}
+}
+
+func TestHelpListsEveryCommand(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Main(context.Background(), &mockToolbox{}, []string{"help"}, &buf); err != nil {
+		t.Fatalf("help: %v", err)
+	}
+	for _, name := range DefaultRegistry().Names() {
+		if !strings.Contains(buf.String(), "\n  "+name) {
+			t.Fatalf("help is missing %q:\n%s", name, buf.String())
+		}
+	}
+	if !strings.Contains(buf.String(), "logs <address> <from> <to> [topic0]") {
+		t.Fatalf("help should print synopses:\n%s", buf.String())
+	}
+}
 
 func TestRun(t *testing.T) {
-	t.Skip("add tests for this module")
+	if _, err := Run(context.Background(), nil, Config{Command: "status"}); err == nil {
+		t.Fatalf("expected nil client error")
+	}
+	var buf bytes.Buffer
+	if err := Main(context.Background(), &mockToolbox{}, []string{"fees", "--output", "json"}, &buf); err != nil {
+		t.Fatalf("Main: %v", err)
+	}
+	var fees FeesOutput
+	if err := json.Unmarshal(buf.Bytes(), &fees); err != nil || fees.BaseFee != "20000000000" {
+		t.Fatalf("unexpected output %v %s", err, buf.String())
+	}
 }
@@ -0,0 +1,100 @@
+package exercise
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// ParseFormat validates an --output value. The empty string means table.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case "":
+		return FormatTable, nil
+	case FormatTable, FormatJSON, FormatCSV:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown output format %q (valid: table, json, csv)", s)
+	}
+}
+
+// Render writes res.Output in res.Format.
+//
+//   - table: single records print as FIELD/VALUE pairs, lists as aligned columns
+//   - json:  the typed output value, indented
+//   - csv:   header line followed by one line per row
+func Render(w io.Writer, res *Result) error {
+	if res == nil || res.Output == nil {
+		return errors.New("nothing to render")
+	}
+	format, err := ParseFormat(string(res.Format))
+	if err != nil {
+		return err
+	}
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(res.Output)
+	case FormatCSV:
+		header, rows := res.Output.Table()
+		cw := csv.NewWriter(w)
+		cw.Write(header)
+		cw.WriteAll(rows)
+		return cw.Error()
+	default:
+		return renderTable(w, res.Output)
+	}
+}
+
+func renderTable(w io.Writer, out Output) error {
+	header, rows := out.Table()
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	if _, single := out.(record); single && len(rows) == 1 {
+		for i, name := range header {
+			fmt.Fprintf(tw, "%s\t%s\n", name, rows[0][i])
+		}
+		return tw.Flush()
+	}
+	upper := make([]string, len(header))
+	for i, h := range header {
+		upper[i] = strings.ToUpper(h)
+	}
+	fmt.Fprintln(tw, strings.Join(upper, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// record marks outputs that describe a single object. They are printed
+// vertically in table mode.
+type record interface {
+	Output
+	record()
+}
+
+// recordTable flattens a struct into a one-row table using the json field
+// names as column headers.
+func recordTable(v any) ([]string, [][]string) {
+	rv := reflect.ValueOf(v)
+	rt := rv.Type()
+	var header, row []string
+	for i := 0; i < rt.NumField(); i++ {
+		name := strings.Split(rt.Field(i).Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = rt.Field(i).Name
+		}
+		header = append(header, name)
+		row = append(row, fmt.Sprint(rv.Field(i).Interface()))
+	}
+	return header, [][]string{row}
+}
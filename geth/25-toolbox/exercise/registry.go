@@ -0,0 +1,156 @@
+package exercise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Arg describes one positional argument of a command.
+type Arg struct {
+	Name     string
+	Help     string
+	Optional bool // optional args must come after required ones
+}
+
+// Command is one toolbox subcommand. Handler receives arguments that have
+// already been checked against Args.
+type Command struct {
+	Name    string
+	Summary string
+	Args    []Arg
+	Handler func(ctx context.Context, client ToolboxClient, args []string) (Output, error)
+}
+
+// Usage returns the one-line synopsis, e.g. "balance <address> [block]".
+func (c Command) Usage() string {
+	parts := []string{c.Name}
+	for _, a := range c.Args {
+		if a.Optional {
+			parts = append(parts, "["+a.Name+"]")
+		} else {
+			parts = append(parts, "<"+a.Name+">")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func (c Command) checkArgs(args []string) error {
+	required := 0
+	for _, a := range c.Args {
+		if !a.Optional {
+			required++
+		}
+	}
+	if len(args) < required || len(args) > len(c.Args) {
+		return fmt.Errorf("usage: %s", c.Usage())
+	}
+	return nil
+}
+
+// Registry maps command names to commands.
+type Registry struct {
+	commands map[string]Command
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{commands: make(map[string]Command)}
+}
+
+// DefaultRegistry returns a registry holding every built-in command.
+func DefaultRegistry() *Registry {
+	r := NewRegistry()
+	for _, c := range builtinCommands() {
+		if err := r.Register(c); err != nil {
+			panic(err) // built-in table is static; a failure is a programming error
+		}
+	}
+	return r
+}
+
+// Register adds a command. Names must be unique and optional args must
+// follow required ones.
+func (r *Registry) Register(c Command) error {
+	if c.Name == "" || c.Handler == nil {
+		return errors.New("command needs a name and a handler")
+	}
+	if _, ok := r.commands[c.Name]; ok {
+		return fmt.Errorf("command %q already registered", c.Name)
+	}
+	optional := false
+	for _, a := range c.Args {
+		if optional && !a.Optional {
+			return fmt.Errorf("command %q: required arg %q follows an optional one", c.Name, a.Name)
+		}
+		optional = optional || a.Optional
+	}
+	r.commands[c.Name] = c
+	return nil
+}
+
+// Lookup returns the command registered under name.
+func (r *Registry) Lookup(name string) (Command, bool) {
+	c, ok := r.commands[name]
+	return c, ok
+}
+
+// Commands returns all commands sorted by name.
+func (r *Registry) Commands() []Command {
+	out := make([]Command, 0, len(r.commands))
+	for _, c := range r.commands {
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Names returns the sorted command names.
+func (r *Registry) Names() []string {
+	var names []string
+	for _, c := range r.Commands() {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+// Run validates cfg, dispatches to the named command and wraps its output.
+func (r *Registry) Run(ctx context.Context, client ToolboxClient, cfg Config) (*Result, error) {
+	if cfg.Command == "" {
+		return nil, errors.New("command is required")
+	}
+	format, err := ParseFormat(string(cfg.Output))
+	if err != nil {
+		return nil, err
+	}
+	cmd, ok := r.Lookup(cfg.Command)
+	if !ok {
+		return nil, fmt.Errorf("unknown command: %s (valid: %s)", cfg.Command, strings.Join(r.Names(), ", "))
+	}
+	if err := cmd.checkArgs(cfg.Args); err != nil {
+		return nil, err
+	}
+	out, err := cmd.Handler(ctx, client, cfg.Args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", cmd.Name, err)
+	}
+	return &Result{Command: cmd.Name, Output: out, Status: "success", Format: format}, nil
+}
+
+// WriteHelp prints every command with its synopsis, summary and args.
+func (r *Registry) WriteHelp(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "usage: toolbox [--output table|json|csv] <command> [args]")
+	fmt.Fprintln(tw)
+	for _, c := range r.Commands() {
+		fmt.Fprintf(tw, "  %s\t%s\n", c.Usage(), c.Summary)
+		for _, a := range c.Args {
+			fmt.Fprintf(tw, "  \t  %s: %s\n", a.Name, a.Help)
+		}
+	}
+	return tw.Flush()
+}
@@ -2,10 +2,13 @@ package exercise
 
 import (
 	"context"
+	"encoding/json"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
 )
 
 // ToolboxClient captures all the ethclient calls needed for the toolbox (combines all previous modules).
@@ -15,6 +18,34 @@ type ToolboxClient interface {
 	NetworkID(ctx context.Context) (*big.Int, error)
 	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
 
+	// From module 04: balances
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+
+	// From module 05: nonces
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+
+	// From module 06: fee suggestions
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+
+	// From module 07: read-only calls
+	CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+
+	// From module 09: logs
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
+
+	// From module 11: raw storage
+	StorageAt(ctx context.Context, contract common.Address, slot common.Hash, blockNumber *big.Int) ([]byte, error)
+
+	// From module 12: Merkle proofs
+	GetProof(ctx context.Context, account common.Address, slots []string, blockNumber *big.Int) (*gethclient.AccountResult, error)
+
+	// From module 13: execution traces
+	TraceTransaction(ctx context.Context, txHash common.Hash) (json.RawMessage, error)
+
+	// From module 15: receipts
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+
 	// From module 21: sync status
 	SyncProgress(ctx context.Context) (*ethereum.SyncProgress, error)
 
@@ -26,20 +57,39 @@ type ToolboxClient interface {
 	TransactionByHash(ctx context.Context, hash string) (*types.Transaction, bool, error)
 }
 
+// Format selects how a Result is rendered.
+type Format string
+
+const (
+	FormatTable Format = "table"
+	FormatJSON  Format = "json"
+	FormatCSV   Format = "csv"
+)
+
 // Config allows configuration for different toolbox commands.
 type Config struct {
 	// Command specifies which operation to perform (status, block, tx, etc.)
 	Command string
 	// Args contains command-specific arguments
 	Args []string
+	// Output selects the rendering format (default table)
+	Output Format
+}
+
+// Output is the typed result of a command. Table returns a header and rows
+// for the table and CSV renderers; JSON encodes the value itself.
+type Output interface {
+	Table() (header []string, rows [][]string)
 }
 
 // Result contains the output from a toolbox command.
 type Result struct {
 	// Command that was executed
 	Command string
-	// Output contains the result data (structure varies by command)
-	Output interface{}
+	// Output contains the typed result data (concrete type varies by command)
+	Output Output
 	// Status indicates success/failure
 	Status string
+	// Format is the rendering format requested in Config.Output
+	Format Format
 }